@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/spf13/cobra"
+)
+
+// GithubAppCmd creates GitHub Apps for Atlantis to authenticate as.
+type GithubAppCmd struct {
+	// ExchangeCode exchanges the code GitHub redirects to us with for the
+	// new app's configuration. If nil, vcs.ExchangeGithubAppManifestCode is
+	// used.
+	ExchangeCode func(hostname string, code string) (*vcs.GithubAppConfig, error)
+
+	hostname    string
+	atlantisURL string
+	org         string
+	name        string
+	keyFile     string
+	port        int
+}
+
+// Init returns the runnable cobra command.
+func (g *GithubAppCmd) Init() *cobra.Command {
+	appCmd := &cobra.Command{
+		Use:   "github-app",
+		Short: "Manage the GitHub App Atlantis authenticates as",
+	}
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a GitHub App for Atlantis from a manifest",
+		Long: "Create a GitHub App for Atlantis from a manifest. This starts a local server that redirects your browser" +
+			" to GitHub to create the app and then saves the app's private key and prints the flags to run Atlantis with.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return g.create()
+		},
+		SilenceUsage: true,
+	}
+	createCmd.Flags().StringVar(&g.hostname, GHHostnameFlag, DefaultGHHostname, "Hostname of your Github Enterprise installation. If using github.com, no need to set.")
+	createCmd.Flags().StringVar(&g.atlantisURL, AtlantisURLFlag, "", "URL that Atlantis can be reached at. GitHub sends webhooks to <url>/events.")
+	createCmd.Flags().StringVar(&g.org, "org", "", "Organization to create the app in. If not set, the app is created for your user.")
+	createCmd.Flags().StringVar(&g.name, "name", "atlantis", "Name of the app.")
+	createCmd.Flags().StringVar(&g.keyFile, "key-file", "atlantis-app.pem", "Path to write the app's private key to.")
+	createCmd.Flags().IntVar(&g.port, PortFlag, DefaultPort, "Port to run the local server on.")
+	createCmd.MarkFlagRequired(AtlantisURLFlag) // nolint: errcheck
+	appCmd.AddCommand(createCmd)
+	return appCmd
+}
+
+func (g *GithubAppCmd) create() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", g.port))
+	if err != nil {
+		return errors.Wrap(err, "starting local server")
+	}
+	// state is sent to GitHub and must be returned to the callback so other
+	// sites can't make us exchange a code for an app they created.
+	stateBytes := make([]byte, 16)
+	if _, err := rand.Read(stateBytes); err != nil {
+		listener.Close() // nolint: errcheck
+		return errors.Wrap(err, "generating state")
+	}
+	done := make(chan error, 1)
+	server := &http.Server{Handler: g.handler(fmt.Sprintf("http://%s", listener.Addr()), hex.EncodeToString(stateBytes), done)}
+	go server.Serve(listener) // nolint: errcheck
+
+	fmt.Printf("Open http://%s in your browser to create the GitHub App.\n", listener.Addr())
+	err = <-done
+	server.Shutdown(context.Background()) // nolint: errcheck
+	return err
+}
+
+// handler returns the handler for the local server at localURL. state is
+// passed to GitHub and callbacks without it are rejected. It sends the result
+// of creating the app on done.
+func (g *GithubAppCmd) handler(localURL string, state string, done chan<- error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		manifest, err := g.manifest(localURL + "/callback")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		newAppURL := fmt.Sprintf("https://%s/settings/apps/new", g.hostname)
+		if g.org != "" {
+			newAppURL = fmt.Sprintf("https://%s/organizations/%s/settings/apps/new", g.hostname, g.org)
+		}
+		newAppURL += "?state=" + url.QueryEscape(state)
+		githubAppFormTemplate.Execute(w, map[string]string{ // nolint: errcheck
+			"URL":      newAppURL,
+			"Manifest": manifest,
+		})
+	})
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("state")), []byte(state)) != 1 {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		code := r.URL.Query().Get("code")
+		if code == "" {
+			http.Error(w, "missing code", http.StatusBadRequest)
+			return
+		}
+		app, err := g.exchangeCode(code)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			done <- err
+			return
+		}
+		fmt.Fprintf(w, "Created GitHub App %s. You can close this window.\n", app.Slug)
+		done <- nil
+	})
+	return mux
+}
+
+// exchangeCode gets the new app's configuration from GitHub, writes its
+// private key to keyFile and prints the flags to run Atlantis with.
+func (g *GithubAppCmd) exchangeCode(code string) (*vcs.GithubAppConfig, error) {
+	exchange := g.ExchangeCode
+	if exchange == nil {
+		exchange = vcs.ExchangeGithubAppManifestCode
+	}
+	app, err := exchange(g.hostname, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(g.keyFile, []byte(app.PEM), 0600); err != nil {
+		return nil, errors.Wrapf(err, "writing private key to %s", g.keyFile)
+	}
+
+	fmt.Printf("Created GitHub App %s: %s\n", app.Slug, app.HTMLURL)
+	fmt.Printf("Wrote its private key to %s. Install the app on your repos and then run:\n\n", g.keyFile)
+	flags := []string{
+		fmt.Sprintf("--%s=%d", GHAppIDFlag, app.ID),
+		fmt.Sprintf("--%s=%s", GHAppKeyFileFlag, g.keyFile),
+		fmt.Sprintf("--%s=%s", GHAppSlugFlag, app.Slug),
+		fmt.Sprintf("--%s=%s", GHWebhookSecretFlag, app.WebhookSecret),
+	}
+	if g.hostname != DefaultGHHostname {
+		flags = append(flags, fmt.Sprintf("--%s=%s", GHHostnameFlag, g.hostname))
+	}
+	fmt.Printf("  atlantis server %s\n", strings.Join(flags, " "))
+	return app, nil
+}
+
+// manifest returns the JSON manifest for the app. GitHub redirects to
+// redirectURL once the app is created.
+// See https://developer.github.com/apps/building-github-apps/creating-github-apps-from-a-manifest/.
+func (g *GithubAppCmd) manifest(redirectURL string) (string, error) {
+	atlantisURL := strings.TrimSuffix(g.atlantisURL, "/")
+	manifest := map[string]interface{}{
+		"name":         g.name,
+		"url":          atlantisURL,
+		"redirect_url": redirectURL,
+		"public":       false,
+		"hook_attributes": map[string]string{
+			"url": atlantisURL + "/events",
+		},
+		"default_permissions": map[string]string{
+			"checks":        "write",
+			"contents":      "write",
+			"issues":        "write",
+			"members":       "read",
+			"pull_requests": "write",
+			"statuses":      "write",
+		},
+		"default_events": []string{
+			"check_run",
+			"issue_comment",
+			"pull_request",
+			"pull_request_review",
+			"push",
+		},
+	}
+	out, err := json.Marshal(manifest)
+	return string(out), err
+}
+
+var githubAppFormTemplate = template.Must(template.New("").Parse(`<html>
+<body onload="document.forms[0].submit()">
+<form action="{{ .URL }}" method="post">
+<input type="hidden" name="manifest" value="{{ .Manifest }}">
+<noscript><input type="submit" value="Create GitHub App"></noscript>
+</form>
+</body>
+</html>
+`))
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"html"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGithubAppCmd_Form(t *testing.T) {
+	g := &GithubAppCmd{
+		hostname:    "github.example.com",
+		atlantisURL: "https://atlantis.example.com/",
+		org:         "runatlantis",
+		name:        "atlantis",
+	}
+	w := httptest.NewRecorder()
+	g.handler("http://localhost:4141", "state", make(chan error, 1)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	Equals(t, http.StatusOK, w.Code)
+
+	body := html.UnescapeString(w.Body.String())
+	for _, exp := range []string{
+		`action="https://github.example.com/organizations/runatlantis/settings/apps/new?state=state"`,
+		`"hook_attributes":{"url":"https://atlantis.example.com/events"}`,
+		`"redirect_url":"http://localhost:4141/callback"`,
+	} {
+		Assert(t, strings.Contains(body, exp), "expected form to contain %q but was %q", exp, body)
+	}
+}
+
+func TestGithubAppCmd_Callback(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	keyFile := filepath.Join(tmp, "key.pem")
+
+	var gotCode string
+	g := &GithubAppCmd{
+		ExchangeCode: func(hostname string, code string) (*vcs.GithubAppConfig, error) {
+			gotCode = code
+			return &vcs.GithubAppConfig{ID: 1, Slug: "atlantis", PEM: "key"}, nil
+		},
+		hostname: DefaultGHHostname,
+		keyFile:  keyFile,
+	}
+	done := make(chan error, 1)
+	w := httptest.NewRecorder()
+	g.handler("http://localhost:4141", "state", done).ServeHTTP(w, httptest.NewRequest("GET", "/callback?code=abc&state=state", nil))
+	Equals(t, http.StatusOK, w.Code)
+	Ok(t, <-done)
+	Equals(t, "abc", gotCode)
+
+	key, err := ioutil.ReadFile(keyFile)
+	Ok(t, err)
+	Equals(t, "key", string(key))
+}
+
+// Callbacks without the state we sent to GitHub should be rejected.
+func TestGithubAppCmd_CallbackInvalidState(t *testing.T) {
+	g := &GithubAppCmd{
+		ExchangeCode: func(hostname string, code string) (*vcs.GithubAppConfig, error) {
+			t.Error("code shouldn't be exchanged")
+			return nil, nil
+		},
+		hostname: DefaultGHHostname,
+	}
+	for _, query := range []string{"code=abc", "code=abc&state=other"} {
+		w := httptest.NewRecorder()
+		g.handler("http://localhost:4141", "state", make(chan error, 1)).ServeHTTP(w, httptest.NewRequest("GET", "/callback?"+query, nil))
+		Equals(t, http.StatusBadRequest, w.Code)
+	}
+}
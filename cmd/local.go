@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/local"
+	"github.com/runatlantis/atlantis/server/logging"
+	"github.com/spf13/cobra"
+)
+
+// Output formats for local plan.
+const (
+	markdownOutput = "markdown"
+	jsonOutput     = "json"
+)
+
+// LocalCmd runs Atlantis's workflow on a local checkout without a VCS host.
+type LocalCmd struct {
+	// NewRunner creates the runner that plans the checkout. If nil,
+	// local.NewRunner is used.
+	NewRunner func(config local.Config, logger *logging.SimpleLogger) (*local.Runner, error)
+	// Out is where results are written. If nil, os.Stdout is used.
+	Out io.Writer
+
+	config   local.Config
+	baseRef  string
+	repoID   string
+	labels   []string
+	output   string
+	logLevel string
+}
+
+// Init returns the runnable cobra command.
+func (l *LocalCmd) Init() *cobra.Command {
+	localCmd := &cobra.Command{
+		Use:   "local",
+		Short: "Run Atlantis on a local checkout without a VCS host",
+	}
+	planCmd := &cobra.Command{
+		Use:   "plan [repo dir]",
+		Short: "Plan the projects Atlantis would autoplan for a local checkout",
+		Long: "Plan the projects Atlantis would autoplan if the checkout was pushed and a pull request was opened for it." +
+			" Files changed since the checkout diverged from --base-ref, including uncommitted and untracked files, are" +
+			" modified. Projects are planned in the checkout with the same config and workflows as the server.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoDir := "."
+			if len(args) > 0 {
+				repoDir = args[0]
+			}
+			return l.plan(repoDir)
+		},
+		SilenceUsage: true,
+	}
+	planCmd.Flags().StringVar(&l.baseRef, "base-ref", "", "Git ref the changes would be merged into, ex. main.")
+	planCmd.Flags().StringVar(&l.repoID, "repo", "", "Repo as Atlantis sees it, ex. github.com/owner/repo. It's matched against the repo config's ids. Defaults to the repo of the origin remote.")
+	planCmd.Flags().StringSliceVar(&l.labels, "label", nil, "Label of the pull request. Can be set multiple times.")
+	planCmd.Flags().StringVar(&l.output, "output", markdownOutput, "Output format. One of markdown or json.")
+	planCmd.Flags().StringVar(&l.logLevel, LogLevelFlag, "warn", "Log level. Logs are written to stderr. Either debug, info, warn, or error.")
+	planCmd.Flags().StringVar(&l.config.DataDir, DataDirFlag, DefaultDataDir, "Path to directory where Terraform binaries are downloaded to.")
+	planCmd.Flags().StringVar(&l.config.DefaultTFVersion, DefaultTFVersionFlag, "", "Terraform version to default to (ex. v0.12.0). Defaults to the version of terraform in the PATH.")
+	planCmd.Flags().StringVar(&l.config.TFDownloadURL, TFDownloadURLFlag, DefaultTFDownloadURL, "Base URL to download Terraform versions from.")
+	planCmd.Flags().StringVar(&l.config.RepoConfig, RepoConfigFlag, "", "Path to the server's repo config file.")
+	planCmd.Flags().StringVar(&l.config.RepoConfigJSON, RepoConfigJSONFlag, "", "The server's repo config as JSON, if not using --"+RepoConfigFlag+".")
+	planCmd.Flags().BoolVar(&l.config.AllowRepoConfig, AllowRepoConfigFlag, false, "Set if the server is run with --"+AllowRepoConfigFlag+".")
+	planCmd.Flags().BoolVar(&l.config.RequireMergeable, RequireMergeableFlag, false, "Set if the server is run with --"+RequireMergeableFlag+".")
+	planCmd.Flags().BoolVar(&l.config.RequireApproval, RequireApprovalFlag, false, "Set if the server is run with --"+RequireApprovalFlag+".")
+	planCmd.Flags().StringVar(&l.config.RedactEnvVars, RedactEnvVarsFlag, "", "Comma separated list of environment variable names whose values are redacted from the output, ex. AWS_SECRET_ACCESS_KEY.")
+	// Patterns can contain commas, ex. {3,8}, so they aren't split on them.
+	planCmd.Flags().StringArrayVar(&l.config.RedactPatterns, "redact-patterns", nil, "Regex for secrets to redact from the output, in addition to common credential formats. Can be set multiple times.")
+	localCmd.AddCommand(planCmd)
+	return localCmd
+}
+
+func (l *LocalCmd) plan(repoDir string) error {
+	if l.baseRef == "" {
+		return errors.New("--base-ref must be set")
+	}
+	if l.output != markdownOutput && l.output != jsonOutput {
+		return fmt.Errorf("invalid --output %q, must be one of %s or %s", l.output, markdownOutput, jsonOutput)
+	}
+	if l.config.RepoConfig != "" && l.config.RepoConfigJSON != "" {
+		return fmt.Errorf("cannot use --%s and --%s at the same time", RepoConfigFlag, RepoConfigJSONFlag)
+	}
+	dataDir, err := homedir.Expand(l.config.DataDir)
+	if err != nil {
+		return errors.Wrap(err, "determining home directory")
+	}
+	l.config.DataDir, err = filepath.Abs(dataDir)
+	if err != nil {
+		return errors.Wrapf(err, "making %s absolute", DataDirFlag)
+	}
+	l.config.DefaultTFVersionFlag = DefaultTFVersionFlag
+
+	newRunner := l.NewRunner
+	if newRunner == nil {
+		newRunner = local.NewRunner
+	}
+	logger := logging.NewSimpleLogger("local", false, l.toLogLevel())
+	runner, err := newRunner(l.config, logger)
+	if err != nil {
+		return err
+	}
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: l.baseRef,
+		RepoID:  l.repoID,
+		Labels:  l.labels,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := l.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if l.output == jsonOutput {
+		resJSON, err := res.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(resJSON)) // nolint: errcheck
+	} else {
+		fmt.Fprintln(out, res.Markdown()) // nolint: errcheck
+	}
+	if res.Result.HasErrors() {
+		return errors.New("plan failed")
+	}
+	return nil
+}
+
+func (l *LocalCmd) toLogLevel() logging.LogLevel {
+	switch strings.ToLower(l.logLevel) {
+	case "debug":
+		return logging.Debug
+	case "info":
+		return logging.Info
+	case "error":
+		return logging.Error
+	}
+	return logging.Warn
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/local"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestLocalCmd_InvalidOutput(t *testing.T) {
+	err := runLocalCmd(&LocalCmd{}, "plan", "--base-ref=main", "--output=yaml")
+	ErrEquals(t, `invalid --output "yaml", must be one of markdown or json`, err)
+}
+
+func TestLocalCmd_RepoConfigAndJSON(t *testing.T) {
+	err := runLocalCmd(&LocalCmd{}, "plan", "--base-ref=main", "--repo-config=repos.yaml", "--repo-config-json={}")
+	ErrEquals(t, "cannot use --repo-config and --repo-config-json at the same time", err)
+}
+
+func TestLocalCmd_BaseRefRequired(t *testing.T) {
+	err := runLocalCmd(&LocalCmd{}, "plan")
+	ErrEquals(t, "--base-ref must be set", err)
+}
+
+func TestLocalCmd_Config(t *testing.T) {
+	var gotConfig local.Config
+	l := &LocalCmd{
+		NewRunner: func(config local.Config, logger *logging.SimpleLogger) (*local.Runner, error) {
+			gotConfig = config
+			return nil, errors.New("err")
+		},
+	}
+	err := runLocalCmd(l, "plan", "--base-ref=main", "--default-tf-version=0.12.0", "--repo-config=repos.yaml",
+		"--allow-repo-config", "--require-mergeable", "--require-approval", "--redact-env-vars=SECRET,TOKEN", "--redact-patterns=key=(\\S+)", "--redact-patterns=[a-z]{3,8}")
+	ErrEquals(t, "err", err)
+
+	dataDir, err := homedir.Expand(DefaultDataDir)
+	Ok(t, err)
+	Equals(t, local.Config{
+		DataDir:              dataDir,
+		DefaultTFVersion:     "0.12.0",
+		DefaultTFVersionFlag: DefaultTFVersionFlag,
+		RepoConfig:           "repos.yaml",
+		TFDownloadURL:        DefaultTFDownloadURL,
+		AllowRepoConfig:      true,
+		RequireMergeable:     true,
+		RequireApproval:      true,
+		RedactEnvVars:        "SECRET,TOKEN",
+		RedactPatterns:       []string{`key=(\S+)`, `[a-z]{3,8}`},
+	}, gotConfig)
+}
+
+func TestLocalCmd_JSON(t *testing.T) {
+	repoDir, cleanup := TempDir(t)
+	defer cleanup()
+	for _, args := range [][]string{
+		{"init"},
+		{"checkout", "-b", "main"},
+		{"config", "--local", "user.email", "atlantisbot@runatlantis.io"},
+		{"config", "--local", "user.name", "atlantisbot"},
+		{"commit", "--allow-empty", "-m", "initial commit"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repoDir}, args...)...).CombinedOutput()
+		Assert(t, err == nil, "running git %s: %s", strings.Join(args, " "), out)
+	}
+
+	var out bytes.Buffer
+	l := &LocalCmd{
+		NewRunner: func(config local.Config, logger *logging.SimpleLogger) (*local.Runner, error) {
+			return &local.Runner{
+				GlobalCfg: valid.NewGlobalCfg(false, false, false),
+				Logger:    logging.NewNoopLogger(),
+			}, nil
+		},
+		Out: &out,
+	}
+	err := runLocalCmd(l, "plan", filepath.Join(repoDir, "."), "--base-ref=main", "--repo=github.com/owner/repo", "--output=json")
+	Ok(t, err)
+
+	var res local.PlanJSON
+	Ok(t, json.Unmarshal(out.Bytes(), &res))
+	Equals(t, "github.com/owner/repo", res.Repo)
+	Equals(t, "main", res.BaseRef)
+	Equals(t, []string{}, res.ModifiedFiles)
+	Equals(t, []local.ProjectJSON{}, res.Projects)
+}
+
+func runLocalCmd(l *LocalCmd, args ...string) error {
+	c := l.Init()
+	c.SetArgs(args)
+	c.SetOutput(&bytes.Buffer{})
+	return c.Execute()
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/spf13/cobra"
@@ -54,10 +55,17 @@ const (
 	DefaultTFVersionFlag       = "default-tf-version"
 	DisableApplyAllFlag        = "disable-apply-all"
 	DisableMarkdownFoldingFlag = "disable-markdown-folding"
+	GHAppIDFlag                = "gh-app-id"
+	GHAppKeyFileFlag           = "gh-app-key-file"
+	GHAppSlugFlag              = "gh-app-slug"
 	GHHostnameFlag             = "gh-hostname"
 	GHTokenFlag                = "gh-token"
 	GHUserFlag                 = "gh-user"
 	GHWebhookSecretFlag        = "gh-webhook-secret" // nolint: gosec
+	GiteaBaseURLFlag           = "gitea-base-url"
+	GiteaTokenFlag             = "gitea-token" // nolint: gosec
+	GiteaUserFlag              = "gitea-user"
+	GiteaWebhookSecretFlag     = "gitea-webhook-secret" // nolint: gosec
 	GitlabHostnameFlag         = "gitlab-hostname"
 	GitlabTokenFlag            = "gitlab-token"
 	GitlabUserFlag             = "gitlab-user"
@@ -65,6 +73,8 @@ const (
 	HidePrevPlanComments       = "hide-prev-plan-comments"
 	LogLevelFlag               = "log-level"
 	PortFlag                   = "port"
+	ProjectStatusesFlag        = "project-statuses"
+	RedactEnvVarsFlag          = "redact-env-vars"
 	RepoConfigFlag             = "repo-config"
 	RepoConfigJSONFlag         = "repo-config-json"
 	RepoWhitelistFlag          = "repo-whitelist"
@@ -74,12 +84,24 @@ const (
 	SilenceVCSStatusNoPlans    = "silence-vcs-status-no-plans"
 	SilenceWhitelistErrorsFlag = "silence-whitelist-errors"
 	SlackTokenFlag             = "slack-token"
+	SMTPFromFlag               = "smtp-from"
+	SMTPHostFlag               = "smtp-host"
+	SMTPPasswordFlag           = "smtp-password" // nolint: gosec
+	SMTPRequireTLSFlag         = "smtp-require-tls"
+	SMTPUserFlag               = "smtp-user"
 	SSLCertFileFlag            = "ssl-cert-file"
 	SSLKeyFileFlag             = "ssl-key-file"
+	StickyCommentsFlag         = "sticky-comments"
 	TFDownloadURLFlag          = "tf-download-url"
 	VCSStatusName              = "vcs-status-name"
 	TFEHostnameFlag            = "tfe-hostname"
 	TFETokenFlag               = "tfe-token"
+	WebBasicAuthFlag           = "web-basic-auth"
+	WebOIDCClientIDFlag        = "web-oidc-client-id"
+	WebOIDCClientSecretFlag    = "web-oidc-client-secret" // nolint: gosec
+	WebOIDCIssuerURLFlag       = "web-oidc-issuer-url"
+	WebPasswordFlag            = "web-password" // nolint: gosec
+	WebUsernameFlag            = "web-username"
 	WriteGitCredsFlag          = "write-git-creds"
 
 	// NOTE: Must manually set these as defaults in the setDefaults function.
@@ -89,6 +111,7 @@ const (
 	DefaultBitbucketBaseURL = bitbucketcloud.BaseURL
 	DefaultDataDir          = "~/.atlantis"
 	DefaultGHHostname       = "github.com"
+	DefaultGiteaBaseURL     = gitea.BaseURL
 	DefaultGitlabHostname   = "gitlab.com"
 	DefaultLogLevel         = "info"
 	DefaultPort             = 4141
@@ -153,6 +176,12 @@ var stringFlags = map[string]stringFlag{
 		description:  "Path to directory to store Atlantis data.",
 		defaultValue: DefaultDataDir,
 	},
+	GHAppKeyFileFlag: {
+		description: "Path to the private key of the GitHub App set with --" + GHAppIDFlag + ".",
+	},
+	GHAppSlugFlag: {
+		description: "URL-friendly name of the GitHub App set with --" + GHAppIDFlag + ". Atlantis can be run with @<slug> in comments. If not set, it's looked up from GitHub on startup.",
+	},
 	GHHostnameFlag: {
 		description:  "Hostname of your Github Enterprise installation. If using github.com, no need to set.",
 		defaultValue: DefaultGHHostname,
@@ -169,6 +198,22 @@ var stringFlags = map[string]stringFlag{
 			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
 			"Should be specified via the ATLANTIS_GH_WEBHOOK_SECRET environment variable.",
 	},
+	GiteaBaseURLFlag: {
+		description:  "Base URL of your Gitea or Forgejo installation. If using gitea.com, no need to set.",
+		defaultValue: DefaultGiteaBaseURL,
+	},
+	GiteaUserFlag: {
+		description: "Gitea username of API user.",
+	},
+	GiteaTokenFlag: {
+		description: "Gitea access token of API user. Can also be specified via the ATLANTIS_GITEA_TOKEN environment variable.",
+	},
+	GiteaWebhookSecretFlag: {
+		description: "Secret used to validate Gitea webhooks." +
+			" SECURITY WARNING: If not specified, Atlantis won't be able to validate that the incoming webhook call came from Gitea. " +
+			"This means that an attacker could spoof calls to Atlantis and cause it to perform malicious actions. " +
+			"Should be specified via the ATLANTIS_GITEA_WEBHOOK_SECRET environment variable.",
+	},
 	GitlabHostnameFlag: {
 		description:  "Hostname of your GitLab Enterprise installation. If using gitlab.com, no need to set.",
 		defaultValue: DefaultGitlabHostname,
@@ -189,6 +234,11 @@ var stringFlags = map[string]stringFlag{
 		description:  "Log level. Either debug, info, warn, or error.",
 		defaultValue: DefaultLogLevel,
 	},
+	RedactEnvVarsFlag: {
+		description: "Comma separated list of environment variable names whose values are secret, ex. AWS_SECRET_ACCESS_KEY,DB_PASSWORD." +
+			" Their values will be redacted from all output posted to pull requests." +
+			" Common credential formats are always redacted. Additional regex patterns can be set via redact-patterns in the config file.",
+	},
 	RepoConfigFlag: {
 		description: "Path to a repo config file, used to customize how Atlantis runs on each repo. See runatlantis.io/docs for more details.",
 	},
@@ -204,12 +254,28 @@ var stringFlags = map[string]stringFlag{
 	SlackTokenFlag: {
 		description: "API token for Slack notifications.",
 	},
+	SMTPFromFlag: {
+		description: "Address that email notifications are sent from. Required if --" + SMTPHostFlag + " is set.",
+	},
+	SMTPHostFlag: {
+		description: "host:port of the SMTP server to send email notifications through, ex. smtp.example.com:587. STARTTLS is used if the server supports it. To require it, set --" + SMTPRequireTLSFlag + ".",
+	},
+	SMTPPasswordFlag: {
+		description: "Password to authenticate with the SMTP server. Can also be specified via the ATLANTIS_SMTP_PASSWORD environment variable.",
+	},
+	SMTPUserFlag: {
+		description: "Username to authenticate with the SMTP server. If not set, Atlantis doesn't authenticate.",
+	},
 	SSLCertFileFlag: {
 		description: "File containing x509 Certificate used for serving HTTPS. If the cert is signed by a CA, the file should be the concatenation of the server's certificate, any intermediates, and the CA's certificate.",
 	},
 	SSLKeyFileFlag: {
 		description: fmt.Sprintf("File containing x509 private key matching --%s.", SSLCertFileFlag),
 	},
+	StickyCommentsFlag: {
+		description: "Keep a single comment per pull request up to date with the latest results instead of commenting on each run." +
+			" Either pull, for one comment per pull request, or command, for one comment per command, ex. plan and apply.",
+	},
 	TFDownloadURLFlag: {
 		description:  "Base URL to download Terraform versions from.",
 		defaultValue: DefaultTFDownloadURL,
@@ -231,6 +297,25 @@ var stringFlags = map[string]stringFlag{
 		description:  "Name used to identify Atlantis for pull request statuses.",
 		defaultValue: DefaultVCSStatusName,
 	},
+	WebOIDCClientIDFlag: {
+		description: "OAuth2 client ID registered with the OpenID Connect provider set by --" + WebOIDCIssuerURLFlag + ".",
+	},
+	WebOIDCClientSecretFlag: {
+		description: "OAuth2 client secret registered with the OpenID Connect provider set by --" + WebOIDCIssuerURLFlag + "." +
+			" Should be specified via the ATLANTIS_WEB_OIDC_CLIENT_SECRET environment variable.",
+	},
+	WebOIDCIssuerURLFlag: {
+		description: "Issuer URL of an OpenID Connect provider, ex. https://accounts.google.com." +
+			" If set, users must log in with the provider to view the web UI or delete locks." +
+			" The provider must allow the redirect URI $atlantis-url/auth/callback.",
+	},
+	WebPasswordFlag: {
+		description: "Password used for basic authentication on the web UI if --" + WebBasicAuthFlag + " is set." +
+			" Should be specified via the ATLANTIS_WEB_PASSWORD environment variable.",
+	},
+	WebUsernameFlag: {
+		description: "Username used for basic authentication on the web UI if --" + WebBasicAuthFlag + " is set.",
+	},
 }
 
 var boolFlags = map[string]boolFlag{
@@ -255,7 +340,7 @@ var boolFlags = map[string]boolFlag{
 	},
 	HidePrevPlanComments: {
 		description: "Hide previous plan comments to reduce clutter in the PR. " +
-			"VCS support is limited to: GitHub.",
+			"GitHub comments are minimized, GitLab comments are collapsed, Bitbucket comments are replaced with a short note and Azure DevOps threads are closed.",
 		defaultValue: false,
 	},
 	RequireApprovalFlag: {
@@ -272,6 +357,15 @@ var boolFlags = map[string]boolFlag{
 		description:  "Silences the posting of fork pull requests not allowed error comments.",
 		defaultValue: false,
 	},
+	ProjectStatusesFlag: {
+		description: "Set a commit status for each project that links to its output while plan and apply run." +
+			" GitHub Apps always report each project as a check run.",
+		defaultValue: false,
+	},
+	SMTPRequireTLSFlag: {
+		description:  "Don't send email notifications if the SMTP server doesn't support STARTTLS.",
+		defaultValue: false,
+	},
 	SilenceVCSStatusNoPlans: {
 		description:  "Silences VCS commit status when autoplan finds no projects to plan.",
 		defaultValue: false,
@@ -284,6 +378,10 @@ var boolFlags = map[string]boolFlag{
 		description:  "Toggle off folding in markdown output.",
 		defaultValue: false,
 	},
+	WebBasicAuthFlag: {
+		description:  "Require basic authentication using --" + WebUsernameFlag + " and --" + WebPasswordFlag + " to view the web UI or delete locks.",
+		defaultValue: false,
+	},
 	WriteGitCredsFlag: {
 		description: "Write out a .git-credentials file with the provider user and token to allow cloning private modules over HTTPS or SSH." +
 			" This writes secrets to disk and should only be enabled in a secure environment.",
@@ -291,6 +389,9 @@ var boolFlags = map[string]boolFlag{
 	},
 }
 var intFlags = map[string]intFlag{
+	GHAppIDFlag: {
+		description: "ID of a GitHub App to authenticate as instead of a GitHub user. Requires --" + GHAppKeyFileFlag + ".",
+	},
 	PortFlag: {
 		description:  "Port to bind to.",
 		defaultValue: DefaultPort,
@@ -482,6 +583,9 @@ func (s *ServerCmd) setDefaults(c *server.UserConfig) {
 	if c.GitlabHostname == "" {
 		c.GitlabHostname = DefaultGitlabHostname
 	}
+	if c.GiteaBaseURL == "" {
+		c.GiteaBaseURL = DefaultGiteaBaseURL
+	}
 	if c.BitbucketBaseURL == "" {
 		c.BitbucketBaseURL = DefaultBitbucketBaseURL
 	}
@@ -519,19 +623,27 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 
 	// The following combinations are valid.
 	// 1. github user and token set
-	// 2. gitlab user and token set
-	// 3. bitbucket user and token set
-	// 4. azuredevops user and token set
-	// 5. any combination of the above
-	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag)
-	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "")) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) || ((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) {
+	// 2. github app id and key file set
+	// 3. gitlab user and token set
+	// 4. bitbucket user and token set
+	// 5. azuredevops user and token set
+	// 6. gitea user and token set
+	// 7. any combination of the above, except a github user and app
+	vcsErr := fmt.Errorf("--%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s or --%s/--%s must be set", GHUserFlag, GHTokenFlag, GitlabUserFlag, GitlabTokenFlag, BitbucketUserFlag, BitbucketTokenFlag, ADUserFlag, ADTokenFlag, GiteaUserFlag, GiteaTokenFlag)
+	if ((userConfig.GithubUser == "") != (userConfig.GithubToken == "")) || ((userConfig.GitlabUser == "") != (userConfig.GitlabToken == "")) || ((userConfig.BitbucketUser == "") != (userConfig.BitbucketToken == "")) || ((userConfig.AzureDevopsUser == "") != (userConfig.AzureDevopsToken == "")) || ((userConfig.GiteaUser == "") != (userConfig.GiteaToken == "")) {
 		return vcsErr
 	}
 	// At this point, we know that there can't be a single user/token without
 	// its partner, but we haven't checked if any user/token is set at all.
-	if userConfig.GithubUser == "" && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" {
+	if userConfig.GithubUser == "" && userConfig.GithubAppID == 0 && userConfig.GitlabUser == "" && userConfig.BitbucketUser == "" && userConfig.AzureDevopsUser == "" && userConfig.GiteaUser == "" {
 		return vcsErr
 	}
+	if (userConfig.GithubAppID == 0) != (userConfig.GithubAppKeyFile == "") {
+		return fmt.Errorf("--%s and --%s must both be set", GHAppIDFlag, GHAppKeyFileFlag)
+	}
+	if userConfig.GithubAppID != 0 && userConfig.GithubUser != "" {
+		return fmt.Errorf("cannot use --%s and --%s at the same time", GHUserFlag, GHAppIDFlag)
+	}
 
 	if userConfig.RepoWhitelist == "" {
 		return fmt.Errorf("--%s must be set for security purposes", RepoWhitelistFlag)
@@ -552,6 +664,22 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		return fmt.Errorf("--%s must have http:// or https://, got %q", BitbucketBaseURLFlag, userConfig.BitbucketBaseURL)
 	}
 
+	parsed, err = url.Parse(userConfig.GiteaBaseURL)
+	if err != nil {
+		return fmt.Errorf("error parsing --%s flag value %q: %s", GiteaBaseURLFlag, userConfig.GiteaBaseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("--%s must have http:// or https://, got %q", GiteaBaseURLFlag, userConfig.GiteaBaseURL)
+	}
+
+	stickyComments := userConfig.StickyComments
+	if stickyComments != "" && stickyComments != "pull" && stickyComments != "command" {
+		return fmt.Errorf("invalid --%s: not one of pull or command", StickyCommentsFlag)
+	}
+	if stickyComments != "" && userConfig.HidePrevPlanComments {
+		return fmt.Errorf("cannot use --%s and --%s at the same time", StickyCommentsFlag, HidePrevPlanComments)
+	}
+
 	if userConfig.RepoConfig != "" && userConfig.RepoConfigJSON != "" {
 		return fmt.Errorf("cannot use --%s and --%s at the same time", RepoConfigFlag, RepoConfigJSONFlag)
 	}
@@ -564,16 +692,32 @@ func (s *ServerCmd) validate(userConfig server.UserConfig) error {
 		GitlabWebhookSecretFlag:    userConfig.GitlabWebhookSecret,
 		BitbucketTokenFlag:         userConfig.BitbucketToken,
 		BitbucketWebhookSecretFlag: userConfig.BitbucketWebhookSecret,
+		GiteaTokenFlag:             userConfig.GiteaToken,
+		GiteaWebhookSecretFlag:     userConfig.GiteaWebhookSecret,
 	} {
 		if strings.Contains(token, "\n") {
 			s.Logger.Warn("--%s contains a newline which is usually unintentional", name)
 		}
 	}
 
+	if userConfig.SMTPHost != "" && userConfig.SMTPFrom == "" {
+		return fmt.Errorf("if setting --%s, must set --%s", SMTPHostFlag, SMTPFromFlag)
+	}
+
 	if userConfig.TFEHostname != DefaultTFEHostname && userConfig.TFEToken == "" {
 		return fmt.Errorf("if setting --%s, must set --%s", TFEHostnameFlag, TFETokenFlag)
 	}
 
+	if userConfig.WebBasicAuth && (userConfig.WebUsername == "" || userConfig.WebPassword == "") {
+		return fmt.Errorf("if setting --%s, must set --%s and --%s", WebBasicAuthFlag, WebUsernameFlag, WebPasswordFlag)
+	}
+	if userConfig.WebOIDCIssuerURL != "" && (userConfig.WebOIDCClientID == "" || userConfig.WebOIDCClientSecret == "") {
+		return fmt.Errorf("if setting --%s, must set --%s and --%s", WebOIDCIssuerURLFlag, WebOIDCClientIDFlag, WebOIDCClientSecretFlag)
+	}
+	if userConfig.WebBasicAuth && userConfig.WebOIDCIssuerURL != "" {
+		return fmt.Errorf("cannot use --%s and --%s at the same time", WebBasicAuthFlag, WebOIDCIssuerURLFlag)
+	}
+
 	return nil
 }
 
@@ -619,10 +763,11 @@ func (s *ServerCmd) trimAtSymbolFromUsers(userConfig *server.UserConfig) {
 	userConfig.GitlabUser = strings.TrimPrefix(userConfig.GitlabUser, "@")
 	userConfig.BitbucketUser = strings.TrimPrefix(userConfig.BitbucketUser, "@")
 	userConfig.AzureDevopsUser = strings.TrimPrefix(userConfig.AzureDevopsUser, "@")
+	userConfig.GiteaUser = strings.TrimPrefix(userConfig.GiteaUser, "@")
 }
 
 func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
-	if userConfig.GithubUser != "" && userConfig.GithubWebhookSecret == "" && !s.SilenceOutput {
+	if (userConfig.GithubUser != "" || userConfig.GithubAppID != 0) && userConfig.GithubWebhookSecret == "" && !s.SilenceOutput {
 		s.Logger.Warn("no GitHub webhook secret set. This could allow attackers to spoof requests from GitHub")
 	}
 	if userConfig.GitlabUser != "" && userConfig.GitlabWebhookSecret == "" && !s.SilenceOutput {
@@ -637,6 +782,9 @@ func (s *ServerCmd) securityWarnings(userConfig *server.UserConfig) {
 	if userConfig.AzureDevopsWebhookUser != "" && userConfig.AzureDevopsWebhookPassword == "" && !s.SilenceOutput {
 		s.Logger.Warn("no Azure DevOps webhook user and password set. This could allow attackers to spoof requests from Azure DevOps.")
 	}
+	if userConfig.GiteaUser != "" && userConfig.GiteaWebhookSecret == "" && !s.SilenceOutput {
+		s.Logger.Warn("no Gitea webhook secret set. This could allow attackers to spoof requests from Gitea")
+	}
 }
 
 // deprecationWarnings prints a warning if flags that are deprecated are
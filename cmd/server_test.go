@@ -66,16 +66,26 @@ var testFlags = map[string]interface{}{
 	DefaultTFVersionFlag:       "v0.11.0",
 	DisableApplyAllFlag:        true,
 	DisableMarkdownFoldingFlag: true,
+	// Can't be set since it conflicts with --gh-user.
+	GHAppIDFlag:                0,
+	GHAppKeyFileFlag:           "",
+	GHAppSlugFlag:              "atlantis",
 	GHHostnameFlag:             "ghhostname",
 	GHTokenFlag:                "token",
 	GHUserFlag:                 "user",
 	GHWebhookSecretFlag:        "secret",
+	GiteaBaseURLFlag:           "https://gitea.example.com",
+	GiteaTokenFlag:             "gitea-token",
+	GiteaUserFlag:              "gitea-user",
+	GiteaWebhookSecretFlag:     "gitea-secret",
 	GitlabHostnameFlag:         "gitlab-hostname",
 	GitlabTokenFlag:            "gitlab-token",
 	GitlabUserFlag:             "gitlab-user",
 	GitlabWebhookSecretFlag:    "gitlab-secret",
 	LogLevelFlag:               "debug",
 	PortFlag:                   8181,
+	ProjectStatusesFlag:        true,
+	RedactEnvVarsFlag:          "AWS_SECRET_ACCESS_KEY,DB_PASSWORD",
 	RepoWhitelistFlag:          "github.com/runatlantis/atlantis",
 	RequireApprovalFlag:        true,
 	RequireMergeableFlag:       true,
@@ -83,13 +93,26 @@ var testFlags = map[string]interface{}{
 	SilenceWhitelistErrorsFlag: true,
 	SilenceVCSStatusNoPlans:    true,
 	SlackTokenFlag:             "slack-token",
+	SMTPFromFlag:               "atlantis@example.com",
+	SMTPHostFlag:               "smtp.example.com:587",
+	SMTPPasswordFlag:           "smtp-password",
+	SMTPRequireTLSFlag:         true,
+	SMTPUserFlag:               "smtp-user",
 	SSLCertFileFlag:            "cert-file",
 	SSLKeyFileFlag:             "key-file",
+	StickyCommentsFlag:         "command",
 	TFDownloadURLFlag:          "https://my-hostname.com",
 	TFEHostnameFlag:            "my-hostname",
 	TFETokenFlag:               "my-token",
 	VCSStatusName:              "my-status",
-	WriteGitCredsFlag:          true,
+	// Can't be set to true since it conflicts with the OIDC flags.
+	WebBasicAuthFlag:        false,
+	WebOIDCClientIDFlag:     "oidc-client-id",
+	WebOIDCClientSecretFlag: "oidc-client-secret",
+	WebOIDCIssuerURLFlag:    "https://accounts.example.com",
+	WebPasswordFlag:         "web-password",
+	WebUsernameFlag:         "web-username",
+	WriteGitCredsFlag:       true,
 }
 
 func TestExecute_Defaults(t *testing.T) {
@@ -346,7 +369,7 @@ func TestExecute_ValidateSSLConfig(t *testing.T) {
 }
 
 func TestExecute_ValidateVCSConfig(t *testing.T) {
-	expErr := "--gh-user/--gh-token or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token must be set"
+	expErr := "--gh-user/--gh-token or --gitlab-user/--gitlab-token or --bitbucket-user/--bitbucket-token or --azuredevops-user/--azuredevops-token or --gitea-user/--gitea-token must be set"
 	cases := []struct {
 		description string
 		flags       map[string]interface{}
@@ -385,6 +408,13 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea token set",
+			map[string]interface{}{
+				GiteaTokenFlag: "token",
+			},
+			true,
+		},
 		{
 			"just github user set",
 			map[string]interface{}{
@@ -413,6 +443,13 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"just gitea user set",
+			map[string]interface{}{
+				GiteaUserFlag: "user",
+			},
+			true,
+		},
 		{
 			"github user and gitlab token set",
 			map[string]interface{}{
@@ -469,6 +506,14 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"gitea user and gitea token set and should be successful",
+			map[string]interface{}{
+				GiteaUserFlag:  "user",
+				GiteaTokenFlag: "token",
+			},
+			false,
+		},
 		{
 			"all set should be successful",
 			map[string]interface{}{
@@ -480,6 +525,8 @@ func TestExecute_ValidateVCSConfig(t *testing.T) {
 				BitbucketTokenFlag: "token",
 				ADUserFlag:         "user",
 				ADTokenFlag:        "token",
+				GiteaUserFlag:      "user",
+				GiteaTokenFlag:     "token",
 			},
 			false,
 		},
@@ -582,6 +629,30 @@ func TestExecute_ADUser(t *testing.T) {
 	Equals(t, "user", passedConfig.AzureDevopsUser)
 }
 
+func TestExecute_GiteaUser(t *testing.T) {
+	t.Log("Should remove the @ from the gitea username if it's passed.")
+	c := setup(map[string]interface{}{
+		GiteaUserFlag:     "@user",
+		GiteaTokenFlag:    "token",
+		RepoWhitelistFlag: "*",
+	})
+	err := c.Execute()
+	Ok(t, err)
+
+	Equals(t, "user", passedConfig.GiteaUser)
+}
+
+// Base URL must have a scheme.
+func TestExecute_GiteaBaseURLScheme(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GiteaUserFlag:     "user",
+		GiteaTokenFlag:    "token",
+		RepoWhitelistFlag: "*",
+		GiteaBaseURLFlag:  "gitea.example.com",
+	})
+	ErrEquals(t, "--gitea-base-url must have http:// or https://, got \"gitea.example.com\"", c.Execute())
+}
+
 // If using bitbucket cloud, webhook secrets are not supported.
 func TestExecute_BitbucketCloudWithWebhookSecret(t *testing.T) {
 	c := setup(map[string]interface{}{
@@ -650,6 +721,103 @@ func TestExecute_TFEHostnameOnly(t *testing.T) {
 	ErrEquals(t, "if setting --tfe-hostname, must set --tfe-token", err)
 }
 
+func TestExecute_GithubApp(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHAppIDFlag:       1,
+		GHAppKeyFileFlag:  "key.pem",
+		RepoWhitelistFlag: "github.com",
+	})
+	Ok(t, c.Execute())
+	Equals(t, 1, passedConfig.GithubAppID)
+	Equals(t, "key.pem", passedConfig.GithubAppKeyFile)
+}
+
+func TestExecute_GithubAppWithoutKeyFile(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHAppIDFlag:       1,
+		RepoWhitelistFlag: "github.com",
+	})
+	err := c.Execute()
+	ErrEquals(t, "--gh-app-id and --gh-app-key-file must both be set", err)
+}
+
+func TestExecute_GithubUserAndApp(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:        "user",
+		GHTokenFlag:       "token",
+		GHAppIDFlag:       1,
+		GHAppKeyFileFlag:  "key.pem",
+		RepoWhitelistFlag: "github.com",
+	})
+	err := c.Execute()
+	ErrEquals(t, "cannot use --gh-user and --gh-app-id at the same time", err)
+}
+
+func TestExecute_InvalidStickyComments(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:         "user",
+		GHTokenFlag:        "token",
+		RepoWhitelistFlag:  "github.com",
+		StickyCommentsFlag: "project",
+	})
+	err := c.Execute()
+	ErrEquals(t, "invalid --sticky-comments: not one of pull or command", err)
+}
+
+func TestExecute_StickyCommentsAndHidePrevPlanComments(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:           "user",
+		GHTokenFlag:          "token",
+		RepoWhitelistFlag:    "github.com",
+		StickyCommentsFlag:   "pull",
+		HidePrevPlanComments: true,
+	})
+	err := c.Execute()
+	ErrEquals(t, "cannot use --sticky-comments and --hide-prev-plan-comments at the same time", err)
+}
+
+func TestExecute_SMTPHostWithoutFrom(t *testing.T) {
+	c := setup(map[string]interface{}{
+		GHUserFlag:        "user",
+		GHTokenFlag:       "token",
+		RepoWhitelistFlag: "github.com",
+		SMTPHostFlag:      "smtp.example.com:587",
+	})
+	err := c.Execute()
+	ErrEquals(t, "if setting --smtp-host, must set --smtp-from", err)
+}
+
+func TestExecute_WebBasicAuthWithoutPassword(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		WebBasicAuthFlag: true,
+		WebUsernameFlag:  "user",
+	})
+	err := c.Execute()
+	ErrEquals(t, "if setting --web-basic-auth, must set --web-username and --web-password", err)
+}
+
+func TestExecute_WebOIDCWithoutClientSecret(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		WebOIDCIssuerURLFlag: "https://accounts.example.com",
+		WebOIDCClientIDFlag:  "id",
+	})
+	err := c.Execute()
+	ErrEquals(t, "if setting --web-oidc-issuer-url, must set --web-oidc-client-id and --web-oidc-client-secret", err)
+}
+
+func TestExecute_WebBasicAuthAndOIDC(t *testing.T) {
+	c := setupWithDefaults(map[string]interface{}{
+		WebBasicAuthFlag:        true,
+		WebUsernameFlag:         "user",
+		WebPasswordFlag:         "pass",
+		WebOIDCIssuerURLFlag:    "https://accounts.example.com",
+		WebOIDCClientIDFlag:     "id",
+		WebOIDCClientSecretFlag: "secret",
+	})
+	err := c.Execute()
+	ErrEquals(t, "cannot use --web-basic-auth and --web-oidc-issuer-url at the same time", err)
+}
+
 func setup(flags map[string]interface{}) *cobra.Command {
 	vipr := viper.New()
 	for k, v := range flags {
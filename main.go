@@ -35,8 +35,12 @@ func main() {
 	}
 	version := &cmd.VersionCmd{AtlantisVersion: atlantisVersion}
 	testdrive := &cmd.TestdriveCmd{}
+	githubApp := &cmd.GithubAppCmd{}
+	local := &cmd.LocalCmd{}
 	cmd.RootCmd.AddCommand(server.Init())
 	cmd.RootCmd.AddCommand(version.Init())
 	cmd.RootCmd.AddCommand(testdrive.Init())
+	cmd.RootCmd.AddCommand(githubApp.Init())
+	cmd.RootCmd.AddCommand(local.Init())
 	cmd.Execute()
 }
@@ -70,14 +70,16 @@ module.exports = {
                         'apply-requirements',
                         'checkout-strategy',
                         'terraform-versions',
-                        'terraform-cloud'
+                        'terraform-cloud',
+                        'sending-notifications-via-webhooks'
                     ]
                 },
                 {
                     title: 'Using Atlantis',
                     collapsable: true,
                     children: [
-                        ['using-atlantis', 'Overview']
+                        ['using-atlantis', 'Overview'],
+                        'planning-locally'
                     ]
                 },
                 {
@@ -88,6 +90,7 @@ module.exports = {
                         'locking',
                         'autoplanning',
                         'automerging',
+                        'apply-after-merge',
                         'security'
                     ]
                 },
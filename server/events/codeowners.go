@@ -0,0 +1,166 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// codeOwnersPaths are the locations, relative to the repo root, that VCS hosts
+// look for a CODEOWNERS file in. The first one that exists is used.
+var codeOwnersPaths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// FindCodeOwners parses the first CODEOWNERS file that readFile returns.
+// readFile is called with paths relative to the repo root and returns nil if
+// the file doesn't exist. It returns nil if the repo doesn't have a
+// CODEOWNERS file.
+func FindCodeOwners(readFile func(path string) ([]byte, error)) (*CodeOwners, error) {
+	for _, path := range codeOwnersPaths {
+		contents, err := readFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+		if contents == nil {
+			continue
+		}
+		codeOwners, err := ParseCodeOwners(bytes.NewReader(contents))
+		return codeOwners, errors.Wrapf(err, "parsing %s", path)
+	}
+	return nil, nil
+}
+
+// ParseCodeOwners parses a CODEOWNERS file. Each line is a gitignore-style
+// pattern followed by its owners. Comments and GitLab section headers are
+// skipped.
+func ParseCodeOwners(r io.Reader) (*CodeOwners, error) {
+	codeOwners := &CodeOwners{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			continue
+		}
+		fields := strings.Fields(line)
+		var owners []string
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			owners = append(owners, owner)
+		}
+		pattern, err := codeOwnersPatternToRegex(fields[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pattern %q", fields[0])
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{
+			pattern: pattern,
+			owners:  owners,
+		})
+	}
+	return codeOwners, scanner.Err()
+}
+
+// OwnersOf returns the owners of relPath, a path relative to the repo root.
+// As in CODEOWNERS files on every VCS host, the last matching rule wins.
+func (c *CodeOwners) OwnersOf(relPath string) []string {
+	return c.ownersOf(filepath.ToSlash(filepath.Clean(relPath)))
+}
+
+func (c *CodeOwners) ownersOf(relPath string) []string {
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(relPath) {
+			return c.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// ProjectOwners returns the owners of the project at repoRelDir. files are
+// the paths, relative to the repo root, of the files directly in the
+// project's directory since those are the files that make up the project.
+// They should be read from the base branch so the pull request can't add a
+// file with different owners. If there are no files, the owners of the
+// directory itself are returned.
+func (c *CodeOwners) ProjectOwners(repoRelDir string, files []string) []string {
+	if len(files) == 0 {
+		// The trailing slash lets patterns that only match directories match.
+		return c.ownersOf(filepath.ToSlash(filepath.Clean(repoRelDir)) + "/")
+	}
+	var owners []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		for _, owner := range c.OwnersOf(f) {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// codeOwnersPatternToRegex converts a gitignore-style pattern to a regex that
+// matches paths relative to the repo root. Like gitignore, a pattern matches
+// everything beneath a matching directory, except that, as on GitHub, a
+// pattern ending in /* only matches the directory's direct children.
+func codeOwnersPatternToRegex(pattern string) (*regexp.Regexp, error) {
+	childrenOnly := strings.HasSuffix(pattern, "/*")
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	// A pattern containing a slash is relative to the repo root, otherwise it
+	// can match at any depth.
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if anchored {
+		expr.WriteString("^")
+	} else {
+		expr.WriteString("^(.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			expr.WriteString("[^/]*")
+		case pattern[i] == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		}
+	}
+	switch {
+	case childrenOnly:
+		expr.WriteString("$")
+	case dirOnly:
+		expr.WriteString("/.*$")
+	default:
+		expr.WriteString("(/.*)?$")
+	}
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,105 @@
+package events_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var testCodeOwners = `
+# Default owners.
+*               @global
+
+*.md            @docs
+/infra/         @org/infra
+/infra/prod/**  @alice @org/sre # Only for prod.
+modules/*.tf    @modules
+build/          @build
+docs?           @docs
+/config/*       @config
+
+[GitLab Section]
+/gitlab/        @gitlab
+`
+
+func TestCodeOwners_OwnersOf(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader(testCodeOwners))
+	Ok(t, err)
+
+	cases := []struct {
+		path      string
+		expOwners []string
+	}{
+		{"main.tf", []string{"@global"}},
+		{"README.md", []string{"@docs"}},
+		{"nested/README.md", []string{"@docs"}},
+		{"infra/main.tf", []string{"@org/infra"}},
+		{"infra/staging/main.tf", []string{"@org/infra"}},
+		{"infra/prod/main.tf", []string{"@alice", "@org/sre"}},
+		{"infra/prod/nested/main.tf", []string{"@alice", "@org/sre"}},
+		{"nested/infra/main.tf", []string{"@global"}},
+		{"modules/main.tf", []string{"@modules"}},
+		{"modules/nested/main.tf", []string{"@global"}},
+		{"build/main.tf", []string{"@build"}},
+		{"nested/build/main.tf", []string{"@build"}},
+		{"build", []string{"@global"}},
+		{"docs1/main.tf", []string{"@docs"}},
+		{"config/app.yaml", []string{"@config"}},
+		{"config/nested/app.yaml", []string{"@global"}},
+		{"gitlab/main.tf", []string{"@gitlab"}},
+	}
+	for _, c := range cases {
+		t.Run(c.path, func(t *testing.T) {
+			Equals(t, c.expOwners, codeOwners.OwnersOf(c.path))
+		})
+	}
+}
+
+func TestCodeOwners_NoMatch(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader("/infra/ @org/infra"))
+	Ok(t, err)
+	Equals(t, []string(nil), codeOwners.OwnersOf("main.tf"))
+}
+
+func TestCodeOwners_ProjectOwners(t *testing.T) {
+	codeOwners, err := events.ParseCodeOwners(strings.NewReader(`
+/project/ @alice
+/project/*.md @docs
+/project/modules/ @modules
+/empty/ @bob
+`))
+	Ok(t, err)
+
+	owners := codeOwners.ProjectOwners("project", []string{"project/main.tf", "project/variables.tf", "project/README.md"})
+	Equals(t, []string{"@alice", "@docs"}, owners)
+
+	owners = codeOwners.ProjectOwners("empty", nil)
+	Equals(t, []string{"@bob"}, owners)
+}
+
+func TestFindCodeOwners(t *testing.T) {
+	files := map[string]string{}
+	readFile := func(path string) ([]byte, error) {
+		if contents, ok := files[path]; ok {
+			return []byte(contents), nil
+		}
+		return nil, nil
+	}
+	codeOwners, err := events.FindCodeOwners(readFile)
+	Ok(t, err)
+	Assert(t, codeOwners == nil, "exp nil when there's no CODEOWNERS file")
+
+	files["CODEOWNERS"] = "* @root"
+	files[".github/CODEOWNERS"] = "* @github"
+	codeOwners, err = events.FindCodeOwners(readFile)
+	Ok(t, err)
+	Equals(t, []string{"@github"}, codeOwners.OwnersOf("main.tf"))
+
+	_, err = events.FindCodeOwners(func(path string) ([]byte, error) {
+		return nil, errors.New("err")
+	})
+	ErrEquals(t, "reading .github/CODEOWNERS: err", err)
+}
@@ -0,0 +1,94 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_command_authorizer.go CommandAuthorizer
+
+// CommandAuthorizer checks whether users are allowed to run commands.
+type CommandAuthorizer interface {
+	// Authorize returns an empty string if user is allowed to run command on
+	// repo. Otherwise it returns the reason they're not allowed. command is
+	// one of valid.PlanCommandKey, valid.ApplyCommandKey or
+	// valid.UnlockCommandKey.
+	Authorize(repo models.Repo, user models.User, command string) (string, error)
+	// Restricted returns true if command is restricted on any repo. It's used
+	// when the repo a command would run on isn't known.
+	Restricted(command string) bool
+}
+
+// DefaultCommandAuthorizer authorizes commands using the permissions in the
+// server-side repo config.
+type DefaultCommandAuthorizer struct {
+	GlobalCfg valid.GlobalCfg
+	// VCSClient is used to look up team membership.
+	VCSClient vcs.Client
+}
+
+// Authorize returns an empty string if user is allowed to run command on repo
+// based on the matching permissions in the server-side repo config. If no
+// permissions match, everyone is allowed.
+func (d *DefaultCommandAuthorizer) Authorize(repo models.Repo, user models.User, command string) (string, error) {
+	perm, rule, ok := d.GlobalCfg.MatchingPermission(repo.ID(), command)
+	if !ok {
+		return "", nil
+	}
+	for _, u := range perm.Users {
+		// Usernames are case-insensitive on every VCS host we support.
+		if strings.EqualFold(u, user.Username) {
+			return "", nil
+		}
+	}
+
+	// We only return an error if we couldn't determine that the user is
+	// allowed through any team.
+	var teamErr error
+	for _, team := range perm.Teams {
+		isMember, err := d.VCSClient.UserIsTeamMember(repo, user, team)
+		if err != nil {
+			teamErr = err
+			continue
+		}
+		if isMember {
+			return "", nil
+		}
+	}
+	if teamErr != nil {
+		return "", teamErr
+	}
+	return fmt.Sprintf("User `%s` is not allowed to run `%s` on this repo. It is restricted by %s to %s.",
+		user.Username, command, rule, describePermission(perm)), nil
+}
+
+// Restricted returns true if any repo config in the server-side repo config
+// has permissions for command.
+func (d *DefaultCommandAuthorizer) Restricted(command string) bool {
+	for _, repo := range d.GlobalCfg.Repos {
+		if _, ok := repo.Permissions[command]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// describePermission returns a description of who perm allows, ex.
+// "users `alice` and members of teams `infra`".
+func describePermission(perm valid.Permission) string {
+	quote := func(items []string) string {
+		return "`" + strings.Join(items, "`, `") + "`"
+	}
+	var parts []string
+	if len(perm.Users) > 0 {
+		parts = append(parts, "users "+quote(perm.Users))
+	}
+	if len(perm.Teams) > 0 {
+		parts = append(parts, "members of teams "+quote(perm.Teams))
+	}
+	return strings.Join(parts, " and ")
+}
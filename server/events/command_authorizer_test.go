@@ -0,0 +1,119 @@
+package events_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestDefaultCommandAuthorizer_Authorize(t *testing.T) {
+	repo := models.Repo{
+		FullName: "owner/repo",
+		VCSHost: models.VCSHost{
+			Hostname: "github.com",
+			Type:     models.Github,
+		},
+	}
+	globalCfg := valid.NewGlobalCfg(false, false, false)
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+		IDRegex: regexp.MustCompile(".*"),
+		Permissions: map[string]valid.Permission{
+			"apply": {
+				Users: []string{"Alice"},
+				Teams: []string{"infra", "owner/admins"},
+			},
+			"unlock": {
+				Users: []string{"alice"},
+			},
+		},
+	})
+
+	cases := []struct {
+		description string
+		user        string
+		command     string
+		teams       map[string]bool
+		teamErr     error
+		expReason   string
+		expErr      string
+	}{
+		{
+			description: "command not restricted",
+			user:        "bob",
+			command:     "plan",
+		},
+		{
+			description: "user allowed case-insensitively",
+			user:        "alice",
+			command:     "apply",
+		},
+		{
+			description: "user allowed through team",
+			user:        "bob",
+			command:     "apply",
+			teams:       map[string]bool{"owner/admins": true},
+		},
+		{
+			description: "user not allowed",
+			user:        "bob",
+			command:     "apply",
+			expReason:   "User `bob` is not allowed to run `apply` on this repo. It is restricted by permissions.apply of repo config with id /.*/ to users `Alice` and members of teams `infra`, `owner/admins`.",
+		},
+		{
+			description: "user not allowed without teams",
+			user:        "bob",
+			command:     "unlock",
+			expReason:   "User `bob` is not allowed to run `unlock` on this repo. It is restricted by permissions.unlock of repo config with id /.*/ to users `alice`.",
+		},
+		{
+			description: "team lookup error",
+			user:        "bob",
+			command:     "apply",
+			teamErr:     errors.New("api down"),
+			expErr:      "api down",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			vcsClient := vcsmocks.NewMockClient()
+			user := models.User{Username: c.user}
+			When(vcsClient.UserIsTeamMember(matchers.AnyModelsRepo(), matchers.AnyModelsUser(), AnyString())).ThenReturn(false, c.teamErr)
+			for team, isMember := range c.teams {
+				When(vcsClient.UserIsTeamMember(repo, user, team)).ThenReturn(isMember, nil)
+			}
+			authorizer := events.DefaultCommandAuthorizer{
+				GlobalCfg: globalCfg,
+				VCSClient: vcsClient,
+			}
+			reason, err := authorizer.Authorize(repo, user, c.command)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expReason, reason)
+		})
+	}
+}
+
+func TestDefaultCommandAuthorizer_Restricted(t *testing.T) {
+	globalCfg := valid.NewGlobalCfg(false, false, false)
+	globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+		ID: "github.com/owner/repo",
+		Permissions: map[string]valid.Permission{
+			"unlock": {Users: []string{"alice"}},
+		},
+	})
+	authorizer := events.DefaultCommandAuthorizer{GlobalCfg: globalCfg}
+	Equals(t, true, authorizer.Restricted("unlock"))
+	Equals(t, false, authorizer.Restricted("apply"))
+}
@@ -24,6 +24,12 @@ type CommandResult struct {
 	// deleted. This happens if automerging is enabled and one project has an
 	// error since automerging requires all plans to succeed.
 	PlansDeleted bool
+	// BaseBranchUpdated is set to the pull request's base branch if this
+	// command was run because commits were pushed to it.
+	BaseBranchUpdated string
+	// MergedBaseBranch is set to the pull request's base branch if this
+	// command was run on it after the pull request was merged.
+	MergedBaseBranch string
 }
 
 // HasErrors returns true if there were any errors during the execution,
@@ -14,7 +14,12 @@
 package events
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/google/go-github/v28/github"
 	"github.com/mcdafydd/go-azuredevops/azuredevops"
@@ -22,6 +27,9 @@ import (
 	"github.com/runatlantis/atlantis/server/events/db"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/recovery"
 	gitlab "github.com/xanzy/go-gitlab"
@@ -36,6 +44,14 @@ type CommandRunner interface {
 	// and then calling the appropriate services to finish executing the command.
 	RunCommentCommand(baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, cmd *CommentCommand)
 	RunAutoplanCommand(baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User)
+	// RunBaseBranchPushCommand re-plans the open pull requests into branch of
+	// baseRepo that have unapplied plans. It's run when commits are pushed to
+	// branch.
+	RunBaseBranchPushCommand(baseRepo models.Repo, branch string)
+	// RunApplyAfterMergeCommand plans and applies the projects modified by
+	// pull on its base branch once it's been merged, if baseRepo is
+	// configured to apply after merge.
+	RunApplyAfterMergeCommand(baseRepo models.Repo, pull models.PullRequest, user models.User)
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_github_pull_getter.go GithubPullGetter
@@ -54,6 +70,14 @@ type AzureDevopsPullGetter interface {
 	GetPullRequest(repo models.Repo, pullNum int) (*azuredevops.GitPullRequest, error)
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitea_pull_getter.go GiteaPullGetter
+
+// GiteaPullGetter makes API calls to get pull requests.
+type GiteaPullGetter interface {
+	// GetPullRequest gets the pull request with id pullNum for the repo.
+	GetPullRequest(repo models.Repo, pullNum int) (*gitea.PullRequest, error)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_gitlab_merge_request_getter.go GitlabMergeRequestGetter
 
 // GitlabMergeRequestGetter makes API calls to get merge requests.
@@ -68,11 +92,14 @@ type DefaultCommandRunner struct {
 	GithubPullGetter         GithubPullGetter
 	AzureDevopsPullGetter    AzureDevopsPullGetter
 	GitlabMergeRequestGetter GitlabMergeRequestGetter
+	GiteaPullGetter          GiteaPullGetter
 	CommitStatusUpdater      CommitStatusUpdater
 	DisableApplyAll          bool
 	EventParser              EventParsing
 	MarkdownRenderer         *MarkdownRenderer
 	Logger                   logging.SimpleLogging
+	// Redactor redacts secrets from project outputs before they're stored.
+	Redactor *SecretRedactor
 	// AllowForkPRs controls whether we operate on pull requests from forks.
 	AllowForkPRs bool
 	// AllowForkPRsFlag is the name of the flag that controls fork PR's. We use
@@ -81,6 +108,10 @@ type DefaultCommandRunner struct {
 	AllowForkPRsFlag string
 	// HidePrevPlanComments will hide previous plan comments to declutter PRs.
 	HidePrevPlanComments bool
+	// StickyComments keeps a single comment per pull request up to date
+	// instead of creating a new comment for each command. If nil, a new
+	// comment is created.
+	StickyComments *StickyCommenter
 	// SilenceForkPRErrors controls whether to comment on Fork PRs when AllowForkPRs = False
 	SilenceForkPRErrors bool
 	// SilenceForkPRErrorsFlag is the name of the flag that controls fork PR's. We use
@@ -97,7 +128,33 @@ type DefaultCommandRunner struct {
 	GlobalAutomerge   bool
 	PendingPlanFinder PendingPlanFinder
 	WorkingDir        WorkingDir
-	DB                *db.BoltDB
+	// WorkingDirLocker is used to skip workspaces that are in use when
+	// re-planning after a push to the base branch.
+	WorkingDirLocker WorkingDirLocker
+	DB               *db.BoltDB
+	// CommandAuthorizer checks whether the commenter is allowed to run the
+	// command. If nil, everyone is allowed.
+	CommandAuthorizer CommandAuthorizer
+	// GlobalCfg is the server-side repo config. It's used to check which repos
+	// apply after merge.
+	GlobalCfg valid.GlobalCfg
+	// PullCleaner deletes the locks and plans held on behalf of merged pull
+	// requests once they've been applied.
+	PullCleaner PullCleaner
+	// Webhooks sends webhooks when projects are planned and when pull
+	// requests are automerged. Apply webhooks are sent by the
+	// ProjectCommandRunner since only it knows if the apply ran. If nil, no
+	// webhooks are sent.
+	Webhooks WebhooksSender
+	// JobURLGenerator generates the links to each project's output that are
+	// included in webhooks. If nil, links aren't included.
+	JobURLGenerator JobURLGenerator
+
+	// replans are the pull requests being re-planned after a push to their
+	// base branch. The value is true if the base branch was pushed to again
+	// while re-planning. Use replansMu to access it.
+	replans   map[string]bool
+	replansMu sync.Mutex
 }
 
 // RunAutoplanCommand runs plan when a pull request is opened or updated.
@@ -114,6 +171,10 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	if !c.validateCtxAndComment(ctx) {
 		return
 	}
+	if c.GlobalCfg.MatchingLabelBehavior(baseRepo.ID(), pull.Labels).SkipAutoplan {
+		log.Info("skipping autoplan because the pull request has a label that skips it")
+		return
+	}
 
 	projectCmds, err := c.ProjectCommandBuilder.BuildAutoplanCommands(ctx)
 	if err != nil {
@@ -158,6 +219,340 @@ func (c *DefaultCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	c.updateCommitStatus(ctx, models.PlanCommand, pullStatus)
 }
 
+// RunBaseBranchPushCommand re-plans the open pull requests into branch of
+// baseRepo that have unapplied plans. With the merge checkout strategy, plans
+// are generated against the base branch so once it moves, they no longer show
+// what will happen after the pull request is merged.
+func (c *DefaultCommandRunner) RunBaseBranchPushCommand(baseRepo models.Repo, branch string) {
+	statuses, err := c.DB.ListPullStatuses()
+	if err != nil {
+		c.Logger.Err("unable to list pull requests to re-plan after push to %s: %s", branch, err)
+		return
+	}
+	for _, status := range statuses {
+		pull := status.Pull
+		if pull.BaseRepo.FullName != baseRepo.FullName ||
+			pull.BaseRepo.VCSHost.Hostname != baseRepo.VCSHost.Hostname ||
+			pull.BaseBranch != branch ||
+			status.StatusCount(models.PlannedPlanStatus) == 0 {
+			continue
+		}
+		c.replanPull(baseRepo, status)
+	}
+}
+
+// replanPull re-runs plan for the projects in status that have unapplied
+// plans because its base branch was updated. Only one re-plan runs at a time
+// per pull request. If the base branch is pushed to while re-planning, the
+// pull request is re-planned again once the running re-plan finishes.
+func (c *DefaultCommandRunner) replanPull(baseRepo models.Repo, status models.PullStatus) {
+	log := c.buildLogger(baseRepo.FullName, status.Pull.Num)
+	defer c.logPanics(baseRepo, status.Pull.Num, log)
+
+	key := fmt.Sprintf("%s#%d", baseRepo.ID(), status.Pull.Num)
+	if !c.startReplan(key) {
+		log.Info("already re-planning, will re-plan again once that finishes")
+		return
+	}
+	running := true
+	defer func() {
+		if running {
+			c.endReplan(key)
+		}
+	}()
+	for {
+		c.replanPullOnce(log, baseRepo, status)
+		if running = c.replanAgain(key); !running {
+			return
+		}
+		// The base branch was pushed to while we were re-planning so the
+		// plans might already be out of date.
+		latest, err := c.DB.GetPullStatus(status.Pull)
+		if err != nil {
+			log.Err("unable to re-plan after push to %s: %s", status.Pull.BaseBranch, err)
+			return
+		}
+		if latest == nil || latest.StatusCount(models.PlannedPlanStatus) == 0 {
+			return
+		}
+		status = *latest
+	}
+}
+
+// startReplan returns true if the pull request with key isn't already being
+// re-planned. Otherwise it asks the running re-plan to re-plan again.
+func (c *DefaultCommandRunner) startReplan(key string) bool {
+	c.replansMu.Lock()
+	defer c.replansMu.Unlock()
+	if c.replans == nil {
+		c.replans = make(map[string]bool)
+	}
+	if _, ok := c.replans[key]; ok {
+		c.replans[key] = true
+		return false
+	}
+	c.replans[key] = false
+	return true
+}
+
+// replanAgain returns true if the base branch of the pull request with key
+// was pushed to while it was being re-planned. If not, the re-plan is over.
+func (c *DefaultCommandRunner) replanAgain(key string) bool {
+	c.replansMu.Lock()
+	defer c.replansMu.Unlock()
+	if c.replans[key] {
+		c.replans[key] = false
+		return true
+	}
+	delete(c.replans, key)
+	return false
+}
+
+// endReplan marks the re-plan of the pull request with key as over.
+func (c *DefaultCommandRunner) endReplan(key string) {
+	c.replansMu.Lock()
+	defer c.replansMu.Unlock()
+	delete(c.replans, key)
+}
+
+// replanPullOnce re-plans the projects in status that have unapplied plans.
+// Workspaces that are in use by another command are skipped.
+func (c *DefaultCommandRunner) replanPullOnce(log *logging.SimpleLogger, baseRepo models.Repo, status models.PullStatus) {
+	// The pull request might have been updated or closed since we stored it.
+	pull, headRepo, err := c.getLatestPullData(baseRepo, status.Pull.Num)
+	if err != nil {
+		log.Err("unable to re-plan after push to %s: %s", status.Pull.BaseBranch, err)
+		return
+	}
+	if pull.State != models.OpenPullState {
+		log.Debug("not re-planning closed pull request")
+		return
+	}
+	ctx := &CommandContext{
+		User:     models.User{Username: pull.Author},
+		Log:      log,
+		Pull:     pull,
+		HeadRepo: headRepo,
+		BaseRepo: baseRepo,
+	}
+	if !c.validateCtxAndComment(ctx) {
+		return
+	}
+
+	// Our clones have the old base branch merged in and are only re-cloned
+	// when the pull request's head commit changes, so we delete the clones
+	// of the projects we're re-planning.
+	unapplied := make(map[string]models.ProjectStatus)
+	// replanWorkspace is whether each workspace's clone was deleted so its
+	// projects can be re-planned.
+	replanWorkspace := make(map[string]bool)
+	for _, p := range status.Projects {
+		if p.Status != models.PlannedPlanStatus {
+			continue
+		}
+		if _, ok := replanWorkspace[p.Workspace]; !ok {
+			deleted, err := c.deleteCloneForReplan(log, baseRepo, pull, p.Workspace)
+			if err != nil {
+				log.Err("unable to delete workspace %q to re-plan: %s", p.Workspace, err)
+				return
+			}
+			replanWorkspace[p.Workspace] = deleted
+		}
+		if replanWorkspace[p.Workspace] {
+			unapplied[p.RepoRelDir+"/"+p.Workspace+"/"+p.ProjectName] = p
+		}
+	}
+	if len(unapplied) == 0 {
+		return
+	}
+	log.Info("re-planning %d project(s) because %s was updated", len(unapplied), pull.BaseBranch)
+
+	allCmds, err := c.ProjectCommandBuilder.BuildAutoplanCommands(ctx)
+	if err != nil {
+		c.updatePull(ctx, AutoplanCommand{}, CommandResult{Error: err, BaseBranchUpdated: pull.BaseBranch})
+		return
+	}
+	// We only re-plan the projects that had unapplied plans. Autoplan has
+	// already filtered out the projects whose when_modified doesn't match.
+	var projectCmds []models.ProjectCommandContext
+	for _, cmd := range allCmds {
+		key := cmd.RepoRelDir + "/" + cmd.Workspace + "/" + cmd.ProjectName
+		if _, ok := unapplied[key]; ok {
+			projectCmds = append(projectCmds, cmd)
+			delete(unapplied, key)
+		}
+	}
+	// Any projects left were planned but aren't autoplanned anymore. Their
+	// plans were deleted along with the clone so we forget about them.
+	for _, p := range unapplied {
+		log.Info("not re-planning dir %q workspace %q since it isn't autoplanned, run plan again to plan it", p.RepoRelDir, p.Workspace)
+		if err := c.DB.DeleteProjectStatus(pull, p.Workspace, p.RepoRelDir); err != nil {
+			log.Err("unable to delete status of dir %q workspace %q: %s", p.RepoRelDir, p.Workspace, err)
+		}
+	}
+	if len(projectCmds) == 0 {
+		return
+	}
+
+	if err := c.CommitStatusUpdater.UpdateCombined(ctx.BaseRepo, ctx.Pull, models.PendingCommitStatus, models.PlanCommand); err != nil {
+		ctx.Log.Warn("unable to update commit status: %s", err)
+	}
+
+	result := c.runProjectCmds(projectCmds, models.PlanCommand)
+	result.BaseBranchUpdated = pull.BaseBranch
+	if c.automergeEnabled(ctx, projectCmds) && result.HasErrors() {
+		ctx.Log.Info("deleting plans because there were errors and automerge requires all plans succeed")
+		c.deletePlans(ctx)
+		result.PlansDeleted = true
+	}
+	c.updatePull(ctx, AutoplanCommand{}, result)
+	pullStatus, err := c.updateDB(ctx, ctx.Pull, result.ProjectResults)
+	if err != nil {
+		c.Logger.Err("writing results: %s", err)
+		return
+	}
+
+	c.updateCommitStatus(ctx, models.PlanCommand, pullStatus)
+}
+
+// deleteCloneForReplan deletes pull's clone for workspace so it's re-cloned
+// with the updated base branch. It returns false if the workspace is in use
+// by another command, in which case it's not re-planned.
+func (c *DefaultCommandRunner) deleteCloneForReplan(log *logging.SimpleLogger, baseRepo models.Repo, pull models.PullRequest, workspace string) (bool, error) {
+	unlockFn, err := c.WorkingDirLocker.TryLock(baseRepo.FullName, pull.Num, workspace)
+	if err != nil {
+		log.Info("not re-planning workspace %q: %s", workspace, err)
+		return false, nil
+	}
+	defer unlockFn()
+	if err := c.WorkingDir.DeleteForWorkspace(baseRepo, pull, workspace); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RunApplyAfterMergeCommand plans and applies the projects modified by pull on
+// its base branch after it's been merged. Apply requirements aren't checked
+// since the pull request has already been merged. Commit statuses are set on
+// the merge commit so failed applies show up on the base branch.
+func (c *DefaultCommandRunner) RunApplyAfterMergeCommand(baseRepo models.Repo, pull models.PullRequest, user models.User) {
+	if !c.GlobalCfg.ApplyAfterMerge(baseRepo.ID()) {
+		return
+	}
+	log := c.buildLogger(baseRepo.FullName, pull.Num)
+	defer c.logPanics(baseRepo, pull.Num, log)
+	// The pull request isn't cleaned up when it's closed if it's going to be
+	// applied, so its locks and ours are deleted once we're done.
+	defer func() {
+		if err := c.PullCleaner.CleanUpPull(baseRepo, pull); err != nil {
+			log.Err("unable to clean up after applying merged pull request: %s", err)
+		}
+	}()
+	// Start from a fresh clone rather than the pull request's checkout.
+	if err := c.WorkingDir.Delete(baseRepo, pull); err != nil {
+		log.Warn("unable to delete pull request's workspace: %s", err)
+	}
+
+	// We run on the merge commit so that's where commit statuses are set. If
+	// the VCS host didn't tell us what it is, we fall back to the pull
+	// request's head commit.
+	if pull.MergeCommit != "" {
+		pull.HeadCommit = pull.MergeCommit
+	}
+	// We're running on the base branch so the head repo doesn't matter, even
+	// if the pull request was from a fork.
+	ctx := &CommandContext{
+		User:     user,
+		Log:      log,
+		Pull:     pull,
+		HeadRepo: baseRepo,
+		BaseRepo: baseRepo,
+	}
+	log.Info("applying pull request on %s after merge", pull.BaseBranch)
+
+	planCmds, err := c.ProjectCommandBuilder.BuildAutoplanCommands(ctx)
+	if err != nil {
+		c.updateMergedCommitStatus(ctx, models.FailedCommitStatus)
+		c.updatePull(ctx, AutoplanCommand{}, CommandResult{Error: err, MergedBaseBranch: pull.BaseBranch})
+		return
+	}
+	if len(planCmds) == 0 {
+		log.Info("determined there was no project to apply after merge")
+		return
+	}
+
+	c.updateMergedCommitStatus(ctx, models.PendingCommitStatus)
+	planResult := c.runProjectCmds(planCmds, models.PlanCommand)
+	numPlanned := 0
+	var failedPlans []models.ProjectResult
+	for _, r := range planResult.ProjectResults {
+		if r.IsSuccessful() {
+			numPlanned++
+		} else {
+			failedPlans = append(failedPlans, r)
+		}
+	}
+	if len(failedPlans) > 0 {
+		// We don't apply anything unless every plan succeeded, otherwise the
+		// base branch would only be partially deployed.
+		if err := c.CommitStatusUpdater.UpdateCombinedCount(baseRepo, pull, models.FailedCommitStatus, models.PlanCommand, numPlanned, len(planCmds)); err != nil {
+			log.Warn("unable to update commit status: %s", err)
+		}
+		if err := c.CommitStatusUpdater.UpdateCombined(baseRepo, pull, models.FailedCommitStatus, models.ApplyCommand); err != nil {
+			log.Warn("unable to update commit status: %s", err)
+		}
+		c.updatePull(ctx, AutoplanCommand{}, CommandResult{
+			ProjectResults:   failedPlans,
+			PlansDeleted:     true,
+			MergedBaseBranch: pull.BaseBranch,
+		})
+		return
+	}
+	if err := c.CommitStatusUpdater.UpdateCombinedCount(baseRepo, pull, models.SuccessCommitStatus, models.PlanCommand, numPlanned, len(planCmds)); err != nil {
+		log.Warn("unable to update commit status: %s", err)
+	}
+
+	applyCmd := &CommentCommand{Name: models.ApplyCommand}
+	applyCmds, err := c.ProjectCommandBuilder.BuildApplyCommands(ctx, applyCmd)
+	if err != nil {
+		if statusErr := c.CommitStatusUpdater.UpdateCombined(baseRepo, pull, models.FailedCommitStatus, models.ApplyCommand); statusErr != nil {
+			log.Warn("unable to update commit status: %s", statusErr)
+		}
+		c.updatePull(ctx, applyCmd, CommandResult{Error: err, MergedBaseBranch: pull.BaseBranch})
+		return
+	}
+	for i := range applyCmds {
+		applyCmds[i].ApplyRequirements = nil
+	}
+	applyResult := c.runProjectCmds(applyCmds, models.ApplyCommand)
+	applyResult.MergedBaseBranch = pull.BaseBranch
+	c.updatePull(ctx, applyCmd, applyResult)
+
+	numApplied := 0
+	for _, r := range applyResult.ProjectResults {
+		if r.IsSuccessful() {
+			numApplied++
+		}
+	}
+	status := models.SuccessCommitStatus
+	if numApplied != len(applyCmds) {
+		status = models.FailedCommitStatus
+	}
+	if err := c.CommitStatusUpdater.UpdateCombinedCount(baseRepo, pull, status, models.ApplyCommand, numApplied, len(applyCmds)); err != nil {
+		log.Warn("unable to update commit status: %s", err)
+	}
+}
+
+// updateMergedCommitStatus sets both the plan and apply commit statuses of the
+// commit being applied after merge to status.
+func (c *DefaultCommandRunner) updateMergedCommitStatus(ctx *CommandContext, status models.CommitStatus) {
+	for _, cmd := range []models.CommandName{models.PlanCommand, models.ApplyCommand} {
+		if err := c.CommitStatusUpdater.UpdateCombined(ctx.BaseRepo, ctx.Pull, status, cmd); err != nil {
+			ctx.Log.Warn("unable to update commit status: %s", err)
+		}
+	}
+}
+
 // RunCommentCommand executes the command.
 // We take in a pointer for maybeHeadRepo because for some events there isn't
 // enough data to construct the Repo model and callers might want to wait until
@@ -175,6 +570,10 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		return
 	}
 
+	if !c.authorizeCommentCommand(baseRepo, pullNum, user, cmd, log) {
+		return
+	}
+
 	var headRepo models.Repo
 	if maybeHeadRepo != nil {
 		headRepo = *maybeHeadRepo
@@ -195,6 +594,8 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 		pull = *maybePull
 	case models.AzureDevops:
 		pull, headRepo, err = c.getAzureDevopsData(baseRepo, pullNum)
+	case models.Gitea:
+		pull, headRepo, err = c.getGiteaData(baseRepo, pullNum)
 	default:
 		err = errors.New("Unknown VCS type–this is a bug")
 	}
@@ -274,7 +675,7 @@ func (c *DefaultCommandRunner) RunCommentCommand(baseRepo models.Repo, maybeHead
 	c.updateCommitStatus(ctx, cmd.Name, pullStatus)
 
 	if cmd.Name == models.ApplyCommand && c.automergeEnabled(ctx, projectCmds) {
-		c.automerge(ctx, pullStatus)
+		c.automerge(ctx, pullStatus, projectCmds)
 	}
 }
 
@@ -310,7 +711,7 @@ func (c *DefaultCommandRunner) updateCommitStatus(ctx *CommandContext, cmd model
 	}
 }
 
-func (c *DefaultCommandRunner) automerge(ctx *CommandContext, pullStatus models.PullStatus) {
+func (c *DefaultCommandRunner) automerge(ctx *CommandContext, pullStatus models.PullStatus, projectCmds []models.ProjectCommandContext) {
 	// We only automerge if all projects have been successfully applied.
 	for _, p := range pullStatus.Projects {
 		if p.Status != models.AppliedPlanStatus {
@@ -325,9 +726,31 @@ func (c *DefaultCommandRunner) automerge(ctx *CommandContext, pullStatus models.
 		// Commenting isn't required so continue.
 	}
 
+	// Options are set per repo so every project has the same ones.
+	var opts models.PullRequestOptions
+	if len(projectCmds) > 0 {
+		opts = projectCmds[0].AutomergeOptions
+	}
+
 	// Make the API call to perform the merge.
 	ctx.Log.Info("automerging pull request")
-	err := c.VCSClient.MergePull(ctx.Pull)
+	var err error
+	opts.CommitMessage, err = c.automergeCommitMsg(ctx.Pull, opts.CommitMessage)
+	if err == nil {
+		err = c.VCSClient.MergePull(ctx.Pull, opts)
+	}
+
+	event := webhooks.Event{
+		Type:    webhooks.AutomergeEvent,
+		Repo:    ctx.BaseRepo,
+		Pull:    ctx.Pull,
+		User:    ctx.User,
+		Success: err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	c.sendWebhook(ctx.Log, event)
 
 	if err != nil {
 		ctx.Log.Err("automerging failed: %s", err)
@@ -339,13 +762,32 @@ func (c *DefaultCommandRunner) automerge(ctx *CommandContext, pullStatus models.
 	}
 }
 
+// automergeCommitMsg renders the configured commit message template tmpl for
+// pull. If tmpl is empty, the VCS client's default message is used.
+func (c *DefaultCommandRunner) automergeCommitMsg(pull models.PullRequest, tmpl string) (string, error) {
+	if tmpl == "" {
+		return "", nil
+	}
+	t, err := template.New("").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "parsing automerge commit message")
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, pull); err != nil {
+		return "", errors.Wrap(err, "rendering automerge commit message")
+	}
+	return buf.String(), nil
+}
+
 func (c *DefaultCommandRunner) runProjectCmds(cmds []models.ProjectCommandContext, cmdName models.CommandName) CommandResult {
 	var results []models.ProjectResult
 	for _, pCmd := range cmds {
 		var res models.ProjectResult
 		switch cmdName {
 		case models.PlanCommand:
+			c.sendWebhook(pCmd.Log, c.projectEvent(webhooks.PlanStartedEvent, pCmd, nil))
 			res = c.ProjectCommandRunner.Plan(pCmd)
+			c.sendWebhook(pCmd.Log, c.projectEvent(webhooks.PlanFinishedEvent, pCmd, &res))
 		case models.ApplyCommand:
 			res = c.ProjectCommandRunner.Apply(pCmd)
 		}
@@ -354,6 +796,85 @@ func (c *DefaultCommandRunner) runProjectCmds(cmds []models.ProjectCommandContex
 	return CommandResult{ProjectResults: results}
 }
 
+// projectEvent returns the webhook event of type eventType for the project
+// command pCmd. res is the result of the command if it has finished.
+func (c *DefaultCommandRunner) projectEvent(eventType string, pCmd models.ProjectCommandContext, res *models.ProjectResult) webhooks.Event {
+	return newProjectEvent(eventType, pCmd, res, c.JobURLGenerator, c.Redactor)
+}
+
+// newProjectEvent returns the webhook event of type eventType for the project
+// command pCmd. res is the result of the command if it has finished. If
+// jobURLGenerator is nil, the event doesn't link to the command's output.
+func newProjectEvent(eventType string, pCmd models.ProjectCommandContext, res *models.ProjectResult, jobURLGenerator JobURLGenerator, redactor *SecretRedactor) webhooks.Event {
+	event := webhooks.Event{
+		Type:        eventType,
+		Repo:        pCmd.BaseRepo,
+		Pull:        pCmd.Pull,
+		User:        pCmd.User,
+		ProjectName: pCmd.ProjectName,
+		Directory:   pCmd.RepoRelDir,
+		Workspace:   pCmd.Workspace,
+		Success:     true,
+	}
+	if jobURLGenerator != nil {
+		event.JobURL = jobURLGenerator.GenerateProjectJobURL(pCmd)
+	}
+	if res == nil {
+		return event
+	}
+	event.Success = res.IsSuccessful()
+	switch {
+	case res.Error != nil:
+		event.Error = redactor.Redact(res.Error.Error())
+	case res.Failure != "":
+		event.Error = redactor.Redact(res.Failure)
+	case res.PlanSuccess != nil:
+		event.Output = redactor.Redact(res.PlanSuccess.TerraformOutput)
+		event.PlanSummary = res.PlanSuccess.Summary()
+	default:
+		event.Output = redactor.Redact(res.ApplySuccess)
+	}
+	return event
+}
+
+// sendWebhook sends the webhooks for event if webhooks are configured.
+func (c *DefaultCommandRunner) sendWebhook(log *logging.SimpleLogger, event webhooks.Event) {
+	sendWebhook(c.Webhooks, log, event)
+}
+
+// sendWebhook sends event with sender. If sender is nil, nothing is sent.
+// Errors are logged since webhooks shouldn't fail the command.
+func sendWebhook(sender WebhooksSender, log *logging.SimpleLogger, event webhooks.Event) {
+	if sender == nil {
+		return
+	}
+	if err := sender.Send(log, event); err != nil {
+		log.Warn("unable to send %s webhook: %s", event.Type, err)
+	}
+}
+
+// authorizeCommentCommand returns true if user is allowed to run cmd. If not,
+// it comments on the pull request with why.
+func (c *DefaultCommandRunner) authorizeCommentCommand(baseRepo models.Repo, pullNum int, user models.User, cmd *CommentCommand, log *logging.SimpleLogger) bool {
+	if c.CommandAuthorizer == nil {
+		return true
+	}
+	reason, err := c.CommandAuthorizer.Authorize(baseRepo, user, cmd.Name.String())
+	if err != nil {
+		// We fail closed because we couldn't verify the user is allowed.
+		log.Err("unable to authorize %s: %s", user.Username, err)
+		reason = fmt.Sprintf("Unable to determine if `%s` is allowed to run `%s`: %s", user.Username, cmd.Name.String(), err)
+	}
+	if reason == "" {
+		return true
+	}
+	log.Info("denying %s for %s: %s", cmd.Name.String(), user.Username, reason)
+	if err := c.VCSClient.CreateComment(baseRepo, pullNum, fmt.Sprintf("**%s Denied**: %s", strings.Title(cmd.Name.String()), reason)); err != nil {
+		log.Err("unable to comment on pull request: %s", err)
+	}
+	return false
+}
+
 func (c *DefaultCommandRunner) getGithubData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
 	if c.GithubPullGetter == nil {
 		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support GitHub")
@@ -396,6 +917,49 @@ func (c *DefaultCommandRunner) getAzureDevopsData(baseRepo models.Repo, pullNum
 	return pull, headRepo, nil
 }
 
+func (c *DefaultCommandRunner) getGiteaData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	if c.GiteaPullGetter == nil {
+		return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support Gitea")
+	}
+	giteaPull, err := c.GiteaPullGetter.GetPullRequest(baseRepo, pullNum)
+	if err != nil {
+		return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making pull request API call to Gitea")
+	}
+	pull, _, headRepo, err := c.EventParser.ParseGiteaPull(giteaPull)
+	if err != nil {
+		return pull, headRepo, errors.Wrap(err, "extracting required fields from comment data")
+	}
+	return pull, headRepo, nil
+}
+
+// getLatestPullData gets pull request pullNum of baseRepo from the VCS host.
+// It's used when we don't have an event with the pull request's data.
+func (c *DefaultCommandRunner) getLatestPullData(baseRepo models.Repo, pullNum int) (models.PullRequest, models.Repo, error) {
+	switch baseRepo.VCSHost.Type {
+	case models.Github:
+		return c.getGithubData(baseRepo, pullNum)
+	case models.Gitlab:
+		if c.GitlabMergeRequestGetter == nil {
+			return models.PullRequest{}, models.Repo{}, errors.New("Atlantis not configured to support GitLab")
+		}
+		mr, err := c.GitlabMergeRequestGetter.GetMergeRequest(baseRepo.FullName, pullNum)
+		if err != nil {
+			return models.PullRequest{}, models.Repo{}, errors.Wrap(err, "making merge request API call to GitLab")
+		}
+		// GitLab's merge request API only has the ID of the source project so
+		// we can't build the head repo of merge requests from forks.
+		if mr.SourceProjectID != mr.TargetProjectID {
+			return models.PullRequest{}, models.Repo{}, errors.New("merge requests from forks aren't supported")
+		}
+		return c.EventParser.ParseGitlabMergeRequest(mr, baseRepo), baseRepo, nil
+	case models.AzureDevops:
+		return c.getAzureDevopsData(baseRepo, pullNum)
+	case models.Gitea:
+		return c.getGiteaData(baseRepo, pullNum)
+	}
+	return models.PullRequest{}, models.Repo{}, fmt.Errorf("getting pull requests from %s isn't supported", baseRepo.VCSHost.Type.String())
+}
+
 func (c *DefaultCommandRunner) buildLogger(repoFullName string, pullNum int) *logging.SimpleLogger {
 	src := fmt.Sprintf("%s#%d", repoFullName, pullNum)
 	return c.Logger.NewLogger(src, true, c.Logger.GetLevel())
@@ -441,11 +1005,38 @@ func (c *DefaultCommandRunner) updatePull(ctx *CommandContext, command PullComma
 	}
 
 	comment := c.MarkdownRenderer.Render(res, command.CommandName(), ctx.Log.History.String(), command.IsVerbose(), ctx.BaseRepo.VCSHost.Type)
+	if c.StickyComments != nil {
+		err := c.StickyComments.Comment(ctx.BaseRepo, ctx.Pull.Num, stickyRun(ctx, command, res), comment)
+		if err == nil {
+			return
+		}
+		ctx.Log.Err("unable to update sticky comment, creating a new comment instead: %s", err)
+	}
 	if err := c.VCSClient.CreateComment(ctx.BaseRepo, ctx.Pull.Num, comment); err != nil {
 		ctx.Log.Err("unable to comment: %s", err)
 	}
 }
 
+// stickyRun returns the run of command with result res to record in the
+// sticky comment's history.
+func stickyRun(ctx *CommandContext, command PullCommand, res CommandResult) StickyRun {
+	run := StickyRun{
+		Command: command.CommandName().String(),
+		User:    ctx.User.Username,
+		Time:    time.Now(),
+		Status:  "success",
+	}
+	if _, ok := command.(AutoplanCommand); ok {
+		run.Command = "autoplan"
+	}
+	if res.Error != nil {
+		run.Status = "error"
+	} else if res.HasErrors() {
+		run.Status = "failed"
+	}
+	return run
+}
+
 // logPanics logs and creates a comment on the pull request for panics.
 func (c *DefaultCommandRunner) logPanics(baseRepo models.Repo, pullNum int, logger logging.SimpleLogging) {
 	if err := recover(); err != nil {
@@ -485,7 +1076,24 @@ func (c *DefaultCommandRunner) updateDB(ctx *CommandContext, pull models.PullReq
 		filtered = append(filtered, r)
 	}
 	ctx.Log.Debug("updating DB with pull results")
-	return c.DB.UpdatePullWithResults(pull, filtered)
+	pullStatus, err := c.DB.UpdatePullWithResults(pull, filtered)
+	if err != nil {
+		return pullStatus, err
+	}
+	c.saveProjectOutputs(ctx, pull, filtered)
+	return pullStatus, nil
+}
+
+// saveProjectOutputs stores the output of each project so it can be viewed in
+// the UI after the pull request comment has been hidden.
+func (c *DefaultCommandRunner) saveProjectOutputs(ctx *CommandContext, pull models.PullRequest, results []models.ProjectResult) {
+	now := time.Now()
+	redacted := c.Redactor.RedactResult(CommandResult{ProjectResults: results})
+	for _, r := range redacted.ProjectResults {
+		if err := c.DB.AddProjectOutput(pull, newProjectOutput(r, pull.HeadCommit, now)); err != nil {
+			ctx.Log.Warn("unable to save output for dir %q workspace %q: %s", r.RepoRelDir, r.Workspace, err)
+		}
+	}
 }
 
 // automergeEnabled returns true if automerging is enabled in this context.
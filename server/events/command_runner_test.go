@@ -24,11 +24,15 @@ import (
 	"github.com/google/go-github/v28/github"
 	. "github.com/petergtz/pegomock"
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/db"
 	"github.com/runatlantis/atlantis/server/events/mocks"
 	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	logmocks "github.com/runatlantis/atlantis/server/logging/mocks"
 	. "github.com/runatlantis/atlantis/testing"
 )
@@ -62,7 +66,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 		ThenReturn(pullLogger)
 	ch = events.DefaultCommandRunner{
 		VCSClient:                vcsClient,
-		CommitStatusUpdater:      &events.DefaultCommitStatusUpdater{vcsClient, "atlantis"},
+		CommitStatusUpdater:      &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: "atlantis"},
 		EventParser:              eventParsing,
 		MarkdownRenderer:         &events.MarkdownRenderer{},
 		GithubPullGetter:         githubGetter,
@@ -75,6 +79,7 @@ func setup(t *testing.T) *vcsmocks.MockClient {
 		ProjectCommandRunner:     projectCommandRunner,
 		PendingPlanFinder:        pendingPlanFinder,
 		WorkingDir:               workingDir,
+		WorkingDirLocker:         events.NewDefaultWorkingDirLocker(),
 		DisableApplyAll:          false,
 	}
 	return vcsClient
@@ -181,6 +186,34 @@ func TestRunCommentCommand_DisableApplyAllDisabled(t *testing.T) {
 	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, modelPull.Num, "**Error:** Running `atlantis apply` without flags is disabled. You must specify which project to apply via the `-d <dir>`, `-w <workspace>` or `-p <project name>` flags.")
 }
 
+func TestRunCommentCommand_Unauthorized(t *testing.T) {
+	t.Log("if the user isn't allowed to run the command atlantis should" +
+		" comment with why and not run it")
+	vcsClient := setup(t)
+	authorizer := mocks.NewMockCommandAuthorizer()
+	ch.CommandAuthorizer = authorizer
+	defer func() { ch.CommandAuthorizer = nil }()
+	When(authorizer.Authorize(fixtures.GithubRepo, fixtures.User, "apply")).ThenReturn("User `lkysow` is not allowed.", nil)
+
+	ch.RunCommentCommand(fixtures.GithubRepo, nil, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.ApplyCommand})
+	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "**Apply Denied**: User `lkysow` is not allowed.")
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(matchers.AnyModelsRepo(), AnyInt())
+}
+
+func TestRunCommentCommand_AuthorizeErr(t *testing.T) {
+	t.Log("if we can't determine whether the user is allowed atlantis should" +
+		" fail closed")
+	vcsClient := setup(t)
+	authorizer := mocks.NewMockCommandAuthorizer()
+	ch.CommandAuthorizer = authorizer
+	defer func() { ch.CommandAuthorizer = nil }()
+	When(authorizer.Authorize(fixtures.GithubRepo, fixtures.User, "plan")).ThenReturn("", errors.New("api down"))
+
+	ch.RunCommentCommand(fixtures.GithubRepo, nil, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.PlanCommand})
+	vcsClient.VerifyWasCalledOnce().CreateComment(fixtures.GithubRepo, fixtures.Pull.Num, "**Plan Denied**: Unable to determine if `lkysow` is allowed to run `plan`: api down")
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(matchers.AnyModelsRepo(), AnyInt())
+}
+
 func TestRunCommentCommand_ClosedPull(t *testing.T) {
 	t.Log("if a command is run on a closed pull request atlantis should" +
 		" comment saying that this is not allowed")
@@ -234,3 +267,446 @@ func TestRunAutoplanCommand_DeletePlans(t *testing.T) {
 	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
 	pendingPlanFinder.VerifyWasCalledOnce().DeletePlans(tmp)
 }
+
+func TestRunAutoplanCommand_SkipLabel(t *testing.T) {
+	setup(t)
+	ch.GlobalCfg = valid.NewGlobalCfg(false, false, false)
+	ch.GlobalCfg.Repos = append(ch.GlobalCfg.Repos, valid.Repo{
+		ID: fixtures.GithubRepo.ID(),
+		Labels: map[string]valid.LabelBehavior{
+			"atlantis/skip": {SkipAutoplan: true},
+		},
+	})
+	pull := fixtures.Pull
+	pull.Labels = []string{"atlantis/skip"}
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, pull, fixtures.User)
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+}
+
+// Test that the result is written to the sticky comment when sticky comments
+// are enabled.
+func TestRunAutoplanCommand_StickyComments(t *testing.T) {
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	ch.StickyComments = &events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsCommand}
+	defer func() {
+		ch.DB = nil
+		ch.StickyComments = nil
+	}()
+
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{{}}, nil)
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:     models.PlanCommand,
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "No changes."},
+	})
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+
+	vcsClient.VerifyWasCalledOnce().FindComment(fixtures.GithubRepo, fixtures.Pull.Num, "[//]: # (atlantis-sticky-comment:plan)")
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.HasPrefix(comment, "[//]: # (atlantis-sticky-comment:plan)\n"), "unexpected comment %q", comment)
+	Assert(t, strings.Contains(comment, "No changes."), "unexpected comment %q", comment)
+	Assert(t, strings.Contains(comment, fmt.Sprintf("* `autoplan` by %s at ", fixtures.User.Username)), "unexpected comment %q", comment)
+}
+
+// Test that when old plan comments are hidden too, the sticky comment isn't
+// one of the comments that are hidden.
+func TestRunAutoplanCommand_StickyCommentsHidePrevPlanComments(t *testing.T) {
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	ch.StickyComments = &events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsCommand}
+	ch.HidePrevPlanComments = true
+	defer func() {
+		ch.DB = nil
+		ch.StickyComments = nil
+		ch.HidePrevPlanComments = false
+	}()
+
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{{}}, nil)
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:     models.PlanCommand,
+		RepoRelDir:  ".",
+		Workspace:   "default",
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "No changes."},
+	})
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+
+	vcsClient.VerifyWasCalledOnce().HidePrevPlanComments(fixtures.GithubRepo, fixtures.Pull.Num)
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, !common.IsPlanComment(comment), "sticky comment %q would be hidden by the next plan", comment)
+}
+
+// Test that the output of each project is saved with secrets and terminal
+// color codes removed.
+func TestRunAutoplanCommand_SavesProjectOutputs(t *testing.T) {
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	ch.Redactor, err = events.NewSecretRedactor([]string{"hunter2"}, nil)
+	Ok(t, err)
+	defer func() {
+		ch.DB = nil
+		ch.Redactor = nil
+	}()
+
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{{}}, nil)
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:    models.PlanCommand,
+		RepoRelDir: ".",
+		Workspace:  "default",
+		PlanSuccess: &models.PlanSuccess{
+			TerraformOutput: "\x1b[1m\x1b[32m+ password = hunter2\x1b[0m",
+		},
+	})
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+
+	outputs, err := boltdb.GetProjectOutputs(fixtures.Pull, ".", "default")
+	Ok(t, err)
+	Equals(t, 1, len(outputs))
+	Equals(t, "+ password = [redacted]", outputs[0].Output)
+	Equals(t, models.PlanCommand, outputs[0].Command)
+	Equals(t, models.PlannedPlanStatus, outputs[0].Status)
+	Equals(t, fixtures.Pull.HeadCommit, outputs[0].HeadCommit)
+}
+
+// Test that webhooks are sent before and after each project is planned with
+// the redacted output.
+func TestRunAutoplanCommand_Webhooks(t *testing.T) {
+	setup(t)
+	mockSender := mocks.NewMockWebhooksSender()
+	ch.Webhooks = mockSender
+	jobURLGenerator := mocks.NewMockJobURLGenerator()
+	ch.JobURLGenerator = jobURLGenerator
+	var err error
+	ch.Redactor, err = events.NewSecretRedactor([]string{"hunter2"}, nil)
+	Ok(t, err)
+	defer func() {
+		ch.Webhooks = nil
+		ch.JobURLGenerator = nil
+		ch.Redactor = nil
+	}()
+
+	pCmd := models.ProjectCommandContext{
+		BaseRepo:    fixtures.GithubRepo,
+		Pull:        fixtures.Pull,
+		User:        fixtures.User,
+		ProjectName: "project",
+		RepoRelDir:  "dir",
+		Workspace:   "default",
+	}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{pCmd}, nil)
+	When(jobURLGenerator.GenerateProjectJobURL(matchers.AnyModelsProjectCommandContext())).ThenReturn("https://atlantis/jobs/id")
+	When(projectCommandRunner.Plan(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:    models.PlanCommand,
+		RepoRelDir: "dir",
+		Workspace:  "default",
+		PlanSuccess: &models.PlanSuccess{
+			TerraformOutput: "+ password = hunter2\n\nPlan: 1 to add, 0 to change, 0 to destroy.",
+		},
+	})
+	ch.RunAutoplanCommand(fixtures.GithubRepo, fixtures.GithubRepo, fixtures.Pull, fixtures.User)
+
+	_, sent := mockSender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	expEvent := webhooks.Event{
+		Type:        webhooks.PlanStartedEvent,
+		Repo:        fixtures.GithubRepo,
+		Pull:        fixtures.Pull,
+		User:        fixtures.User,
+		ProjectName: "project",
+		Directory:   "dir",
+		Workspace:   "default",
+		Success:     true,
+		JobURL:      "https://atlantis/jobs/id",
+	}
+	Equals(t, expEvent, sent[0])
+	expEvent.Type = webhooks.PlanFinishedEvent
+	expEvent.Output = "+ password = [redacted]\n\nPlan: 1 to add, 0 to change, 0 to destroy."
+	expEvent.PlanSummary = "Plan: 1 to add, 0 to change, 0 to destroy."
+	Equals(t, expEvent, sent[1])
+}
+
+// Test that when automerging, the project's automerge options are passed to
+// the VCS client with the commit message template rendered.
+func TestRunCommentCommand_AutomergeOptions(t *testing.T) {
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	ch.GlobalAutomerge = true
+	defer func() {
+		ch.DB = nil
+		ch.GlobalAutomerge = false
+	}()
+
+	pull := &github.PullRequest{
+		State: github.String("open"),
+	}
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num, HeadBranch: "feature"}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+	When(projectCommandBuilder.BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).
+		ThenReturn([]models.ProjectCommandContext{
+			{
+				AutomergeOptions: models.PullRequestOptions{
+					MergeMethod:               models.SquashMergeMethod,
+					DeleteSourceBranchOnMerge: true,
+					CommitMessage:             "Merge #{{ .Num }} from {{ .HeadBranch }}",
+				},
+			},
+		}, nil)
+	When(projectCommandRunner.Apply(matchers.AnyModelsProjectCommandContext())).ThenReturn(models.ProjectResult{
+		Command:      models.ApplyCommand,
+		RepoRelDir:   ".",
+		Workspace:    "default",
+		ApplySuccess: "success",
+	})
+
+	ch.RunCommentCommand(fixtures.GithubRepo, &fixtures.GithubRepo, nil, fixtures.User, fixtures.Pull.Num, &events.CommentCommand{Name: models.ApplyCommand})
+	vcsClient.VerifyWasCalledOnce().MergePull(modelPull, models.PullRequestOptions{
+		MergeMethod:               models.SquashMergeMethod,
+		DeleteSourceBranchOnMerge: true,
+		CommitMessage:             fmt.Sprintf("Merge #%d from feature", fixtures.Pull.Num),
+	})
+}
+
+// Test that when the base branch is pushed to, only the projects with
+// unapplied plans in pull requests into that branch are re-planned.
+func TestRunBaseBranchPushCommand(t *testing.T) {
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	defer func() { ch.DB = nil }()
+
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num, BaseBranch: "master", HeadCommit: "abc"}
+	_, err = boltdb.UpdatePullWithResults(modelPull, []models.ProjectResult{
+		{Command: models.PlanCommand, RepoRelDir: "planned", Workspace: "default", PlanSuccess: &models.PlanSuccess{}},
+		{Command: models.ApplyCommand, RepoRelDir: "applied", Workspace: "default", ApplySuccess: "success"},
+	})
+	Ok(t, err)
+	// A pull request into another branch shouldn't be re-planned.
+	otherPull := modelPull
+	otherPull.Num = 2
+	otherPull.BaseBranch = "other"
+	_, err = boltdb.UpdatePullWithResults(otherPull, []models.ProjectResult{
+		{Command: models.PlanCommand, RepoRelDir: "planned", Workspace: "default", PlanSuccess: &models.PlanSuccess{}},
+	})
+	Ok(t, err)
+
+	pull := &github.PullRequest{State: github.String("open")}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+	plannedCmd := models.ProjectCommandContext{RepoRelDir: "planned", Workspace: "default"}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{
+			plannedCmd,
+			{RepoRelDir: "applied", Workspace: "default"},
+		}, nil)
+	When(projectCommandRunner.Plan(plannedCmd)).ThenReturn(models.ProjectResult{
+		Command:     models.PlanCommand,
+		RepoRelDir:  "planned",
+		Workspace:   "default",
+		PlanSuccess: &models.PlanSuccess{TerraformOutput: "output"},
+	})
+
+	ch.RunBaseBranchPushCommand(fixtures.GithubRepo, "master")
+
+	githubGetter.VerifyWasCalled(Never()).GetPullRequest(fixtures.GithubRepo, 2)
+	workingDir.(*mocks.MockWorkingDir).VerifyWasCalledOnce().DeleteForWorkspace(fixtures.GithubRepo, modelPull, "default")
+	projectCommandRunner.VerifyWasCalledOnce().Plan(matchers.AnyModelsProjectCommandContext())
+	_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.HasPrefix(comment, ":arrows_counterclockwise: `master` was updated so Atlantis re-ran plan for the projects with unapplied plans.\n\n"), "comment should note the base branch was updated but was %q", comment)
+}
+
+// Test that workspaces that are in use aren't re-planned.
+func TestRunBaseBranchPushCommand_SkipsLockedWorkspace(t *testing.T) {
+	vcsClient := setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	defer func() { ch.DB = nil }()
+	locker := events.NewDefaultWorkingDirLocker()
+	ch.WorkingDirLocker = locker
+
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num, BaseBranch: "master", HeadCommit: "abc"}
+	_, err = boltdb.UpdatePullWithResults(modelPull, []models.ProjectResult{
+		{Command: models.PlanCommand, RepoRelDir: "planned", Workspace: "default", PlanSuccess: &models.PlanSuccess{}},
+	})
+	Ok(t, err)
+	pull := &github.PullRequest{State: github.String("open")}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+
+	unlockFn, err := locker.TryLock(fixtures.GithubRepo.FullName, fixtures.Pull.Num, "default")
+	Ok(t, err)
+	defer unlockFn()
+	ch.RunBaseBranchPushCommand(fixtures.GithubRepo, "master")
+
+	workingDir.(*mocks.MockWorkingDir).VerifyWasCalled(Never()).DeleteForWorkspace(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())
+	projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+	projectCommandRunner.VerifyWasCalled(Never()).Plan(matchers.AnyModelsProjectCommandContext())
+	vcsClient.VerifyWasCalled(Never()).CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString())
+	status, err := boltdb.GetPullStatus(modelPull)
+	Ok(t, err)
+	Equals(t, 1, status.StatusCount(models.PlannedPlanStatus))
+}
+
+// Test that a push while a pull request is being re-planned doesn't start
+// another re-plan but re-plans again once the running one finishes.
+func TestRunBaseBranchPushCommand_PushWhileReplanning(t *testing.T) {
+	setup(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	ch.DB = boltdb
+	defer func() { ch.DB = nil }()
+
+	modelPull := models.PullRequest{BaseRepo: fixtures.GithubRepo, State: models.OpenPullState, Num: fixtures.Pull.Num, BaseBranch: "master", HeadCommit: "abc"}
+	_, err = boltdb.UpdatePullWithResults(modelPull, []models.ProjectResult{
+		{Command: models.PlanCommand, RepoRelDir: "planned", Workspace: "default", PlanSuccess: &models.PlanSuccess{}},
+	})
+	Ok(t, err)
+	pull := &github.PullRequest{State: github.String("open")}
+	When(githubGetter.GetPullRequest(fixtures.GithubRepo, fixtures.Pull.Num)).ThenReturn(pull, nil)
+	When(eventParsing.ParseGithubPull(pull)).ThenReturn(modelPull, modelPull.BaseRepo, fixtures.GithubRepo, nil)
+	plannedCmd := models.ProjectCommandContext{RepoRelDir: "planned", Workspace: "default"}
+	When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+		ThenReturn([]models.ProjectCommandContext{plannedCmd}, nil)
+	plans := 0
+	When(projectCommandRunner.Plan(plannedCmd)).Then(func(params []Param) ReturnValues {
+		plans++
+		if plans == 1 {
+			// Simulate a push to the base branch while planning.
+			ch.RunBaseBranchPushCommand(fixtures.GithubRepo, "master")
+		}
+		return []ReturnValue{models.ProjectResult{
+			Command:     models.PlanCommand,
+			RepoRelDir:  "planned",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "output"},
+		}}
+	})
+
+	ch.RunBaseBranchPushCommand(fixtures.GithubRepo, "master")
+
+	Equals(t, 2, plans)
+	workingDir.(*mocks.MockWorkingDir).VerifyWasCalled(Times(2)).DeleteForWorkspace(fixtures.GithubRepo, modelPull, "default")
+
+	// Once the re-plans are done, the next push re-plans again.
+	ch.RunBaseBranchPushCommand(fixtures.GithubRepo, "master")
+	Equals(t, 3, plans)
+}
+
+func TestRunApplyAfterMergeCommand(t *testing.T) {
+	mergedPull := models.PullRequest{
+		Num:         fixtures.Pull.Num,
+		BaseRepo:    fixtures.GithubRepo,
+		BaseBranch:  "master",
+		HeadCommit:  "head",
+		State:       models.MergedPullState,
+		MergeCommit: "merge",
+	}
+	// Statuses and comments are for the merge commit.
+	expPull := mergedPull
+	expPull.HeadCommit = "merge"
+	planCmd := models.ProjectCommandContext{RepoRelDir: "dir", Workspace: "default"}
+	setupApplyAfterMerge := func(t *testing.T, enabled bool) (*vcsmocks.MockClient, *mocks.MockPullCleaner) {
+		vcsClient := setup(t)
+		cleaner := mocks.NewMockPullCleaner()
+		ch.PullCleaner = cleaner
+		ch.GlobalCfg = valid.NewGlobalCfg(false, false, false)
+		ch.GlobalCfg.Repos = append(ch.GlobalCfg.Repos, valid.Repo{
+			ID:              fixtures.GithubRepo.ID(),
+			ApplyAfterMerge: &enabled,
+		})
+		When(projectCommandBuilder.BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())).
+			ThenReturn([]models.ProjectCommandContext{planCmd}, nil)
+		return vcsClient, cleaner
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		_, cleaner := setupApplyAfterMerge(t, false)
+		ch.RunApplyAfterMergeCommand(fixtures.GithubRepo, mergedPull, fixtures.User)
+		projectCommandBuilder.VerifyWasCalled(Never()).BuildAutoplanCommands(matchers.AnyPtrToEventsCommandContext())
+		cleaner.VerifyWasCalled(Never()).CleanUpPull(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())
+	})
+
+	t.Run("applies after planning", func(t *testing.T) {
+		vcsClient, cleaner := setupApplyAfterMerge(t, true)
+		When(projectCommandRunner.Plan(planCmd)).ThenReturn(models.ProjectResult{
+			Command:     models.PlanCommand,
+			RepoRelDir:  "dir",
+			Workspace:   "default",
+			PlanSuccess: &models.PlanSuccess{TerraformOutput: "plan"},
+		})
+		When(projectCommandBuilder.BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())).
+			ThenReturn([]models.ProjectCommandContext{
+				{
+					RepoRelDir:        "dir",
+					Workspace:         "default",
+					ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
+				},
+			}, nil)
+		// Apply requirements are cleared since the pull request was merged.
+		When(projectCommandRunner.Apply(planCmd)).ThenReturn(models.ProjectResult{
+			Command:      models.ApplyCommand,
+			RepoRelDir:   "dir",
+			Workspace:    "default",
+			ApplySuccess: "applied",
+		})
+
+		ch.RunApplyAfterMergeCommand(fixtures.GithubRepo, mergedPull, fixtures.User)
+
+		projectCommandRunner.VerifyWasCalledOnce().Apply(planCmd)
+		_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+		Assert(t, strings.HasPrefix(comment, ":twisted_rightwards_arrows: This pull request was merged so Atlantis applied the projects it modified on `master`.\n\n"), "comment should note the pull request was applied after merge but was %q", comment)
+		vcsClient.VerifyWasCalledOnce().UpdateStatus(fixtures.GithubRepo, expPull, models.SuccessCommitStatus, "atlantis/apply", "1/1 projects applied successfully.", "")
+		// The pull request's checkout is deleted first and it's cleaned up
+		// once at the end.
+		workingDir.(*mocks.MockWorkingDir).VerifyWasCalledOnce().Delete(fixtures.GithubRepo, mergedPull)
+		cleaner.VerifyWasCalledOnce().CleanUpPull(fixtures.GithubRepo, expPull)
+	})
+
+	t.Run("doesn't apply if plans fail", func(t *testing.T) {
+		vcsClient, cleaner := setupApplyAfterMerge(t, true)
+		When(projectCommandRunner.Plan(planCmd)).ThenReturn(models.ProjectResult{
+			Command:    models.PlanCommand,
+			RepoRelDir: "dir",
+			Workspace:  "default",
+			Error:      errors.New("plan failed"),
+		})
+
+		ch.RunApplyAfterMergeCommand(fixtures.GithubRepo, mergedPull, fixtures.User)
+
+		projectCommandBuilder.VerifyWasCalled(Never()).BuildApplyCommands(matchers.AnyPtrToEventsCommandContext(), matchers.AnyPtrToEventsCommentCommand())
+		projectCommandRunner.VerifyWasCalled(Never()).Apply(matchers.AnyModelsProjectCommandContext())
+		_, _, comment := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+		Assert(t, strings.Contains(comment, "**Nothing was applied** because planning failed."), "comment should note nothing was applied but was %q", comment)
+		Assert(t, strings.Contains(comment, "plan failed"), "comment should contain the plan error but was %q", comment)
+		vcsClient.VerifyWasCalledOnce().UpdateStatus(fixtures.GithubRepo, expPull, models.FailedCommitStatus, "atlantis/apply", "Apply failed.", "")
+		cleaner.VerifyWasCalledOnce().CleanUpPull(fixtures.GithubRepo, expPull)
+	})
+}
@@ -14,7 +14,11 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/runatlantis/atlantis/server/events/models"
@@ -35,13 +39,22 @@ type CommitStatusUpdater interface {
 	// UpdateProject sets the commit status for the project represented by
 	// ctx.
 	UpdateProject(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus, url string) error
+	// UpdateProjectResult sets the commit status for the project represented
+	// by ctx to the status of projectResult. If the status is a check run, it
+	// includes projectResult's output.
+	UpdateProjectResult(ctx models.ProjectCommandContext, projectResult models.ProjectResult, url string) error
 }
 
 // DefaultCommitStatusUpdater implements CommitStatusUpdater.
 type DefaultCommitStatusUpdater struct {
 	Client vcs.Client
+	// ChecksClient, if set, is used to report project statuses on GitHub
+	// as check runs instead of commit statuses.
+	ChecksClient vcs.ChecksClient
 	// StatusName is the name used to identify Atlantis when creating PR statuses.
 	StatusName string
+	// Redactor masks secrets in the output included in check runs.
+	Redactor *SecretRedactor
 }
 
 func (d *DefaultCommitStatusUpdater) UpdateCombined(repo models.Repo, pull models.PullRequest, status models.CommitStatus, command models.CommandName) error {
@@ -69,20 +82,147 @@ func (d *DefaultCommitStatusUpdater) UpdateCombinedCount(repo models.Repo, pull
 }
 
 func (d *DefaultCommitStatusUpdater) UpdateProject(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus, url string) error {
+	src := d.projectStatusName(ctx, cmdName)
+	descrip := fmt.Sprintf("%s %s", strings.Title(cmdName.String()), statusDescription(status))
+	if d.useChecks(ctx) {
+		return d.ChecksClient.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, models.CheckRun{
+			Name:       src,
+			Status:     status,
+			DetailsURL: url,
+			ExternalID: checkRunExternalID(ctx),
+			Title:      descrip,
+			Summary:    descrip,
+		})
+	}
+	return d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, status, src, descrip, url)
+}
+
+func (d *DefaultCommitStatusUpdater) UpdateProjectResult(ctx models.ProjectCommandContext, result models.ProjectResult, url string) error {
+	if !d.useChecks(ctx) {
+		return d.UpdateProject(ctx, result.Command, result.CommitStatus(), url)
+	}
+
+	status := result.CommitStatus()
+	title := fmt.Sprintf("%s %s", strings.Title(result.Command.String()), statusDescription(status))
+	var summary string
+	var annotations []models.CheckRunAnnotation
+	switch {
+	case result.Error != nil:
+		summary = fmt.Sprintf("**%s Error**\n```\n%s\n```", strings.Title(result.Command.String()), result.Error.Error())
+		annotations = terraformErrorAnnotations(ctx.RepoRelDir, result.Error.Error())
+	case result.Failure != "":
+		summary = fmt.Sprintf("**%s Failed**: %s", strings.Title(result.Command.String()), result.Failure)
+	case result.PlanSuccess != nil:
+		if planSummary := result.PlanSuccess.Summary(); planSummary != "" {
+			title = planSummary
+		}
+		summary = fmt.Sprintf("```diff\n%s\n```", strings.TrimSpace(result.PlanSuccess.TerraformOutput))
+		if result.PlanSuccess.ApplyCmd != "" {
+			summary += fmt.Sprintf("\n\n* :arrow_forward: To **apply** this plan, comment:\n    * `%s`", result.PlanSuccess.ApplyCmd)
+		}
+		if result.PlanSuccess.RePlanCmd != "" {
+			summary += fmt.Sprintf("\n* :repeat: To **plan** this project again, comment `%s` or re-run this check.", result.PlanSuccess.RePlanCmd)
+		}
+	default:
+		summary = fmt.Sprintf("```diff\n%s\n```", strings.TrimSpace(result.ApplySuccess))
+	}
+	// Check runs are as public as comments so they must be redacted too.
+	for i := range annotations {
+		annotations[i].Title = d.Redactor.Redact(annotations[i].Title)
+		annotations[i].Message = d.Redactor.Redact(annotations[i].Message)
+	}
+	return d.ChecksClient.UpdateCheckRun(ctx.BaseRepo, ctx.Pull, models.CheckRun{
+		Name:        d.projectStatusName(ctx, result.Command),
+		Status:      status,
+		DetailsURL:  url,
+		ExternalID:  checkRunExternalID(ctx),
+		Title:       d.Redactor.Redact(title),
+		Summary:     d.Redactor.Redact(summary),
+		Annotations: annotations,
+	})
+}
+
+// useChecks returns true if the project's status should be a check run.
+func (d *DefaultCommitStatusUpdater) useChecks(ctx models.ProjectCommandContext) bool {
+	return d.ChecksClient != nil && ctx.BaseRepo.VCSHost.Type == models.Github
+}
+
+func (d *DefaultCommitStatusUpdater) projectStatusName(ctx models.ProjectCommandContext, cmdName models.CommandName) string {
 	projectID := ctx.ProjectName
 	if projectID == "" {
 		projectID = fmt.Sprintf("%s/%s", ctx.RepoRelDir, ctx.Workspace)
 	}
-	src := fmt.Sprintf("%s/%s: %s", d.StatusName, cmdName.String(), projectID)
-	var descripWords string
+	return fmt.Sprintf("%s/%s: %s", d.StatusName, cmdName.String(), projectID)
+}
+
+func statusDescription(status models.CommitStatus) string {
 	switch status {
 	case models.PendingCommitStatus:
-		descripWords = "in progress..."
+		return "in progress..."
 	case models.FailedCommitStatus:
-		descripWords = "failed."
+		return "failed."
 	case models.SuccessCommitStatus:
-		descripWords = "succeeded."
+		return "succeeded."
 	}
-	descrip := fmt.Sprintf("%s %s", strings.Title(cmdName.String()), descripWords)
-	return d.Client.UpdateStatus(ctx.BaseRepo, ctx.Pull, status, src, descrip, url)
+	return ""
+}
+
+// CheckRunProject identifies the project a check run is for. It's stored as
+// the check run's external ID so that re-running the check run can plan the
+// project again.
+type CheckRunProject struct {
+	RepoRelDir  string `json:"dir"`
+	Workspace   string `json:"workspace"`
+	ProjectName string `json:"project,omitempty"`
+}
+
+func checkRunExternalID(ctx models.ProjectCommandContext) string {
+	// Marshalling a struct of strings can't fail.
+	id, _ := json.Marshal(CheckRunProject{
+		RepoRelDir:  ctx.RepoRelDir,
+		Workspace:   ctx.Workspace,
+		ProjectName: ctx.ProjectName,
+	})
+	return string(id)
+}
+
+// terraformErrorRegex matches the start of an error printed by Terraform.
+var terraformErrorRegex = regexp.MustCompile(`(?m)^Error: `)
+
+// terraformErrorLocationRegex matches the title and location of an error
+// printed by Terraform, ex.
+//
+//	Error: Unsupported argument
+//
+//	  on main.tf line 3, in resource "null_resource" "a":
+var terraformErrorLocationRegex = regexp.MustCompile(`^Error: (.+)\n\s*\n\s+on (\S+) line (\d+)`)
+
+// terraformErrorAnnotations returns an annotation for each error in the
+// output of Terraform that points at a file. Files are relative to
+// repoRelDir.
+func terraformErrorAnnotations(repoRelDir string, output string) []models.CheckRunAnnotation {
+	var annotations []models.CheckRunAnnotation
+	starts := terraformErrorRegex.FindAllStringIndex(output, -1)
+	for i, start := range starts {
+		end := len(output)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		block := strings.TrimSpace(output[start[0]:end])
+		match := terraformErrorLocationRegex.FindStringSubmatch(block)
+		if match == nil {
+			continue
+		}
+		line, err := strconv.Atoi(match[3])
+		if err != nil {
+			continue
+		}
+		annotations = append(annotations, models.CheckRunAnnotation{
+			Path:    filepath.ToSlash(filepath.Join(repoRelDir, match[2])),
+			Line:    line,
+			Title:   match[1],
+			Message: block,
+		})
+	}
+	return annotations
 }
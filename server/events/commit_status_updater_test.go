@@ -14,6 +14,7 @@
 package events_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks/matchers"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -257,3 +259,138 @@ func TestDefaultCommitStatusUpdater_UpdateProjectCustomStatusName(t *testing.T)
 	client.VerifyWasCalledOnce().UpdateStatus(models.Repo{}, models.PullRequest{},
 		models.SuccessCommitStatus, "custom/apply: ./default", "Apply succeeded.", "url")
 }
+
+// Test that project results are sent as commit statuses unless the repo is
+// on GitHub and there's a checks client.
+func TestDefaultCommitStatusUpdater_UpdateProjectResultStatus(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	checksClient := mocks.NewMockChecksClient()
+	s := events.DefaultCommitStatusUpdater{Client: client, ChecksClient: checksClient, StatusName: "atlantis"}
+	repo := models.Repo{VCSHost: models.VCSHost{Type: models.Gitlab}}
+	err := s.UpdateProjectResult(models.ProjectCommandContext{
+		BaseRepo:   repo,
+		RepoRelDir: ".",
+		Workspace:  "default",
+	}, models.ProjectResult{
+		Command: models.PlanCommand,
+		Failure: "failure",
+	}, "url")
+	Ok(t, err)
+	client.VerifyWasCalledOnce().UpdateStatus(repo, models.PullRequest{}, models.FailedCommitStatus, "atlantis/plan: ./default", "Plan failed.", "url")
+	checksClient.VerifyWasCalled(Never()).UpdateCheckRun(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyModelsCheckRun())
+}
+
+func TestDefaultCommitStatusUpdater_UpdateProjectResultCheckRun(t *testing.T) {
+	repo := models.Repo{VCSHost: models.VCSHost{Type: models.Github}}
+	ctx := models.ProjectCommandContext{
+		BaseRepo:    repo,
+		RepoRelDir:  "dir",
+		Workspace:   "default",
+		ProjectName: "project1",
+	}
+	externalID := `{"dir":"dir","workspace":"default","project":"project1"}`
+	cases := []struct {
+		description string
+		result      models.ProjectResult
+		exp         models.CheckRun
+	}{
+		{
+			description: "plan success",
+			result: models.ProjectResult{
+				Command: models.PlanCommand,
+				PlanSuccess: &models.PlanSuccess{
+					TerraformOutput: "+ null_resource.a\n\nPlan: 1 to add, 0 to change, 0 to destroy.",
+					ApplyCmd:        "atlantis apply -p project1",
+					RePlanCmd:       "atlantis plan -p project1",
+				},
+			},
+			exp: models.CheckRun{
+				Name:       "atlantis/plan: project1",
+				Status:     models.SuccessCommitStatus,
+				DetailsURL: "url",
+				ExternalID: externalID,
+				Title:      "Plan: 1 to add, 0 to change, 0 to destroy.",
+				Summary: "```diff\n+ null_resource.a\n\nPlan: 1 to add, 0 to change, 0 to destroy.\n```" +
+					"\n\n* :arrow_forward: To **apply** this plan, comment:\n    * `atlantis apply -p project1`" +
+					"\n* :repeat: To **plan** this project again, comment `atlantis plan -p project1` or re-run this check.",
+			},
+		},
+		{
+			description: "plan error",
+			result: models.ProjectResult{
+				Command: models.PlanCommand,
+				Error: errors.New("exit status 1: running \"terraform plan\" in \"/tmp/dir\": \n" +
+					"Error: Unsupported argument\n\n  on main.tf line 3, in resource \"null_resource\" \"a\":\n   3:   foo = \"bar\"\n\nAn argument named \"foo\" is not expected here.\n\n" +
+					"Error: No configuration files\n"),
+			},
+			exp: models.CheckRun{
+				Name:       "atlantis/plan: project1",
+				Status:     models.FailedCommitStatus,
+				DetailsURL: "url",
+				ExternalID: externalID,
+				Title:      "Plan failed.",
+				Summary: "**Plan Error**\n```\nexit status 1: running \"terraform plan\" in \"/tmp/dir\": \n" +
+					"Error: Unsupported argument\n\n  on main.tf line 3, in resource \"null_resource\" \"a\":\n   3:   foo = \"bar\"\n\nAn argument named \"foo\" is not expected here.\n\n" +
+					"Error: No configuration files\n\n```",
+				Annotations: []models.CheckRunAnnotation{
+					{
+						Path:    "dir/main.tf",
+						Line:    3,
+						Title:   "Unsupported argument",
+						Message: "Error: Unsupported argument\n\n  on main.tf line 3, in resource \"null_resource\" \"a\":\n   3:   foo = \"bar\"\n\nAn argument named \"foo\" is not expected here.",
+					},
+				},
+			},
+		},
+		{
+			description: "apply success",
+			result: models.ProjectResult{
+				Command:      models.ApplyCommand,
+				ApplySuccess: "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.\n",
+			},
+			exp: models.CheckRun{
+				Name:       "atlantis/apply: project1",
+				Status:     models.SuccessCommitStatus,
+				DetailsURL: "url",
+				ExternalID: externalID,
+				Title:      "Apply succeeded.",
+				Summary:    "```diff\nApply complete! Resources: 1 added, 0 changed, 0 destroyed.\n```",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			client := mocks.NewMockClient()
+			checksClient := mocks.NewMockChecksClient()
+			s := events.DefaultCommitStatusUpdater{Client: client, ChecksClient: checksClient, StatusName: "atlantis"}
+			Ok(t, s.UpdateProjectResult(ctx, c.result, "url"))
+			checksClient.VerifyWasCalledOnce().UpdateCheckRun(repo, models.PullRequest{}, c.exp)
+			client.VerifyWasCalled(Never()).UpdateStatus(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyModelsCommitStatus(), AnyString(), AnyString(), AnyString())
+		})
+	}
+}
+
+func TestDefaultCommitStatusUpdater_UpdateProjectResultCheckRunRedacts(t *testing.T) {
+	RegisterMockTestingT(t)
+	client := mocks.NewMockClient()
+	checksClient := mocks.NewMockChecksClient()
+	redactor, err := events.NewSecretRedactor([]string{"password=(\\S+)"}, nil)
+	Ok(t, err)
+	s := events.DefaultCommitStatusUpdater{Client: client, ChecksClient: checksClient, StatusName: "atlantis", Redactor: redactor}
+	repo := models.Repo{VCSHost: models.VCSHost{Type: models.Github}}
+	ctx := models.ProjectCommandContext{
+		BaseRepo:   repo,
+		RepoRelDir: "dir",
+		Workspace:  "default",
+	}
+	Ok(t, s.UpdateProjectResult(ctx, models.ProjectResult{
+		Command: models.PlanCommand,
+		PlanSuccess: &models.PlanSuccess{
+			TerraformOutput: "+ password=hunter22",
+		},
+	}, "url"))
+	_, _, checkRun := checksClient.VerifyWasCalledOnce().UpdateCheckRun(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyModelsCheckRun()).GetCapturedArguments()
+	Equals(t, "```diff\n+ password=[redacted]\n```", checkRun.Summary)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -17,15 +18,32 @@ import (
 
 // BoltDB is a database using BoltDB
 type BoltDB struct {
-	db              *bolt.DB
-	locksBucketName []byte
-	pullsBucketName []byte
+	db                     *bolt.DB
+	locksBucketName        []byte
+	pullsBucketName        []byte
+	outputsBucketName      []byte
+	slackThreadsBucketName []byte
+
+	// healthMu guards lastHealthWrite and serializes pings.
+	healthMu        sync.Mutex
+	lastHealthWrite time.Time
 }
 
 const (
-	locksBucketName  = "runLocks"
-	pullsBucketName  = "pulls"
-	pullKeySeparator = "::"
+	locksBucketName        = "runLocks"
+	pullsBucketName        = "pulls"
+	outputsBucketName      = "outputs"
+	slackThreadsBucketName = "slackThreads"
+	healthBucketName       = "health"
+	pullKeySeparator       = "::"
+	// healthKey is the only key in the health bucket. Pings overwrite it.
+	healthKey = "ping"
+	// healthWriteInterval is the minimum time between pings that write to
+	// the database. Pings in between only read.
+	healthWriteInterval = 10 * time.Second
+	// maxProjectOutputs is how many outputs we keep per project. Older
+	// outputs are deleted.
+	maxProjectOutputs = 10
 )
 
 // New returns a valid locker. We need to be able to write to dataDir
@@ -50,18 +68,24 @@ func New(dataDir string) (*BoltDB, error) {
 		if _, err = tx.CreateBucketIfNotExists([]byte(pullsBucketName)); err != nil {
 			return errors.Wrapf(err, "creating bucket %q", pullsBucketName)
 		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(outputsBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", outputsBucketName)
+		}
+		if _, err = tx.CreateBucketIfNotExists([]byte(slackThreadsBucketName)); err != nil {
+			return errors.Wrapf(err, "creating bucket %q", slackThreadsBucketName)
+		}
 		return nil
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "starting BoltDB")
 	}
 	// todo: close BoltDB when server is sigtermed
-	return &BoltDB{db: db, locksBucketName: []byte(locksBucketName), pullsBucketName: []byte(pullsBucketName)}, nil
+	return &BoltDB{db: db, locksBucketName: []byte(locksBucketName), pullsBucketName: []byte(pullsBucketName), outputsBucketName: []byte(outputsBucketName), slackThreadsBucketName: []byte(slackThreadsBucketName)}, nil
 }
 
 // NewWithDB is used for testing.
 func NewWithDB(db *bolt.DB, bucket string) (*BoltDB, error) {
-	return &BoltDB{db: db, locksBucketName: []byte(bucket), pullsBucketName: []byte(pullsBucketName)}, nil
+	return &BoltDB{db: db, locksBucketName: []byte(bucket), pullsBucketName: []byte(pullsBucketName), outputsBucketName: []byte(outputsBucketName), slackThreadsBucketName: []byte(slackThreadsBucketName)}, nil
 }
 
 // TryLock attempts to create a new lock. If the lock is
@@ -147,7 +171,7 @@ func (b *BoltDB) List() ([]models.ProjectLock, error) {
 	for k, v := range locksBytes {
 		var lock models.ProjectLock
 		if err := json.Unmarshal(v, &lock); err != nil {
-			return locks, errors.Wrap(err, fmt.Sprintf("failed to deserialize lock at key %q", string(k)))
+			return locks, errors.Wrap(err, fmt.Sprintf("failed to deserialize lock at key %d", k))
 		}
 		locks = append(locks, lock)
 	}
@@ -274,6 +298,7 @@ func (b *BoltDB) UpdatePullWithResults(pull models.PullRequest, newResults []mod
 		}
 
 		// Now, we overwrite the key with our new status.
+		newStatus.UpdatedAt = time.Now()
 		return b.writePullToBucket(bucket, key, newStatus)
 	})
 	return newStatus, errors.Wrap(err, "DB transaction failed")
@@ -296,7 +321,26 @@ func (b *BoltDB) GetPullStatus(pull models.PullRequest) (*models.PullStatus, err
 	return s, errors.Wrap(err, "DB transaction failed")
 }
 
-// DeletePullStatus deletes the status for pull.
+// ListPullStatuses returns the statuses of all pull requests that Atlantis
+// has run commands on and that haven't been closed.
+func (b *BoltDB) ListPullStatuses() ([]models.PullStatus, error) {
+	var statuses []models.PullStatus
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.pullsBucketName)
+		return bucket.ForEach(func(k, v []byte) error {
+			var p models.PullStatus
+			if err := json.Unmarshal(v, &p); err != nil {
+				return errors.Wrapf(err, "deserializing pull at %q with contents %q", k, v)
+			}
+			statuses = append(statuses, p)
+			return nil
+		})
+	})
+	return statuses, errors.Wrap(err, "DB transaction failed")
+}
+
+// DeletePullStatus deletes the status for pull along with the outputs of
+// all its projects.
 func (b *BoltDB) DeletePullStatus(pull models.PullRequest) error {
 	key, err := b.pullKey(pull)
 	if err != nil {
@@ -304,7 +348,107 @@ func (b *BoltDB) DeletePullStatus(pull models.PullRequest) error {
 	}
 	err = b.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(b.pullsBucketName)
-		return bucket.Delete(key)
+		if err := bucket.Delete(key); err != nil {
+			return err
+		}
+
+		// Output and Slack thread keys are prefixed by the pull key.
+		prefix := append(key, []byte(pullKeySeparator)...)
+		if err := deleteWithPrefix(tx.Bucket(b.outputsBucketName), prefix); err != nil {
+			return err
+		}
+		// The bucket won't exist if the DB was created with NewWithDB.
+		if bucket := tx.Bucket(b.slackThreadsBucketName); bucket != nil {
+			return deleteWithPrefix(bucket, prefix)
+		}
+		return nil
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// deleteWithPrefix deletes all keys in bucket that start with prefix.
+func deleteWithPrefix(bucket *bolt.Bucket, prefix []byte) error {
+	var keys [][]byte
+	c := bucket.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, k)
+	}
+	// Can't delete while iterating with the cursor.
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProjectOutput stores output for the project at output.RepoRelDir and
+// output.Workspace in pull. Only the latest maxProjectOutputs outputs are
+// kept.
+func (b *BoltDB) AddProjectOutput(pull models.PullRequest, output models.ProjectOutput) error {
+	key, err := b.projectOutputsKey(pull, output.RepoRelDir, output.Workspace)
+	if err != nil {
+		return err
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.outputsBucketName)
+		outputs, err := b.getOutputsFromBucket(bucket, key)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, output)
+		if len(outputs) > maxProjectOutputs {
+			outputs = outputs[len(outputs)-maxProjectOutputs:]
+		}
+		serialized, err := json.Marshal(outputs)
+		if err != nil {
+			return errors.Wrap(err, "serializing")
+		}
+		return bucket.Put(key, serialized)
+	})
+	return errors.Wrap(err, "DB transaction failed")
+}
+
+// GetProjectOutputs returns the stored outputs for the project at repoRelDir
+// and workspace in pull, oldest first.
+func (b *BoltDB) GetProjectOutputs(pull models.PullRequest, repoRelDir string, workspace string) ([]models.ProjectOutput, error) {
+	key, err := b.projectOutputsKey(pull, repoRelDir, workspace)
+	if err != nil {
+		return nil, err
+	}
+	var outputs []models.ProjectOutput
+	err = b.db.View(func(tx *bolt.Tx) error {
+		var txErr error
+		outputs, txErr = b.getOutputsFromBucket(tx.Bucket(b.outputsBucketName), key)
+		return txErr
+	})
+	return outputs, errors.Wrap(err, "DB transaction failed")
+}
+
+// GetSlackThread returns the timestamp of the Slack thread for pull in
+// channel. It returns an empty string if there's no thread yet.
+func (b *BoltDB) GetSlackThread(pull models.PullRequest, channel string) (string, error) {
+	key, err := b.slackThreadKey(pull, channel)
+	if err != nil {
+		return "", err
+	}
+	var ts string
+	err = b.db.View(func(tx *bolt.Tx) error {
+		ts = string(tx.Bucket(b.slackThreadsBucketName).Get(key))
+		return nil
+	})
+	return ts, errors.Wrap(err, "DB transaction failed")
+}
+
+// SetSlackThread stores ts as the timestamp of the Slack thread for pull in
+// channel. The thread is deleted along with the pull's status.
+func (b *BoltDB) SetSlackThread(pull models.PullRequest, channel string, ts string) error {
+	key, err := b.slackThreadKey(pull, channel)
+	if err != nil {
+		return err
+	}
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.slackThreadsBucketName).Put(key, []byte(ts))
 	})
 	return errors.Wrap(err, "DB transaction failed")
 }
@@ -344,6 +488,53 @@ func (b *BoltDB) DeleteProjectStatus(pull models.PullRequest, workspace string,
 	return errors.Wrap(err, "DB transaction failed")
 }
 
+// Ping checks that the database can be written to and read from. It's used
+// by health checks to detect a wedged database. Each write overwrites the
+// same key and writes are limited to one per healthWriteInterval so frequent
+// probes don't grow the database or wear the disk. Pings in between only
+// read the last value written.
+func (b *BoltDB) Ping() error {
+	b.healthMu.Lock()
+	defer b.healthMu.Unlock()
+
+	var val []byte
+	if time.Since(b.lastHealthWrite) >= healthWriteInterval {
+		val = []byte(time.Now().UTC().Format(time.RFC3339Nano))
+		err := b.db.Update(func(tx *bolt.Tx) error {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(healthBucketName))
+			if err != nil {
+				return errors.Wrapf(err, "creating bucket %q", healthBucketName)
+			}
+			return bucket.Put([]byte(healthKey), val)
+		})
+		if err != nil {
+			return errors.Wrap(err, "writing to DB")
+		}
+		b.lastHealthWrite = time.Now()
+	}
+
+	var read []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(healthBucketName))
+		if bucket == nil {
+			return fmt.Errorf("bucket %q does not exist", healthBucketName)
+		}
+		// Copy since the value is only valid for the life of the transaction.
+		read = append(read, bucket.Get([]byte(healthKey))...)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "reading from DB")
+	}
+	if len(read) == 0 {
+		return fmt.Errorf("key %q in bucket %q is empty", healthKey, healthBucketName)
+	}
+	if val != nil && !bytes.Equal(read, val) {
+		return fmt.Errorf("read %q from DB but expected %q", read, val)
+	}
+	return nil
+}
+
 func (b *BoltDB) pullKey(pull models.PullRequest) ([]byte, error) {
 	hostname := pull.BaseRepo.VCSHost.Hostname
 	if strings.Contains(hostname, pullKeySeparator) {
@@ -358,6 +549,22 @@ func (b *BoltDB) pullKey(pull models.PullRequest) ([]byte, error) {
 		nil
 }
 
+func (b *BoltDB) projectOutputsKey(pull models.PullRequest, repoRelDir string, workspace string) ([]byte, error) {
+	key, err := b.pullKey(pull)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%s%s%s%s%s", key, pullKeySeparator, repoRelDir, pullKeySeparator, workspace)), nil
+}
+
+func (b *BoltDB) slackThreadKey(pull models.PullRequest, channel string) ([]byte, error) {
+	key, err := b.pullKey(pull)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fmt.Sprintf("%s%s%s", key, pullKeySeparator, channel)), nil
+}
+
 func (b *BoltDB) lockKey(p models.Project, workspace string) string {
 	return fmt.Sprintf("%s/%s/%s", p.RepoFullName, p.Path, workspace)
 }
@@ -375,6 +582,19 @@ func (b *BoltDB) getPullFromBucket(bucket *bolt.Bucket, key []byte) (*models.Pul
 	return &p, nil
 }
 
+func (b *BoltDB) getOutputsFromBucket(bucket *bolt.Bucket, key []byte) ([]models.ProjectOutput, error) {
+	serialized := bucket.Get(key)
+	if serialized == nil {
+		return nil, nil
+	}
+
+	var outputs []models.ProjectOutput
+	if err := json.Unmarshal(serialized, &outputs); err != nil {
+		return nil, errors.Wrapf(err, "deserializing outputs at %q", key)
+	}
+	return outputs, nil
+}
+
 func (b *BoltDB) writePullToBucket(bucket *bolt.Bucket, key []byte, pull models.PullStatus) error {
 	serialized, err := json.Marshal(pull)
 	if err != nil {
@@ -14,6 +14,7 @@
 package db_test
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -685,6 +686,176 @@ func TestPullStatus_UpdateMerge(t *testing.T) {
 	}
 }
 
+func TestListPullStatuses(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	statuses, err := b.ListPullStatuses()
+	Ok(t, err)
+	Equals(t, 0, len(statuses))
+
+	repo := models.Repo{
+		FullName: "runatlantis/atlantis",
+		VCSHost: models.VCSHost{
+			Hostname: "github.com",
+			Type:     models.Github,
+		},
+	}
+	before := time.Now()
+	for _, num := range []int{1, 2} {
+		_, err := b.UpdatePullWithResults(
+			models.PullRequest{Num: num, HeadCommit: "sha", BaseRepo: repo},
+			[]models.ProjectResult{
+				{
+					Command:    models.PlanCommand,
+					RepoRelDir: ".",
+					Workspace:  "default",
+					Failure:    "failure",
+				},
+			})
+		Ok(t, err)
+	}
+
+	statuses, err = b.ListPullStatuses()
+	Ok(t, err)
+	Equals(t, 2, len(statuses))
+	for i, s := range statuses {
+		Equals(t, i+1, s.Pull.Num)
+		Equals(t, []models.ProjectStatus{
+			{
+				Workspace:  "default",
+				RepoRelDir: ".",
+				Status:     models.ErroredPlanStatus,
+			},
+		}, s.Projects)
+		Assert(t, !s.UpdatedAt.Before(before.Truncate(time.Second)), "exp UpdatedAt to be set")
+	}
+}
+
+func TestProjectOutputs(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	outputs, err := b.GetProjectOutputs(pull, ".", "default")
+	Ok(t, err)
+	Equals(t, 0, len(outputs))
+
+	// Add more than the max so we can check the oldest are dropped.
+	var added []models.ProjectOutput
+	for i := 0; i < 12; i++ {
+		output := models.ProjectOutput{
+			RepoRelDir: ".",
+			Workspace:  "default",
+			Command:    models.PlanCommand,
+			Status:     models.PlannedPlanStatus,
+			HeadCommit: "sha",
+			Output:     fmt.Sprintf("output %d", i),
+			Time:       time.Now().UTC(),
+		}
+		Ok(t, b.AddProjectOutput(pull, output))
+		added = append(added, output)
+	}
+	// Output for a different workspace shouldn't be returned.
+	Ok(t, b.AddProjectOutput(pull, models.ProjectOutput{RepoRelDir: ".", Workspace: "staging"}))
+
+	outputs, err = b.GetProjectOutputs(pull, ".", "default")
+	Ok(t, err)
+	Equals(t, added[2:], outputs)
+
+	// Outputs for a pull whose number has the same prefix shouldn't be
+	// deleted with the pull.
+	pull10 := pull
+	pull10.Num = 10
+	Ok(t, b.AddProjectOutput(pull10, models.ProjectOutput{RepoRelDir: ".", Workspace: "default"}))
+
+	Ok(t, b.DeletePullStatus(pull))
+	outputs, err = b.GetProjectOutputs(pull, ".", "default")
+	Ok(t, err)
+	Equals(t, 0, len(outputs))
+	outputs, err = b.GetProjectOutputs(pull, ".", "staging")
+	Ok(t, err)
+	Equals(t, 0, len(outputs))
+	outputs, err = b.GetProjectOutputs(pull10, ".", "default")
+	Ok(t, err)
+	Equals(t, 1, len(outputs))
+}
+
+func TestSlackThreads(t *testing.T) {
+	b, cleanup := newTestDB2(t)
+	defer cleanup()
+
+	pull := models.PullRequest{
+		Num: 1,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			VCSHost: models.VCSHost{
+				Hostname: "github.com",
+				Type:     models.Github,
+			},
+		},
+	}
+	ts, err := b.GetSlackThread(pull, "channel")
+	Ok(t, err)
+	Equals(t, "", ts)
+
+	Ok(t, b.SetSlackThread(pull, "channel", "1234.5678"))
+	Ok(t, b.SetSlackThread(pull, "other-channel", "8765.4321"))
+	ts, err = b.GetSlackThread(pull, "channel")
+	Ok(t, err)
+	Equals(t, "1234.5678", ts)
+	ts, err = b.GetSlackThread(pull, "other-channel")
+	Ok(t, err)
+	Equals(t, "8765.4321", ts)
+
+	// Threads should be deleted with the pull.
+	Ok(t, b.DeletePullStatus(pull))
+	ts, err = b.GetSlackThread(pull, "channel")
+	Ok(t, err)
+	Equals(t, "", ts)
+	ts, err = b.GetSlackThread(pull, "other-channel")
+	Ok(t, err)
+	Equals(t, "", ts)
+}
+
+func TestPing(t *testing.T) {
+	boltDB, b := newTestDB()
+	defer cleanupDB(boltDB)
+	Ok(t, b.Ping())
+	written := readHealthKey(t, boltDB)
+	Assert(t, written != "", "exp Ping to write the health key")
+
+	// Pings right after a write only read so they don't write on every
+	// probe.
+	Ok(t, b.Ping())
+	Equals(t, written, readHealthKey(t, boltDB))
+
+	// The health bucket only ever has one key.
+	Ok(t, boltDB.View(func(tx *bolt.Tx) error {
+		Equals(t, 1, tx.Bucket([]byte("health")).Stats().KeyN)
+		return nil
+	}))
+}
+
+func readHealthKey(t *testing.T, boltDB *bolt.DB) string {
+	var val string
+	Ok(t, boltDB.View(func(tx *bolt.Tx) error {
+		val = string(tx.Bucket([]byte("health")).Get([]byte("ping")))
+		return nil
+	}))
+	return val
+}
+
 // newTestDB returns a TestDB using a temporary path.
 func newTestDB() (*bolt.DB, *db.BoltDB) {
 	// Retrieve a temporary path.
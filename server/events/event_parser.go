@@ -26,11 +26,14 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/xanzy/go-gitlab"
 	"gopkg.in/go-playground/validator.v9"
 )
 
 const gitlabPullOpened = "opened"
+const gitlabPullMerged = "merged"
+const branchRefPrefix = "refs/heads/"
 const usagesCols = 90
 
 // PullCommand is a command to run on a pull request.
@@ -143,6 +146,15 @@ type EventParsing interface {
 	ParseGithubIssueCommentEvent(comment *github.IssueCommentEvent) (
 		baseRepo models.Repo, user models.User, pullNum int, err error)
 
+	// ParseGithubCheckRunEvent parses GitHub check run events for check runs
+	// created by Atlantis.
+	// baseRepo is the repo that the pull request will be merged into.
+	// user is the user that triggered the event, ex. by re-running the check.
+	// pullNum is the number of the pull request the check run is for.
+	// cmd is the command to plan the project the check run is for.
+	ParseGithubCheckRunEvent(event *github.CheckRunEvent) (
+		baseRepo models.Repo, user models.User, pullNum int, cmd *CommentCommand, err error)
+
 	// ParseGithubPull parses the response from the GitHub API endpoint (not
 	// from a webhook) that returns a pull request.
 	// pull is the parsed pull request.
@@ -165,6 +177,12 @@ type EventParsing interface {
 	// returns a repo into the Atlantis model.
 	ParseGithubRepo(ghRepo *github.Repository) (models.Repo, error)
 
+	// ParseGithubPushEvent parses GitHub push events.
+	// repo is the repo that was pushed to.
+	// branch is the branch that was pushed to. It's empty if the push was to
+	// a tag or deleted the branch.
+	ParseGithubPushEvent(event *github.PushEvent) (repo models.Repo, branch string, err error)
+
 	// ParseGitlabMergeRequestEvent parses GitLab merge request events.
 	// pull is the parsed merge request.
 	// pullEventType is the type of event, for example opened/closed.
@@ -187,6 +205,12 @@ type EventParsing interface {
 	// that returns a merge request.
 	ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo models.Repo) models.PullRequest
 
+	// ParseGitlabPushEvent parses GitLab push events.
+	// repo is the repo that was pushed to.
+	// branch is the branch that was pushed to. It's empty if the push
+	// deleted the branch.
+	ParseGitlabPushEvent(event gitlab.PushEvent) (repo models.Repo, branch string, err error)
+
 	// ParseBitbucketCloudPullEvent parses a pull request event from Bitbucket
 	// Cloud (bitbucket.org).
 	// pull is the parsed pull request.
@@ -258,6 +282,31 @@ type EventParsing interface {
 	// ParseAzureDevopsRepo parses the response from the Azure DevOps API endpoint that
 	// returns a repo into the Atlantis model.
 	ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (models.Repo, error)
+
+	// ParseGiteaPull parses the response from the Gitea API endpoint (not
+	// from a webhook) that returns a pull request.
+	// pull is the parsed pull request.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	ParseGiteaPull(giteaPull *gitea.PullRequest) (
+		pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error)
+
+	// ParseGiteaPullEvent parses Gitea pull request events.
+	// pull is the parsed pull request.
+	// pullEventType is the type of event, for example opened/closed.
+	// baseRepo is the repo the pull request will be merged into.
+	// headRepo is the repo the pull request branch is from.
+	// user is the user that triggered the event.
+	ParseGiteaPullEvent(event *gitea.PullRequestEvent) (
+		pull models.PullRequest, pullEventType models.PullRequestEventType,
+		baseRepo models.Repo, headRepo models.Repo, user models.User, err error)
+
+	// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+	// baseRepo is the repo the pull request will be merged into.
+	// user is the user that left the comment.
+	// pullNum is the number of the pull request that was commented on.
+	ParseGiteaIssueCommentEvent(event *gitea.IssueCommentEvent) (
+		baseRepo models.Repo, user models.User, pullNum int, err error)
 }
 
 // EventParser parses VCS events.
@@ -271,6 +320,8 @@ type EventParser struct {
 	BitbucketServerURL string
 	AzureDevopsToken   string
 	AzureDevopsUser    string
+	GiteaUser          string
+	GiteaToken         string
 }
 
 // GetBitbucketCloudPullEventType returns the type of the pull request
@@ -311,7 +362,7 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 	case "OPEN":
 		prState = models.OpenPullState
 	case "MERGED":
-		prState = models.ClosedPullState
+		prState = models.MergedPullState
 	case "SUPERSEDED":
 		prState = models.ClosedPullState
 	case "DECLINED":
@@ -350,6 +401,9 @@ func (e *EventParser) parseCommonBitbucketCloudEventData(event bitbucketcloud.Co
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
+	if event.PullRequest.MergeCommit != nil {
+		pull.MergeCommit = *event.PullRequest.MergeCommit.Hash
+	}
 	user = models.User{
 		Username: *event.Actor.Nickname,
 	}
@@ -396,6 +450,44 @@ func (e *EventParser) ParseGithubIssueCommentEvent(comment *github.IssueCommentE
 	return
 }
 
+// ParseGithubCheckRunEvent parses GitHub check run events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGithubCheckRunEvent(event *github.CheckRunEvent) (baseRepo models.Repo, user models.User, pullNum int, cmd *CommentCommand, err error) {
+	baseRepo, err = e.ParseGithubRepo(event.Repo)
+	if err != nil {
+		return
+	}
+	if event.Sender.GetLogin() == "" {
+		err = errors.New("sender.login is null")
+		return
+	}
+	user = models.User{
+		Username: event.Sender.GetLogin(),
+	}
+	if event.CheckRun == nil || len(event.CheckRun.PullRequests) == 0 {
+		err = errors.New("check_run.pull_requests is empty")
+		return
+	}
+	pullNum = event.CheckRun.PullRequests[0].GetNumber()
+
+	var project CheckRunProject
+	if jsonErr := json.Unmarshal([]byte(event.CheckRun.GetExternalID()), &project); jsonErr != nil {
+		err = errors.Wrapf(jsonErr, "parsing check_run.external_id %q", event.CheckRun.GetExternalID())
+		return
+	}
+	// Projects with names are planned by name since the name is enough to
+	// identify them.
+	cmd = &CommentCommand{
+		Name:        models.PlanCommand,
+		ProjectName: project.ProjectName,
+	}
+	if project.ProjectName == "" {
+		cmd.RepoRelDir = project.RepoRelDir
+		cmd.Workspace = project.Workspace
+	}
+	return
+}
+
 // ParseGithubPullEvent parses GitHub pull request events.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGithubPullEvent(pullEvent *github.PullRequestEvent) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
@@ -433,6 +525,8 @@ func (e *EventParser) ParseGithubPullEvent(pullEvent *github.PullRequestEvent) (
 			pullEventType = models.UpdatedPullEvent
 		case "closed":
 			pullEventType = models.ClosedPullEvent
+		case "labeled", "unlabeled":
+			pullEventType = models.LabeledPullEvent
 		default:
 			pullEventType = models.OtherPullEvent
 		}
@@ -487,19 +581,30 @@ func (e *EventParser) ParseGithubPull(pull *github.PullRequest) (pullModel model
 	}
 
 	pullState := models.ClosedPullState
+	var mergeCommit string
 	if pull.GetState() == "open" {
 		pullState = models.OpenPullState
+	} else if pull.GetMerged() {
+		pullState = models.MergedPullState
+		mergeCommit = pull.GetMergeCommitSHA()
+	}
+
+	var labels []string
+	for _, label := range pull.Labels {
+		labels = append(labels, label.GetName())
 	}
 
 	pullModel = models.PullRequest{
-		Author:     authorUsername,
-		HeadBranch: headBranch,
-		HeadCommit: commit,
-		URL:        url,
-		Num:        num,
-		State:      pullState,
-		BaseRepo:   baseRepo,
-		BaseBranch: baseBranch,
+		Author:      authorUsername,
+		HeadBranch:  headBranch,
+		HeadCommit:  commit,
+		URL:         url,
+		Num:         num,
+		State:       pullState,
+		MergeCommit: mergeCommit,
+		Labels:      labels,
+		BaseRepo:    baseRepo,
+		BaseBranch:  baseBranch,
 	}
 	return
 }
@@ -511,16 +616,34 @@ func (e *EventParser) ParseGithubRepo(ghRepo *github.Repository) (models.Repo, e
 	return models.NewRepo(models.Github, ghRepo.GetFullName(), ghRepo.GetCloneURL(), e.GithubUser, e.GithubToken)
 }
 
+// ParseGithubPushEvent parses GitHub push events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGithubPushEvent(event *github.PushEvent) (repo models.Repo, branch string, err error) {
+	if event.Repo == nil {
+		err = errors.New("repository is null")
+		return
+	}
+	repo, err = models.NewRepo(models.Github, event.Repo.GetFullName(), event.Repo.GetCloneURL(), e.GithubUser, e.GithubToken)
+	if err != nil {
+		return
+	}
+	if !event.GetDeleted() {
+		branch = branchFromRef(event.GetRef())
+	}
+	return
+}
+
 // ParseGitlabMergeRequestEvent parses GitLab merge request events.
 // pull is the parsed merge request.
 // See EventParsing for return value docs.
 func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pull models.PullRequest, eventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
 	modelState := models.ClosedPullState
-	if event.ObjectAttributes.State == gitlabPullOpened {
+	switch event.ObjectAttributes.State {
+	case gitlabPullOpened:
 		modelState = models.OpenPullState
+	case gitlabPullMerged:
+		modelState = models.MergedPullState
 	}
-	// GitLab also has a "merged" state, but we map that to Closed so we don't
-	// need to check for it.
 
 	baseRepo, err = models.NewRepo(models.Gitlab, event.Project.PathWithNamespace, event.Project.GitHTTPURL, e.GitlabUser, e.GitlabToken)
 	if err != nil {
@@ -532,14 +655,18 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 	}
 
 	pull = models.PullRequest{
-		URL:        event.ObjectAttributes.URL,
-		Author:     event.User.Username,
-		Num:        event.ObjectAttributes.IID,
-		HeadCommit: event.ObjectAttributes.LastCommit.ID,
-		HeadBranch: event.ObjectAttributes.SourceBranch,
-		BaseBranch: event.ObjectAttributes.TargetBranch,
-		State:      modelState,
-		BaseRepo:   baseRepo,
+		URL:         event.ObjectAttributes.URL,
+		Author:      event.User.Username,
+		Num:         event.ObjectAttributes.IID,
+		HeadCommit:  event.ObjectAttributes.LastCommit.ID,
+		HeadBranch:  event.ObjectAttributes.SourceBranch,
+		BaseBranch:  event.ObjectAttributes.TargetBranch,
+		State:       modelState,
+		MergeCommit: event.ObjectAttributes.MergeCommitSHA,
+		BaseRepo:    baseRepo,
+	}
+	for _, label := range event.Labels {
+		pull.Labels = append(pull.Labels, label.Name)
 	}
 
 	switch event.ObjectAttributes.Action {
@@ -547,6 +674,12 @@ func (e *EventParser) ParseGitlabMergeRequestEvent(event gitlab.MergeEvent) (pul
 		eventType = models.OpenedPullEvent
 	case "update":
 		eventType = models.UpdatedPullEvent
+		// GitLab only sets oldrev if new commits were pushed so if it's
+		// empty and the labels changed, this update only changed labels.
+		labelChanges := event.Changes.Labels
+		if event.ObjectAttributes.OldRev == "" && (len(labelChanges.Previous) > 0 || len(labelChanges.Current) > 0) {
+			eventType = models.LabeledPullEvent
+		}
 	case "merge", "close":
 		eventType = models.ClosedPullEvent
 	default:
@@ -588,22 +721,52 @@ func (e *EventParser) ParseGitlabMergeRequestCommentEvent(event gitlab.MergeComm
 // data so we can construct the pull request object correctly.
 func (e *EventParser) ParseGitlabMergeRequest(mr *gitlab.MergeRequest, baseRepo models.Repo) models.PullRequest {
 	pullState := models.ClosedPullState
-	if mr.State == gitlabPullOpened {
+	switch mr.State {
+	case gitlabPullOpened:
 		pullState = models.OpenPullState
+	case gitlabPullMerged:
+		pullState = models.MergedPullState
 	}
-	// GitLab also has a "merged" state, but we map that to Closed so we don't
-	// need to check for it.
+
+	// Appending to a nil slice keeps labels nil if there are none.
+	var labels []string
+	labels = append(labels, mr.Labels...)
 
 	return models.PullRequest{
-		URL:        mr.WebURL,
-		Author:     mr.Author.Username,
-		Num:        mr.IID,
-		HeadCommit: mr.SHA,
-		HeadBranch: mr.SourceBranch,
-		BaseBranch: mr.TargetBranch,
-		State:      pullState,
-		BaseRepo:   baseRepo,
+		URL:         mr.WebURL,
+		Author:      mr.Author.Username,
+		Num:         mr.IID,
+		HeadCommit:  mr.SHA,
+		HeadBranch:  mr.SourceBranch,
+		BaseBranch:  mr.TargetBranch,
+		State:       pullState,
+		MergeCommit: mr.MergeCommitSHA,
+		Labels:      labels,
+		BaseRepo:    baseRepo,
+	}
+}
+
+// ParseGitlabPushEvent parses GitLab push events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGitlabPushEvent(event gitlab.PushEvent) (repo models.Repo, branch string, err error) {
+	repo, err = models.NewRepo(models.Gitlab, event.Project.PathWithNamespace, event.Project.GitHTTPURL, e.GitlabUser, e.GitlabToken)
+	if err != nil {
+		return
+	}
+	// GitLab sets after to all zeros when the branch is deleted.
+	if strings.Trim(event.After, "0") != "" {
+		branch = branchFromRef(event.Ref)
+	}
+	return
+}
+
+// branchFromRef returns the branch name of ref, ex. refs/heads/master =>
+// master. If ref isn't a branch, ex. it's a tag, it returns an empty string.
+func branchFromRef(ref string) string {
+	if !strings.HasPrefix(ref, branchRefPrefix) {
+		return ""
 	}
+	return strings.TrimPrefix(ref, branchRefPrefix)
 }
 
 // GetBitbucketServerPullEventType returns the type of the pull request
@@ -642,7 +805,7 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 	case "OPEN":
 		prState = models.OpenPullState
 	case "MERGED":
-		prState = models.ClosedPullState
+		prState = models.MergedPullState
 	case "DECLINED":
 		prState = models.ClosedPullState
 	default:
@@ -686,6 +849,9 @@ func (e *EventParser) parseCommonBitbucketServerEventData(event bitbucketserver.
 		State:      prState,
 		BaseRepo:   baseRepo,
 	}
+	if props := event.PullRequest.Properties; props != nil && props.MergeCommit != nil && props.MergeCommit.ID != nil {
+		pull.MergeCommit = *props.MergeCommit.ID
+	}
 	user = models.User{
 		Username: *event.Actor.Username,
 	}
@@ -736,7 +902,7 @@ func (e *EventParser) ParseAzureDevopsPullEvent(event azuredevops.Event) (pull m
 		pullEventType = models.OpenedPullEvent
 	case "git.pullrequest.updated":
 		pullEventType = models.UpdatedPullEvent
-		if pull.State == models.ClosedPullState {
+		if pull.State != models.OpenPullState {
 			pullEventType = models.ClosedPullEvent
 		}
 	default:
@@ -794,20 +960,32 @@ func (e *EventParser) ParseAzureDevopsPull(pull *azuredevops.GitPullRequest) (pu
 		return
 	}
 	pullState := models.ClosedPullState
-	if *pull.Status == azuredevops.PullActive.String() {
+	var mergeCommit string
+	switch *pull.Status {
+	case azuredevops.PullActive.String():
 		pullState = models.OpenPullState
+	case azuredevops.PullCompleted.String():
+		pullState = models.MergedPullState
+		mergeCommit = pull.GetLastMergeCommit().GetCommitID()
+	}
+
+	var labels []string
+	for _, label := range pull.Labels {
+		labels = append(labels, label.GetName())
 	}
 
 	pullModel = models.PullRequest{
 		Author: authorUsername,
 		// Change webhook refs from "refs/heads/<branch>" to "<branch>"
-		HeadBranch: strings.Replace(headBranch, "refs/heads/", "", 1),
-		HeadCommit: commit,
-		URL:        url,
-		Num:        num,
-		State:      pullState,
-		BaseRepo:   baseRepo,
-		BaseBranch: strings.Replace(baseBranch, "refs/heads/", "", 1),
+		HeadBranch:  strings.Replace(headBranch, "refs/heads/", "", 1),
+		HeadCommit:  commit,
+		URL:         url,
+		Num:         num,
+		State:       pullState,
+		MergeCommit: mergeCommit,
+		Labels:      labels,
+		BaseRepo:    baseRepo,
+		BaseBranch:  strings.Replace(baseBranch, "refs/heads/", "", 1),
 	}
 	return
 }
@@ -850,3 +1028,99 @@ func (e *EventParser) ParseAzureDevopsRepo(adRepo *azuredevops.GitRepository) (m
 	fullName := fmt.Sprintf("%s/%s/%s", owner, project, repo)
 	return models.NewRepo(models.AzureDevops, fullName, cloneURL, e.AzureDevopsUser, e.AzureDevopsToken)
 }
+
+// ParseGiteaPullEvent parses Gitea pull request events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPullEvent(event *gitea.PullRequestEvent) (pull models.PullRequest, pullEventType models.PullRequestEventType, baseRepo models.Repo, headRepo models.Repo, user models.User, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrap(err, "event was missing fields")
+		return
+	}
+	pull, baseRepo, headRepo, err = e.ParseGiteaPull(event.PullRequest)
+	if err != nil {
+		return
+	}
+
+	switch event.Action {
+	case "opened", "reopened":
+		pullEventType = models.OpenedPullEvent
+	case "synchronized":
+		pullEventType = models.UpdatedPullEvent
+	case "closed":
+		pullEventType = models.ClosedPullEvent
+	default:
+		// Gitea's label events don't say which labels changed so they're
+		// ignored along with other events.
+		pullEventType = models.OtherPullEvent
+	}
+	user = models.User{Username: *event.Sender.Login}
+	return
+}
+
+// ParseGiteaPull parses the response from the Gitea API endpoint (not
+// from a webhook) that returns a pull request.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaPull(giteaPull *gitea.PullRequest) (pull models.PullRequest, baseRepo models.Repo, headRepo models.Repo, err error) {
+	if err = validator.New().Struct(giteaPull); err != nil {
+		err = errors.Wrap(err, "pull request was missing fields")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(giteaPull.Base.Repo)
+	if err != nil {
+		return
+	}
+	headRepo, err = e.parseGiteaRepo(giteaPull.Head.Repo)
+	if err != nil {
+		return
+	}
+
+	pullState := models.ClosedPullState
+	var mergeCommit string
+	if *giteaPull.State == "open" {
+		pullState = models.OpenPullState
+	} else if giteaPull.Merged {
+		pullState = models.MergedPullState
+		if giteaPull.MergeCommitSHA != nil {
+			mergeCommit = *giteaPull.MergeCommitSHA
+		}
+	}
+
+	var labels []string
+	for _, label := range giteaPull.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	pull = models.PullRequest{
+		Author:      *giteaPull.User.Login,
+		HeadBranch:  *giteaPull.Head.Ref,
+		HeadCommit:  *giteaPull.Head.SHA,
+		URL:         *giteaPull.HTMLURL,
+		Num:         *giteaPull.Number,
+		State:       pullState,
+		MergeCommit: mergeCommit,
+		Labels:      labels,
+		BaseRepo:    baseRepo,
+		BaseBranch:  *giteaPull.Base.Ref,
+	}
+	return
+}
+
+// ParseGiteaIssueCommentEvent parses Gitea pull request comment events.
+// See EventParsing for return value docs.
+func (e *EventParser) ParseGiteaIssueCommentEvent(event *gitea.IssueCommentEvent) (baseRepo models.Repo, user models.User, pullNum int, err error) {
+	if err = validator.New().Struct(event); err != nil {
+		err = errors.Wrap(err, "event was missing fields")
+		return
+	}
+	baseRepo, err = e.parseGiteaRepo(event.Repository)
+	if err != nil {
+		return
+	}
+	user = models.User{Username: *event.Comment.User.Login}
+	pullNum = *event.Issue.Number
+	return
+}
+
+func (e *EventParser) parseGiteaRepo(repo *gitea.Repository) (models.Repo, error) {
+	return models.NewRepo(models.Gitea, *repo.FullName, *repo.CloneURL, e.GiteaUser, e.GiteaToken)
+}
@@ -39,6 +39,9 @@ type MarkdownRenderer struct {
 	GitlabSupportsCommonMark bool
 	DisableApplyAll          bool
 	DisableMarkdownFolding   bool
+	// Redactor masks secrets in the output before it's rendered. If nil,
+	// nothing is redacted.
+	Redactor *SecretRedactor
 }
 
 // commonData is data that all responses have.
@@ -83,6 +86,8 @@ type projectResultTmplData struct {
 // Render formats the data into a markdown string.
 // nolint: interfacer
 func (m *MarkdownRenderer) Render(res CommandResult, cmdName models.CommandName, log string, verbose bool, vcsHost models.VCSHostType) string {
+	res = m.Redactor.RedactResult(res)
+	log = m.Redactor.Redact(log)
 	commandStr := strings.Title(cmdName.String())
 	common := commonData{
 		Command:         commandStr,
@@ -91,13 +96,26 @@ func (m *MarkdownRenderer) Render(res CommandResult, cmdName models.CommandName,
 		PlansDeleted:    res.PlansDeleted,
 		DisableApplyAll: m.DisableApplyAll,
 	}
-	if res.Error != nil {
-		return m.renderTemplate(unwrappedErrWithLogTmpl, errData{res.Error.Error(), common})
+	var rendered string
+	switch {
+	case res.Error != nil:
+		rendered = m.renderTemplate(unwrappedErrWithLogTmpl, errData{res.Error.Error(), common})
+	case res.Failure != "":
+		rendered = m.renderTemplate(failureWithLogTmpl, failureData{res.Failure, common})
+	default:
+		rendered = m.renderProjectResults(res.ProjectResults, common, vcsHost)
 	}
-	if res.Failure != "" {
-		return m.renderTemplate(failureWithLogTmpl, failureData{res.Failure, common})
+	if res.BaseBranchUpdated != "" {
+		rendered = fmt.Sprintf(baseBranchUpdatedTmpl, res.BaseBranchUpdated) + rendered
+	}
+	if res.MergedBaseBranch != "" {
+		tmpl := mergedApplyTmpl
+		if cmdName == models.PlanCommand {
+			tmpl = mergedPlanFailedTmpl
+		}
+		rendered = fmt.Sprintf(tmpl, res.MergedBaseBranch) + rendered
 	}
-	return m.renderProjectResults(res.ProjectResults, common, vcsHost)
+	return rendered
 }
 
 func (m *MarkdownRenderer) renderProjectResults(results []models.ProjectResult, common commonData, vcsHost models.VCSHostType) string {
@@ -197,6 +215,18 @@ func (m *MarkdownRenderer) renderTemplate(tmpl *template.Template, data interfac
 	return buf.String()
 }
 
+// baseBranchUpdatedTmpl is prepended to the output of plans that were re-run
+// because commits were pushed to the base branch.
+var baseBranchUpdatedTmpl = ":arrows_counterclockwise: `%s` was updated so Atlantis re-ran plan for the projects with unapplied plans.\n\n"
+
+// mergedApplyTmpl is prepended to the output of applies that were run on the
+// base branch after the pull request was merged.
+var mergedApplyTmpl = ":twisted_rightwards_arrows: This pull request was merged so Atlantis applied the projects it modified on `%s`.\n\n"
+
+// mergedPlanFailedTmpl is prepended to the output of plans that failed when
+// the pull request was being applied after it was merged.
+var mergedPlanFailedTmpl = ":twisted_rightwards_arrows: This pull request was merged so Atlantis planned the projects it modified on `%s`. **Nothing was applied** because planning failed.\n\n"
+
 // todo: refactor to remove duplication #refactor
 var singleProjectApplyTmpl = template.Must(template.New("").Parse(
 	"{{$result := index .Results 0}}Ran {{.Command}} for {{ if $result.ProjectName }}project: `{{$result.ProjectName}}` {{ end }}dir: `{{$result.RepoRelDir}}` workspace: `{{$result.Workspace}}`\n\n{{$result.Rendered}}\n" + logTmpl))
@@ -251,7 +281,8 @@ var planNextSteps = "{{ if .PlanWasDeleted }}This plan was not saved because one
 	"    * `{{.ApplyCmd}}`\n" +
 	"* :put_litter_in_its_place: To **delete** this plan click [here]({{.LockURL}})\n" +
 	"* :repeat: To **plan** this project again, comment:\n" +
-	"    * `{{.RePlanCmd}}`{{end}}"
+	"    * `{{.RePlanCmd}}`{{ if .OutputsURL }}\n" +
+	"* :scroll: To view previous plans and applies click [here]({{.OutputsURL}}){{end}}{{end}}"
 var applyUnwrappedSuccessTmpl = template.Must(template.New("").Parse(
 	"```diff\n" +
 		"{{.Output}}\n" +
@@ -156,6 +156,41 @@ $$$
 * :repeat: To **plan** this project again, comment:
     * $atlantis plan -d path -w workspace$
 
+---
+* :fast_forward: To **apply** all unapplied plans from this pull request, comment:
+    * $atlantis apply$
+`,
+		},
+		{
+			"single successful plan with outputs url",
+			models.PlanCommand,
+			[]models.ProjectResult{
+				{
+					PlanSuccess: &models.PlanSuccess{
+						TerraformOutput: "terraform-output",
+						LockURL:         "lock-url",
+						OutputsURL:      "outputs-url",
+						RePlanCmd:       "atlantis plan -d path -w workspace",
+						ApplyCmd:        "atlantis apply -d path -w workspace",
+					},
+					Workspace:  "workspace",
+					RepoRelDir: "path",
+				},
+			},
+			models.Github,
+			`Ran Plan for dir: $path$ workspace: $workspace$
+
+$$$diff
+terraform-output
+$$$
+
+* :arrow_forward: To **apply** this plan, comment:
+    * $atlantis apply -d path -w workspace$
+* :put_litter_in_its_place: To **delete** this plan click [here](lock-url)
+* :repeat: To **plan** this project again, comment:
+    * $atlantis plan -d path -w workspace$
+* :scroll: To view previous plans and applies click [here](outputs-url)
+
 ---
 * :fast_forward: To **apply** all unapplied plans from this pull request, comment:
     * $atlantis apply$
@@ -1254,3 +1289,21 @@ This plan was not saved because one or more projects failed and automerge requir
 		})
 	}
 }
+
+func TestRenderProjectResults_Redacted(t *testing.T) {
+	redactor, err := events.NewSecretRedactor([]string{"hunter2"}, nil)
+	Ok(t, err)
+	mr := events.MarkdownRenderer{Redactor: redactor}
+	rendered := mr.Render(events.CommandResult{
+		ProjectResults: []models.ProjectResult{
+			{
+				RepoRelDir:   ".",
+				Workspace:    "default",
+				ApplySuccess: "password = hunter2",
+			},
+		},
+	}, models.ApplyCommand, "running with hunter2\n", true, models.Github)
+	Assert(t, !strings.Contains(rendered, "hunter2"), "expected secret to be redacted but got %q", rendered)
+	Assert(t, strings.Contains(rendered, "password = [redacted]"), "expected redacted output but got %q", rendered)
+	Assert(t, strings.Contains(rendered, "running with [redacted]"), "expected redacted log but got %q", rendered)
+}
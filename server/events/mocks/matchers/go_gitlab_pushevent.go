@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"github.com/petergtz/pegomock"
+	go_gitlab "github.com/xanzy/go-gitlab"
+	"reflect"
+)
+
+func AnyGoGitlabPushEvent() go_gitlab.PushEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(go_gitlab.PushEvent))(nil)).Elem()))
+	var nullValue go_gitlab.PushEvent
+	return nullValue
+}
+
+func EqGoGitlabPushEvent(value go_gitlab.PushEvent) go_gitlab.PushEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue go_gitlab.PushEvent
+	return nullValue
+}
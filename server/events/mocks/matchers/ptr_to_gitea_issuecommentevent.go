@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyPtrToGiteaIssueCommentEvent() *gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.IssueCommentEvent))(nil)).Elem()))
+	var nullValue *gitea.IssueCommentEvent
+	return nullValue
+}
+
+func EqPtrToGiteaIssueCommentEvent(value *gitea.IssueCommentEvent) *gitea.IssueCommentEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.IssueCommentEvent
+	return nullValue
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyPtrToGiteaPullRequest() *gitea.PullRequest {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.PullRequest))(nil)).Elem()))
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
+
+func EqPtrToGiteaPullRequest(value *gitea.PullRequest) *gitea.PullRequest {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.PullRequest
+	return nullValue
+}
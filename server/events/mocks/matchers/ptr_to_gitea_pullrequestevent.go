@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	gitea "github.com/runatlantis/atlantis/server/events/vcs/gitea"
+)
+
+func AnyPtrToGiteaPullRequestEvent() *gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*gitea.PullRequestEvent))(nil)).Elem()))
+	var nullValue *gitea.PullRequestEvent
+	return nullValue
+}
+
+func EqPtrToGiteaPullRequestEvent(value *gitea.PullRequestEvent) *gitea.PullRequestEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *gitea.PullRequestEvent
+	return nullValue
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	github "github.com/google/go-github/v28/github"
+)
+
+func AnyPtrToGithubCheckRunEvent() *github.CheckRunEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*github.CheckRunEvent))(nil)).Elem()))
+	var nullValue *github.CheckRunEvent
+	return nullValue
+}
+
+func EqPtrToGithubCheckRunEvent(value *github.CheckRunEvent) *github.CheckRunEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *github.CheckRunEvent
+	return nullValue
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	github "github.com/google/go-github/v28/github"
+	"github.com/petergtz/pegomock"
+	"reflect"
+)
+
+func AnyPtrToGithubPushEvent() *github.PushEvent {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*github.PushEvent))(nil)).Elem()))
+	var nullValue *github.PushEvent
+	return nullValue
+}
+
+func EqPtrToGithubPushEvent(value *github.PushEvent) *github.PushEvent {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *github.PushEvent
+	return nullValue
+}
@@ -0,0 +1,159 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: CommandAuthorizer)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockCommandAuthorizer struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockCommandAuthorizer(options ...pegomock.Option) *MockCommandAuthorizer {
+	mock := &MockCommandAuthorizer{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockCommandAuthorizer) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockCommandAuthorizer) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockCommandAuthorizer) Authorize(repo models.Repo, user models.User, command string) (string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommandAuthorizer().")
+	}
+	params := []pegomock.Param{repo, user, command}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Authorize", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockCommandAuthorizer) Restricted(command string) bool {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommandAuthorizer().")
+	}
+	params := []pegomock.Param{command}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("Restricted", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem()})
+	var ret0 bool
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(bool)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockCommandAuthorizer) VerifyWasCalledOnce() *VerifierMockCommandAuthorizer {
+	return &VerifierMockCommandAuthorizer{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockCommandAuthorizer) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockCommandAuthorizer {
+	return &VerifierMockCommandAuthorizer{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockCommandAuthorizer) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockCommandAuthorizer {
+	return &VerifierMockCommandAuthorizer{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockCommandAuthorizer) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockCommandAuthorizer {
+	return &VerifierMockCommandAuthorizer{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockCommandAuthorizer struct {
+	mock                   *MockCommandAuthorizer
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockCommandAuthorizer) Authorize(repo models.Repo, user models.User, command string) *MockCommandAuthorizer_Authorize_OngoingVerification {
+	params := []pegomock.Param{repo, user, command}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Authorize", params, verifier.timeout)
+	return &MockCommandAuthorizer_Authorize_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommandAuthorizer_Authorize_OngoingVerification struct {
+	mock              *MockCommandAuthorizer
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommandAuthorizer_Authorize_OngoingVerification) GetCapturedArguments() (models.Repo, models.User, string) {
+	repo, user, command := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], user[len(user)-1], command[len(command)-1]
+}
+
+func (c *MockCommandAuthorizer_Authorize_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.User, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.User, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.User)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockCommandAuthorizer) Restricted(command string) *MockCommandAuthorizer_Restricted_OngoingVerification {
+	params := []pegomock.Param{command}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "Restricted", params, verifier.timeout)
+	return &MockCommandAuthorizer_Restricted_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommandAuthorizer_Restricted_OngoingVerification struct {
+	mock              *MockCommandAuthorizer
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommandAuthorizer_Restricted_OngoingVerification) GetCapturedArguments() string {
+	command := c.GetAllCapturedArguments()
+	return command[len(command)-1]
+}
+
+func (c *MockCommandAuthorizer_Restricted_OngoingVerification) GetAllCapturedArguments() (_param0 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]string, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(string)
+		}
+	}
+	return
+}
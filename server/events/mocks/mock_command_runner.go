@@ -42,6 +42,22 @@ func (mock *MockCommandRunner) RunAutoplanCommand(baseRepo models.Repo, headRepo
 	pegomock.GetGenericMockFrom(mock).Invoke("RunAutoplanCommand", params, []reflect.Type{})
 }
 
+func (mock *MockCommandRunner) RunBaseBranchPushCommand(baseRepo models.Repo, branch string) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommandRunner().")
+	}
+	params := []pegomock.Param{baseRepo, branch}
+	pegomock.GetGenericMockFrom(mock).Invoke("RunBaseBranchPushCommand", params, []reflect.Type{})
+}
+
+func (mock *MockCommandRunner) RunApplyAfterMergeCommand(baseRepo models.Repo, pull models.PullRequest, user models.User) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommandRunner().")
+	}
+	params := []pegomock.Param{baseRepo, pull, user}
+	pegomock.GetGenericMockFrom(mock).Invoke("RunApplyAfterMergeCommand", params, []reflect.Type{})
+}
+
 func (mock *MockCommandRunner) VerifyWasCalledOnce() *VerifierMockCommandRunner {
 	return &VerifierMockCommandRunner{
 		mock:                   mock,
@@ -164,3 +180,69 @@ func (c *MockCommandRunner_RunAutoplanCommand_OngoingVerification) GetAllCapture
 	}
 	return
 }
+
+func (verifier *VerifierMockCommandRunner) RunBaseBranchPushCommand(baseRepo models.Repo, branch string) *MockCommandRunner_RunBaseBranchPushCommand_OngoingVerification {
+	params := []pegomock.Param{baseRepo, branch}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RunBaseBranchPushCommand", params, verifier.timeout)
+	return &MockCommandRunner_RunBaseBranchPushCommand_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommandRunner_RunBaseBranchPushCommand_OngoingVerification struct {
+	mock              *MockCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommandRunner_RunBaseBranchPushCommand_OngoingVerification) GetCapturedArguments() (models.Repo, string) {
+	baseRepo, branch := c.GetAllCapturedArguments()
+	return baseRepo[len(baseRepo)-1], branch[len(branch)-1]
+}
+
+func (c *MockCommandRunner_RunBaseBranchPushCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockCommandRunner) RunApplyAfterMergeCommand(baseRepo models.Repo, pull models.PullRequest, user models.User) *MockCommandRunner_RunApplyAfterMergeCommand_OngoingVerification {
+	params := []pegomock.Param{baseRepo, pull, user}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "RunApplyAfterMergeCommand", params, verifier.timeout)
+	return &MockCommandRunner_RunApplyAfterMergeCommand_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommandRunner_RunApplyAfterMergeCommand_OngoingVerification struct {
+	mock              *MockCommandRunner
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommandRunner_RunApplyAfterMergeCommand_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest, models.User) {
+	baseRepo, pull, user := c.GetAllCapturedArguments()
+	return baseRepo[len(baseRepo)-1], pull[len(pull)-1], user[len(user)-1]
+}
+
+func (c *MockCommandRunner_RunApplyAfterMergeCommand_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest, _param2 []models.User) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+		_param2 = make([]models.User, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.User)
+		}
+	}
+	return
+}
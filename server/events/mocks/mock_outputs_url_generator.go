@@ -0,0 +1,113 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: OutputsURLGenerator)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockOutputsURLGenerator struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockOutputsURLGenerator(options ...pegomock.Option) *MockOutputsURLGenerator {
+	mock := &MockOutputsURLGenerator{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockOutputsURLGenerator) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockOutputsURLGenerator) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockOutputsURLGenerator) GenerateProjectOutputsURL(pull models.PullRequest, repoRelDir string, workspace string) string {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockOutputsURLGenerator().")
+	}
+	params := []pegomock.Param{pull, repoRelDir, workspace}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GenerateProjectOutputsURL", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem()})
+	var ret0 string
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(string)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockOutputsURLGenerator) VerifyWasCalledOnce() *VerifierMockOutputsURLGenerator {
+	return &VerifierMockOutputsURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockOutputsURLGenerator) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockOutputsURLGenerator {
+	return &VerifierMockOutputsURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockOutputsURLGenerator) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockOutputsURLGenerator {
+	return &VerifierMockOutputsURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockOutputsURLGenerator) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockOutputsURLGenerator {
+	return &VerifierMockOutputsURLGenerator{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockOutputsURLGenerator struct {
+	mock                   *MockOutputsURLGenerator
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockOutputsURLGenerator) GenerateProjectOutputsURL(pull models.PullRequest, repoRelDir string, workspace string) *MockOutputsURLGenerator_GenerateProjectOutputsURL_OngoingVerification {
+	params := []pegomock.Param{pull, repoRelDir, workspace}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GenerateProjectOutputsURL", params, verifier.timeout)
+	return &MockOutputsURLGenerator_GenerateProjectOutputsURL_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockOutputsURLGenerator_GenerateProjectOutputsURL_OngoingVerification struct {
+	mock              *MockOutputsURLGenerator
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockOutputsURLGenerator_GenerateProjectOutputsURL_OngoingVerification) GetCapturedArguments() (models.PullRequest, string, string) {
+	pull, repoRelDir, workspace := c.GetAllCapturedArguments()
+	return pull[len(pull)-1], repoRelDir[len(repoRelDir)-1], workspace[len(workspace)-1]
+}
+
+func (c *MockOutputsURLGenerator_GenerateProjectOutputsURL_OngoingVerification) GetAllCapturedArguments() (_param0 []models.PullRequest, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.PullRequest)
+		}
+		_param1 = make([]string, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
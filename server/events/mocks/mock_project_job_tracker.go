@@ -0,0 +1,172 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events (interfaces: ProjectJobTracker)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockProjectJobTracker struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockProjectJobTracker(options ...pegomock.Option) *MockProjectJobTracker {
+	mock := &MockProjectJobTracker{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockProjectJobTracker) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockProjectJobTracker) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockProjectJobTracker) StartJob(ctx models.ProjectCommandContext, cmdName models.CommandName) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectJobTracker().")
+	}
+	params := []pegomock.Param{ctx, cmdName}
+	pegomock.GetGenericMockFrom(mock).Invoke("StartJob", params, []reflect.Type{})
+}
+
+func (mock *MockProjectJobTracker) CompleteJob(ctx models.ProjectCommandContext) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectJobTracker().")
+	}
+	params := []pegomock.Param{ctx}
+	pegomock.GetGenericMockFrom(mock).Invoke("CompleteJob", params, []reflect.Type{})
+}
+
+func (mock *MockProjectJobTracker) CleanUpPull(pull models.PullRequest) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockProjectJobTracker().")
+	}
+	params := []pegomock.Param{pull}
+	pegomock.GetGenericMockFrom(mock).Invoke("CleanUpPull", params, []reflect.Type{})
+}
+
+func (mock *MockProjectJobTracker) VerifyWasCalledOnce() *VerifierMockProjectJobTracker {
+	return &VerifierMockProjectJobTracker{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockProjectJobTracker) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockProjectJobTracker {
+	return &VerifierMockProjectJobTracker{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockProjectJobTracker) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockProjectJobTracker {
+	return &VerifierMockProjectJobTracker{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockProjectJobTracker) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockProjectJobTracker {
+	return &VerifierMockProjectJobTracker{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockProjectJobTracker struct {
+	mock                   *MockProjectJobTracker
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockProjectJobTracker) StartJob(ctx models.ProjectCommandContext, cmdName models.CommandName) *MockProjectJobTracker_StartJob_OngoingVerification {
+	params := []pegomock.Param{ctx, cmdName}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "StartJob", params, verifier.timeout)
+	return &MockProjectJobTracker_StartJob_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectJobTracker_StartJob_OngoingVerification struct {
+	mock              *MockProjectJobTracker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectJobTracker_StartJob_OngoingVerification) GetCapturedArguments() (models.ProjectCommandContext, models.CommandName) {
+	ctx, cmdName := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1], cmdName[len(cmdName)-1]
+}
+
+func (c *MockProjectJobTracker_StartJob_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ProjectCommandContext, _param1 []models.CommandName) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.ProjectCommandContext, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.ProjectCommandContext)
+		}
+		_param1 = make([]models.CommandName, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.CommandName)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockProjectJobTracker) CompleteJob(ctx models.ProjectCommandContext) *MockProjectJobTracker_CompleteJob_OngoingVerification {
+	params := []pegomock.Param{ctx}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CompleteJob", params, verifier.timeout)
+	return &MockProjectJobTracker_CompleteJob_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectJobTracker_CompleteJob_OngoingVerification struct {
+	mock              *MockProjectJobTracker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectJobTracker_CompleteJob_OngoingVerification) GetCapturedArguments() models.ProjectCommandContext {
+	ctx := c.GetAllCapturedArguments()
+	return ctx[len(ctx)-1]
+}
+
+func (c *MockProjectJobTracker_CompleteJob_OngoingVerification) GetAllCapturedArguments() (_param0 []models.ProjectCommandContext) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.ProjectCommandContext, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.ProjectCommandContext)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockProjectJobTracker) CleanUpPull(pull models.PullRequest) *MockProjectJobTracker_CleanUpPull_OngoingVerification {
+	params := []pegomock.Param{pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "CleanUpPull", params, verifier.timeout)
+	return &MockProjectJobTracker_CleanUpPull_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockProjectJobTracker_CleanUpPull_OngoingVerification struct {
+	mock              *MockProjectJobTracker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockProjectJobTracker_CleanUpPull_OngoingVerification) GetCapturedArguments() models.PullRequest {
+	pull := c.GetAllCapturedArguments()
+	return pull[len(pull)-1]
+}
+
+func (c *MockProjectJobTracker_CleanUpPull_OngoingVerification) GetAllCapturedArguments() (_param0 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
@@ -46,6 +46,63 @@ func (mock *MockWorkingDir) Clone(log *logging.SimpleLogger, baseRepo models.Rep
 	return ret0, false, ret1
 }
 
+func (mock *MockWorkingDir) HasDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) (bool, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
+	}
+	params := []pegomock.Param{log, cloneDir, headRepo, p}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("HasDiverged", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 bool
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(bool)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockWorkingDir) ReadBaseFile(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, path string) ([]byte, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
+	}
+	params := []pegomock.Param{log, cloneDir, headRepo, p, path}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ReadBaseFile", params, []reflect.Type{reflect.TypeOf((*[]byte)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []byte
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]byte)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockWorkingDir) ListBaseFiles(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, dir string) ([]string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
+	}
+	params := []pegomock.Param{log, cloneDir, headRepo, p, dir}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("ListBaseFiles", params, []reflect.Type{reflect.TypeOf((*[]string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
 func (mock *MockWorkingDir) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockWorkingDir().")
@@ -194,6 +251,131 @@ func (c *MockWorkingDir_Clone_OngoingVerification) GetAllCapturedArguments() (_p
 	return
 }
 
+func (verifier *VerifierMockWorkingDir) HasDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) *MockWorkingDir_HasDiverged_OngoingVerification {
+	params := []pegomock.Param{log, cloneDir, headRepo, p}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "HasDiverged", params, verifier.timeout)
+	return &MockWorkingDir_HasDiverged_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDir_HasDiverged_OngoingVerification struct {
+	mock              *MockWorkingDir
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDir_HasDiverged_OngoingVerification) GetCapturedArguments() (*logging.SimpleLogger, string, models.Repo, models.PullRequest) {
+	log, cloneDir, headRepo, p := c.GetAllCapturedArguments()
+	return log[len(log)-1], cloneDir[len(cloneDir)-1], headRepo[len(headRepo)-1], p[len(p)-1]
+}
+
+func (c *MockWorkingDir_HasDiverged_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger, _param1 []string, _param2 []models.Repo, _param3 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]*logging.SimpleLogger, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(*logging.SimpleLogger)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]models.Repo, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.Repo)
+		}
+		_param3 = make([]models.PullRequest, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockWorkingDir) ReadBaseFile(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, path string) *MockWorkingDir_ReadBaseFile_OngoingVerification {
+	params := []pegomock.Param{log, cloneDir, headRepo, p, path}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ReadBaseFile", params, verifier.timeout)
+	return &MockWorkingDir_ReadBaseFile_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDir_ReadBaseFile_OngoingVerification struct {
+	mock              *MockWorkingDir
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDir_ReadBaseFile_OngoingVerification) GetCapturedArguments() (*logging.SimpleLogger, string, models.Repo, models.PullRequest, string) {
+	log, cloneDir, headRepo, p, path := c.GetAllCapturedArguments()
+	return log[len(log)-1], cloneDir[len(cloneDir)-1], headRepo[len(headRepo)-1], p[len(p)-1], path[len(path)-1]
+}
+
+func (c *MockWorkingDir_ReadBaseFile_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger, _param1 []string, _param2 []models.Repo, _param3 []models.PullRequest, _param4 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]*logging.SimpleLogger, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(*logging.SimpleLogger)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]models.Repo, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.Repo)
+		}
+		_param3 = make([]models.PullRequest, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(models.PullRequest)
+		}
+		_param4 = make([]string, len(params[4]))
+		for u, param := range params[4] {
+			_param4[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockWorkingDir) ListBaseFiles(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, dir string) *MockWorkingDir_ListBaseFiles_OngoingVerification {
+	params := []pegomock.Param{log, cloneDir, headRepo, p, dir}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "ListBaseFiles", params, verifier.timeout)
+	return &MockWorkingDir_ListBaseFiles_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockWorkingDir_ListBaseFiles_OngoingVerification struct {
+	mock              *MockWorkingDir
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockWorkingDir_ListBaseFiles_OngoingVerification) GetCapturedArguments() (*logging.SimpleLogger, string, models.Repo, models.PullRequest, string) {
+	log, cloneDir, headRepo, p, dir := c.GetAllCapturedArguments()
+	return log[len(log)-1], cloneDir[len(cloneDir)-1], headRepo[len(headRepo)-1], p[len(p)-1], dir[len(dir)-1]
+}
+
+func (c *MockWorkingDir_ListBaseFiles_OngoingVerification) GetAllCapturedArguments() (_param0 []*logging.SimpleLogger, _param1 []string, _param2 []models.Repo, _param3 []models.PullRequest, _param4 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]*logging.SimpleLogger, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(*logging.SimpleLogger)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]models.Repo, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.Repo)
+		}
+		_param3 = make([]models.PullRequest, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(models.PullRequest)
+		}
+		_param4 = make([]string, len(params[4]))
+		for u, param := range params[4] {
+			_param4[u] = param.(string)
+		}
+	}
+	return
+}
+
 func (verifier *VerifierMockWorkingDir) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) *MockWorkingDir_GetWorkingDir_OngoingVerification {
 	params := []pegomock.Param{r, p, workspace}
 	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetWorkingDir", params, verifier.timeout)
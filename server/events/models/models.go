@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/url"
 	paths "path"
+	"regexp"
 	"strings"
 	"time"
 
@@ -157,19 +158,46 @@ type PullRequest struct {
 	BaseBranch string
 	// Author is the username of the pull request author.
 	Author string
-	// State will be one of Open or Closed.
-	// Gitlab supports an additional "merged" state but Github doesn't so we map
-	// merged to Closed.
+	// State will be one of Open, Closed or Merged.
 	State PullRequestState
+	// MergeCommit is the sha of the commit created when the pull request was
+	// merged. It's only set if State is Merged and the VCS host reports it.
+	MergeCommit string
+	// Labels are the names of the labels on the pull request. They're always
+	// empty for Bitbucket since it doesn't support labels.
+	Labels []string
 	// BaseRepo is the repository that the pull request will be merged into.
 	BaseRepo Repo
 }
 
+// Merge methods that can be used when merging a pull request.
+const (
+	MergeMergeMethod  = "merge"
+	SquashMergeMethod = "squash"
+	RebaseMergeMethod = "rebase"
+)
+
+// PullRequestOptions are the options used when merging a pull request.
+type PullRequestOptions struct {
+	// MergeMethod is one of MergeMergeMethod, SquashMergeMethod or
+	// RebaseMergeMethod. If it's empty, the VCS host's default is used.
+	MergeMethod string
+	// DeleteSourceBranchOnMerge is true if the pull request's head branch
+	// should be deleted once it's merged.
+	DeleteSourceBranchOnMerge bool
+	// CommitMessage is the message of the merge or squash commit. If it's
+	// empty, a default message is used.
+	CommitMessage string
+}
+
 type PullRequestState int
 
 const (
 	OpenPullState PullRequestState = iota
 	ClosedPullState
+	// MergedPullState is a closed pull request whose changes were merged into
+	// the base branch.
+	MergedPullState
 )
 
 type PullRequestEventType int
@@ -178,6 +206,7 @@ const (
 	OpenedPullEvent PullRequestEventType = iota
 	UpdatedPullEvent
 	ClosedPullEvent
+	LabeledPullEvent
 	OtherPullEvent
 )
 
@@ -189,6 +218,8 @@ func (p PullRequestEventType) String() string {
 		return "updated"
 	case ClosedPullEvent:
 		return "closed"
+	case LabeledPullEvent:
+		return "labeled"
 	case OtherPullEvent:
 		return "other"
 	}
@@ -201,6 +232,73 @@ type User struct {
 	Username string
 }
 
+// Approval is an approval of a pull request.
+type Approval struct {
+	// User is who approved the pull request.
+	User User
+	// CommitSHA is the commit of the pull request that was approved. It's
+	// empty if the VCS host doesn't associate approvals with commits. In that
+	// case the approval is for the latest commit.
+	CommitSHA string
+}
+
+// CommitCheck is a commit status or check run reported on a pull request's
+// head commit, ex. by a CI system.
+type CommitCheck struct {
+	// Name identifies the check, ex. ci/build or atlantis/plan.
+	Name string
+	// State is the state of the check. Skipped checks are reported as
+	// SuccessCommitStatus.
+	State CommitStatus
+	// Skipped is true if the check completed without passing or failing, ex.
+	// GitHub's skipped and neutral conclusions.
+	Skipped bool
+	// Required is true if the VCS host requires the check to pass before the
+	// pull request can be merged, ex. a required status check of GitHub's
+	// branch protection.
+	Required bool
+}
+
+// PullComment is a comment on a pull request.
+type PullComment struct {
+	// ID identifies the comment. On Azure DevOps, it's the ID of the thread
+	// the comment starts.
+	ID int64
+	// Body is the comment's markdown.
+	Body string
+}
+
+// CheckRun is a GitHub check run reporting the result of a command on a
+// project.
+type CheckRun struct {
+	// Name identifies the check run, ex. atlantis/plan: project1.
+	Name string
+	// Status is the status of the check run. PendingCommitStatus means the
+	// command is in progress.
+	Status CommitStatus
+	// DetailsURL is an optional link to more details, ex. the job output.
+	DetailsURL string
+	// ExternalID identifies the project the check run is for so that the
+	// project can be planned again when the check run is re-run.
+	ExternalID string
+	// Title is a short summary of the result.
+	Title string
+	// Summary is the Markdown formatted result.
+	Summary string
+	// Annotations point at the lines of files that caused errors.
+	Annotations []CheckRunAnnotation
+}
+
+// CheckRunAnnotation is an error in a file reported on a check run.
+type CheckRunAnnotation struct {
+	// Path is the path of the file relative to the repo root.
+	Path string
+	// Line is the line of the file the error is on.
+	Line    int
+	Title   string
+	Message string
+}
+
 // ProjectLock represents a lock on a project.
 type ProjectLock struct {
 	// Project is the project that is being locked.
@@ -278,6 +376,7 @@ const (
 	BitbucketCloud
 	BitbucketServer
 	AzureDevops
+	Gitea
 )
 
 func (h VCSHostType) String() string {
@@ -292,6 +391,8 @@ func (h VCSHostType) String() string {
 		return "BitbucketServer"
 	case AzureDevops:
 		return "AzureDevops"
+	case Gitea:
+		return "Gitea"
 	}
 	return "<missing String() implementation>"
 }
@@ -304,10 +405,13 @@ type ProjectCommandContext struct {
 	ApplyCmd string
 	// ApplyRequirements is the list of requirements that must be satisfied
 	// before we will run the apply stage.
-	ApplyRequirements []string
+	ApplyRequirements []valid.ApplyRequirement
 	// AutoplanEnabled is true if automerge is enabled for the repo that this
 	// project is in.
 	AutomergeEnabled bool
+	// AutomergeOptions configure how the pull request is merged when
+	// automerge is enabled.
+	AutomergeOptions PullRequestOptions
 	// AutoplanEnabled is true if autoplanning is enabled for this project.
 	AutoplanEnabled bool
 	// BaseRepo is the repository that the pull request will be merged into.
@@ -427,6 +531,9 @@ type PlanSuccess struct {
 	TerraformOutput string
 	// LockURL is the full URL to the lock held by this plan.
 	LockURL string
+	// OutputsURL is the full URL to the history of plans and applies for
+	// this project. It's empty if there's no UI to link to.
+	OutputsURL string
 	// RePlanCmd is the command that users should run to re-plan this project.
 	RePlanCmd string
 	// ApplyCmd is the command that users should run to apply this plan.
@@ -437,12 +544,44 @@ type PlanSuccess struct {
 	HasDiverged bool
 }
 
+// planSummaryRegex matches the line Terraform prints at the end of a plan.
+var planSummaryRegex = regexp.MustCompile(`(?m)^(Plan: \d+ to add, \d+ to change, \d+ to destroy\.|No changes\. .*)$`)
+
+// Summary returns the summary line of the plan's output, ex.
+// "Plan: 1 to add, 0 to change, 0 to destroy.". It returns an empty string if
+// there's no summary.
+func (p PlanSuccess) Summary() string {
+	return planSummaryRegex.FindString(p.TerraformOutput)
+}
+
+// ProjectOutput is the output of running a command on a project. We store
+// these so that users can view previous plans and applies after the pull
+// request comments have been hidden or deleted.
+type ProjectOutput struct {
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	// Command is the command that was run, ex. plan.
+	Command CommandName
+	// Status is the status of the project after the command was run.
+	Status ProjectPlanStatus
+	// HeadCommit is the commit of the pull request the command was run on.
+	HeadCommit string
+	// Output is the output of the command with any secrets redacted and
+	// terminal color codes removed.
+	Output string
+	// Time is when the command was run.
+	Time time.Time
+}
+
 // PullStatus is the current status of a pull request that is in progress.
 type PullStatus struct {
 	// Projects are the projects that have been modified in this pull request.
 	Projects []ProjectStatus
 	// Pull is the original pull request model.
 	Pull PullRequest
+	// UpdatedAt is when a command was last run on this pull request.
+	UpdatedAt time.Time
 }
 
 // StatusCount returns the number of projects that have status.
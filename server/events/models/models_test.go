@@ -460,3 +460,17 @@ func TestPullStatus_StatusCount(t *testing.T) {
 	Equals(t, 1, ps.StatusCount(models.ErroredApplyStatus))
 	Equals(t, 0, ps.StatusCount(models.ErroredPlanStatus))
 }
+
+func TestPlanSuccess_Summary(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"some output": "",
+		"Refreshing state...\n\nPlan: 1 to add, 2 to change, 3 to destroy.\n\n---\nmore output":         "Plan: 1 to add, 2 to change, 3 to destroy.",
+		"Refreshing state...\n\nNo changes. Infrastructure is up-to-date.\n\nThis means that Terraform": "No changes. Infrastructure is up-to-date.",
+	}
+	for output, exp := range cases {
+		t.Run(output, func(t *testing.T) {
+			Equals(t, exp, models.PlanSuccess{TerraformOutput: output}.Summary())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package events
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -118,6 +119,16 @@ func (p *DefaultProjectCommandBuilder) buildPlanAllCommands(ctx *CommandContext,
 		return nil, err
 	}
 
+	// If the pull request has a label that plans every project then we
+	// act as if every file in the repo was modified.
+	if p.GlobalCfg.MatchingLabelBehavior(ctx.BaseRepo.ID(), ctx.Pull.Labels).PlanAll {
+		modifiedFiles, err = p.repoFiles(repoDir)
+		if err != nil {
+			return nil, err
+		}
+		ctx.Log.Info("pull request has a plan all label so planning all %d files in the repo", len(modifiedFiles))
+	}
+
 	// Parse config file if it exists.
 	hasRepoCfg, err := p.ParserValidator.HasRepoCfg(repoDir)
 	if err != nil {
@@ -392,11 +403,18 @@ func (p *DefaultProjectCommandBuilder) buildCtx(ctx *CommandContext,
 		projCfg.TerraformVersion = p.getTfVersion(ctx, filepath.Join(absRepoDir, projCfg.RepoRelDir))
 	}
 
+	// Labels on the pull request can add to the project's apply requirements.
+	applyReqs := projCfg.ApplyRequirements
+	if labelReqs := p.GlobalCfg.MatchingLabelBehavior(ctx.BaseRepo.ID(), ctx.Pull.Labels).ApplyRequirements; len(labelReqs) > 0 {
+		applyReqs = append(append([]valid.ApplyRequirement{}, applyReqs...), labelReqs...)
+	}
+
 	return models.ProjectCommandContext{
 		ApplyCmd:           p.CommentBuilder.BuildApplyComment(projCfg.RepoRelDir, projCfg.Workspace, projCfg.Name),
 		BaseRepo:           ctx.BaseRepo,
 		EscapedCommentArgs: p.escapeArgs(commentArgs),
 		AutomergeEnabled:   automergeEnabled,
+		AutomergeOptions:   p.automergeOptions(projCfg.AutomergeOptions),
 		AutoplanEnabled:    projCfg.AutoplanEnabled,
 		Steps:              steps,
 		HeadRepo:           ctx.HeadRepo,
@@ -404,7 +422,7 @@ func (p *DefaultProjectCommandBuilder) buildCtx(ctx *CommandContext,
 		PullMergeable:      ctx.PullMergeable,
 		Pull:               ctx.Pull,
 		ProjectName:        projCfg.Name,
-		ApplyRequirements:  projCfg.ApplyRequirements,
+		ApplyRequirements:  applyReqs,
 		RePlanCmd:          p.CommentBuilder.BuildPlanComment(projCfg.RepoRelDir, projCfg.Workspace, projCfg.Name, commentArgs),
 		RepoRelDir:         projCfg.RepoRelDir,
 		RepoConfigVersion:  projCfg.RepoCfgVersion,
@@ -415,6 +433,46 @@ func (p *DefaultProjectCommandBuilder) buildCtx(ctx *CommandContext,
 	}
 }
 
+// repoFiles returns the paths, relative to repoDir, of all the files in the
+// repo. The .git and .terraform directories are skipped.
+func (p *DefaultProjectCommandBuilder) repoFiles(repoDir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(repoDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" || info.Name() == ".terraform" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relPath, err := filepath.Rel(repoDir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	return files, errors.Wrapf(err, "listing files in %q", repoDir)
+}
+
+// automergeOptions converts the configured automerge options into the options
+// passed to the VCS client.
+func (p *DefaultProjectCommandBuilder) automergeOptions(cfg valid.AutomergeOptions) models.PullRequestOptions {
+	var opts models.PullRequestOptions
+	if cfg.MergeMethod != nil {
+		opts.MergeMethod = *cfg.MergeMethod
+	}
+	if cfg.DeleteSourceBranch != nil {
+		opts.DeleteSourceBranchOnMerge = *cfg.DeleteSourceBranch
+	}
+	if cfg.CommitMessage != nil {
+		opts.CommitMessage = *cfg.CommitMessage
+	}
+	return opts
+}
+
 func (p *DefaultProjectCommandBuilder) escapeArgs(args []string) []string {
 	var escaped []string
 	for _, arg := range args {
@@ -59,7 +59,7 @@ workflows:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{},
+				ApplyRequirements:  []valid.ApplyRequirement{},
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
 				User:               models.User{},
@@ -108,7 +108,7 @@ projects:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{},
+				ApplyRequirements:  []valid.ApplyRequirement{},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -159,7 +159,7 @@ projects:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{"approved", "mergeable"},
+				ApplyRequirements:  []valid.ApplyRequirement{{Name: "approved"}, {Name: "mergeable"}},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -218,7 +218,7 @@ projects:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{"approved"},
+				ApplyRequirements:  []valid.ApplyRequirement{{Name: "approved"}},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -364,7 +364,7 @@ workflows:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{},
+				ApplyRequirements:  []valid.ApplyRequirement{},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -419,7 +419,7 @@ projects:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{},
+				ApplyRequirements:  []valid.ApplyRequirement{},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -477,7 +477,7 @@ workflows:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{},
+				ApplyRequirements:  []valid.ApplyRequirement{},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -519,7 +519,7 @@ projects:
 				PullMergeable:      true,
 				Pull:               models.PullRequest{},
 				ProjectName:        "",
-				ApplyRequirements:  []string{"approved"},
+				ApplyRequirements:  []valid.ApplyRequirement{{Name: "approved"}},
 				RepoConfigVersion:  3,
 				RePlanCmd:          "atlantis plan -d project1 -w myworkspace -- flag",
 				RepoRelDir:         "project1",
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -161,6 +162,92 @@ projects:
 }
 
 // Test building a plan and apply command for one project.
+// Test that pull request labels can plan every project and add apply
+// requirements.
+func TestDefaultProjectCommandBuilder_Labels(t *testing.T) {
+	sreReq := valid.ApplyRequirement{Name: "approved", ApprovalCount: 1, ApprovalsFrom: []string{"sre"}}
+	cases := []struct {
+		Description  string
+		Labels       []string
+		ExpDirs      []string
+		ExpApplyReqs []valid.ApplyRequirement
+	}{
+		{
+			Description: "no labels",
+			ExpDirs:     []string{"modified"},
+		},
+		{
+			Description: "plan all label",
+			Labels:      []string{"atlantis/plan-all"},
+			ExpDirs:     []string{"modified", "unmodified"},
+		},
+		{
+			Description:  "extra apply requirements label",
+			Labels:       []string{"needs-sre"},
+			ExpDirs:      []string{"modified"},
+			ExpApplyReqs: []valid.ApplyRequirement{{Name: "mergeable"}, sreReq},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			tmpDir, cleanup := DirStructure(t, map[string]interface{}{
+				"modified": map[string]interface{}{
+					"main.tf": nil,
+				},
+				"unmodified": map[string]interface{}{
+					"main.tf": nil,
+				},
+				".git": map[string]interface{}{
+					"main.tf": nil,
+				},
+			})
+			defer cleanup()
+
+			workingDir := mocks.NewMockWorkingDir()
+			When(workingDir.Clone(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyModelsRepo(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(tmpDir, nil)
+			vcsClient := vcsmocks.NewMockClient()
+			When(vcsClient.GetModifiedFiles(matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest())).ThenReturn([]string{"modified/main.tf"}, nil)
+
+			globalCfg := valid.NewGlobalCfg(false, false, false)
+			globalCfg.Repos[0].ApplyRequirements = []valid.ApplyRequirement{{Name: "mergeable"}}
+			globalCfg.Repos = append(globalCfg.Repos, valid.Repo{
+				IDRegex: regexp.MustCompile(".*"),
+				Labels: map[string]valid.LabelBehavior{
+					"atlantis/plan-all": {PlanAll: true},
+					"needs-sre":         {ApplyRequirements: []valid.ApplyRequirement{sreReq}},
+				},
+			})
+			builder := &events.DefaultProjectCommandBuilder{
+				WorkingDirLocker:  events.NewDefaultWorkingDirLocker(),
+				WorkingDir:        workingDir,
+				ParserValidator:   &yaml.ParserValidator{},
+				VCSClient:         vcsClient,
+				ProjectFinder:     &events.DefaultProjectFinder{},
+				PendingPlanFinder: &events.DefaultPendingPlanFinder{},
+				CommentBuilder:    &events.CommentParser{},
+				GlobalCfg:         globalCfg,
+			}
+
+			ctxs, err := builder.BuildAutoplanCommands(&events.CommandContext{
+				Pull: models.PullRequest{Labels: c.Labels},
+			})
+			Ok(t, err)
+			var actDirs []string
+			for _, actCtx := range ctxs {
+				actDirs = append(actDirs, actCtx.RepoRelDir)
+				if c.ExpApplyReqs != nil {
+					Equals(t, c.ExpApplyReqs, actCtx.ApplyRequirements)
+				} else {
+					Equals(t, []valid.ApplyRequirement{{Name: "mergeable"}}, actCtx.ApplyRequirements)
+				}
+			}
+			Equals(t, c.ExpDirs, actDirs)
+		})
+	}
+}
+
 func TestDefaultProjectCommandBuilder_BuildSinglePlanApplyCommand(t *testing.T) {
 	cases := []struct {
 		Description    string
@@ -171,7 +258,7 @@ func TestDefaultProjectCommandBuilder_BuildSinglePlanApplyCommand(t *testing.T)
 		ExpDir         string
 		ExpProjectName string
 		ExpErr         string
-		ExpApplyReqs   []string
+		ExpApplyReqs   []valid.ApplyRequirement
 	}{
 		{
 			Description: "no atlantis.yaml",
@@ -185,7 +272,7 @@ func TestDefaultProjectCommandBuilder_BuildSinglePlanApplyCommand(t *testing.T)
 			ExpCommentArgs: []string{`\c\o\m\m\e\n\t\a\r\g`},
 			ExpWorkspace:   "myworkspace",
 			ExpDir:         ".",
-			ExpApplyReqs:   []string{},
+			ExpApplyReqs:   []valid.ApplyRequirement{},
 		},
 		{
 			Description: "no atlantis.yaml with project flag",
@@ -210,7 +297,7 @@ projects:
 - dir: .
   workspace: myworkspace
   apply_requirements: [approved]`,
-			ExpApplyReqs: []string{"approved"},
+			ExpApplyReqs: []valid.ApplyRequirement{{Name: "approved"}},
 			ExpWorkspace: "myworkspace",
 			ExpDir:       ".",
 		},
@@ -229,7 +316,7 @@ projects:
   apply_requirements: [approved]`,
 			ExpWorkspace: "myworkspace",
 			ExpDir:       ".",
-			ExpApplyReqs: []string{},
+			ExpApplyReqs: []valid.ApplyRequirement{},
 		},
 		{
 			Description: "atlantis.yaml wrong workspace",
@@ -259,7 +346,7 @@ projects:
   dir: .
   workspace: myworkspace
   apply_requirements: [approved]`,
-			ExpApplyReqs:   []string{"approved"},
+			ExpApplyReqs:   []valid.ApplyRequirement{{Name: "approved"}},
 			ExpProjectName: "myproject",
 			ExpWorkspace:   "myworkspace",
 			ExpDir:         ".",
@@ -277,7 +364,7 @@ projects:
   dir: .
   workspace: myworkspace
   apply_requirements: [mergeable]`,
-			ExpApplyReqs:   []string{"mergeable"},
+			ExpApplyReqs:   []valid.ApplyRequirement{{Name: "mergeable"}},
 			ExpProjectName: "myproject",
 			ExpWorkspace:   "myworkspace",
 			ExpDir:         ".",
@@ -295,7 +382,7 @@ projects:
   dir: .
   workspace: myworkspace
   apply_requirements: [mergeable, approved]`,
-			ExpApplyReqs:   []string{"mergeable", "approved"},
+			ExpApplyReqs:   []valid.ApplyRequirement{{Name: "mergeable"}, {Name: "approved"}},
 			ExpProjectName: "myproject",
 			ExpWorkspace:   "myworkspace",
 			ExpDir:         ".",
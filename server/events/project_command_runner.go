@@ -46,6 +46,38 @@ type LockURLGenerator interface {
 	GenerateLockURL(lockID string) string
 }
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_outputs_url_generator.go OutputsURLGenerator
+
+// OutputsURLGenerator generates urls to the output history of projects.
+type OutputsURLGenerator interface {
+	// GenerateProjectOutputsURL returns the full URL to the plan and apply
+	// outputs of the project at repoRelDir and workspace in pull.
+	GenerateProjectOutputsURL(pull models.PullRequest, repoRelDir string, workspace string) string
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_job_url_generator.go JobURLGenerator
+
+// JobURLGenerator generates urls to the live output of project commands.
+type JobURLGenerator interface {
+	// GenerateProjectJobURL returns the full URL to the output of the command
+	// running for the project described by ctx.
+	GenerateProjectJobURL(ctx models.ProjectCommandContext) string
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_job_tracker.go ProjectJobTracker
+
+// ProjectJobTracker tracks the commands running for projects so their output
+// can be streamed while they run.
+type ProjectJobTracker interface {
+	// StartJob starts tracking cmdName for the project described by ctx.
+	StartJob(ctx models.ProjectCommandContext, cmdName models.CommandName)
+	// CompleteJob marks the command for the project described by ctx as
+	// complete.
+	CompleteJob(ctx models.ProjectCommandContext)
+	// CleanUpPull stops tracking all jobs for pull.
+	CleanUpPull(pull models.PullRequest)
+}
+
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_step_runner.go StepRunner
 
 // StepRunner runs steps. Steps are individual pieces of execution like
@@ -72,10 +104,10 @@ type EnvStepRunner interface {
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_webhooks_sender.go WebhooksSender
 
-// WebhooksSender sends webhook.
+// WebhooksSender sends webhooks for events.
 type WebhooksSender interface {
-	// Send sends the webhook.
-	Send(log *logging.SimpleLogger, res webhooks.ApplyResult) error
+	// Send sends the webhooks for event.
+	Send(log *logging.SimpleLogger, event webhooks.Event) error
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_project_command_runner.go ProjectCommandRunner
@@ -93,21 +125,38 @@ type ProjectCommandRunner interface {
 type DefaultProjectCommandRunner struct {
 	Locker              ProjectLocker
 	LockURLGenerator    LockURLGenerator
+	OutputsURLGenerator OutputsURLGenerator
 	InitStepRunner      StepRunner
 	PlanStepRunner      StepRunner
 	ApplyStepRunner     StepRunner
 	RunStepRunner       CustomStepRunner
 	EnvStepRunner       EnvStepRunner
 	PullApprovedChecker runtime.PullApprovedChecker
-	WorkingDir          WorkingDir
-	Webhooks            WebhooksSender
-	WorkingDirLocker    WorkingDirLocker
+	CommitChecksGetter  runtime.CommitChecksGetter
+	// StatusName is the name Atlantis prefixes its commit statuses with. Its
+	// own statuses are ignored when checking if the other checks are passing.
+	StatusName       string
+	WorkingDir       WorkingDir
+	WorkingDirLocker WorkingDirLocker
+	// JobTracker, JobURLGenerator and CommitStatusUpdater are optional. If
+	// set, the output of each command is tracked as a job and the project's
+	// commit status links to it while the command runs.
+	JobTracker          ProjectJobTracker
+	JobURLGenerator     JobURLGenerator
+	CommitStatusUpdater CommitStatusUpdater
+	// Webhooks sends webhooks when projects are applied. They're only sent
+	// once the apply requirements pass so rejected applies don't send them.
+	// If nil, no webhooks are sent.
+	Webhooks WebhooksSender
+	// Redactor redacts secrets from the output included in webhooks.
+	Redactor *SecretRedactor
 }
 
 // Plan runs terraform plan for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Plan(ctx models.ProjectCommandContext) models.ProjectResult {
+	p.startJob(ctx, models.PlanCommand)
 	planSuccess, failure, err := p.doPlan(ctx)
-	return models.ProjectResult{
+	result := models.ProjectResult{
 		Command:     models.PlanCommand,
 		PlanSuccess: planSuccess,
 		Error:       err,
@@ -116,12 +165,15 @@ func (p *DefaultProjectCommandRunner) Plan(ctx models.ProjectCommandContext) mod
 		Workspace:   ctx.Workspace,
 		ProjectName: ctx.ProjectName,
 	}
+	p.completeJob(ctx, result)
+	return result
 }
 
 // Apply runs terraform apply for the project described by ctx.
 func (p *DefaultProjectCommandRunner) Apply(ctx models.ProjectCommandContext) models.ProjectResult {
-	applyOut, failure, err := p.doApply(ctx)
-	return models.ProjectResult{
+	p.startJob(ctx, models.ApplyCommand)
+	applyOut, failure, ran, err := p.doApply(ctx)
+	result := models.ProjectResult{
 		Command:      models.ApplyCommand,
 		Failure:      failure,
 		Error:        err,
@@ -130,6 +182,47 @@ func (p *DefaultProjectCommandRunner) Apply(ctx models.ProjectCommandContext) mo
 		Workspace:    ctx.Workspace,
 		ProjectName:  ctx.ProjectName,
 	}
+	p.completeJob(ctx, result)
+	if ran {
+		sendWebhook(p.Webhooks, ctx.Log, newProjectEvent(webhooks.ApplyFinishedEvent, ctx, &result, p.JobURLGenerator, p.Redactor))
+	}
+	return result
+}
+
+// startJob starts tracking the output of cmdName and sets the project's
+// commit status to pending with a link to the output.
+func (p *DefaultProjectCommandRunner) startJob(ctx models.ProjectCommandContext, cmdName models.CommandName) {
+	if p.JobTracker == nil {
+		return
+	}
+	p.JobTracker.StartJob(ctx, cmdName)
+	p.updateJobStatus(ctx, cmdName, models.PendingCommitStatus)
+}
+
+// completeJob marks the job as complete and updates the project's commit
+// status with the result.
+func (p *DefaultProjectCommandRunner) completeJob(ctx models.ProjectCommandContext, result models.ProjectResult) {
+	if p.JobTracker == nil {
+		return
+	}
+	p.JobTracker.CompleteJob(ctx)
+	if p.CommitStatusUpdater == nil || p.JobURLGenerator == nil {
+		return
+	}
+	url := p.JobURLGenerator.GenerateProjectJobURL(ctx)
+	if err := p.CommitStatusUpdater.UpdateProjectResult(ctx, result, url); err != nil {
+		ctx.Log.Warn("unable to update project status: %s", err)
+	}
+}
+
+func (p *DefaultProjectCommandRunner) updateJobStatus(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus) {
+	if p.CommitStatusUpdater == nil || p.JobURLGenerator == nil {
+		return
+	}
+	url := p.JobURLGenerator.GenerateProjectJobURL(ctx)
+	if err := p.CommitStatusUpdater.UpdateProject(ctx, cmdName, status, url); err != nil {
+		ctx.Log.Warn("unable to update project status: %s", err)
+	}
 }
 
 func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (*models.PlanSuccess, string, error) {
@@ -171,8 +264,13 @@ func (p *DefaultProjectCommandRunner) doPlan(ctx models.ProjectCommandContext) (
 		return nil, "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
 
+	var outputsURL string
+	if p.OutputsURLGenerator != nil {
+		outputsURL = p.OutputsURLGenerator.GenerateProjectOutputsURL(ctx.Pull, ctx.RepoRelDir, ctx.Workspace)
+	}
 	return &models.PlanSuccess{
 		LockURL:         p.LockURLGenerator.GenerateLockURL(lockAttempt.LockKey),
+		OutputsURL:      outputsURL,
 		TerraformOutput: strings.Join(outputs, "\n"),
 		RePlanCmd:       ctx.RePlanCmd,
 		ApplyCmd:        ctx.ApplyCmd,
@@ -213,53 +311,265 @@ func (p *DefaultProjectCommandRunner) runSteps(steps []valid.Step, ctx models.Pr
 	return outputs, nil
 }
 
-func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext) (applyOut string, failure string, err error) {
+// checkApproval returns a failure message if the pull request doesn't have
+// the approvals that req, an approved requirement, needs.
+func (p *DefaultProjectCommandRunner) checkApproval(ctx models.ProjectCommandContext, req valid.ApplyRequirement) (string, error) {
+	approvals, err := p.currentApprovals(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(req.ApprovalsFrom) > 0 {
+		approvals, err = p.approvalsFrom(ctx.BaseRepo, approvals, req.ApprovalsFrom)
+		if err != nil {
+			return "", err
+		}
+	}
+	count := req.ApprovalCount
+	if count < 1 {
+		count = 1
+	}
+	if len(approvals) >= count {
+		return "", nil
+	}
+
+	people := "one person"
+	if count > 1 {
+		people = fmt.Sprintf("%d people", count)
+	}
+	if len(req.ApprovalsFrom) > 0 {
+		return fmt.Sprintf("Pull request must be approved by at least %s from `%s` before running apply.",
+			people, strings.Join(req.ApprovalsFrom, "`, `")), nil
+	}
+	return fmt.Sprintf("Pull request must be approved by at least %s other than the author before running apply.", people), nil
+}
+
+// checkCodeOwnersApproval returns a failure message if none of the code owners
+// of the project have approved the pull request. Owners are read from the
+// CODEOWNERS file on the base branch so the pull request can't change who
+// must approve it, and so are the project's files, so it can't add a file
+// with looser owners. If the project has no owners we can check, apply is
+// refused.
+func (p *DefaultProjectCommandRunner) checkCodeOwnersApproval(ctx models.ProjectCommandContext, repoDir string) (string, error) {
+	codeOwners, err := FindCodeOwners(func(path string) ([]byte, error) {
+		return p.WorkingDir.ReadBaseFile(ctx.Log, repoDir, ctx.HeadRepo, ctx.Pull, path)
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "reading CODEOWNERS from the base branch")
+	}
+	if codeOwners == nil {
+		return fmt.Sprintf("Pull request must be approved by a code owner of `%s` before running apply but `%s` has no CODEOWNERS file.",
+			ctx.RepoRelDir, ctx.Pull.BaseBranch), nil
+	}
+	files, err := p.WorkingDir.ListBaseFiles(ctx.Log, repoDir, ctx.HeadRepo, ctx.Pull, ctx.RepoRelDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "listing files in %s on the base branch", ctx.RepoRelDir)
+	}
+	var owners []string
+	for _, owner := range codeOwners.ProjectOwners(ctx.RepoRelDir, files) {
+		// Owners can also be email addresses which we have no way of
+		// matching to approvers so they're ignored.
+		if strings.HasPrefix(owner, "@") {
+			owners = append(owners, owner)
+		}
+	}
+	if len(owners) == 0 {
+		return fmt.Sprintf("Pull request must be approved by a code owner of `%s` before running apply but it has no code owners in the CODEOWNERS file on `%s`.",
+			ctx.RepoRelDir, ctx.Pull.BaseBranch), nil
+	}
+
+	approvals, err := p.currentApprovals(ctx)
+	if err != nil {
+		return "", err
+	}
+	ownerApprovals, err := p.approvalsFrom(ctx.BaseRepo, approvals, owners)
+	if err != nil {
+		return "", err
+	}
+	if len(ownerApprovals) > 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("Pull request must be approved by at least one code owner of `%s` before running apply: `%s`.",
+		ctx.RepoRelDir, strings.Join(owners, "`, `")), nil
+}
+
+// currentApprovals returns the approvals of the pull request that count.
+// Approvals by the author and approvals of commits other than the latest
+// commit are dismissed.
+func (p *DefaultProjectCommandRunner) currentApprovals(ctx models.ProjectCommandContext) ([]models.Approval, error) {
+	approvals, err := p.PullApprovedChecker.GetApprovals(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		return nil, errors.Wrap(err, "getting approvals")
+	}
+	var current []models.Approval
+	for _, approval := range approvals {
+		if strings.EqualFold(approval.User.Username, ctx.Pull.Author) {
+			continue
+		}
+		if approval.CommitSHA != "" && approval.CommitSHA != ctx.Pull.HeadCommit {
+			ctx.Log.Debug("dismissing approval by %s of commit %s since it isn't the latest commit", approval.User.Username, approval.CommitSHA)
+			continue
+		}
+		current = append(current, approval)
+	}
+	return current, nil
+}
+
+// approvalsFrom returns the approvals that are from one of owners. Owners are
+// users, ex. @alice, or teams, ex. @org/sre. Owners containing a slash are
+// teams.
+func (p *DefaultProjectCommandRunner) approvalsFrom(repo models.Repo, approvals []models.Approval, owners []string) ([]models.Approval, error) {
+	var users, teams []string
+	for _, owner := range owners {
+		name := strings.TrimPrefix(owner, "@")
+		if strings.Contains(name, "/") {
+			teams = append(teams, name)
+		} else {
+			users = append(users, name)
+		}
+	}
+
+	var from []models.Approval
+	for _, approval := range approvals {
+		isOwner := false
+		for _, u := range users {
+			if strings.EqualFold(u, approval.User.Username) {
+				isOwner = true
+				break
+			}
+		}
+		for _, team := range teams {
+			if isOwner {
+				break
+			}
+			isMember, err := p.PullApprovedChecker.UserIsTeamMember(repo, approval.User, team)
+			if err != nil {
+				return nil, errors.Wrapf(err, "checking if %s is a member of %s", approval.User.Username, team)
+			}
+			isOwner = isMember
+		}
+		if isOwner {
+			from = append(from, approval)
+		}
+	}
+	return from, nil
+}
+
+// checkChecksPassing returns a failure message if any of the commit statuses
+// or checks of the pull request, other than the ones created by Atlantis,
+// are failing or pending. If the VCS host requires some checks to pass
+// before merging, only those are checked.
+func (p *DefaultProjectCommandRunner) checkChecksPassing(ctx models.ProjectCommandContext) (string, error) {
+	checks, err := p.CommitChecksGetter.GetCommitChecks(ctx.BaseRepo, ctx.Pull)
+	if err != nil {
+		return "", errors.Wrap(err, "getting commit checks")
+	}
+	onlyRequired := false
+	for _, check := range checks {
+		if check.Required && !strings.HasPrefix(check.Name, p.StatusName+"/") {
+			onlyRequired = true
+		}
+	}
+	var failing, pending []string
+	for _, check := range checks {
+		if strings.HasPrefix(check.Name, p.StatusName+"/") {
+			continue
+		}
+		if onlyRequired && !check.Required {
+			continue
+		}
+		// Skipped checks didn't run so there's nothing to wait for. VCS hosts
+		// also treat them as passing when deciding if a pull request can be
+		// merged.
+		if check.Skipped {
+			continue
+		}
+		switch check.State {
+		case models.FailedCommitStatus:
+			failing = append(failing, check.Name)
+		case models.PendingCommitStatus:
+			pending = append(pending, check.Name)
+		}
+	}
+	if len(failing) == 0 && len(pending) == 0 {
+		return "", nil
+	}
+	var notPassing []string
+	if len(failing) > 0 {
+		notPassing = append(notPassing, fmt.Sprintf("failing: `%s`", strings.Join(failing, "`, `")))
+	}
+	if len(pending) > 0 {
+		notPassing = append(notPassing, fmt.Sprintf("pending: `%s`", strings.Join(pending, "`, `")))
+	}
+	if onlyRequired {
+		return fmt.Sprintf("All required commit checks must pass before running apply, %s.", strings.Join(notPassing, ", ")), nil
+	}
+	return fmt.Sprintf("All commit checks must pass before running apply, %s.", strings.Join(notPassing, ", ")), nil
+}
+
+func (p *DefaultProjectCommandRunner) doApply(ctx models.ProjectCommandContext) (applyOut string, failure string, ran bool, err error) {
 	repoDir, err := p.WorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", "", errors.New("project has not been cloned–did you run plan?")
+			return "", "", false, errors.New("project has not been cloned–did you run plan?")
 		}
-		return "", "", err
+		return "", "", false, err
 	}
 	absPath := filepath.Join(repoDir, ctx.RepoRelDir)
 	if _, err = os.Stat(absPath); os.IsNotExist(err) {
-		return "", "", DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
+		return "", "", false, DirNotExistErr{RepoRelDir: ctx.RepoRelDir}
 	}
 
 	for _, req := range ctx.ApplyRequirements {
-		switch req {
+		switch req.Name {
 		case raw.ApprovedApplyRequirement:
-			approved, err := p.PullApprovedChecker.PullIsApproved(ctx.BaseRepo, ctx.Pull) // nolint: vetshadow
+			failure, err := p.checkApproval(ctx, req) // nolint: vetshadow
 			if err != nil {
-				return "", "", errors.Wrap(err, "checking if pull request was approved")
+				return "", "", false, errors.Wrap(err, "checking if pull request was approved")
 			}
-			if !approved {
-				return "", "Pull request must be approved by at least one person other than the author before running apply.", nil
+			if failure != "" {
+				return "", failure, false, nil
 			}
 		case raw.MergeableApplyRequirement:
 			if !ctx.PullMergeable {
-				return "", "Pull request must be mergeable before running apply.", nil
+				return "", "Pull request must be mergeable before running apply.", false, nil
+			}
+		case raw.ChecksPassingApplyRequirement:
+			failure, err := p.checkChecksPassing(ctx) // nolint: vetshadow
+			if err != nil {
+				return "", "", false, errors.Wrap(err, "checking if commit checks are passing")
+			}
+			if failure != "" {
+				return "", failure, false, nil
+			}
+		case raw.UndivergedApplyRequirement:
+			hasDiverged, err := p.WorkingDir.HasDiverged(ctx.Log, repoDir, ctx.HeadRepo, ctx.Pull) // nolint: vetshadow
+			if err != nil {
+				return "", "", false, errors.Wrap(err, "checking if pull request is up to date with base branch")
+			}
+			if hasDiverged {
+				return "", fmt.Sprintf("Pull request must be up to date with `%s` before running apply. Update the branch and run plan again.", ctx.Pull.BaseBranch), false, nil
+			}
+		case raw.CodeOwnersApplyRequirement:
+			failure, err := p.checkCodeOwnersApproval(ctx, repoDir) // nolint: vetshadow
+			if err != nil {
+				return "", "", false, errors.Wrap(err, "checking if pull request was approved by code owners")
+			}
+			if failure != "" {
+				return "", failure, false, nil
 			}
 		}
 	}
 	// Acquire internal lock for the directory we're going to operate in.
 	unlockFn, err := p.WorkingDirLocker.TryLock(ctx.BaseRepo.FullName, ctx.Pull.Num, ctx.Workspace)
 	if err != nil {
-		return "", "", err
+		return "", "", false, err
 	}
 	defer unlockFn()
 
+	sendWebhook(p.Webhooks, ctx.Log, newProjectEvent(webhooks.ApplyStartedEvent, ctx, nil, p.JobURLGenerator, p.Redactor))
 	outputs, err := p.runSteps(ctx.Steps, ctx, absPath)
-	p.Webhooks.Send(ctx.Log, webhooks.ApplyResult{ // nolint: errcheck
-		Workspace: ctx.Workspace,
-		User:      ctx.User,
-		Repo:      ctx.BaseRepo,
-		Pull:      ctx.Pull,
-		Success:   err == nil,
-		Directory: ctx.RepoRelDir,
-	})
 	if err != nil {
-		return "", "", fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
+		return "", "", true, fmt.Errorf("%s\n%s", err, strings.Join(outputs, "\n"))
 	}
-	return strings.Join(outputs, "\n"), "", nil
+	return strings.Join(outputs, "\n"), "", true, nil
 }
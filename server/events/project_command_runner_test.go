@@ -14,7 +14,10 @@
 package events_test
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/hashicorp/go-version"
@@ -26,6 +29,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/runtime"
 	mocks2 "github.com/runatlantis/atlantis/server/events/runtime/mocks"
 	tmocks "github.com/runatlantis/atlantis/server/events/terraform/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
@@ -52,7 +56,6 @@ func TestDefaultProjectCommandRunner_Plan(t *testing.T) {
 		EnvStepRunner:       &realEnv,
 		PullApprovedChecker: nil,
 		WorkingDir:          mockWorkingDir,
-		Webhooks:            nil,
 		WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
 	}
 
@@ -154,17 +157,222 @@ func TestDefaultProjectCommandRunner_ApplyNotApproved(t *testing.T) {
 		WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
 	}
 	ctx := models.ProjectCommandContext{
-		ApplyRequirements: []string{"approved"},
+		ApplyRequirements: []valid.ApplyRequirement{{Name: "approved"}},
 	}
 	tmp, cleanup := TempDir(t)
 	defer cleanup()
 	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
-	When(mockApproved.PullIsApproved(ctx.BaseRepo, ctx.Pull)).ThenReturn(false, nil)
+	When(mockApproved.GetApprovals(ctx.BaseRepo, ctx.Pull)).ThenReturn(nil, nil)
 
 	res := runner.Apply(ctx)
 	Equals(t, "Pull request must be approved by at least one person other than the author before running apply.", res.Failure)
 }
 
+// Test that the approved requirement's count and from options are respected and
+// that approvals of earlier commits or by the author don't count. Mergeable is
+// also required, and the pull isn't mergeable, so that we can tell when the
+// approval check passed.
+func TestDefaultProjectCommandRunner_ApplyApprovalRules(t *testing.T) {
+	alice := models.Approval{User: models.User{Username: "alice"}, CommitSHA: "sha"}
+	bob := models.Approval{User: models.User{Username: "bob"}}
+	carol := models.Approval{User: models.User{Username: "carol"}, CommitSHA: "sha"}
+	cases := []struct {
+		description string
+		req         valid.ApplyRequirement
+		approvals   []models.Approval
+		teamMembers []string
+		expFailure  string
+	}{
+		{
+			description: "count met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalCount: 2},
+			approvals:   []models.Approval{alice, bob},
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "count not met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalCount: 2},
+			approvals:   []models.Approval{alice},
+			expFailure:  "Pull request must be approved by at least 2 people other than the author before running apply.",
+		},
+		{
+			description: "approval of earlier commit",
+			req:         valid.ApplyRequirement{Name: "approved"},
+			approvals:   []models.Approval{{User: models.User{Username: "alice"}, CommitSHA: "old-sha"}},
+			expFailure:  "Pull request must be approved by at least one person other than the author before running apply.",
+		},
+		{
+			description: "approval by author",
+			req:         valid.ApplyRequirement{Name: "approved"},
+			approvals:   []models.Approval{{User: models.User{Username: "Author"}}},
+			expFailure:  "Pull request must be approved by at least one person other than the author before running apply.",
+		},
+		{
+			description: "from user met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalsFrom: []string{"@bob"}},
+			approvals:   []models.Approval{alice, bob},
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "from user not met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalsFrom: []string{"@bob"}},
+			approvals:   []models.Approval{alice},
+			expFailure:  "Pull request must be approved by at least one person from `@bob` before running apply.",
+		},
+		{
+			description: "from team with count met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalCount: 2, ApprovalsFrom: []string{"@bob", "@org/sre"}},
+			approvals:   []models.Approval{alice, bob, carol},
+			teamMembers: []string{"carol"},
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "from team with count not met",
+			req:         valid.ApplyRequirement{Name: "approved", ApprovalCount: 2, ApprovalsFrom: []string{"@bob", "@org/sre"}},
+			approvals:   []models.Approval{alice, bob, carol},
+			expFailure:  "Pull request must be approved by at least 2 people from `@bob`, `@org/sre` before running apply.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApproved := mocks2.NewMockPullApprovedChecker()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:          mockWorkingDir,
+				PullApprovedChecker: mockApproved,
+				WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
+			}
+			ctx := models.ProjectCommandContext{
+				Pull:              models.PullRequest{HeadCommit: "sha", Author: "author"},
+				ApplyRequirements: []valid.ApplyRequirement{c.req, {Name: "mergeable"}},
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockApproved.GetApprovals(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.approvals, nil)
+			When(mockApproved.UserIsTeamMember(matchers.AnyModelsRepo(), matchers.AnyModelsUser(), AnyString())).ThenReturn(false, nil)
+			for _, member := range c.teamMembers {
+				When(mockApproved.UserIsTeamMember(ctx.BaseRepo, models.User{Username: member}, "org/sre")).ThenReturn(true, nil)
+			}
+
+			res := runner.Apply(ctx)
+			Ok(t, res.Error)
+			Equals(t, c.expFailure, res.Failure)
+		})
+	}
+}
+
+// Test that if code owner approval is required we only allow apply once a code
+// owner of the project has approved. Mergeable is also required, and the pull
+// isn't mergeable, so that we can tell when the code owners check passed.
+func TestDefaultProjectCommandRunner_ApplyCodeOwners(t *testing.T) {
+	cases := []struct {
+		description string
+		repoRelDir  string
+		approvals   []models.Approval
+		teamMembers []string
+		// noCodeOwners is true if the base branch has no CODEOWNERS file.
+		noCodeOwners bool
+		expFailure   string
+	}{
+		{
+			description: "no approvals",
+			repoRelDir:  "infra/prod",
+			expFailure:  "Pull request must be approved by at least one code owner of `infra/prod` before running apply: `@alice`, `@org/infra`.",
+		},
+		{
+			description: "approved by someone else",
+			repoRelDir:  "infra/prod",
+			approvals:   []models.Approval{{User: models.User{Username: "bob"}}},
+			expFailure:  "Pull request must be approved by at least one code owner of `infra/prod` before running apply: `@alice`, `@org/infra`.",
+		},
+		{
+			description: "approved by owner",
+			repoRelDir:  "infra/prod",
+			approvals:   []models.Approval{{User: models.User{Username: "bob"}}, {User: models.User{Username: "Alice"}}},
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "approved by owning team member",
+			repoRelDir:  "infra/prod",
+			approvals:   []models.Approval{{User: models.User{Username: "bob"}, CommitSHA: "sha"}},
+			teamMembers: []string{"bob"},
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "owner approved an earlier commit",
+			repoRelDir:  "infra/prod",
+			approvals:   []models.Approval{{User: models.User{Username: "alice"}, CommitSHA: "old-sha"}},
+			expFailure:  "Pull request must be approved by at least one code owner of `infra/prod` before running apply: `@alice`, `@org/infra`.",
+		},
+		{
+			description: "approved by owner of a file the pull request added",
+			repoRelDir:  "infra/prod",
+			approvals:   []models.Approval{{User: models.User{Username: "docs"}}},
+			expFailure:  "Pull request must be approved by at least one code owner of `infra/prod` before running apply: `@alice`, `@org/infra`.",
+		},
+		{
+			description: "no owners",
+			repoRelDir:  "other",
+			approvals:   []models.Approval{{User: models.User{Username: "bob"}}},
+			expFailure:  "Pull request must be approved by a code owner of `other` before running apply but it has no code owners in the CODEOWNERS file on `main`.",
+		},
+		{
+			description:  "no CODEOWNERS file on the base branch",
+			repoRelDir:   "infra/prod",
+			noCodeOwners: true,
+			approvals:    []models.Approval{{User: models.User{Username: "alice"}}},
+			expFailure:   "Pull request must be approved by a code owner of `infra/prod` before running apply but `main` has no CODEOWNERS file.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockApproved := mocks2.NewMockPullApprovedChecker()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:          mockWorkingDir,
+				PullApprovedChecker: mockApproved,
+				WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
+			}
+			ctx := models.ProjectCommandContext{
+				Pull:              models.PullRequest{HeadCommit: "sha", BaseBranch: "main"},
+				RepoRelDir:        c.repoRelDir,
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "codeowners"}, {Name: "mergeable"}},
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			Ok(t, os.MkdirAll(filepath.Join(tmp, "infra", "prod"), 0700))
+			Ok(t, os.MkdirAll(filepath.Join(tmp, "other"), 0700))
+			Ok(t, ioutil.WriteFile(filepath.Join(tmp, "infra", "prod", "main.tf"), nil, 0600))
+			Ok(t, ioutil.WriteFile(filepath.Join(tmp, "other", "main.tf"), nil, 0600))
+			// Files the pull request adds must be ignored too.
+			Ok(t, ioutil.WriteFile(filepath.Join(tmp, "infra", "prod", "README.md"), nil, 0600))
+			// The pull request's own CODEOWNERS file must be ignored.
+			Ok(t, ioutil.WriteFile(filepath.Join(tmp, "CODEOWNERS"), []byte("* @bob\n"), 0600))
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockWorkingDir.ReadBaseFile(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), AnyString())).ThenReturn(nil, nil)
+			if !c.noCodeOwners {
+				When(mockWorkingDir.ReadBaseFile(ctx.Log, tmp, ctx.HeadRepo, ctx.Pull, ".github/CODEOWNERS")).ThenReturn([]byte("/infra/prod/ @alice @org/infra\n/infra/prod/*.md @docs\n"), nil)
+			}
+			When(mockWorkingDir.ListBaseFiles(ctx.Log, tmp, ctx.HeadRepo, ctx.Pull, "infra/prod")).ThenReturn([]string{"infra/prod/main.tf"}, nil)
+			When(mockWorkingDir.ListBaseFiles(ctx.Log, tmp, ctx.HeadRepo, ctx.Pull, "other")).ThenReturn([]string{"other/main.tf"}, nil)
+			When(mockApproved.GetApprovals(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.approvals, nil)
+			When(mockApproved.UserIsTeamMember(matchers.AnyModelsRepo(), matchers.AnyModelsUser(), AnyString())).ThenReturn(false, nil)
+			for _, member := range c.teamMembers {
+				When(mockApproved.UserIsTeamMember(ctx.BaseRepo, models.User{Username: member}, "org/infra")).ThenReturn(true, nil)
+			}
+
+			res := runner.Apply(ctx)
+			Ok(t, res.Error)
+			Equals(t, c.expFailure, res.Failure)
+		})
+	}
+}
+
 // Test that if mergeable is required and the PR isn't mergeable we give an error.
 func TestDefaultProjectCommandRunner_ApplyNotMergeable(t *testing.T) {
 	RegisterMockTestingT(t)
@@ -175,7 +383,7 @@ func TestDefaultProjectCommandRunner_ApplyNotMergeable(t *testing.T) {
 	}
 	ctx := models.ProjectCommandContext{
 		PullMergeable:     false,
-		ApplyRequirements: []string{"mergeable"},
+		ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
 	}
 	tmp, cleanup := TempDir(t)
 	defer cleanup()
@@ -185,12 +393,207 @@ func TestDefaultProjectCommandRunner_ApplyNotMergeable(t *testing.T) {
 	Equals(t, "Pull request must be mergeable before running apply.", res.Failure)
 }
 
+func TestDefaultProjectCommandRunner_ApplyChecksPassing(t *testing.T) {
+	cases := []struct {
+		description string
+		checks      []models.CommitCheck
+		expFailure  string
+	}{
+		{
+			description: "no checks",
+			expFailure:  "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "passing",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.SuccessCommitStatus},
+				{Name: "atlantis/plan", State: models.PendingCommitStatus},
+				{Name: "atlantis/apply: dir/default", State: models.FailedCommitStatus},
+			},
+			expFailure: "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "pending",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.PendingCommitStatus},
+				{Name: "atlantis-other/build", State: models.SuccessCommitStatus},
+			},
+			expFailure: "All commit checks must pass before running apply, pending: `ci/build`.",
+		},
+		{
+			description: "failing and pending",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.FailedCommitStatus},
+				{Name: "ci/lint", State: models.FailedCommitStatus},
+				{Name: "ci/test", State: models.PendingCommitStatus},
+			},
+			expFailure: "All commit checks must pass before running apply, failing: `ci/build`, `ci/lint`, pending: `ci/test`.",
+		},
+		{
+			description: "skipped",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.SuccessCommitStatus},
+				{Name: "ci/docs", State: models.SuccessCommitStatus, Skipped: true},
+				// Skipped checks pass whatever their state.
+				{Name: "ci/deploy", State: models.PendingCommitStatus, Skipped: true},
+			},
+			expFailure: "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "only required checks count",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.SuccessCommitStatus, Required: true},
+				{Name: "ci/optional", State: models.FailedCommitStatus},
+				{Name: "ci/test", State: models.PendingCommitStatus, Required: true},
+			},
+			expFailure: "All required commit checks must pass before running apply, pending: `ci/test`.",
+		},
+		{
+			description: "required checks passing",
+			checks: []models.CommitCheck{
+				{Name: "ci/build", State: models.SuccessCommitStatus, Required: true},
+				{Name: "ci/docs", State: models.SuccessCommitStatus, Required: true, Skipped: true},
+				{Name: "ci/optional", State: models.FailedCommitStatus},
+			},
+			expFailure: "Pull request must be mergeable before running apply.",
+		},
+		{
+			description: "only atlantis checks required",
+			checks: []models.CommitCheck{
+				{Name: "atlantis/plan", State: models.PendingCommitStatus, Required: true},
+				{Name: "ci/build", State: models.FailedCommitStatus},
+			},
+			expFailure: "All commit checks must pass before running apply, failing: `ci/build`.",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockChecks := mocks2.NewMockCommitChecksGetter()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:         mockWorkingDir,
+				CommitChecksGetter: mockChecks,
+				StatusName:         "atlantis",
+				WorkingDirLocker:   events.NewDefaultWorkingDirLocker(),
+			}
+			ctx := models.ProjectCommandContext{
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "checks_passing"}, {Name: "mergeable"}},
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockChecks.GetCommitChecks(ctx.BaseRepo, ctx.Pull)).ThenReturn(c.checks, nil)
+
+			res := runner.Apply(ctx)
+			Equals(t, c.expFailure, res.Failure)
+		})
+	}
+}
+
+func TestDefaultProjectCommandRunner_ApplyDiverged(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:       mockWorkingDir,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+	}
+	ctx := models.ProjectCommandContext{
+		Pull:              models.PullRequest{BaseBranch: "master"},
+		ApplyRequirements: []valid.ApplyRequirement{{Name: "undiverged"}},
+	}
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+	When(mockWorkingDir.HasDiverged(ctx.Log, tmp, ctx.HeadRepo, ctx.Pull)).ThenReturn(true, nil)
+
+	res := runner.Apply(ctx)
+	Equals(t, "Pull request must be up to date with `master` before running apply. Update the branch and run plan again.", res.Failure)
+}
+
+// Test that if a job tracker is set, the command is tracked as a job and the
+// project's status links to it.
+func TestDefaultProjectCommandRunner_ApplyTracksJob(t *testing.T) {
+	RegisterMockTestingT(t)
+	mockWorkingDir := mocks.NewMockWorkingDir()
+	mockTracker := mocks.NewMockProjectJobTracker()
+	mockURLGen := mocks.NewMockJobURLGenerator()
+	mockUpdater := mocks.NewMockCommitStatusUpdater()
+	runner := &events.DefaultProjectCommandRunner{
+		WorkingDir:          mockWorkingDir,
+		WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
+		JobTracker:          mockTracker,
+		JobURLGenerator:     mockURLGen,
+		CommitStatusUpdater: mockUpdater,
+	}
+	ctx := models.ProjectCommandContext{
+		Log:               logging.NewNoopLogger(),
+		PullMergeable:     false,
+		ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
+	}
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+	When(mockURLGen.GenerateProjectJobURL(ctx)).ThenReturn("https://atlantis/jobs/1")
+
+	res := runner.Apply(ctx)
+	Equals(t, "Pull request must be mergeable before running apply.", res.Failure)
+	mockTracker.VerifyWasCalledOnce().StartJob(ctx, models.ApplyCommand)
+	mockTracker.VerifyWasCalledOnce().CompleteJob(ctx)
+	mockUpdater.VerifyWasCalledOnce().UpdateProject(ctx, models.ApplyCommand, models.PendingCommitStatus, "https://atlantis/jobs/1")
+	mockUpdater.VerifyWasCalledOnce().UpdateProjectResult(ctx, res, "https://atlantis/jobs/1")
+}
+
+// Test that apply webhooks are only sent if the apply requirements pass.
+func TestDefaultProjectCommandRunner_ApplyWebhooks(t *testing.T) {
+	for _, mergeable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("mergeable %t", mergeable), func(t *testing.T) {
+			RegisterMockTestingT(t)
+			mockWorkingDir := mocks.NewMockWorkingDir()
+			mockRun := mocks.NewMockCustomStepRunner()
+			mockSender := mocks.NewMockWebhooksSender()
+			runner := &events.DefaultProjectCommandRunner{
+				WorkingDir:       mockWorkingDir,
+				WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+				RunStepRunner:    mockRun,
+				Webhooks:         mockSender,
+			}
+			ctx := models.ProjectCommandContext{
+				Log:               logging.NewNoopLogger(),
+				PullMergeable:     mergeable,
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
+				Steps:             []valid.Step{{StepName: "run", RunCommand: "apply"}},
+				RepoRelDir:        ".",
+				Workspace:         "default",
+			}
+			tmp, cleanup := TempDir(t)
+			defer cleanup()
+			When(mockWorkingDir.GetWorkingDir(ctx.BaseRepo, ctx.Pull, ctx.Workspace)).ThenReturn(tmp, nil)
+			When(mockRun.Run(matchers.AnyModelsProjectCommandContext(), EqString("apply"), AnyString(), matchers.AnyMapOfStringToString())).ThenReturn("applied", nil)
+
+			res := runner.Apply(ctx)
+			if !mergeable {
+				Equals(t, "Pull request must be mergeable before running apply.", res.Failure)
+				mockSender.VerifyWasCalled(Never()).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent())
+				return
+			}
+			Equals(t, "applied", res.ApplySuccess)
+			_, sent := mockSender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+			Equals(t, webhooks.ApplyStartedEvent, sent[0].Type)
+			Equals(t, webhooks.ApplyFinishedEvent, sent[1].Type)
+			Equals(t, "applied", sent[1].Output)
+			Assert(t, sent[1].Success, "exp apply_finished to be successful")
+		})
+	}
+}
+
 // Test that it runs the expected apply steps.
 func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 	cases := []struct {
 		description string
 		steps       []valid.Step
-		applyReqs   []string
+		applyReqs   []valid.ApplyRequirement
 
 		expSteps      []string
 		expOut        string
@@ -206,7 +609,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 		{
 			description: "approval required",
 			steps:       valid.DefaultApplyStage.Steps,
-			applyReqs:   []string{"approved"},
+			applyReqs:   []valid.ApplyRequirement{{Name: "approved"}},
 			expSteps:    []string{"approve", "apply"},
 			expOut:      "apply",
 		},
@@ -214,7 +617,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			description:   "mergeable required",
 			steps:         valid.DefaultApplyStage.Steps,
 			pullMergeable: true,
-			applyReqs:     []string{"mergeable"},
+			applyReqs:     []valid.ApplyRequirement{{Name: "mergeable"}},
 			expSteps:      []string{"apply"},
 			expOut:        "apply",
 		},
@@ -222,7 +625,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			description:   "mergeable required, pull not mergeable",
 			steps:         valid.DefaultApplyStage.Steps,
 			pullMergeable: false,
-			applyReqs:     []string{"mergeable"},
+			applyReqs:     []valid.ApplyRequirement{{Name: "mergeable"}},
 			expSteps:      []string{""},
 			expOut:        "",
 			expFailure:    "Pull request must be mergeable before running apply.",
@@ -231,7 +634,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			description:   "mergeable and approved required",
 			steps:         valid.DefaultApplyStage.Steps,
 			pullMergeable: true,
-			applyReqs:     []string{"mergeable", "approved"},
+			applyReqs:     []valid.ApplyRequirement{{Name: "mergeable"}, {Name: "approved"}},
 			expSteps:      []string{"approved", "apply"},
 			expOut:        "apply",
 		},
@@ -275,7 +678,6 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			mockApproved := mocks2.NewMockPullApprovedChecker()
 			mockWorkingDir := mocks.NewMockWorkingDir()
 			mockLocker := mocks.NewMockProjectLocker()
-			mockSender := mocks.NewMockWebhooksSender()
 
 			runner := events.DefaultProjectCommandRunner{
 				Locker:              mockLocker,
@@ -287,7 +689,6 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 				EnvStepRunner:       mockEnv,
 				PullApprovedChecker: mockApproved,
 				WorkingDir:          mockWorkingDir,
-				Webhooks:            mockSender,
 				WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
 			}
 			repoDir, cleanup := TempDir(t)
@@ -314,7 +715,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			When(mockApply.Run(ctx, nil, repoDir, expEnvs)).ThenReturn("apply", nil)
 			When(mockRun.Run(ctx, "", repoDir, expEnvs)).ThenReturn("run", nil)
 			When(mockEnv.Run(ctx, "", "value", repoDir, make(map[string]string))).ThenReturn("value", nil)
-			When(mockApproved.PullIsApproved(ctx.BaseRepo, ctx.Pull)).ThenReturn(true, nil)
+			When(mockApproved.GetApprovals(ctx.BaseRepo, ctx.Pull)).ThenReturn([]models.Approval{{User: models.User{Username: "approver"}}}, nil)
 
 			res := runner.Apply(ctx)
 			Equals(t, c.expOut, res.ApplySuccess)
@@ -323,7 +724,7 @@ func TestDefaultProjectCommandRunner_Apply(t *testing.T) {
 			for _, step := range c.expSteps {
 				switch step {
 				case "approved":
-					mockApproved.VerifyWasCalledOnce().PullIsApproved(ctx.BaseRepo, ctx.Pull)
+					mockApproved.VerifyWasCalledOnce().GetApprovals(ctx.BaseRepo, ctx.Pull)
 				case "init":
 					mockInit.VerifyWasCalledOnce().Run(ctx, nil, repoDir, expEnvs)
 				case "plan":
@@ -364,7 +765,6 @@ func TestDefaultProjectCommandRunner_RunEnvSteps(t *testing.T) {
 		EnvStepRunner:       &env,
 		PullApprovedChecker: nil,
 		WorkingDir:          mockWorkingDir,
-		Webhooks:            nil,
 		WorkingDirLocker:    events.NewDefaultWorkingDirLocker(),
 	}
 
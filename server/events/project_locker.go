@@ -19,6 +19,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -40,6 +41,9 @@ type ProjectLocker interface {
 type DefaultProjectLocker struct {
 	Locker    locking.Locker
 	VCSClient vcs.Client
+	// Webhooks sends webhooks when locks are acquired and released. If nil,
+	// no webhooks are sent.
+	Webhooks WebhooksSender
 }
 
 // TryLockResponse is the result of trying to lock a project.
@@ -78,12 +82,38 @@ func (p *DefaultProjectLocker) TryLock(log *logging.SimpleLogger, pull models.Pu
 		}, nil
 	}
 	log.Info("acquired lock with id %q", lockAttempt.LockKey)
+	// LockAcquired is false if this pull already held the lock.
+	if lockAttempt.LockAcquired {
+		p.sendWebhook(log, webhooks.LockAcquiredEvent, pull, user, workspace, project)
+	}
 	return &TryLockResponse{
 		LockAcquired: true,
 		UnlockFn: func() error {
-			_, err := p.Locker.Unlock(lockAttempt.LockKey)
-			return err
+			if _, err := p.Locker.Unlock(lockAttempt.LockKey); err != nil {
+				return err
+			}
+			p.sendWebhook(log, webhooks.LockReleasedEvent, pull, user, workspace, project)
+			return nil
 		},
 		LockKey: lockAttempt.LockKey,
 	}, nil
 }
+
+// sendWebhook sends the webhooks for a lock event if webhooks are configured.
+func (p *DefaultProjectLocker) sendWebhook(log *logging.SimpleLogger, eventType string, pull models.PullRequest, user models.User, workspace string, project models.Project) {
+	if p.Webhooks == nil {
+		return
+	}
+	err := p.Webhooks.Send(log, webhooks.Event{
+		Type:      eventType,
+		Repo:      pull.BaseRepo,
+		Pull:      pull,
+		User:      user,
+		Directory: project.Path,
+		Workspace: workspace,
+		Success:   true,
+	})
+	if err != nil {
+		log.Warn("unable to send %s webhook: %s", eventType, err)
+	}
+}
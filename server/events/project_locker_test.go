@@ -21,15 +21,18 @@ import (
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/locking"
 	"github.com/runatlantis/atlantis/server/events/locking/mocks"
+	emocks "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
 func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -65,7 +68,7 @@ func TestDefaultProjectLocker_TryLockWhenLocked(t *testing.T) {
 func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -104,7 +107,7 @@ func TestDefaultProjectLocker_TryLockWhenLockedSamePull(t *testing.T) {
 func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	RegisterMockTestingT(t)
 	var githubClient *vcs.GithubClient
-	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil)
+	mockClient := vcs.NewClientProxy(githubClient, nil, nil, nil, nil, nil)
 	mockLocker := mocks.NewMockLocker()
 	locker := events.DefaultProjectLocker{
 		Locker:    mockLocker,
@@ -139,3 +142,69 @@ func TestDefaultProjectLocker_TryLockUnlocked(t *testing.T) {
 	Ok(t, err)
 	mockLocker.VerifyWasCalledOnce().Unlock(lockKey)
 }
+
+func TestDefaultProjectLocker_Webhooks(t *testing.T) {
+	t.Log("Webhooks should be sent when the lock is acquired and released")
+	RegisterMockTestingT(t)
+	mockLocker := mocks.NewMockLocker()
+	mockSender := emocks.NewMockWebhooksSender()
+	locker := events.DefaultProjectLocker{
+		Locker:   mockLocker,
+		Webhooks: mockSender,
+	}
+	expProject := models.Project{Path: "path"}
+	expWorkspace := "default"
+	expPull := models.PullRequest{Num: 2, BaseRepo: models.Repo{FullName: "owner/repo"}}
+	expUser := models.User{Username: "user"}
+	lockKey := "key"
+	When(mockLocker.TryLock(expProject, expWorkspace, expPull, expUser)).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: true,
+			LockKey:      lockKey,
+		},
+		nil,
+	)
+	log := logging.NewNoopLogger()
+	expEvent := webhooks.Event{
+		Type:      webhooks.LockAcquiredEvent,
+		Repo:      expPull.BaseRepo,
+		Pull:      expPull,
+		User:      expUser,
+		Directory: "path",
+		Workspace: expWorkspace,
+		Success:   true,
+	}
+	res, err := locker.TryLock(log, expPull, expUser, expWorkspace, expProject)
+	Ok(t, err)
+	mockSender.VerifyWasCalledOnce().Send(log, expEvent)
+
+	Ok(t, res.UnlockFn())
+	expEvent.Type = webhooks.LockReleasedEvent
+	mockSender.VerifyWasCalledOnce().Send(log, expEvent)
+}
+
+func TestDefaultProjectLocker_NoWebhookWhenAlreadyLocked(t *testing.T) {
+	t.Log("No webhook should be sent if the pull already held the lock")
+	RegisterMockTestingT(t)
+	mockLocker := mocks.NewMockLocker()
+	mockSender := emocks.NewMockWebhooksSender()
+	locker := events.DefaultProjectLocker{
+		Locker:   mockLocker,
+		Webhooks: mockSender,
+	}
+	expPull := models.PullRequest{Num: 2}
+	When(mockLocker.TryLock(models.Project{}, "default", expPull, models.User{})).ThenReturn(
+		locking.TryLockResponse{
+			LockAcquired: false,
+			CurrLock: models.ProjectLock{
+				Pull: expPull,
+			},
+			LockKey: "key",
+		},
+		nil,
+	)
+	res, err := locker.TryLock(logging.NewNoopLogger(), expPull, models.User{}, "default", models.Project{})
+	Ok(t, err)
+	Equals(t, true, res.LockAcquired)
+	mockSender.VerifyWasCalled(Never()).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent())
+}
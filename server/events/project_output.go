@@ -0,0 +1,43 @@
+package events
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// ansiEscapeRegex matches terminal escape sequences, ex. the color codes
+// output by custom run steps that don't use -no-color.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// stripANSI removes terminal escape sequences from s.
+func stripANSI(s string) string {
+	return ansiEscapeRegex.ReplaceAllString(s, "")
+}
+
+// newProjectOutput converts the result of running a command on a project into
+// the output we store for the project's history.
+func newProjectOutput(res models.ProjectResult, headCommit string, t time.Time) models.ProjectOutput {
+	var output string
+	switch {
+	case res.Error != nil:
+		output = res.Error.Error()
+	case res.Failure != "":
+		output = res.Failure
+	case res.PlanSuccess != nil:
+		output = res.PlanSuccess.TerraformOutput
+	default:
+		output = res.ApplySuccess
+	}
+	return models.ProjectOutput{
+		RepoRelDir:  res.RepoRelDir,
+		Workspace:   res.Workspace,
+		ProjectName: res.ProjectName,
+		Command:     res.Command,
+		Status:      res.PlanStatus(),
+		HeadCommit:  headCommit,
+		Output:      stripANSI(output),
+		Time:        t,
+	}
+}
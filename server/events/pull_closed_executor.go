@@ -28,6 +28,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pull_cleaner.go PullCleaner
@@ -47,6 +48,11 @@ type PullClosedExecutor struct {
 	WorkingDir WorkingDir
 	Logger     logging.SimpleLogging
 	DB         *db.BoltDB
+	// JobTracker is optional. If set, the jobs for the pull are deleted.
+	JobTracker ProjectJobTracker
+	// Webhooks sends a lock_released webhook for each lock that's deleted.
+	// If nil, no webhooks are sent.
+	Webhooks WebhooksSender
 }
 
 type templatedProject struct {
@@ -72,12 +78,17 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 	if err != nil {
 		return errors.Wrap(err, "cleaning up locks")
 	}
+	p.sendLockReleasedWebhooks(repo, pull, locks)
 
 	// Delete pull from DB.
 	if err := p.DB.DeletePullStatus(pull); err != nil {
 		p.Logger.Err("deleting pull from db: %s", err)
 	}
 
+	if p.JobTracker != nil {
+		p.JobTracker.CleanUpPull(pull)
+	}
+
 	// If there are no locks then there's no need to comment.
 	if len(locks) == 0 {
 		return nil
@@ -91,6 +102,26 @@ func (p *PullClosedExecutor) CleanUpPull(repo models.Repo, pull models.PullReque
 	return p.VCSClient.CreateComment(repo, pull.Num, buf.String())
 }
 
+// sendLockReleasedWebhooks sends a lock_released webhook for each of locks,
+// which were deleted because pull was closed.
+func (p *PullClosedExecutor) sendLockReleasedWebhooks(repo models.Repo, pull models.PullRequest, locks []models.ProjectLock) {
+	if p.Webhooks == nil || len(locks) == 0 {
+		return
+	}
+	log := p.Logger.NewLogger(fmt.Sprintf("%s#%d", repo.FullName, pull.Num), true, p.Logger.GetLevel())
+	for _, lock := range locks {
+		sendWebhook(p.Webhooks, log, webhooks.Event{
+			Type:      webhooks.LockReleasedEvent,
+			Repo:      repo,
+			Pull:      pull,
+			User:      lock.User,
+			Directory: lock.Project.Path,
+			Workspace: lock.Workspace,
+			Success:   true,
+		})
+	}
+}
+
 // buildTemplateData formats the lock data into a slice that can easily be
 // templated for the VCS comment. We organize all the workspaces by their
 // respective project paths so the comment can look like:
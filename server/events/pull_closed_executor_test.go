@@ -27,6 +27,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/models/fixtures"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -171,3 +173,51 @@ func TestCleanUpPullComments(t *testing.T) {
 		}()
 	}
 }
+
+// Test that a lock_released webhook is sent for each lock that's deleted.
+func TestCleanUpPullLockReleasedWebhooks(t *testing.T) {
+	RegisterMockTestingT(t)
+	w := mocks.NewMockWorkingDir()
+	cp := vcsmocks.NewMockClient()
+	l := lockmocks.NewMockLocker()
+	sender := mocks.NewMockWebhooksSender()
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	pce := events.PullClosedExecutor{
+		Locker:     l,
+		VCSClient:  cp,
+		WorkingDir: w,
+		Logger:     logging.NewNoopLogger(),
+		DB:         db,
+		Webhooks:   sender,
+	}
+	user := models.User{Username: "lkysow"}
+	When(l.UnlockByPull(fixtures.GithubRepo.FullName, fixtures.Pull.Num)).ThenReturn([]models.ProjectLock{
+		{
+			Project:   models.NewProject("owner/repo", "path1"),
+			Workspace: "default",
+			User:      user,
+		},
+		{
+			Project:   models.NewProject("owner/repo", "path2"),
+			Workspace: "staging",
+			User:      user,
+		},
+	}, nil)
+	Ok(t, pce.CleanUpPull(fixtures.GithubRepo, fixtures.Pull))
+
+	_, sent := sender.VerifyWasCalled(Times(2)).Send(matchers.AnyPtrToLoggingSimpleLogger(), matchers.AnyWebhooksEvent()).GetAllCapturedArguments()
+	for i, exp := range []struct{ dir, workspace string }{{"path1", "default"}, {"path2", "staging"}} {
+		Equals(t, webhooks.Event{
+			Type:      webhooks.LockReleasedEvent,
+			Repo:      fixtures.GithubRepo,
+			Pull:      fixtures.Pull,
+			User:      user,
+			Directory: exp.dir,
+			Workspace: exp.workspace,
+			Success:   true,
+		}, sent[i])
+	}
+}
@@ -20,6 +20,8 @@ type ApplyStepRunner struct {
 	TerraformExecutor   TerraformExec
 	CommitStatusUpdater StatusUpdater
 	AsyncTFExec         AsyncTFExec
+	// OutputWriter, if set, receives the output of apply while it's running.
+	OutputWriter OutputWriter
 }
 
 func (a *ApplyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
@@ -47,7 +49,7 @@ func (a *ApplyStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []stri
 		// NOTE: we need to quote the plan path because Bitbucket Server can
 		// have spaces in its repo owner names which is part of the path.
 		args := append(append(append([]string{"apply", "-input=false", "-no-color"}, extraArgs...), ctx.EscapedCommentArgs...), fmt.Sprintf("%q", planPath))
-		out, err = a.TerraformExecutor.RunCommandWithVersion(ctx.Log, path, args, envs, ctx.TerraformVersion, ctx.Workspace)
+		out, err = runTerraform(ctx, a.TerraformExecutor, a.AsyncTFExec, a.OutputWriter, path, args, envs, ctx.TerraformVersion)
 	}
 
 	// If the apply was successful, delete the plan.
@@ -151,6 +153,9 @@ func (a *ApplyStepRunner) runRemoteApply(
 			break
 		}
 		lines = append(lines, line.Line)
+		if a.OutputWriter != nil {
+			a.OutputWriter.WriteLine(ctx, line.Line)
+		}
 
 		// Here we're checking for the run url and updating the status
 		// if found.
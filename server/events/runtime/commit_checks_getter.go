@@ -0,0 +1,13 @@
+package runtime
+
+import (
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_commit_checks_getter.go CommitChecksGetter
+
+type CommitChecksGetter interface {
+	// GetCommitChecks returns the commit statuses and checks reported on
+	// pull's head commit, including the ones created by Atlantis.
+	GetCommitChecks(baseRepo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error)
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnySliceOfModelsCommitCheck() []models.CommitCheck {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*([]models.CommitCheck))(nil)).Elem()))
+	var nullValue []models.CommitCheck
+	return nullValue
+}
+
+func EqSliceOfModelsCommitCheck(value []models.CommitCheck) []models.CommitCheck {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue []models.CommitCheck
+	return nullValue
+}
@@ -0,0 +1,113 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events/runtime (interfaces: CommitChecksGetter)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockCommitChecksGetter struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockCommitChecksGetter(options ...pegomock.Option) *MockCommitChecksGetter {
+	mock := &MockCommitChecksGetter{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockCommitChecksGetter) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockCommitChecksGetter) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockCommitChecksGetter) GetCommitChecks(baseRepo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockCommitChecksGetter().")
+	}
+	params := []pegomock.Param{baseRepo, pull}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetCommitChecks", params, []reflect.Type{reflect.TypeOf((*[]models.CommitCheck)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []models.CommitCheck
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]models.CommitCheck)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockCommitChecksGetter) VerifyWasCalledOnce() *VerifierMockCommitChecksGetter {
+	return &VerifierMockCommitChecksGetter{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockCommitChecksGetter) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockCommitChecksGetter {
+	return &VerifierMockCommitChecksGetter{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockCommitChecksGetter) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockCommitChecksGetter {
+	return &VerifierMockCommitChecksGetter{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockCommitChecksGetter) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockCommitChecksGetter {
+	return &VerifierMockCommitChecksGetter{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockCommitChecksGetter struct {
+	mock                   *MockCommitChecksGetter
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockCommitChecksGetter) GetCommitChecks(baseRepo models.Repo, pull models.PullRequest) *MockCommitChecksGetter_GetCommitChecks_OngoingVerification {
+	params := []pegomock.Param{baseRepo, pull}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetCommitChecks", params, verifier.timeout)
+	return &MockCommitChecksGetter_GetCommitChecks_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockCommitChecksGetter_GetCommitChecks_OngoingVerification struct {
+	mock              *MockCommitChecksGetter
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockCommitChecksGetter_GetCommitChecks_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+	baseRepo, pull := c.GetAllCapturedArguments()
+	return baseRepo[len(baseRepo)-1], pull[len(pull)-1]
+}
+
+func (c *MockCommitChecksGetter_GetCommitChecks_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+	}
+	return
+}
@@ -25,12 +25,31 @@ func NewMockPullApprovedChecker(options ...pegomock.Option) *MockPullApprovedChe
 func (mock *MockPullApprovedChecker) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
 func (mock *MockPullApprovedChecker) FailHandler() pegomock.FailHandler      { return mock.fail }
 
-func (mock *MockPullApprovedChecker) PullIsApproved(baseRepo models.Repo, pull models.PullRequest) (bool, error) {
+func (mock *MockPullApprovedChecker) GetApprovals(baseRepo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
 	if mock == nil {
 		panic("mock must not be nil. Use myMock := NewMockPullApprovedChecker().")
 	}
 	params := []pegomock.Param{baseRepo, pull}
-	result := pegomock.GetGenericMockFrom(mock).Invoke("PullIsApproved", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetApprovals", params, []reflect.Type{reflect.TypeOf((*[]models.Approval)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 []models.Approval
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].([]models.Approval)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockPullApprovedChecker) UserIsTeamMember(baseRepo models.Repo, user models.User, team string) (bool, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockPullApprovedChecker().")
+	}
+	params := []pegomock.Param{baseRepo, user, team}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("UserIsTeamMember", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
 	var ret0 bool
 	var ret1 error
 	if len(result) != 0 {
@@ -81,33 +100,68 @@ type VerifierMockPullApprovedChecker struct {
 	timeout                time.Duration
 }
 
-func (verifier *VerifierMockPullApprovedChecker) PullIsApproved(baseRepo models.Repo, pull models.PullRequest) *MockPullApprovedChecker_PullIsApproved_OngoingVerification {
+func (verifier *VerifierMockPullApprovedChecker) GetApprovals(baseRepo models.Repo, pull models.PullRequest) *MockPullApprovedChecker_GetApprovals_OngoingVerification {
 	params := []pegomock.Param{baseRepo, pull}
-	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "PullIsApproved", params, verifier.timeout)
-	return &MockPullApprovedChecker_PullIsApproved_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetApprovals", params, verifier.timeout)
+	return &MockPullApprovedChecker_GetApprovals_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
 }
 
-type MockPullApprovedChecker_PullIsApproved_OngoingVerification struct {
+type MockPullApprovedChecker_GetApprovals_OngoingVerification struct {
 	mock              *MockPullApprovedChecker
 	methodInvocations []pegomock.MethodInvocation
 }
 
-func (c *MockPullApprovedChecker_PullIsApproved_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
+func (c *MockPullApprovedChecker_GetApprovals_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest) {
 	baseRepo, pull := c.GetAllCapturedArguments()
 	return baseRepo[len(baseRepo)-1], pull[len(pull)-1]
 }
 
-func (c *MockPullApprovedChecker_PullIsApproved_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
+func (c *MockPullApprovedChecker_GetApprovals_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest) {
 	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
 	if len(params) > 0 {
-		_param0 = make([]models.Repo, len(params[0]))
+		_param0 = make([]models.Repo, len(c.methodInvocations))
 		for u, param := range params[0] {
 			_param0[u] = param.(models.Repo)
 		}
-		_param1 = make([]models.PullRequest, len(params[1]))
+		_param1 = make([]models.PullRequest, len(c.methodInvocations))
 		for u, param := range params[1] {
 			_param1[u] = param.(models.PullRequest)
 		}
 	}
 	return
 }
+
+func (verifier *VerifierMockPullApprovedChecker) UserIsTeamMember(baseRepo models.Repo, user models.User, team string) *MockPullApprovedChecker_UserIsTeamMember_OngoingVerification {
+	params := []pegomock.Param{baseRepo, user, team}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UserIsTeamMember", params, verifier.timeout)
+	return &MockPullApprovedChecker_UserIsTeamMember_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockPullApprovedChecker_UserIsTeamMember_OngoingVerification struct {
+	mock              *MockPullApprovedChecker
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockPullApprovedChecker_UserIsTeamMember_OngoingVerification) GetCapturedArguments() (models.Repo, models.User, string) {
+	baseRepo, user, team := c.GetAllCapturedArguments()
+	return baseRepo[len(baseRepo)-1], user[len(user)-1], team[len(team)-1]
+}
+
+func (c *MockPullApprovedChecker_UserIsTeamMember_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.User, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(c.methodInvocations))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.User, len(c.methodInvocations))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.User)
+		}
+		_param2 = make([]string, len(c.methodInvocations))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
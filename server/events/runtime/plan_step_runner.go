@@ -31,6 +31,8 @@ type PlanStepRunner struct {
 	DefaultTFVersion    *version.Version
 	CommitStatusUpdater StatusUpdater
 	AsyncTFExec         AsyncTFExec
+	// OutputWriter, if set, receives the output of plan while it's running.
+	OutputWriter OutputWriter
 }
 
 func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []string, path string, envs map[string]string) (string, error) {
@@ -47,7 +49,7 @@ func (p *PlanStepRunner) Run(ctx models.ProjectCommandContext, extraArgs []strin
 
 	planFile := filepath.Join(path, GetPlanFilename(ctx.Workspace, ctx.ProjectName))
 	planCmd := p.buildPlanCmd(ctx, extraArgs, path, tfVersion, planFile)
-	output, err := p.TerraformExecutor.RunCommandWithVersion(ctx.Log, filepath.Clean(path), planCmd, envs, tfVersion, ctx.Workspace)
+	output, err := runTerraform(ctx, p.TerraformExecutor, p.AsyncTFExec, p.OutputWriter, filepath.Clean(path), planCmd, envs, tfVersion)
 	if p.isRemoteOpsErr(output, err) {
 		ctx.Log.Debug("detected that this project is using TFE remote ops")
 		return p.remotePlan(ctx, extraArgs, path, tfVersion, planFile, envs)
@@ -276,6 +278,9 @@ func (p *PlanStepRunner) runRemotePlan(
 			break
 		}
 		lines = append(lines, line.Line)
+		if p.OutputWriter != nil {
+			p.OutputWriter.WriteLine(ctx, line.Line)
+		}
 
 		// Here we're checking for the run url and updating the status
 		// if found.
@@ -793,6 +793,54 @@ Plan: 0 to add, 0 to change, 1 to destroy.`, string(bytes))
 	}
 }
 
+func TestRun_StreamsOutput(t *testing.T) {
+	// Tests that if an OutputWriter is set, plan is run asynchronously and its
+	// output is written line by line.
+	RegisterMockTestingT(t)
+	terraform := mocks.NewMockClient()
+	tfVersion, _ := version.NewVersion("0.12.0")
+	logger := logging.NewNoopLogger()
+	asyncTf := &remotePlanMock{LinesToSend: "Refreshing state...\n  + null_resource.hi"}
+	writer := &testOutputWriter{}
+	s := runtime.PlanStepRunner{
+		TerraformExecutor: terraform,
+		DefaultTFVersion:  tfVersion,
+		AsyncTFExec:       asyncTf,
+		OutputWriter:      writer,
+	}
+	When(terraform.RunCommandWithVersion(logger, "/path", []string{"workspace", "show"}, map[string]string(nil), tfVersion, "default")).ThenReturn("default\n", nil)
+
+	ctx := models.ProjectCommandContext{
+		Log:        logger,
+		Workspace:  "default",
+		RepoRelDir: ".",
+		User:       models.User{Username: "username"},
+		Pull: models.PullRequest{
+			Num: 2,
+		},
+		BaseRepo: models.Repo{
+			FullName: "owner/repo",
+			Owner:    "owner",
+			Name:     "repo",
+		},
+	}
+	output, err := s.Run(ctx, nil, "/path", map[string]string(nil))
+	Ok(t, err)
+	Equals(t, "Refreshing state...\n+ null_resource.hi\n", output)
+	Equals(t, []string{"Refreshing state...", "  + null_resource.hi"}, writer.lines)
+	Equals(t, "plan", asyncTf.CalledArgs[0])
+	// Plan shouldn't also be run synchronously.
+	terraform.VerifyWasCalled(Once()).RunCommandWithVersion(matchers.AnyPtrToLoggingSimpleLogger(), AnyString(), AnyStringSlice(), matchers2.AnyMapOfStringToString(), matchers2.AnyPtrToGoVersionVersion(), AnyString())
+}
+
+type testOutputWriter struct {
+	lines []string
+}
+
+func (w *testOutputWriter) WriteLine(_ models.ProjectCommandContext, line string) {
+	w.lines = append(w.lines, line)
+}
+
 type remotePlanMock struct {
 	// LinesToSend will be sent on the channel.
 	LinesToSend string
@@ -7,5 +7,10 @@ import (
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_pull_approved_checker.go PullApprovedChecker
 
 type PullApprovedChecker interface {
-	PullIsApproved(baseRepo models.Repo, pull models.PullRequest) (bool, error)
+	// GetApprovals returns the approvals of pull. Each approval is associated
+	// with the commit that was approved if the VCS host supports it.
+	GetApprovals(baseRepo models.Repo, pull models.PullRequest) ([]models.Approval, error)
+	// UserIsTeamMember returns true if user is a member of team. It's used to
+	// check whether approvers belong to teams that are required to approve.
+	UserIsTeamMember(baseRepo models.Repo, user models.User, team string) (bool, error)
 }
@@ -48,6 +48,38 @@ type StatusUpdater interface {
 	UpdateProject(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus, url string) error
 }
 
+// OutputWriter receives the output of terraform commands line by line while
+// they're running so it can be streamed to the UI.
+type OutputWriter interface {
+	// WriteLine writes a line of output for the project in ctx.
+	WriteLine(ctx models.ProjectCommandContext, line string)
+}
+
+// runTerraform runs terraform with args. If outputWriter is set, the command is
+// run asynchronously and each line of output is written to outputWriter as
+// it's output. Otherwise it's equivalent to
+// TerraformExec.RunCommandWithVersion.
+func runTerraform(ctx models.ProjectCommandContext, tfExec TerraformExec, asyncTFExec AsyncTFExec, outputWriter OutputWriter, path string, args []string, envs map[string]string, v *version.Version) (string, error) {
+	if outputWriter == nil || asyncTFExec == nil {
+		return tfExec.RunCommandWithVersion(ctx.Log, path, args, envs, v, ctx.Workspace)
+	}
+
+	_, outCh := asyncTFExec.RunCommandAsync(ctx.Log, path, args, envs, v, ctx.Workspace)
+	var output strings.Builder
+	var err error
+	for line := range outCh {
+		if line.Err != nil {
+			err = line.Err
+			break
+		}
+		outputWriter.WriteLine(ctx, line.Line)
+		// Add back the newline so the output is the same as when running
+		// synchronously.
+		output.WriteString(line.Line + "\n")
+	}
+	return output.String(), err
+}
+
 // MustConstraint returns a constraint. It panics on error.
 func MustConstraint(constraint string) version.Constraints {
 	c, err := version.NewConstraint(constraint)
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+)
+
+const (
+	// StickyCommentsPull keeps one comment per pull request.
+	StickyCommentsPull = "pull"
+	// StickyCommentsCommand keeps one comment per pull request for each
+	// command, ex. one for plan and one for apply.
+	StickyCommentsCommand = "command"
+
+	// stickyCommentHistoryLen is the number of runs listed in the history.
+	stickyCommentHistoryLen = 5
+	// stickyCommentMaxLength is the maximum length of a sticky comment. It's
+	// the smallest maximum of the VCS hosts so that sticky comments are never
+	// split or truncated by the VCS clients.
+	stickyCommentMaxLength = 32768
+
+	// The markers are markdown link reference definitions which all the VCS
+	// hosts hide, even those that don't support HTML comments.
+	stickyCommentMarkerFmt    = common.StickyCommentMarkerPrefix + "%s)"
+	stickyCommentHistoryStart = "[//]: # (atlantis-sticky-history:"
+)
+
+// StickyRun is a run of a command that's recorded in a sticky comment's
+// history.
+type StickyRun struct {
+	Command string    `json:"command"`
+	User    string    `json:"user"`
+	Time    time.Time `json:"time"`
+	// Status is one of "success", "failed" or "error".
+	Status string `json:"status"`
+}
+
+// StickyCommenter keeps a single comment per pull request up to date instead
+// of creating a new comment for each command. It finds its comment by a hidden
+// marker and replaces it with the latest result and a short history of runs.
+type StickyCommenter struct {
+	VCSClient vcs.Client
+	// Mode is StickyCommentsPull or StickyCommentsCommand.
+	Mode string
+}
+
+// Comment replaces the pull request's sticky comment with comment, the
+// rendered result of run. If there isn't a sticky comment yet or it can't be
+// updated, a new one is created.
+func (s *StickyCommenter) Comment(repo models.Repo, pullNum int, run StickyRun, comment string) error {
+	marker := s.marker(run)
+	existing, err := s.VCSClient.FindComment(repo, pullNum, marker)
+	if err != nil {
+		return errors.Wrap(err, "finding sticky comment")
+	}
+
+	history := []StickyRun{run}
+	if existing != nil {
+		history = append(history, parseStickyHistory(existing.Body)...)
+	}
+	if len(history) > stickyCommentHistoryLen {
+		history = history[:stickyCommentHistoryLen]
+	}
+	body, err := renderStickyComment(marker, history, comment)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		updateErr := s.VCSClient.UpdateComment(repo, pullNum, *existing, body)
+		if updateErr == nil {
+			return nil
+		}
+		// The comment may have been deleted since we found it, or the VCS
+		// host may refuse the edit, ex. because the comment is locked or too
+		// long. Create a new comment which will be found from now on since
+		// it's more recent.
+		if err := s.VCSClient.CreateComment(repo, pullNum, body); err != nil {
+			return errors.Wrapf(err, "creating sticky comment after failing to update comment %d: %s", existing.ID, updateErr)
+		}
+		return nil
+	}
+	return s.VCSClient.CreateComment(repo, pullNum, body)
+}
+
+// marker returns the marker that identifies the sticky comment for run.
+func (s *StickyCommenter) marker(run StickyRun) string {
+	key := StickyCommentsPull
+	if s.Mode == StickyCommentsCommand {
+		key = run.Command
+		// Autoplans share the plan comment.
+		if key == "autoplan" {
+			key = models.PlanCommand.String()
+		}
+	}
+	return fmt.Sprintf(stickyCommentMarkerFmt, key)
+}
+
+// renderStickyComment returns the body of a sticky comment. The history is
+// stored in a hidden marker so it can be read back on the next run and is
+// also listed below the result. The result is truncated so that the body fits
+// in stickyCommentMaxLength.
+func renderStickyComment(marker string, history []StickyRun, result string) (string, error) {
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return "", errors.Wrap(err, "encoding sticky comment history")
+	}
+	header := fmt.Sprintf("%s\n%s%s)\n\n", marker, stickyCommentHistoryStart, base64.RawURLEncoding.EncodeToString(historyJSON))
+
+	var footer strings.Builder
+	footer.WriteString("\n\n---\n**Run History**\n")
+	for _, r := range history {
+		fmt.Fprintf(&footer, "* `%s` by %s at %s: %s\n", r.Command, r.User, r.Time.UTC().Format("2006-01-02 15:04 MST"), r.Status)
+	}
+
+	result = common.TruncateComment(result, stickyCommentMaxLength-len(header)-footer.Len())
+	return header + result + footer.String(), nil
+}
+
+// parseStickyHistory returns the history stored in body. Malformed histories
+// are ignored since they'll be replaced by the next update.
+func parseStickyHistory(body string) []StickyRun {
+	start := strings.Index(body, stickyCommentHistoryStart)
+	if start == -1 {
+		return nil
+	}
+	encoded := body[start+len(stickyCommentHistoryStart):]
+	end := strings.Index(encoded, ")")
+	if end == -1 {
+		return nil
+	}
+	historyJSON, err := base64.RawURLEncoding.DecodeString(encoded[:end])
+	if err != nil {
+		return nil
+	}
+	var history []StickyRun
+	if err := json.Unmarshal(historyJSON, &history); err != nil {
+		return nil
+	}
+	return history
+}
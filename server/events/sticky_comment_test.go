@@ -0,0 +1,117 @@
+package events_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/vcs/mocks/matchers"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var stickyRunTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestStickyCommenter_CreatesComment(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	commenter := events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsPull}
+
+	run := events.StickyRun{Command: "plan", User: "lkysow", Time: stickyRunTime, Status: "success"}
+	Ok(t, commenter.Comment(repo, 1, run, "Ran Plan"))
+
+	vcsClient.VerifyWasCalledOnce().FindComment(repo, 1, "[//]: # (atlantis-sticky-comment:pull)")
+	_, _, body := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.HasPrefix(body, "[//]: # (atlantis-sticky-comment:pull)\n[//]: # (atlantis-sticky-history:"), "unexpected body %q", body)
+	Assert(t, strings.Contains(body, "\n\nRan Plan\n\n---\n**Run History**\n* `plan` by lkysow at 2020-01-02 03:04 UTC: success\n"), "unexpected body %q", body)
+}
+
+// Updates should keep the most recent runs in the history.
+func TestStickyCommenter_UpdatesComment(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	commenter := events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsPull}
+
+	// Build up a full history by feeding each body back into the next run.
+	var existing *models.PullComment
+	for i := 0; i < 6; i++ {
+		When(vcsClient.FindComment(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn(existing, nil)
+		run := events.StickyRun{Command: "plan", User: fmt.Sprintf("user%d", i), Time: stickyRunTime, Status: "success"}
+		Ok(t, commenter.Comment(repo, 1, run, fmt.Sprintf("result %d", i)))
+		if existing == nil {
+			_, _, body := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+			existing = &models.PullComment{ID: 5, Body: body}
+			continue
+		}
+		_, _, comment, body := vcsClient.VerifyWasCalled(Times(i)).UpdateComment(matchers.AnyModelsRepo(), AnyInt(), matchers.AnyModelsPullComment(), AnyString()).GetCapturedArguments()
+		Equals(t, int64(5), comment.ID)
+		existing = &models.PullComment{ID: 5, Body: body}
+	}
+
+	Assert(t, strings.Contains(existing.Body, "\n\nresult 5\n\n"), "unexpected body %q", existing.Body)
+	Assert(t, !strings.Contains(existing.Body, "result 4"), "unexpected body %q", existing.Body)
+	Assert(t, strings.HasSuffix(existing.Body, "**Run History**\n"+
+		"* `plan` by user5 at 2020-01-02 03:04 UTC: success\n"+
+		"* `plan` by user4 at 2020-01-02 03:04 UTC: success\n"+
+		"* `plan` by user3 at 2020-01-02 03:04 UTC: success\n"+
+		"* `plan` by user2 at 2020-01-02 03:04 UTC: success\n"+
+		"* `plan` by user1 at 2020-01-02 03:04 UTC: success\n"), "unexpected body %q", existing.Body)
+}
+
+// If the comment can't be updated, ex. because someone else wrote it, a new
+// comment should be created.
+func TestStickyCommenter_UpdateFails(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	commenter := events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsPull}
+	existing := &models.PullComment{ID: 5, Body: "[//]: # (atlantis-sticky-comment:pull)"}
+	When(vcsClient.FindComment(matchers.AnyModelsRepo(), AnyInt(), AnyString())).ThenReturn(existing, nil)
+	When(vcsClient.UpdateComment(matchers.AnyModelsRepo(), AnyInt(), matchers.AnyModelsPullComment(), AnyString())).ThenReturn(errors.New("forbidden"))
+
+	run := events.StickyRun{Command: "apply", User: "lkysow", Time: stickyRunTime, Status: "failed"}
+	Ok(t, commenter.Comment(repo, 1, run, "Ran Apply"))
+
+	_, _, body := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Assert(t, strings.HasSuffix(body, "* `apply` by lkysow at 2020-01-02 03:04 UTC: failed\n"), "unexpected body %q", body)
+}
+
+// In command mode, each command should have its own comment and autoplans
+// should share the plan comment.
+func TestStickyCommenter_CommandMode(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	repo := models.Repo{FullName: "owner/repo"}
+	commenter := events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsCommand}
+
+	for cmd, marker := range map[string]string{
+		"autoplan": "[//]: # (atlantis-sticky-comment:plan)",
+		"plan":     "[//]: # (atlantis-sticky-comment:plan)",
+		"apply":    "[//]: # (atlantis-sticky-comment:apply)",
+	} {
+		run := events.StickyRun{Command: cmd, User: "lkysow", Time: stickyRunTime, Status: "success"}
+		Ok(t, commenter.Comment(repo, 1, run, "result"))
+		vcsClient.VerifyWasCalled(AtLeast(1)).FindComment(repo, 1, marker)
+	}
+}
+
+// Long results should be truncated so that the comment is never split.
+func TestStickyCommenter_TruncatesResult(t *testing.T) {
+	RegisterMockTestingT(t)
+	vcsClient := vcsmocks.NewMockClient()
+	commenter := events.StickyCommenter{VCSClient: vcsClient, Mode: events.StickyCommentsPull}
+
+	run := events.StickyRun{Command: "plan", User: "lkysow", Time: stickyRunTime, Status: "success"}
+	Ok(t, commenter.Comment(models.Repo{}, 1, run, strings.Repeat("a", 40000)))
+
+	_, _, body := vcsClient.VerifyWasCalledOnce().CreateComment(matchers.AnyModelsRepo(), AnyInt(), AnyString()).GetCapturedArguments()
+	Equals(t, 32768, len(body))
+	Assert(t, strings.Contains(body, "Truncated.\n\n---\n**Run History**\n"), "unexpected body %q", body)
+}
@@ -52,6 +52,10 @@ type DefaultClient struct {
 	defaultVersion          *version.Version
 	terraformPluginCacheDir string
 	binDir                  string
+	// localVersion and localPath are the version and path of the terraform
+	// binary in the $PATH, if there is one.
+	localVersion *version.Version
+	localPath    string
 	// overrideTF can be used to override the terraform binary during testing
 	// with another binary, ex. echo.
 	overrideTF string
@@ -175,6 +179,8 @@ func NewClient(
 		defaultVersion:          finalDefaultVersion,
 		terraformPluginCacheDir: cacheDir,
 		binDir:                  binDir,
+		localVersion:            localVersion,
+		localPath:               localPath,
 		downloader:              tfDownloader,
 		downloadBaseURL:         tfDownloadURL,
 		versionsLock:            &versionsLock,
@@ -210,6 +216,31 @@ func (c *DefaultClient) EnsureVersion(log *logging.SimpleLogger, v *version.Vers
 	return nil
 }
 
+// HasVersion returns an error if terraform version v isn't on disk. If v is
+// nil, the default version is checked. Unlike EnsureVersion it never
+// downloads terraform, so it's safe to call from health checks.
+func (c *DefaultClient) HasVersion(v *version.Version) error {
+	if v == nil {
+		v = c.defaultVersion
+	}
+	if v == nil {
+		return errors.New("no default terraform version")
+	}
+	if c.localVersion != nil && c.localVersion.Equal(v) {
+		if _, err := os.Stat(c.localPath); err == nil {
+			return nil
+		}
+	}
+	binFile := "terraform" + v.String()
+	if _, err := exec.LookPath(binFile); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(c.binDir, binFile)); err == nil {
+		return nil
+	}
+	return fmt.Errorf("terraform %s not found in $PATH or %s", v, c.binDir)
+}
+
 // See Client.RunCommandWithVersion.
 func (c *DefaultClient) RunCommandWithVersion(log *logging.SimpleLogger, path string, args []string, customEnvVars map[string]string, v *version.Version, workspace string) (string, error) {
 	tfCmd, cmd, err := c.prepCmd(log, v, workspace, path, args)
@@ -277,3 +277,22 @@ func tempSetEnv(t *testing.T, key string, value string) func() {
 	Ok(t, os.Setenv(key, value))
 	return func() { os.Setenv(key, orig) }
 }
+
+// Test that HasVersion finds versions in the bin dir and never downloads.
+func TestHasVersion(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+
+	mockDownloader := mocks.NewMockDownloader()
+	c, err := terraform.NewClient(nil, tmp, "", "", "0.11.10", cmd.DefaultTFVersionFlag, cmd.DefaultTFDownloadURL, mockDownloader)
+	Ok(t, err)
+
+	v, err := version.NewVersion("99.99.99")
+	Ok(t, err)
+	ErrContains(t, "terraform 99.99.99 not found", c.HasVersion(v))
+
+	Ok(t, ioutil.WriteFile(filepath.Join(tmp, "bin", "terraform99.99.99"), []byte("#!/bin/sh"), 0755))
+	Ok(t, c.HasVersion(v))
+	mockDownloader.VerifyWasCalled(Never()).GetFile(EqString(filepath.Join(tmp, "bin", "terraform99.99.99")), AnyString())
+}
@@ -85,6 +85,11 @@ func (g *AzureDevopsClient) GetModifiedFiles(repo models.Repo, pull models.PullR
 	return files, nil
 }
 
+// azureDevopsMaxCommentLength is the maximum number of chars allowed in a
+// single comment. This length was copied from the Github client - haven't
+// found documentation or tested limit in Azure DevOps.
+const azureDevopsMaxCommentLength = 65536
+
 // CreateComment creates a comment on a pull request.
 //
 // If comment length is greater than the max comment length we split into
@@ -95,12 +100,7 @@ func (g *AzureDevopsClient) CreateComment(repo models.Repo, pullNum int, comment
 	sepStart := "Continued from previous comment.\n<details><summary>Show Output</summary>\n\n" +
 		"```diff\n"
 
-	// maxCommentLength is the maximum number of chars allowed in a single comment
-	// This length was copied from the Github client - haven't found documentation
-	// or tested limit in Azure DevOps.
-	const maxCommentLength = 65536
-
-	comments := common.SplitComment(comment, maxCommentLength, sepEnd, sepStart)
+	comments := common.SplitComment(comment, azureDevopsMaxCommentLength, sepEnd, sepStart)
 	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
 
 	for _, c := range comments {
@@ -124,13 +124,138 @@ func (g *AzureDevopsClient) CreateComment(repo models.Repo, pullNum int, comment
 	return nil
 }
 
+// azureDevopsPullThreads is the response of the list pull request threads
+// API which the azuredevops library doesn't support.
+type azureDevopsPullThreads struct {
+	Value []azuredevops.GitPullRequestCommentThread `json:"value"`
+}
+
+// FindComment returns the most recent thread on the pull request whose first
+// comment contains marker and was written by the authenticated user, so that
+// others can't plant the marker. Replies aren't searched since Atlantis never
+// replies to threads.
+func (g *AzureDevopsClient) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	userID, err := g.authenticatedUserID(repo)
+	if err != nil {
+		return nil, err
+	}
+	threads, err := g.listThreads(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	var found *models.PullComment
+	for _, thread := range threads {
+		if thread.Comments[0].GetAuthor().GetID() != userID {
+			continue
+		}
+		content := thread.Comments[0].GetContent()
+		if strings.Contains(content, marker) {
+			found = &models.PullComment{ID: int64(thread.GetID()), Body: content}
+		}
+	}
+	return found, nil
+}
+
+// UpdateComment replaces the content of the first comment in the thread
+// comment. Comment IDs start at 1 in each thread.
+func (g *AzureDevopsClient) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
+	commentURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads/%d/comments/1?api-version=5.1",
+		owner, project, repoName, pullNum, comment.ID)
+	content := common.TruncateComment(body, azureDevopsMaxCommentLength)
+	req, err := g.Client.NewRequest("PATCH", commentURL, azuredevops.Comment{Content: &content})
+	if err != nil {
+		return errors.Wrap(err, "constructing request")
+	}
+	_, err = g.Client.Execute(g.ctx, req, nil)
+	return errors.Wrap(err, "updating pull request comment")
+}
+
+// azureDevopsConnectionData is the response of the connection data API
+// which the azuredevops library doesn't support.
+type azureDevopsConnectionData struct {
+	AuthenticatedUser struct {
+		ID string `json:"id"`
+	} `json:"authenticatedUser"`
+}
+
+// HidePrevPlanComments closes the threads of the plan comments Atlantis
+// previously left on the pull request. Azure DevOps collapses closed threads
+// but keeps their comments.
 func (g *AzureDevopsClient) HidePrevPlanComments(repo models.Repo, pullNum int) error {
+	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
+	userID, err := g.authenticatedUserID(repo)
+	if err != nil {
+		return err
+	}
+	threads, err := g.listThreads(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	for _, thread := range threads {
+		comment := thread.Comments[0]
+		if thread.GetStatus() == "closed" || comment.GetAuthor().GetID() != userID || !common.IsPlanComment(comment.GetContent()) {
+			continue
+		}
+		threadURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads/%d?api-version=5.1",
+			owner, project, repoName, pullNum, thread.GetID())
+		req, err := g.Client.NewRequest("PATCH", threadURL, azuredevops.GitPullRequestCommentThread{Status: azuredevops.String("closed")})
+		if err != nil {
+			return errors.Wrap(err, "constructing request")
+		}
+		if _, err := g.Client.Execute(g.ctx, req, nil); err != nil {
+			return errors.Wrapf(err, "closing thread %d", thread.GetID())
+		}
+	}
 	return nil
 }
 
-// PullIsApproved returns true if the merge request was approved by another reviewer.
+// authenticatedUserID returns the ID of the user Atlantis is authenticated
+// as in repo's organization. Comments only identify their author by ID.
+func (g *AzureDevopsClient) authenticatedUserID(repo models.Repo) (string, error) {
+	owner, _, _ := SplitAzureDevopsRepoFullName(repo.FullName)
+	req, err := g.Client.NewRequest("GET", fmt.Sprintf("%s/_apis/connectionData", owner), nil)
+	if err != nil {
+		return "", errors.Wrap(err, "constructing request")
+	}
+	var connection azureDevopsConnectionData
+	if _, err := g.Client.Execute(g.ctx, req, &connection); err != nil {
+		return "", errors.Wrap(err, "getting authenticated user")
+	}
+	if connection.AuthenticatedUser.ID == "" {
+		return "", errors.New("authenticated user has no ID")
+	}
+	return connection.AuthenticatedUser.ID, nil
+}
+
+// listThreads returns the pull request's threads that haven't been deleted
+// and have at least one comment, oldest first.
+func (g *AzureDevopsClient) listThreads(repo models.Repo, pullNum int) ([]azuredevops.GitPullRequestCommentThread, error) {
+	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
+	threadsURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d/threads?api-version=5.1",
+		owner, project, repoName, pullNum)
+	req, err := g.Client.NewRequest("GET", threadsURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	var resp azureDevopsPullThreads
+	if _, err := g.Client.Execute(g.ctx, req, &resp); err != nil {
+		return nil, errors.Wrap(err, "listing pull request threads")
+	}
+	var threads []azuredevops.GitPullRequestCommentThread
+	for _, thread := range resp.Value {
+		if !thread.GetIsDeleted() && len(thread.Comments) > 0 {
+			threads = append(threads, thread)
+		}
+	}
+	return threads, nil
+}
+
+// GetApprovals returns the approvals of the pull request by reviewers other
+// than the author. Azure DevOps doesn't associate votes with commits but
+// branch policies can reset votes when new commits are pushed.
 // https://docs.microsoft.com/en-us/azure/devops/repos/git/branch-policies?view=azure-devops#require-a-minimum-number-of-reviewers
-func (g *AzureDevopsClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+func (g *AzureDevopsClient) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
 	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
 
 	opts := azuredevops.PullRequestGetOptions{
@@ -138,9 +263,10 @@ func (g *AzureDevopsClient) PullIsApproved(repo models.Repo, pull models.PullReq
 	}
 	adPull, _, err := g.Client.PullRequests.GetWithRepo(g.ctx, owner, project, repoName, pull.Num, &opts)
 	if err != nil {
-		return false, errors.Wrap(err, "getting pull request")
+		return nil, errors.Wrap(err, "getting pull request")
 	}
 
+	var approvals []models.Approval
 	for _, review := range adPull.Reviewers {
 		if review == nil {
 			continue
@@ -151,11 +277,12 @@ func (g *AzureDevopsClient) PullIsApproved(repo models.Repo, pull models.PullReq
 		}
 
 		if review.GetVote() == azuredevops.VoteApproved || review.GetVote() == azuredevops.VoteApprovedWithSuggestions {
-			return true, nil
+			approvals = append(approvals, models.Approval{
+				User: models.User{Username: review.IdentityRef.GetUniqueName()},
+			})
 		}
 	}
-
-	return false, nil
+	return approvals, nil
 }
 
 // PullIsMergeable returns true if the merge request can be merged.
@@ -217,6 +344,58 @@ func (g *AzureDevopsClient) GetPullRequest(repo models.Repo, num int) (*azuredev
 	return pull, err
 }
 
+// azureDevopsPullStatuses is the response of the list pull request statuses
+// API which the azuredevops library doesn't support.
+type azureDevopsPullStatuses struct {
+	Value []azuredevops.GitPullRequestStatus `json:"value"`
+}
+
+// GetCommitChecks returns the statuses of the pull request. Azure DevOps keeps
+// every status that was posted so only the latest status for each context is
+// returned.
+func (g *AzureDevopsClient) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	owner, project, repoName := SplitAzureDevopsRepoFullName(repo.FullName)
+	statusesURL := fmt.Sprintf("%s/%s/_apis/git/repositories/%s/pullrequests/%d/statuses?api-version=5.1-preview.1",
+		owner, project, repoName, pull.Num)
+	req, err := g.Client.NewRequest("GET", statusesURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	var statuses azureDevopsPullStatuses
+	if _, err := g.Client.Execute(g.ctx, req, &statuses); err != nil {
+		return nil, errors.Wrap(err, "listing pull request statuses")
+	}
+
+	var names []string
+	latest := make(map[string]*azuredevops.GitPullRequestStatus)
+	for i := range statuses.Value {
+		status := &statuses.Value[i]
+		name := status.GetContext().GetName()
+		prev, ok := latest[name]
+		if !ok {
+			names = append(names, name)
+		}
+		if !ok || status.GetID() > prev.GetID() {
+			latest[name] = status
+		}
+	}
+	var checks []models.CommitCheck
+	for _, name := range names {
+		check := models.CommitCheck{Name: name, State: models.PendingCommitStatus}
+		switch latest[name].GetState() {
+		case azuredevops.GitSucceeded.String():
+			check.State = models.SuccessCommitStatus
+		case azuredevops.GitNotApplicable.String():
+			check.State = models.SuccessCommitStatus
+			check.Skipped = true
+		case azuredevops.GitFailed.String(), azuredevops.GitError.String():
+			check.State = models.FailedCommitStatus
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
 // UpdateStatus updates the build status of a commit.
 func (g *AzureDevopsClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	adState := azuredevops.GitError.String()
@@ -284,11 +463,12 @@ func (g *AzureDevopsClient) UpdateStatus(repo models.Repo, pull models.PullReque
 	return err
 }
 
-// MergePull merges the merge request using the default no fast-forward strategy
-// If the user has set a branch policy that disallows no fast-forward, the merge will fail
+// MergePull merges the merge request using opts.MergeMethod, defaulting to the
+// no fast-forward strategy.
+// If the user has set a branch policy that disallows the strategy, the merge will fail
 // until we handle branch policies
 // https://docs.microsoft.com/en-us/azure/devops/repos/git/branch-policies?view=azure-devops
-func (g *AzureDevopsClient) MergePull(pull models.PullRequest) error {
+func (g *AzureDevopsClient) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
 	descriptor := "Atlantis Terraform Pull Request Automation"
 	i := "atlantis"
 	imageURL := "https://github.com/runatlantis/atlantis/raw/master/runatlantis.io/.vuepress/public/hero.png"
@@ -299,15 +479,21 @@ func (g *AzureDevopsClient) MergePull(pull models.PullRequest) error {
 	}
 	// Set default pull request completion options
 	mcm := azuredevops.NoFastForward.String()
+	switch opts.MergeMethod {
+	case models.SquashMergeMethod:
+		mcm = azuredevops.Squash.String()
+	case models.RebaseMergeMethod:
+		mcm = azuredevops.Rebase.String()
+	}
 	twi := new(bool)
 	*twi = true
 	completionOpts := azuredevops.GitPullRequestCompletionOptions{
 		BypassPolicy:            new(bool),
 		BypassReason:            azuredevops.String(""),
-		DeleteSourceBranch:      new(bool),
-		MergeCommitMessage:      azuredevops.String(common.AutomergeCommitMsg),
+		DeleteSourceBranch:      azuredevops.Bool(opts.DeleteSourceBranchOnMerge),
+		MergeCommitMessage:      azuredevops.String(common.MergeCommitMsg(opts)),
 		MergeStrategy:           &mcm,
-		SquashMerge:             new(bool),
+		SquashMerge:             azuredevops.Bool(opts.MergeMethod == models.SquashMergeMethod),
 		TransitionWorkItems:     twi,
 		TriggeredByAutoComplete: new(bool),
 	}
@@ -342,6 +528,70 @@ func (g *AzureDevopsClient) MarkdownPullLink(pull models.PullRequest) (string, e
 	return fmt.Sprintf("!%d", pull.Num), nil
 }
 
+// azureDevopsTeamMembersPageSize is how many team members we request at a time.
+const azureDevopsTeamMembersPageSize = 100
+
+// azureDevopsTeamMembers is the response from the team members API.
+type azureDevopsTeamMembers struct {
+	Value []struct {
+		Identity struct {
+			UniqueName string `json:"uniqueName"`
+		} `json:"identity"`
+	} `json:"value"`
+}
+
+// UserIsTeamMember returns true if user is a member of team. team is either
+// the name of a team in repo's project or {project}/{team}.
+func (g *AzureDevopsClient) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	owner, project, _ := SplitAzureDevopsRepoFullName(repo.FullName)
+	if i := strings.Index(team, "/"); i >= 0 {
+		project, team = team[:i], team[i+1:]
+	}
+	// We'll only loop 1000 times as a safety measure.
+	for page := 0; page < 1000; page++ {
+		membersURL := fmt.Sprintf("%s/_apis/projects/%s/teams/%s/members?api-version=5.1&$top=%d&$skip=%d",
+			owner, url.PathEscape(project), url.PathEscape(team), azureDevopsTeamMembersPageSize, page*azureDevopsTeamMembersPageSize)
+		req, err := g.Client.NewRequest("GET", membersURL, nil)
+		if err != nil {
+			return false, errors.Wrap(err, "constructing request")
+		}
+		var members azureDevopsTeamMembers
+		if _, err := g.Client.Execute(g.ctx, req, &members); err != nil {
+			return false, errors.Wrapf(err, "listing members of team %s/%s", project, team)
+		}
+		for _, m := range members.Value {
+			if strings.EqualFold(m.Identity.UniqueName, user.Username) {
+				return true, nil
+			}
+		}
+		if len(members.Value) < azureDevopsTeamMembersPageSize {
+			break
+		}
+	}
+	return false, nil
+}
+
+// azureDevopsProfileURL returns the profile of the authenticated user. It's
+// one of the few APIs that doesn't require an organization.
+const azureDevopsProfileURL = "https://app.vssps.visualstudio.com/_apis/profile/profiles/me?api-version=5.1"
+
+// Ping makes a lightweight authenticated API call to verify that Azure DevOps
+// is reachable and our credentials are valid.
+func (g *AzureDevopsClient) Ping() error {
+	pingURL := azureDevopsProfileURL
+	if g.Client.BaseURL.Host != "dev.azure.com" {
+		// Azure DevOps Server doesn't have the profile API but every
+		// collection serves its connection data.
+		pingURL = "_apis/connectionData"
+	}
+	req, err := g.Client.NewRequest("GET", pingURL, nil)
+	if err != nil {
+		return errors.Wrap(err, "constructing request")
+	}
+	_, err = g.Client.Execute(g.ctx, req, nil)
+	return err
+}
+
 // SplitAzureDevopsRepoFullName splits a repo full name up into its owner,
 // repo and project name segments. If the repoFullName is malformed, may
 // return empty strings for owner, repo, or project.  Azure DevOps uses
@@ -108,7 +108,7 @@ func TestAzureDevopsClient_MergePull(t *testing.T) {
 					Owner:    "owner",
 					Name:     "repo",
 				},
-			})
+			}, models.PullRequestOptions{})
 			if c.expErr == "" {
 				Ok(t, err)
 			} else {
@@ -360,7 +360,7 @@ func TestAzureDevopsClient_PullIsMergeable(t *testing.T) {
 	}
 }
 
-func TestAzureDevopsClient_PullIsApproved(t *testing.T) {
+func TestAzureDevopsClient_GetApprovals(t *testing.T) {
 	cases := []struct {
 		testName           string
 		reviewerUniqueName string
@@ -435,7 +435,7 @@ func TestAzureDevopsClient_PullIsApproved(t *testing.T) {
 
 			defer disableSSLVerification()()
 
-			actApproved, err := client.PullIsApproved(models.Repo{
+			approvals, err := client.GetApprovals(models.Repo{
 				FullName:          "owner/project/repo",
 				Owner:             "owner",
 				Name:              "repo",
@@ -449,11 +449,59 @@ func TestAzureDevopsClient_PullIsApproved(t *testing.T) {
 				Num: 1,
 			})
 			Ok(t, err)
-			Equals(t, c.expApproved, actApproved)
+			if c.expApproved {
+				Equals(t, []models.Approval{{User: models.User{Username: c.reviewerUniqueName}}}, approvals)
+			} else {
+				Equals(t, 0, len(approvals))
+			}
 		})
 	}
 }
 
+// Test that only the latest status of each context is returned.
+func TestAzureDevopsClient_GetCommitChecks(t *testing.T) {
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/owner/project/_apis/git/repositories/repo/pullrequests/1/statuses?api-version=5.1-preview.1":
+				w.Write([]byte(`{"count": 5, "value": [
+					{"id": 1, "state": "failed", "context": {"genre": "continuous-integration", "name": "build"}},
+					{"id": 2, "state": "pending", "context": {"genre": "Atlantis Bot", "name": "atlantis/plan"}},
+					{"id": 3, "state": "succeeded", "context": {"genre": "continuous-integration", "name": "build"}},
+					{"id": 4, "state": "error", "context": {"genre": "continuous-integration", "name": "lint"}},
+					{"id": 5, "state": "notApplicable", "context": {"genre": "continuous-integration", "name": "docs"}}
+				]}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "token")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	checks, err := client.GetCommitChecks(models.Repo{
+		FullName: "owner/project/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.AzureDevops,
+			Hostname: "dev.azure.com",
+		},
+	}, models.PullRequest{
+		Num: 1,
+	})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "build", State: models.SuccessCommitStatus},
+		{Name: "atlantis/plan", State: models.PendingCommitStatus},
+		{Name: "lint", State: models.FailedCommitStatus},
+		{Name: "docs", State: models.SuccessCommitStatus, Skipped: true},
+	}, checks)
+}
+
 func TestAzureDevopsClient_GetPullRequest(t *testing.T) {
 	// Use a real Azure DevOps json response and edit the mergeable_state field.
 	jsBytes, err := ioutil.ReadFile("fixtures/azuredevops-pr.json")
@@ -525,3 +573,79 @@ var adMergeSuccess = `{
 					"triggeredByAutoComplete":false
 	}
 }`
+
+func TestAzureDevopsClient_FindAndUpdateComment(t *testing.T) {
+	var updated string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /owner/_apis/connectionData":
+				w.Write([]byte(`{"authenticatedUser": {"id": "atlantis-id"}}`)) // nolint: errcheck
+			case "GET /owner/project/_apis/git/repositories/repo/pullrequests/1/threads?api-version=5.1":
+				w.Write([]byte(`{"count": 5, "value": [
+					{"id": 1, "comments": [{"id": 1, "content": "marker", "author": {"id": "atlantis-id"}}]},
+					{"id": 2, "comments": [{"id": 1, "content": "with marker", "author": {"id": "atlantis-id"}}]},
+					{"id": 3, "isDeleted": true, "comments": [{"id": 1, "content": "deleted marker", "author": {"id": "atlantis-id"}}]},
+					{"id": 4, "comments": [{"id": 1, "content": "other", "author": {"id": "atlantis-id"}}, {"id": 2, "content": "reply marker", "author": {"id": "atlantis-id"}}]},
+					{"id": 5, "comments": [{"id": 1, "content": "marker", "author": {"id": "attacker-id"}}]}
+				]}`)) // nolint: errcheck
+			case "PATCH /owner/project/_apis/git/repositories/repo/pullrequests/1/threads/2/comments/1?api-version=5.1":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				updated = string(body)
+				w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "token")
+	Ok(t, err)
+	defer disableSSLVerification()()
+	repo := models.Repo{FullName: "owner/project/repo", Owner: "owner", Name: "repo"}
+
+	comment, err := client.FindComment(repo, 1, "marker")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 2, Body: "with marker"}, comment)
+
+	Ok(t, client.UpdateComment(repo, 1, *comment, "new body"))
+	Equals(t, `{"content":"new body"}`+"\n", updated)
+}
+
+// Only open threads started by the Atlantis user with a plan should be
+// closed.
+func TestAzureDevopsClient_HidePrevPlanComments(t *testing.T) {
+	var closed []string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /owner/_apis/connectionData":
+				w.Write([]byte(`{"authenticatedUser": {"id": "atlantis-id"}}`)) // nolint: errcheck
+			case "GET /owner/project/_apis/git/repositories/repo/pullrequests/1/threads?api-version=5.1":
+				w.Write([]byte(`{"count": 4, "value": [
+					{"id": 1, "status": "active", "comments": [{"id": 1, "content": "Ran Plan for dir: .", "author": {"id": "atlantis-id"}}]},
+					{"id": 2, "status": "active", "comments": [{"id": 1, "content": "Ran Apply for dir: .", "author": {"id": "atlantis-id"}}]},
+					{"id": 3, "status": "active", "comments": [{"id": 1, "content": "Ran Plan for dir: .", "author": {"id": "someone-id"}}]},
+					{"id": 4, "status": "closed", "comments": [{"id": 1, "content": "Ran Plan for dir: .", "author": {"id": "atlantis-id"}}]}
+				]}`)) // nolint: errcheck
+			case "PATCH /owner/project/_apis/git/repositories/repo/pullrequests/1/threads/1?api-version=5.1":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				closed = append(closed, string(body))
+				w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewAzureDevopsClient(testServerURL.Host, "token")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	Ok(t, client.HidePrevPlanComments(models.Repo{FullName: "owner/project/repo", Owner: "owner", Name: "repo"}, 1))
+	Equals(t, []string{`{"status":"closed"}` + "\n"}, closed)
+}
@@ -7,9 +7,12 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
 	validator "gopkg.in/go-playground/validator.v9"
 )
 
@@ -100,33 +103,148 @@ func (b *Client) CreateComment(repo models.Repo, pullNum int, comment string) er
 	return err
 }
 
+// FindComment returns the most recent comment on the pull request containing
+// marker. Only comments by the authenticated user are considered so that
+// others can't plant the marker.
+func (b *Client) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	userUUID, err := b.currentUserUUID()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := b.listComments(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	var found *models.PullComment
+	for _, c := range comments {
+		if c.User == nil || c.User.UUID == nil || *c.User.UUID != userUUID {
+			continue
+		}
+		if strings.Contains(*c.Content.Raw, marker) {
+			found = &models.PullComment{ID: *c.ID, Body: *c.Content.Raw}
+		}
+	}
+	return found, nil
+}
+
+// UpdateComment replaces the content of comment.
+func (b *Client) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	bodyBytes, err := json.Marshal(map[string]map[string]string{"content": {
+		"raw": body,
+	}})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/comments/%d", b.BaseURL, repo.FullName, pullNum, comment.ID)
+	_, err = b.makeRequest("PUT", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// HidePrevPlanComments replaces the plan comments Atlantis previously left on
+// the pull request with a short note since Bitbucket can't hide or collapse
+// comments.
 func (b *Client) HidePrevPlanComments(repo models.Repo, pullNum int) error {
+	userUUID, err := b.currentUserUUID()
+	if err != nil {
+		return err
+	}
+	comments, err := b.listComments(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if c.User == nil || c.User.UUID == nil || *c.User.UUID != userUUID || !common.IsPlanComment(*c.Content.Raw) {
+			continue
+		}
+		body := common.OutdatedPlanComment(*c.Content.Raw, false)
+		if err := b.UpdateComment(repo, pullNum, models.PullComment{ID: *c.ID}, body); err != nil {
+			return errors.Wrapf(err, "hiding comment %d", *c.ID)
+		}
+	}
 	return nil
 }
 
-// PullIsApproved returns true if the merge request was approved.
-func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+// currentUserUUID returns the UUID of the authenticated user. Comments only
+// identify their author by UUID.
+func (b *Client) currentUserUUID() (string, error) {
+	resp, err := b.makeRequest("GET", fmt.Sprintf("%s/2.0/user", b.BaseURL), nil)
+	if err != nil {
+		return "", err
+	}
+	var user User
+	if err := json.Unmarshal(resp, &user); err != nil {
+		return "", errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	if err := validator.New().Struct(user); err != nil {
+		return "", errors.Wrapf(err, "API response %q was missing fields", string(resp))
+	}
+	return *user.UUID, nil
+}
+
+// listComments returns the pull request's comments that haven't been
+// deleted, oldest first.
+func (b *Client) listComments(repo models.Repo, pullNum int) ([]PullComment, error) {
+	var comments []PullComment
+	nextPageURL := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/comments?pagelen=100", b.BaseURL, repo.FullName, pullNum)
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", nextPageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page PullComments
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(page); err != nil {
+			return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, v := range page.Values {
+			if !v.Deleted {
+				comments = append(comments, v)
+			}
+		}
+		if page.Next == nil || *page.Next == "" {
+			break
+		}
+		nextPageURL = *page.Next
+	}
+	return comments, nil
+}
+
+// GetApprovals returns the approvals of the pull request by participants
+// other than the author. Bitbucket doesn't associate approvals with commits
+// but it can be configured to reset approvals when new commits are pushed.
+func (b *Client) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
 	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d", b.BaseURL, repo.FullName, pull.Num)
 	resp, err := b.makeRequest("GET", path, nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	var pullResp PullRequest
 	if err := json.Unmarshal(resp, &pullResp); err != nil {
-		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
 	}
 	if err := validator.New().Struct(pullResp); err != nil {
-		return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
 	}
 	authorUUID := *pullResp.Author.UUID
+	var approvals []models.Approval
 	for _, participant := range pullResp.Participants {
 		// Bitbucket allows the author to approve their own pull request. This
 		// defeats the purpose of approvals so we don't count that approval.
 		if *participant.Approved && *participant.User.UUID != authorUUID {
-			return true, nil
+			username := *participant.User.UUID
+			if participant.User.Nickname != nil {
+				username = *participant.User.Nickname
+			}
+			approvals = append(approvals, models.Approval{
+				User: models.User{Username: username},
+			})
 		}
 	}
-	return false, nil
+	return approvals, nil
 }
 
 // PullIsMergeable returns true if the merge request has no conflicts and can be merged.
@@ -160,6 +278,43 @@ func (b *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (boo
 	return true, nil
 }
 
+// GetCommitChecks returns the build statuses of the pull request's head
+// commit.
+func (b *Client) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	nextPageURL := fmt.Sprintf("%s/2.0/repositories/%s/commit/%s/statuses", b.BaseURL, repo.FullName, pull.HeadCommit)
+	var checks []models.CommitCheck
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", nextPageURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		var statuses CommitStatuses
+		if err := json.Unmarshal(resp, &statuses); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(statuses); err != nil {
+			return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, v := range statuses.Values {
+			state := models.PendingCommitStatus
+			switch *v.State {
+			case "SUCCESSFUL":
+				state = models.SuccessCommitStatus
+			case "FAILED", "STOPPED":
+				state = models.FailedCommitStatus
+			}
+			checks = append(checks, models.CommitCheck{Name: *v.Key, State: state})
+		}
+		if statuses.Next == nil || *statuses.Next == "" {
+			break
+		}
+		nextPageURL = *statuses.Next
+	}
+	return checks, nil
+}
+
 // UpdateStatus updates the status of a commit.
 func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string, url string) error {
 	bbState := "FAILED"
@@ -193,10 +348,28 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	return err
 }
 
-// MergePull merges the pull request.
-func (b *Client) MergePull(pull models.PullRequest) error {
+// MergePull merges the pull request. Bitbucket Cloud can't rebase when
+// merging so the rebase method isn't supported.
+func (b *Client) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
+	body := map[string]interface{}{
+		"type":                "pullrequest",
+		"message":             common.MergeCommitMsg(opts),
+		"close_source_branch": opts.DeleteSourceBranchOnMerge,
+	}
+	switch opts.MergeMethod {
+	case models.MergeMergeMethod:
+		body["merge_strategy"] = "merge_commit"
+	case models.SquashMergeMethod:
+		body["merge_strategy"] = "squash"
+	case models.RebaseMergeMethod:
+		return errors.New("the rebase merge method isn't supported by Bitbucket Cloud")
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
 	path := fmt.Sprintf("%s/2.0/repositories/%s/pullrequests/%d/merge", b.BaseURL, pull.BaseRepo.FullName, pull.Num)
-	_, err := b.makeRequest("POST", path, nil)
+	_, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
 	return err
 }
 
@@ -205,6 +378,40 @@ func (b *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return fmt.Sprintf("#%d", pull.Num), nil
 }
 
+// UserIsTeamMember returns true if user is a member of the group team. team is
+// either the slug of a group in repo's workspace or {workspace}/{group-slug}.
+// Bitbucket Cloud only exposes groups through its 1.0 API.
+func (b *Client) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	owner, slug := repo.Owner, team
+	if i := strings.Index(team, "/"); i >= 0 {
+		owner, slug = team[:i], team[i+1:]
+	}
+	path := fmt.Sprintf("%s/1.0/groups/%s/%s/members", b.BaseURL, url.PathEscape(owner), url.PathEscape(slug))
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return false, err
+	}
+	var members []GroupMember
+	if err := json.Unmarshal(resp, &members); err != nil {
+		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	for _, m := range members {
+		// Comment events identify users by nickname but older accounts may
+		// still be identified by username.
+		if m.Nickname == user.Username || m.Username == user.Username {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Ping makes a lightweight authenticated API call to verify that Bitbucket is
+// reachable and our credentials are valid.
+func (b *Client) Ping() error {
+	_, err := b.makeRequest("GET", fmt.Sprintf("%s/2.0/user", b.BaseURL), nil)
+	return err
+}
+
 // prepRequest adds auth and necessary headers.
 func (b *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, path, body)
@@ -151,31 +151,31 @@ func TestClient_GetModifiedFilesOldNil(t *testing.T) {
 	Equals(t, []string{"parent/child/file1.txt"}, files)
 }
 
-func TestClient_PullIsApproved(t *testing.T) {
+func TestClient_GetApprovals(t *testing.T) {
 	cases := []struct {
 		description string
 		testdata    string
-		exp         bool
+		exp         []models.Approval
 	}{
 		{
 			"no approvers",
 			"pull-unapproved.json",
-			false,
+			nil,
 		},
 		{
 			"approver is the author",
 			"pull-approved-by-author.json",
-			false,
+			nil,
 		},
 		{
 			"single approver",
 			"pull-approved.json",
-			true,
+			[]models.Approval{{User: models.User{Username: "Atlantisbot"}}},
 		},
 		{
 			"two approvers one author",
 			"pull-approved-multiple.json",
-			true,
+			[]models.Approval{{User: models.User{Username: "Atlantisbot"}}, {User: models.User{Username: "Atlantisbot2"}}},
 		},
 	}
 
@@ -202,18 +202,58 @@ func TestClient_PullIsApproved(t *testing.T) {
 
 			repo, err := models.NewRepo(models.BitbucketServer, "owner/repo", "https://bitbucket.org/owner/repo.git", "user", "token")
 			Ok(t, err)
-			approved, err := client.PullIsApproved(repo, models.PullRequest{
+			approvals, err := client.GetApprovals(repo, models.PullRequest{
 				Num:        1,
 				HeadBranch: "branch",
 				Author:     "author",
 				BaseRepo:   repo,
 			})
 			Ok(t, err)
-			Equals(t, c.exp, approved)
+			Equals(t, c.exp, approvals)
 		})
 	}
 }
 
+func TestClient_GetCommitChecks(t *testing.T) {
+	var serverURL string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/2.0/repositories/owner/repo/commit/sha/statuses":
+			resp := fmt.Sprintf(`{"values": [{"key": "build", "state": "SUCCESSFUL"}, {"key": "test", "state": "INPROGRESS"}], "next": "%s/2.0/repositories/owner/repo/commit/sha/statuses?page=2"}`, serverURL)
+			w.Write([]byte(resp)) // nolint: errcheck
+		case "/2.0/repositories/owner/repo/commit/sha/statuses?page=2":
+			w.Write([]byte(`{"values": [{"key": "lint", "state": "STOPPED"}]}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	serverURL = testServer.URL
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+
+	checks, err := client.GetCommitChecks(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.BitbucketCloud,
+			Hostname: "bitbucket.org",
+		},
+	}, models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+	})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "build", State: models.SuccessCommitStatus},
+		{Name: "test", State: models.PendingCommitStatus},
+		{Name: "lint", State: models.FailedCommitStatus},
+	}, checks)
+}
+
 func TestClient_PullIsMergeable(t *testing.T) {
 	cases := map[string]struct {
 		DiffStat     string
@@ -345,6 +385,75 @@ func TestClient_PullIsMergeable(t *testing.T) {
 
 }
 
+func TestClient_MergePull(t *testing.T) {
+	cases := []struct {
+		description string
+		opts        models.PullRequestOptions
+		expBody     string
+		expErr      string
+	}{
+		{
+			description: "defaults",
+			opts:        models.PullRequestOptions{},
+			expBody:     `{"close_source_branch":false,"message":"[Atlantis] Automatically merging after successful apply","type":"pullrequest"}`,
+		},
+		{
+			description: "squash and close source branch",
+			opts: models.PullRequestOptions{
+				MergeMethod:               "squash",
+				DeleteSourceBranchOnMerge: true,
+				CommitMessage:             "Merge #1",
+			},
+			expBody: `{"close_source_branch":true,"merge_strategy":"squash","message":"Merge #1","type":"pullrequest"}`,
+		},
+		{
+			description: "rebase",
+			opts:        models.PullRequestOptions{MergeMethod: "rebase"},
+			expErr:      "the rebase merge method isn't supported by Bitbucket Cloud",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/2.0/repositories/owner/repo/pullrequests/1/merge":
+					Equals(t, "POST", r.Method)
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					Equals(t, c.expBody, string(body))
+					w.Write([]byte("{}")) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+			client.BaseURL = testServer.URL
+
+			err := client.MergePull(models.PullRequest{
+				Num: 1,
+				BaseRepo: models.Repo{
+					FullName: "owner/repo",
+					Owner:    "owner",
+					Name:     "repo",
+					VCSHost: models.VCSHost{
+						Type:     models.BitbucketCloud,
+						Hostname: "bitbucket.org",
+					},
+				},
+			}, c.opts)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+		})
+	}
+}
+
 func TestClient_MarkdownPullLink(t *testing.T) {
 	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
 	pull := models.PullRequest{Num: 1}
@@ -352,3 +461,104 @@ func TestClient_MarkdownPullLink(t *testing.T) {
 	exp := "#1"
 	Equals(t, exp, s)
 }
+
+func TestClient_Ping(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/2.0/user":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "user" || pass != "pass" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"username": "user"}`)) // nolint: errcheck
+			return
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+	Ok(t, client.Ping())
+
+	client = bitbucketcloud.NewClient(http.DefaultClient, "user", "wrong", "runatlantis.io")
+	client.BaseURL = testServer.URL
+	ErrContains(t, "unexpected status code: 401", client.Ping())
+}
+
+func TestClient_FindAndUpdateComment(t *testing.T) {
+	var serverURL, updated string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /2.0/user":
+			w.Write([]byte(`{"uuid": "{atlantis}"}`)) // nolint: errcheck
+		case "GET /2.0/repositories/owner/repo/pullrequests/1/comments?pagelen=100":
+			resp := fmt.Sprintf(`{"values": [{"id": 1, "content": {"raw": "marker"}, "user": {"uuid": "{atlantis}"}}], "next": "%s/2.0/repositories/owner/repo/pullrequests/1/comments?pagelen=100&page=2"}`, serverURL)
+			w.Write([]byte(resp)) // nolint: errcheck
+		case "GET /2.0/repositories/owner/repo/pullrequests/1/comments?pagelen=100&page=2":
+			w.Write([]byte(`{"values": [
+				{"id": 2, "content": {"raw": "with marker"}, "user": {"uuid": "{atlantis}"}},
+				{"id": 3, "content": {"raw": "deleted marker"}, "user": {"uuid": "{atlantis}"}, "deleted": true},
+				{"id": 4, "content": {"raw": "marker"}, "user": {"uuid": "{attacker}"}}
+			]}`)) // nolint: errcheck
+		case "PUT /2.0/repositories/owner/repo/pullrequests/1/comments/2":
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			updated = string(body)
+			w.Write([]byte(`{"id": 2}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	serverURL = testServer.URL
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+
+	comment, err := client.FindComment(repo, 1, "marker")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 2, Body: "with marker"}, comment)
+
+	Ok(t, client.UpdateComment(repo, 1, *comment, "new body"))
+	Equals(t, `{"content":{"raw":"new body"}}`, updated)
+}
+
+// Only plan comments written by the Atlantis user should be replaced.
+func TestClient_HidePrevPlanComments(t *testing.T) {
+	var updated []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /2.0/user":
+			w.Write([]byte(`{"uuid": "{atlantis}"}`)) // nolint: errcheck
+		case "GET /2.0/repositories/owner/repo/pullrequests/1/comments?pagelen=100":
+			w.Write([]byte(`{"values": [
+				{"id": 1, "content": {"raw": "Ran Plan for dir: .\n\noutput"}, "user": {"uuid": "{atlantis}"}},
+				{"id": 2, "content": {"raw": "Ran Apply for dir: ."}, "user": {"uuid": "{atlantis}"}},
+				{"id": 3, "content": {"raw": "Ran Plan for dir: ."}, "user": {"uuid": "{someone}"}},
+				{"id": 4, "content": {"raw": "Ran Plan for dir: ."}, "user": {"uuid": "{atlantis}"}, "deleted": true}
+			]}`)) // nolint: errcheck
+		case "PUT /2.0/repositories/owner/repo/pullrequests/1/comments/1":
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			updated = append(updated, string(body))
+			w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := bitbucketcloud.NewClient(http.DefaultClient, "user", "pass", "runatlantis.io")
+	client.BaseURL = testServer.URL
+
+	Ok(t, client.HidePrevPlanComments(models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}, 1))
+	Equals(t, []string{`{"content":{"raw":"**Outdated**: This plan was superseded by a newer plan.\n\nRan Plan for dir: ."}}`}, updated)
+}
@@ -38,6 +38,29 @@ type DiffStatFile struct {
 	Path *string `json:"path,omitempty" validate:"required"`
 }
 
+type PullComments struct {
+	Values []PullComment `json:"values,omitempty" validate:"required"`
+	Next   *string       `json:"next,omitempty"`
+}
+type PullComment struct {
+	ID      *int64          `json:"id,omitempty" validate:"required"`
+	Content *CommentContent `json:"content,omitempty" validate:"required"`
+	Deleted bool            `json:"deleted,omitempty"`
+	User    *User           `json:"user,omitempty"`
+}
+type User struct {
+	UUID *string `json:"uuid,omitempty" validate:"required"`
+}
+
+type CommitStatuses struct {
+	Values []CommitStatus `json:"values,omitempty" validate:"required"`
+	Next   *string        `json:"next,omitempty"`
+}
+type CommitStatus struct {
+	Key   *string `json:"key,omitempty" validate:"required"`
+	State *string `json:"state,omitempty" validate:"required"`
+}
+
 type Actor struct {
 	Nickname *string `json:"nickname,omitempty" validate:"required"`
 }
@@ -53,6 +76,7 @@ type PullRequest struct {
 	Links        *Links        `json:"links,omitempty" validate:"required"`
 	State        *string       `json:"state,omitempty" validate:"required"`
 	Author       *Author       `jsonN:"author,omitempty" validate:"required"`
+	MergeCommit  *Commit       `json:"merge_commit,omitempty"`
 }
 type Links struct {
 	HTML *Link `json:"html,omitempty" validate:"required"`
@@ -63,7 +87,8 @@ type Link struct {
 type Participant struct {
 	Approved *bool `json:"approved,omitempty" validate:"required"`
 	User     *struct {
-		UUID *string `json:"uuid,omitempty" validate:"required"`
+		UUID     *string `json:"uuid,omitempty" validate:"required"`
+		Nickname *string `json:"nickname,omitempty"`
 	} `json:"user,omitempty" validate:"required"`
 }
 type BranchMeta struct {
@@ -86,3 +111,7 @@ type CommentContent struct {
 type Author struct {
 	UUID *string `json:"uuid,omitempty" validate:"required"`
 }
+type GroupMember struct {
+	Username string `json:"username"`
+	Nickname string `json:"nickname"`
+}
@@ -141,10 +141,113 @@ func (b *Client) CreateComment(repo models.Repo, pullNum int, comment string) er
 	return nil
 }
 
+// HidePrevPlanComments replaces the plan comments Atlantis previously left on
+// the pull request with a short note since Bitbucket can't hide or collapse
+// comments.
 func (b *Client) HidePrevPlanComments(repo models.Repo, pullNum int) error {
+	comments, err := b.listComments(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		// Usernames aren't case sensitive.
+		if c.Author == nil || c.Author.Username == nil || !strings.EqualFold(*c.Author.Username, b.Username) || !common.IsPlanComment(c.Text) {
+			continue
+		}
+		body := common.OutdatedPlanComment(c.Text, false)
+		if err := b.UpdateComment(repo, pullNum, models.PullComment{ID: c.ID}, body); err != nil {
+			return errors.Wrapf(err, "hiding comment %d", c.ID)
+		}
+	}
 	return nil
 }
 
+// listComments returns the comments on the pull request, newest first.
+// Replies to comments aren't returned since Atlantis never replies.
+func (b *Client) listComments(repo models.Repo, pullNum int) ([]PullComment, error) {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return nil, err
+	}
+	var comments []PullComment
+	nextPageStart := 0
+	baseURL := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/activities",
+		b.BaseURL, projectKey, repo.Name, pullNum)
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s?start=%d", baseURL, nextPageStart), nil)
+		if err != nil {
+			return nil, err
+		}
+		var activities Activities
+		if err := json.Unmarshal(resp, &activities); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(activities); err != nil {
+			return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, v := range activities.Values {
+			if v.Action == "COMMENTED" && v.CommentAction == "ADDED" && v.Comment != nil {
+				comments = append(comments, *v.Comment)
+			}
+		}
+		if *activities.IsLastPage {
+			break
+		}
+		nextPageStart = *activities.NextPageStart
+	}
+	return comments, nil
+}
+
+// FindComment returns the most recent comment on the pull request containing
+// marker. Only comments by the authenticated user are considered so that
+// others can't plant the marker.
+func (b *Client) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	comments, err := b.listComments(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range comments {
+		// Usernames aren't case sensitive.
+		if c.Author == nil || c.Author.Username == nil || !strings.EqualFold(*c.Author.Username, b.Username) {
+			continue
+		}
+		if strings.Contains(c.Text, marker) {
+			return &models.PullComment{ID: c.ID, Body: c.Text}, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateComment replaces the text of comment. Bitbucket requires the
+// comment's current version to update it so it's fetched first.
+func (b *Client) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/comments/%d", b.BaseURL, projectKey, repo.Name, pullNum, comment.ID)
+	resp, err := b.makeRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	var current PullComment
+	if err := json.Unmarshal(resp, &current); err != nil {
+		return errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+
+	bodyBytes, err := json.Marshal(PullComment{
+		Text:    common.TruncateComment(body, maxCommentLength),
+		Version: current.Version,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	_, err = b.makeRequest("PUT", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
 // postComment actually posts the comment. It's a helper for CreateComment().
 func (b *Client) postComment(repo models.Repo, pullNum int, comment string) error {
 	bodyBytes, err := json.Marshal(map[string]string{"text": comment})
@@ -160,30 +263,40 @@ func (b *Client) postComment(repo models.Repo, pullNum int, comment string) erro
 	return err
 }
 
-// PullIsApproved returns true if the merge request was approved.
-func (b *Client) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+// GetApprovals returns the approvals of the pull request. Each approval is
+// associated with the last commit the reviewer reviewed.
+func (b *Client) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
 	projectKey, err := b.GetProjectKey(repo.Name, repo.SanitizedCloneURL)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	path := fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d", b.BaseURL, projectKey, repo.Name, pull.Num)
 	resp, err := b.makeRequest("GET", path, nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	var pullResp PullRequest
 	if err := json.Unmarshal(resp, &pullResp); err != nil {
-		return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
 	}
 	if err := validator.New().Struct(pullResp); err != nil {
-		return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
 	}
+	var approvals []models.Approval
 	for _, reviewer := range pullResp.Reviewers {
-		if *reviewer.Approved {
-			return true, nil
+		if !*reviewer.Approved {
+			continue
+		}
+		var approval models.Approval
+		if reviewer.User != nil && reviewer.User.Name != nil {
+			approval.User.Username = *reviewer.User.Name
 		}
+		if reviewer.LastReviewedCommit != nil {
+			approval.CommitSHA = *reviewer.LastReviewedCommit
+		}
+		approvals = append(approvals, approval)
 	}
-	return false, nil
+	return approvals, nil
 }
 
 // PullIsMergeable returns true if the merge request has no conflicts and can be merged.
@@ -210,6 +323,44 @@ func (b *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (boo
 	return false, nil
 }
 
+// GetCommitChecks returns the build statuses of the pull request's head
+// commit. Bitbucket only keeps the latest status for each key.
+func (b *Client) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	var checks []models.CommitCheck
+	nextPageStart := 0
+	baseURL := fmt.Sprintf("%s/rest/build-status/1.0/commits/%s", b.BaseURL, pull.HeadCommit)
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s?start=%d", baseURL, nextPageStart), nil)
+		if err != nil {
+			return nil, err
+		}
+		var statuses BuildStatuses
+		if err := json.Unmarshal(resp, &statuses); err != nil {
+			return nil, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(statuses); err != nil {
+			return nil, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, v := range statuses.Values {
+			state := models.PendingCommitStatus
+			switch *v.State {
+			case "SUCCESSFUL":
+				state = models.SuccessCommitStatus
+			case "FAILED":
+				state = models.FailedCommitStatus
+			}
+			checks = append(checks, models.CommitCheck{Name: *v.Key, State: state})
+		}
+		if *statuses.IsLastPage {
+			break
+		}
+		nextPageStart = *statuses.NextPageStart
+	}
+	return checks, nil
+}
+
 // UpdateStatus updates the status of a commit.
 func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status models.CommitStatus, src string, description string, url string) error {
 	bbState := "FAILED"
@@ -243,8 +394,9 @@ func (b *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, status
 	return err
 }
 
-// MergePull merges the pull request.
-func (b *Client) MergePull(pull models.PullRequest) error {
+// MergePull merges the pull request. Merge methods map to Bitbucket's merge
+// strategies which need to be enabled for the repo.
+func (b *Client) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
 	projectKey, err := b.GetProjectKey(pull.BaseRepo.Name, pull.BaseRepo.SanitizedCloneURL)
 	if err != nil {
 		return err
@@ -263,9 +415,50 @@ func (b *Client) MergePull(pull models.PullRequest) error {
 	if err := validator.New().Struct(pullResp); err != nil {
 		return errors.Wrapf(err, "API response %q was missing fields", string(resp))
 	}
+
+	body := map[string]string{
+		"message": common.MergeCommitMsg(opts),
+	}
+	switch opts.MergeMethod {
+	case models.MergeMergeMethod:
+		body["strategyId"] = "no-ff"
+	case models.SquashMergeMethod:
+		body["strategyId"] = "squash"
+	case models.RebaseMergeMethod:
+		body["strategyId"] = "rebase-no-ff"
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
 	path = fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/merge?version=%d", b.BaseURL, projectKey, pull.BaseRepo.Name, pull.Num, *pullResp.Version)
-	_, err = b.makeRequest("POST", path, nil)
-	return err
+	if _, err = b.makeRequest("POST", path, bytes.NewBuffer(bodyBytes)); err != nil {
+		return err
+	}
+
+	if opts.DeleteSourceBranchOnMerge {
+		return b.deleteFromBranch(pullResp)
+	}
+	return nil
+}
+
+// deleteFromBranch deletes the branch pull was merged from. Branches of pull
+// requests from forks aren't deleted since they're in a different repo.
+func (b *Client) deleteFromBranch(pull PullRequest) error {
+	from, to := pull.FromRef.Repository, pull.ToRef.Repository
+	if *from.Slug != *to.Slug || *from.Project.Key != *to.Project.Key {
+		return nil
+	}
+	bodyBytes, err := json.Marshal(map[string]interface{}{
+		"name":   "refs/heads/" + *pull.FromRef.DisplayID,
+		"dryRun": false,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/rest/branch-utils/1.0/projects/%s/repos/%s/branches", b.BaseURL, *to.Project.Key, *to.Slug)
+	_, err = b.makeRequest("DELETE", path, bytes.NewBuffer(bodyBytes))
+	return errors.Wrapf(err, "deleting branch %s", *pull.FromRef.DisplayID)
 }
 
 // MarkdownPullLink specifies the character used in a pull request comment.
@@ -273,6 +466,47 @@ func (b *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return fmt.Sprintf("#%d", pull.Num), nil
 }
 
+// UserIsTeamMember returns true if user is a member of the group team. The
+// user Atlantis authenticates as needs admin permission to list group members.
+func (b *Client) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	nextPageStart := 0
+	// The filter matches on substrings so we need to check for an exact match.
+	baseURL := fmt.Sprintf("%s/rest/api/1.0/admin/groups/more-members?context=%s&filter=%s",
+		b.BaseURL, url.QueryEscape(team), url.QueryEscape(user.Username))
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for i := 0; i < maxLoops; i++ {
+		resp, err := b.makeRequest("GET", fmt.Sprintf("%s&start=%d", baseURL, nextPageStart), nil)
+		if err != nil {
+			return false, err
+		}
+		var members GroupMembers
+		if err := json.Unmarshal(resp, &members); err != nil {
+			return false, errors.Wrapf(err, "Could not parse response %q", string(resp))
+		}
+		if err := validator.New().Struct(members); err != nil {
+			return false, errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+		for _, m := range members.Values {
+			if strings.EqualFold(m.Name, user.Username) {
+				return true, nil
+			}
+		}
+		if *members.IsLastPage {
+			break
+		}
+		nextPageStart = *members.NextPageStart
+	}
+	return false, nil
+}
+
+// Ping makes a lightweight authenticated API call to verify that Bitbucket
+// Server is reachable and our credentials are valid.
+func (b *Client) Ping() error {
+	_, err := b.makeRequest("GET", fmt.Sprintf("%s/rest/api/1.0/users/%s", b.BaseURL, url.PathEscape(b.Username)), nil)
+	return err
+}
+
 // prepRequest adds auth and necessary headers.
 func (b *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
 	req, err := http.NewRequest(method, path, body)
@@ -137,48 +137,139 @@ func TestClient_GetModifiedFilesPagination(t *testing.T) {
 // Test that we use the correct version parameter in our call to merge the pull
 // request.
 func TestClient_MergePull(t *testing.T) {
+	cases := []struct {
+		description string
+		opts        models.PullRequestOptions
+		expBody     string
+		expDelete   bool
+	}{
+		{
+			description: "defaults",
+			opts:        models.PullRequestOptions{},
+			expBody:     `{"message":"[Atlantis] Automatically merging after successful apply"}`,
+		},
+		{
+			description: "with options",
+			opts: models.PullRequestOptions{
+				MergeMethod:               "squash",
+				DeleteSourceBranchOnMerge: true,
+				CommitMessage:             "Merge #1",
+			},
+			expBody:   `{"message":"Merge #1","strategyId":"squash"}`,
+			expDelete: true,
+		},
+	}
+
 	pullRequest, err := ioutil.ReadFile(filepath.Join("testdata", "pull-request.json"))
 	Ok(t, err)
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			deleted := false
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				// The first request should hit this URL.
+				case "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1":
+					w.Write(pullRequest) // nolint: errcheck
+					return
+				case "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/merge?version=3":
+					Equals(t, "POST", r.Method)
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					Equals(t, c.expBody, string(body))
+					w.Write(pullRequest) // nolint: errcheck
+				case "/rest/branch-utils/1.0/projects/AT/repos/example/branches":
+					Equals(t, "DELETE", r.Method)
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					Equals(t, `{"dryRun":false,"name":"refs/heads/hi"}`, string(body))
+					deleted = true
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+			}))
+			defer testServer.Close()
+
+			client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+			Ok(t, err)
+
+			err = client.MergePull(models.PullRequest{
+				Num:        1,
+				HeadCommit: "",
+				URL:        "",
+				HeadBranch: "",
+				BaseBranch: "",
+				Author:     "",
+				State:      0,
+				BaseRepo: models.Repo{
+					FullName:          "owner/repo",
+					Owner:             "owner",
+					Name:              "repo",
+					SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
+					VCSHost: models.VCSHost{
+						Type:     models.BitbucketCloud,
+						Hostname: "bitbucket.org",
+					},
+				},
+			}, c.opts)
+			Ok(t, err)
+			Equals(t, c.expDelete, deleted)
+		})
+	}
+}
+
+// Test that we page through group members and only match exact usernames.
+func TestClient_UserIsTeamMember(t *testing.T) {
 	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.RequestURI {
-		// The first request should hit this URL.
-		case "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1":
-			w.Write(pullRequest) // nolint: errcheck
-			return
-		case "/rest/api/1.0/projects/ow/repos/repo/pull-requests/1/merge?version=3":
-			Equals(t, "POST", r.Method)
-			w.Write(pullRequest) // nolint: errcheck
+		case "/rest/api/1.0/admin/groups/more-members?context=infra&filter=jdoe&start=0":
+			w.Write([]byte(`{"values": [{"name": "jdoe2"}], "isLastPage": false, "nextPageStart": 1}`)) // nolint: errcheck
+		case "/rest/api/1.0/admin/groups/more-members?context=infra&filter=jdoe&start=1":
+			w.Write([]byte(`{"values": [{"name": "JDoe"}], "isLastPage": true}`)) // nolint: errcheck
+		case "/rest/api/1.0/admin/groups/more-members?context=other&filter=jdoe&start=0":
+			w.Write([]byte(`{"values": [{"name": "jdoe2"}], "isLastPage": true}`)) // nolint: errcheck
 		default:
 			t.Errorf("got unexpected request at %q", r.RequestURI)
 			http.Error(w, "not found", http.StatusNotFound)
-			return
 		}
 	}))
 	defer testServer.Close()
 
-	client, err := bitbucketserver.NewClient(http.DefaultClient, "user", "pass", testServer.URL, "runatlantis.io")
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
 	Ok(t, err)
+	isMember, err := client.UserIsTeamMember(models.Repo{}, models.User{Username: "jdoe"}, "infra")
+	Ok(t, err)
+	Equals(t, true, isMember)
+	isMember, err = client.UserIsTeamMember(models.Repo{}, models.User{Username: "jdoe"}, "other")
+	Ok(t, err)
+	Equals(t, false, isMember)
+}
 
-	err = client.MergePull(models.PullRequest{
-		Num:        1,
-		HeadCommit: "",
-		URL:        "",
-		HeadBranch: "",
-		BaseBranch: "",
-		Author:     "",
-		State:      0,
-		BaseRepo: models.Repo{
-			FullName:          "owner/repo",
-			Owner:             "owner",
-			Name:              "repo",
-			SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL),
-			VCSHost: models.VCSHost{
-				Type:     models.BitbucketCloud,
-				Hostname: "bitbucket.org",
-			},
-		},
-	})
+func TestClient_GetCommitChecks(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.RequestURI {
+		case "/rest/build-status/1.0/commits/sha?start=0":
+			w.Write([]byte(`{"values": [{"key": "build", "state": "SUCCESSFUL"}, {"key": "test", "state": "INPROGRESS"}], "isLastPage": false, "nextPageStart": 2}`)) // nolint: errcheck
+		case "/rest/build-status/1.0/commits/sha?start=2":
+			w.Write([]byte(`{"values": [{"key": "lint", "state": "FAILED"}], "isLastPage": true}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request at %q", r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
 	Ok(t, err)
+	checks, err := client.GetCommitChecks(models.Repo{}, models.PullRequest{HeadCommit: "sha"})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "build", State: models.SuccessCommitStatus},
+		{Name: "test", State: models.PendingCommitStatus},
+		{Name: "lint", State: models.FailedCommitStatus},
+	}, checks)
 }
 
 func TestClient_MarkdownPullLink(t *testing.T) {
@@ -189,3 +280,78 @@ func TestClient_MarkdownPullLink(t *testing.T) {
 	exp := "#1"
 	Equals(t, exp, s)
 }
+
+func TestClient_FindAndUpdateComment(t *testing.T) {
+	var updated string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/activities?start=0":
+			// Comments by other users with the marker should be ignored.
+			w.Write([]byte(`{"values": [
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 4, "text": "marker", "author": {"name": "attacker"}}},
+				{"action": "APPROVED"},
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 1, "text": "other", "author": {"name": "user"}}}
+			], "isLastPage": false, "nextPageStart": 2}`)) // nolint: errcheck
+		case "GET /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/activities?start=2":
+			w.Write([]byte(`{"values": [
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 2, "text": "with marker", "author": {"name": "User"}}},
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 3, "text": "older marker", "author": {"name": "user"}}}
+			], "isLastPage": true}`)) // nolint: errcheck
+		case "GET /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/2":
+			w.Write([]byte(`{"id": 2, "version": 4, "text": "with marker"}`)) // nolint: errcheck
+		case "PUT /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/2":
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			updated = string(body)
+			w.Write([]byte(`{"id": 2}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	repo := models.Repo{Name: "repo", SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL)}
+
+	comment, err := client.FindComment(repo, 1, "marker")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 2, Body: "with marker"}, comment)
+
+	Ok(t, client.UpdateComment(repo, 1, *comment, "new body"))
+	Equals(t, `{"version":4,"text":"new body"}`, updated)
+}
+
+// Only plan comments written by the Atlantis user should be replaced.
+func TestClient_HidePrevPlanComments(t *testing.T) {
+	var updated []string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/activities?start=0":
+			w.Write([]byte(`{"values": [
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 1, "text": "Ran Plan for dir: .\n\noutput", "author": {"name": "User"}}},
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 2, "text": "Ran Apply for dir: .", "author": {"name": "user"}}},
+				{"action": "COMMENTED", "commentAction": "ADDED", "comment": {"id": 3, "text": "Ran Plan for dir: .", "author": {"name": "someone"}}}
+			], "isLastPage": true}`)) // nolint: errcheck
+		case "GET /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/1":
+			w.Write([]byte(`{"id": 1, "version": 0, "text": "Ran Plan for dir: ."}`)) // nolint: errcheck
+		case "PUT /rest/api/1.0/projects/ow/repos/repo/pull-requests/1/comments/1":
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			updated = append(updated, string(body))
+			w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client, err := bitbucketserver.NewClient(nil, "user", "pass", testServer.URL, "runatlantis.io")
+	Ok(t, err)
+	repo := models.Repo{Name: "repo", SanitizedCloneURL: fmt.Sprintf("%s/scm/ow/repo.git", testServer.URL)}
+
+	Ok(t, client.HidePrevPlanComments(repo, 1))
+	Equals(t, []string{`{"version":0,"text":"**Outdated**: This plan was superseded by a newer plan.\n\nRan Plan for dir: ."}`}, updated)
+}
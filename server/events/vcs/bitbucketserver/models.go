@@ -31,7 +31,18 @@ type PullRequest struct {
 	State     *string `json:"state,omitempty" validate:"required"`
 	Reviewers []struct {
 		Approved *bool `json:"approved,omitempty" validate:"required"`
+		User     *struct {
+			Name *string `json:"name,omitempty"`
+		} `json:"user,omitempty"`
+		LastReviewedCommit *string `json:"lastReviewedCommit,omitempty"`
 	} `json:"reviewers,omitempty" validate:"required"`
+	Properties *PullRequestProperties `json:"properties,omitempty"`
+}
+
+type PullRequestProperties struct {
+	MergeCommit *struct {
+		ID *string `json:"id,omitempty"`
+	} `json:"mergeCommit,omitempty"`
 }
 
 type Ref struct {
@@ -58,6 +69,23 @@ type Comment struct {
 	Text *string `json:"text,omitempty" validate:"required"`
 }
 
+type Activities struct {
+	Values []struct {
+		Action        string       `json:"action"`
+		CommentAction string       `json:"commentAction"`
+		Comment       *PullComment `json:"comment,omitempty"`
+	} `json:"values,omitempty" validate:"required"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
+
+type PullComment struct {
+	ID      int64  `json:"id,omitempty"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+	Author  *Actor `json:"author,omitempty"`
+}
+
 type Changes struct {
 	Values []struct {
 		Path struct {
@@ -71,6 +99,23 @@ type Changes struct {
 	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
 }
 
+type GroupMembers struct {
+	Values []struct {
+		Name string `json:"name"`
+	} `json:"values"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
+
+type BuildStatuses struct {
+	Values []struct {
+		Key   *string `json:"key,omitempty" validate:"required"`
+		State *string `json:"state,omitempty" validate:"required"`
+	} `json:"values,omitempty" validate:"required"`
+	NextPageStart *int  `json:"nextPageStart,omitempty"`
+	IsLastPage    *bool `json:"isLastPage,omitempty" validate:"required"`
+}
+
 type MergeStatus struct {
 	CanMerge   *bool `json:"canMerge,omitempty" validate:"required"`
 	Conflicted *bool `json:"conflicted,omitempty" validate:"required"`
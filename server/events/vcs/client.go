@@ -26,8 +26,22 @@ type Client interface {
 	GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error)
 	CreateComment(repo models.Repo, pullNum int, comment string) error
 	HidePrevPlanComments(repo models.Repo, pullNum int) error
-	PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error)
+	// FindComment returns the most recent comment written by the
+	// authenticated user on the pull request whose body contains marker or
+	// nil if there isn't one.
+	FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error)
+	// UpdateComment replaces the body of comment with body. Bodies that are
+	// too long for the VCS host are truncated.
+	UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error
+	// GetApprovals returns the current approvals of pull. Approvals by the
+	// pull request's author aren't returned if the VCS host allows them.
+	GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error)
 	PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error)
+	// GetCommitChecks returns the commit statuses and checks reported on
+	// pull's head commit. Only the latest result of each check is returned.
+	// Checks the VCS host requires before merging are marked as required,
+	// including ones that haven't been reported yet.
+	GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error)
 	// UpdateStatus updates the commit status to state for pull. src is the
 	// source of this status. This should be relatively static across runs,
 	// ex. atlantis/plan or atlantis/apply.
@@ -36,6 +50,23 @@ type Client interface {
 	// url is an optional link that users should click on for more information
 	// about this status.
 	UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error
-	MergePull(pull models.PullRequest) error
+	// MergePull merges pull using opts. Hosts that can't merge with the
+	// requested method return an error instead of falling back to another.
+	MergePull(pull models.PullRequest, opts models.PullRequestOptions) error
 	MarkdownPullLink(pull models.PullRequest) (string, error)
+	// UserIsTeamMember returns true if user is a member of team. What a team
+	// is depends on the VCS host, ex. a GitHub team or a GitLab group. team
+	// may be relative to repo's owner, ex. a team in the repo's organization.
+	UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error)
+}
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_checks_client.go ChecksClient
+
+// ChecksClient reports check runs. Only GitHub supports check runs and only
+// for GitHub Apps.
+type ChecksClient interface {
+	// UpdateCheckRun creates or updates run on pull's head commit. A pending
+	// run starts a new check run. Otherwise the latest check run with the
+	// same name is updated.
+	UpdateCheckRun(repo models.Repo, pull models.PullRequest, run models.CheckRun) error
 }
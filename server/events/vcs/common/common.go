@@ -3,13 +3,27 @@
 package common
 
 import (
+	"fmt"
 	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/runatlantis/atlantis/server/events/models"
 )
 
 // AutomergeCommitMsg is the commit message Atlantis will use when automatically
 // merging pull requests.
 const AutomergeCommitMsg = "[Atlantis] Automatically merging after successful apply"
 
+// MergeCommitMsg returns the commit message to merge with when merging using
+// opts.
+func MergeCommitMsg(opts models.PullRequestOptions) string {
+	if opts.CommitMessage != "" {
+		return opts.CommitMessage
+	}
+	return AutomergeCommitMsg
+}
+
 // SplitComment splits comment into a slice of comments that are under maxSize.
 // It appends sepEnd to all comments that have a following comment.
 // It prepends sepStart to all comments that have a preceding comment.
@@ -41,3 +55,65 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TruncateComment truncates comment so that it's at most maxSize bytes,
+// replacing the end with a warning if it was too long. It never splits a
+// multi-byte character. If maxSize is too small for the warning, the comment
+// is truncated without it.
+func TruncateComment(comment string, maxSize int) string {
+	if len(comment) <= maxSize {
+		return comment
+	}
+	const warning = "\n\n**Warning**: Output length greater than max comment size. Truncated."
+	if maxSize < len(warning) {
+		return truncateRunes(comment, maxSize)
+	}
+	return truncateRunes(comment, maxSize-len(warning)) + warning
+}
+
+// truncateRunes returns the longest prefix of s that's at most max bytes and
+// doesn't end in the middle of a character.
+func truncateRunes(s string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max]
+}
+
+// OutdatedPlanPrefix starts plan comments that were hidden because a newer
+// plan was run.
+const OutdatedPlanPrefix = "**Outdated**: This plan was superseded by a newer plan."
+
+// StickyCommentMarkerPrefix starts sticky comments, which are updated in place
+// rather than hidden.
+const StickyCommentMarkerPrefix = "[//]: # (atlantis-sticky-comment:"
+
+// IsPlanComment returns true if comment is the result of a plan that hasn't
+// been hidden yet. Sticky comments aren't plan comments since they're reused
+// by the next plan. Callers must also check that Atlantis wrote the comment.
+func IsPlanComment(comment string) bool {
+	// Crude filtering: The comment templates typically include the command
+	// name somewhere in the first line.
+	if strings.HasPrefix(comment, OutdatedPlanPrefix) || strings.HasPrefix(comment, StickyCommentMarkerPrefix) {
+		return false
+	}
+	firstLine := strings.ToLower(strings.SplitN(comment, "\n", 2)[0])
+	return strings.Contains(firstLine, models.PlanCommand.String())
+}
+
+// OutdatedPlanComment returns the body to replace the plan comment with
+// when hiding it. If collapse is true, the plan is kept in a collapsed
+// section. Otherwise only its first line is kept, for VCS hosts that don't
+// support HTML.
+func OutdatedPlanComment(comment string, collapse bool) string {
+	if collapse {
+		return fmt.Sprintf("%s\n\n<details><summary>Show outdated plan</summary>\n\n%s\n</details>", OutdatedPlanPrefix, comment)
+	}
+	return fmt.Sprintf("%s\n\n%s", OutdatedPlanPrefix, strings.SplitN(comment, "\n", 2)[0])
+}
@@ -16,6 +16,7 @@ package common_test
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
 
@@ -61,3 +62,37 @@ func TestSplitComment_FourComments(t *testing.T) {
 		sepStart + comment[expMax*2:expMax*3] + sepEnd,
 		sepStart + comment[expMax*3:]}, split)
 }
+
+func TestTruncateComment(t *testing.T) {
+	Equals(t, "comment", common.TruncateComment("comment", 7))
+
+	truncated := common.TruncateComment(strings.Repeat("a", 200), 100)
+	Equals(t, 100, len(truncated))
+	Assert(t, strings.HasSuffix(truncated, "Truncated."), "expected warning but got %q", truncated)
+
+	// Multi-byte characters shouldn't be split.
+	truncated = common.TruncateComment(strings.Repeat("é", 100), 100)
+	Assert(t, len(truncated) <= 100, "expected at most 100 bytes but got %d", len(truncated))
+	Assert(t, utf8.ValidString(truncated), "expected valid UTF-8 but got %q", truncated)
+	Assert(t, strings.HasSuffix(truncated, "Truncated."), "expected warning but got %q", truncated)
+
+	// If there isn't room for the warning, the comment is truncated without it.
+	Equals(t, "aaaaa", common.TruncateComment(strings.Repeat("a", 200), 5))
+	Equals(t, "é", common.TruncateComment("éé", 3))
+	Equals(t, "", common.TruncateComment("comment", 0))
+}
+
+func TestIsPlanComment(t *testing.T) {
+	Equals(t, true, common.IsPlanComment("Ran Plan for dir: `.` workspace: `default`\n\nNo changes."))
+	Equals(t, true, common.IsPlanComment("Ran Plan for 2 projects:"))
+	Equals(t, false, common.IsPlanComment("Ran Apply for dir: `.` workspace: `default`\n\nplan"))
+	Equals(t, false, common.IsPlanComment(common.OutdatedPlanComment("Ran Plan for 2 projects:", true)))
+	Equals(t, false, common.IsPlanComment("[//]: # (atlantis-sticky-comment:plan)\nRan Plan for 2 projects:"))
+}
+
+func TestOutdatedPlanComment(t *testing.T) {
+	Equals(t, "**Outdated**: This plan was superseded by a newer plan.\n\n<details><summary>Show outdated plan</summary>\n\nRan Plan\n\noutput\n</details>",
+		common.OutdatedPlanComment("Ran Plan\n\noutput", true))
+	Equals(t, "**Outdated**: This plan was superseded by a newer plan.\n\nRan Plan",
+		common.OutdatedPlanComment("Ran Plan\n\noutput", false))
+}
@@ -0,0 +1,466 @@
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	validator "gopkg.in/go-playground/validator.v9"
+)
+
+// pageLimit is the number of items requested per page. Gitea caps it at its
+// MAX_RESPONSE_ITEMS setting, which defaults to 50.
+const pageLimit = 50
+
+type Client struct {
+	HTTPClient *http.Client
+	Token      string
+	BaseURL    string
+}
+
+// NewClient builds a Gitea client. baseURL is the URL of the Gitea or Forgejo
+// installation, ex. https://gitea.com.
+func NewClient(httpClient *http.Client, token string, baseURL string) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing %s", baseURL)
+	}
+	if parsedURL.Scheme == "" {
+		return nil, fmt.Errorf("must have 'http://' or 'https://' in base url %q", baseURL)
+	}
+	return &Client{
+		HTTPClient: httpClient,
+		Token:      token,
+		BaseURL:    strings.TrimRight(parsedURL.String(), "/"),
+	}, nil
+}
+
+// GetModifiedFiles returns the names of files that were modified in the pull
+// request relative to the repo root, e.g. parent/child/file.txt.
+func (g *Client) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	var files []string
+	path := fmt.Sprintf("%s/api/v1/repos/%s/pulls/%d/files", g.BaseURL, repo.FullName, pull.Num)
+	err := g.getPages(path, func(resp []byte) (int, error) {
+		var page []ChangedFile
+		if err := g.unmarshal(resp, &page); err != nil {
+			return 0, err
+		}
+		for _, f := range page {
+			files = append(files, *f.Filename)
+			// Renamed files are modified in both their old and new location.
+			if f.PreviousFilename != "" {
+				files = append(files, f.PreviousFilename)
+			}
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Now ensure all files are unique.
+	hash := make(map[string]bool)
+	var unique []string
+	for _, f := range files {
+		if !hash[f] {
+			unique = append(unique, f)
+			hash[f] = true
+		}
+	}
+	return unique, nil
+}
+
+// CreateComment creates a comment on the pull request. Gitea doesn't limit
+// the length of comments so they aren't split.
+func (g *Client) CreateComment(repo models.Repo, pullNum int, comment string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"body": comment})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/api/v1/repos/%s/issues/%d/comments", g.BaseURL, repo.FullName, pullNum)
+	_, err = g.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// FindComment returns the most recent comment on the pull request containing
+// marker. Only comments by the authenticated user are considered so that
+// others can't plant the marker.
+func (g *Client) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	user, err := g.currentUser()
+	if err != nil {
+		return nil, err
+	}
+	comments, err := g.listComments(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	var found *models.PullComment
+	for _, c := range comments {
+		if !strings.EqualFold(*c.User.Login, *user.Login) {
+			continue
+		}
+		if strings.Contains(*c.Body, marker) {
+			found = &models.PullComment{ID: *c.ID, Body: *c.Body}
+		}
+	}
+	return found, nil
+}
+
+// UpdateComment replaces the body of comment.
+func (g *Client) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/api/v1/repos/%s/issues/comments/%d", g.BaseURL, repo.FullName, comment.ID)
+	_, err = g.makeRequest("PATCH", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// HidePrevPlanComments collapses the plan comments Atlantis previously left
+// on the pull request since Gitea can't hide comments.
+func (g *Client) HidePrevPlanComments(repo models.Repo, pullNum int) error {
+	user, err := g.currentUser()
+	if err != nil {
+		return err
+	}
+	comments, err := g.listComments(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if !strings.EqualFold(*c.User.Login, *user.Login) || !common.IsPlanComment(*c.Body) {
+			continue
+		}
+		body := common.OutdatedPlanComment(*c.Body, true)
+		if err := g.UpdateComment(repo, pullNum, models.PullComment{ID: *c.ID}, body); err != nil {
+			return errors.Wrapf(err, "hiding comment %d", *c.ID)
+		}
+	}
+	return nil
+}
+
+// listComments returns the pull request's comments, oldest first. Gitea
+// doesn't paginate comments.
+func (g *Client) listComments(repo models.Repo, pullNum int) ([]Comment, error) {
+	path := fmt.Sprintf("%s/api/v1/repos/%s/issues/%d/comments", g.BaseURL, repo.FullName, pullNum)
+	resp, err := g.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var comments []Comment
+	if err := g.unmarshal(resp, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// GetApprovals returns the approvals of the pull request. Only each
+// reviewer's latest review counts and dismissed reviews are ignored. Gitea
+// doesn't allow authors to approve their own pull requests.
+func (g *Client) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
+	var usernames []string
+	latestReviews := make(map[string]Review)
+	path := fmt.Sprintf("%s/api/v1/repos/%s/pulls/%d/reviews", g.BaseURL, repo.FullName, pull.Num)
+	err := g.getPages(path, func(resp []byte) (int, error) {
+		var page []Review
+		if err := g.unmarshal(resp, &page); err != nil {
+			return 0, err
+		}
+		// Reviews are returned in chronological order.
+		for _, review := range page {
+			switch *review.State {
+			case "APPROVED", "REQUEST_CHANGES":
+			default:
+				// Comments, pending reviews and review requests don't change
+				// whether the reviewer approves.
+				continue
+			}
+			username := *review.User.Login
+			if _, ok := latestReviews[username]; !ok {
+				usernames = append(usernames, username)
+			}
+			latestReviews[username] = review
+		}
+		return len(page), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals []models.Approval
+	for _, username := range usernames {
+		review := latestReviews[username]
+		if *review.State == "APPROVED" && !review.Dismissed {
+			approvals = append(approvals, models.Approval{
+				User:      models.User{Username: username},
+				CommitSHA: review.CommitID,
+			})
+		}
+	}
+	return approvals, nil
+}
+
+// PullIsMergeable returns true if the pull request has no conflicts and can
+// be merged.
+func (g *Client) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
+	giteaPull, err := g.GetPullRequest(repo, pull.Num)
+	if err != nil {
+		return false, errors.Wrap(err, "getting pull request")
+	}
+	return giteaPull.Mergeable, nil
+}
+
+// GetPullRequest returns the pull request.
+func (g *Client) GetPullRequest(repo models.Repo, pullNum int) (*PullRequest, error) {
+	path := fmt.Sprintf("%s/api/v1/repos/%s/pulls/%d", g.BaseURL, repo.FullName, pullNum)
+	resp, err := g.makeRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	var pull PullRequest
+	if err := g.unmarshal(resp, &pull); err != nil {
+		return nil, err
+	}
+	return &pull, nil
+}
+
+// GetCommitChecks returns the latest commit status of each context on the
+// pull request's head commit.
+func (g *Client) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	var checks []models.CommitCheck
+	path := fmt.Sprintf("%s/api/v1/repos/%s/commits/%s/status", g.BaseURL, repo.FullName, pull.HeadCommit)
+	err := g.getPages(path, func(resp []byte) (int, error) {
+		var combined CombinedStatus
+		if err := g.unmarshal(resp, &combined); err != nil {
+			return 0, err
+		}
+		for _, s := range combined.Statuses {
+			state := models.PendingCommitStatus
+			switch *s.Status {
+			case "success", "warning":
+				state = models.SuccessCommitStatus
+			case "failure", "error":
+				state = models.FailedCommitStatus
+			}
+			checks = append(checks, models.CommitCheck{Name: *s.Context, State: state})
+		}
+		return len(combined.Statuses), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// UpdateStatus updates the status of the pull request's head commit.
+func (g *Client) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
+	giteaState := "failure"
+	switch state {
+	case models.PendingCommitStatus:
+		giteaState = "pending"
+	case models.SuccessCommitStatus:
+		giteaState = "success"
+	case models.FailedCommitStatus:
+		giteaState = "failure"
+	}
+	bodyBytes, err := json.Marshal(map[string]string{
+		"context":     src,
+		"target_url":  url,
+		"state":       giteaState,
+		"description": description,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/api/v1/repos/%s/statuses/%s", g.BaseURL, repo.FullName, pull.HeadCommit)
+	_, err = g.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// MergePull merges the pull request. If no merge method is set, a merge
+// commit is created.
+func (g *Client) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
+	body := map[string]interface{}{
+		"Do":                        "merge",
+		"MergeMessageField":         common.MergeCommitMsg(opts),
+		"delete_branch_after_merge": opts.DeleteSourceBranchOnMerge,
+	}
+	switch opts.MergeMethod {
+	case models.SquashMergeMethod:
+		body["Do"] = "squash"
+	case models.RebaseMergeMethod:
+		body["Do"] = "rebase"
+	}
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "json encoding")
+	}
+	path := fmt.Sprintf("%s/api/v1/repos/%s/pulls/%d/merge", g.BaseURL, pull.BaseRepo.FullName, pull.Num)
+	_, err = g.makeRequest("POST", path, bytes.NewBuffer(bodyBytes))
+	return err
+}
+
+// MarkdownPullLink specifies the character used in a pull request comment.
+func (g *Client) MarkdownPullLink(pull models.PullRequest) (string, error) {
+	return fmt.Sprintf("#%d", pull.Num), nil
+}
+
+// UserIsTeamMember returns true if user is a member of the team. team is
+// either the name of a team in repo's organization or {org}/{team}.
+func (g *Client) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	org, name := repo.Owner, team
+	if i := strings.Index(team, "/"); i >= 0 {
+		org, name = team[:i], team[i+1:]
+	}
+	path := fmt.Sprintf("%s/api/v1/orgs/%s/teams/search?q=%s", g.BaseURL, url.PathEscape(org), url.QueryEscape(name))
+	resp, err := g.makeRequest("GET", path, nil)
+	if err != nil {
+		return false, err
+	}
+	var results TeamSearchResults
+	if err := g.unmarshal(resp, &results); err != nil {
+		return false, err
+	}
+	var teamID *int64
+	for _, t := range results.Data {
+		// Search matches substrings so we look for the exact team.
+		if strings.EqualFold(*t.Name, name) {
+			teamID = t.ID
+			break
+		}
+	}
+	if teamID == nil {
+		return false, fmt.Errorf("team %q not found in organization %q", name, org)
+	}
+
+	isMember := false
+	path = fmt.Sprintf("%s/api/v1/teams/%d/members", g.BaseURL, *teamID)
+	err = g.getPages(path, func(resp []byte) (int, error) {
+		var members []User
+		if err := g.unmarshal(resp, &members); err != nil {
+			return 0, err
+		}
+		for _, m := range members {
+			if strings.EqualFold(*m.Login, user.Username) {
+				isMember = true
+			}
+		}
+		return len(members), nil
+	})
+	return isMember, err
+}
+
+// Ping makes a lightweight authenticated API call to verify that Gitea is
+// reachable and our credentials are valid.
+func (g *Client) Ping() error {
+	_, err := g.currentUser()
+	return err
+}
+
+// currentUser returns the user we're authenticated as.
+func (g *Client) currentUser() (User, error) {
+	var user User
+	resp, err := g.makeRequest("GET", fmt.Sprintf("%s/api/v1/user", g.BaseURL), nil)
+	if err != nil {
+		return user, err
+	}
+	err = g.unmarshal(resp, &user)
+	return user, err
+}
+
+// getPages calls handlePage with each page of path until handlePage returns
+// that a page was empty. Gitea may return fewer items per page than requested
+// so a short page doesn't mean it's the last.
+func (g *Client) getPages(path string, handlePage func(resp []byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	// We'll only loop 1000 times as a safety measure.
+	maxLoops := 1000
+	for page := 1; page <= maxLoops; page++ {
+		resp, err := g.makeRequest("GET", fmt.Sprintf("%s%spage=%d&limit=%d", path, sep, page, pageLimit), nil)
+		if err != nil {
+			return err
+		}
+		n, err := handlePage(resp)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// unmarshal parses resp into v and validates that it has the required fields.
+// v must be a pointer to a struct or to a slice of structs.
+func (g *Client) unmarshal(resp []byte, v interface{}) error {
+	if err := json.Unmarshal(resp, v); err != nil {
+		return errors.Wrapf(err, "Could not parse response %q", string(resp))
+	}
+	validate := validator.New()
+	items := []interface{}{v}
+	if rv := reflect.ValueOf(v).Elem(); rv.Kind() == reflect.Slice {
+		items = nil
+		for i := 0; i < rv.Len(); i++ {
+			items = append(items, rv.Index(i).Interface())
+		}
+	}
+	for _, item := range items {
+		if err := validate.Struct(item); err != nil {
+			return errors.Wrapf(err, "API response %q was missing fields", string(resp))
+		}
+	}
+	return nil
+}
+
+// prepRequest adds auth and necessary headers.
+func (g *Client) prepRequest(method string, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "token "+g.Token)
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (g *Client) makeRequest(method string, path string, reqBody io.Reader) ([]byte, error) {
+	req, err := g.prepRequest(method, path, reqBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "constructing request")
+	}
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	requestStr := fmt.Sprintf("%s %s", method, path)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("making request %q unexpected status code: %d, body: %s", requestStr, resp.StatusCode, string(respBody))
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading response from request %q", requestStr)
+	}
+	return respBody, nil
+}
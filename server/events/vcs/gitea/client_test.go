@@ -0,0 +1,360 @@
+package gitea_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs/common"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var repo = models.Repo{FullName: "lkysow/atlantis-example", Owner: "lkysow", Name: "atlantis-example"}
+
+// writeFixture writes the recorded response in testdata/name.
+func writeFixture(t *testing.T, w http.ResponseWriter, name string) {
+	resp, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	Ok(t, err)
+	w.Write(resp) // nolint: errcheck
+}
+
+func newClient(t *testing.T, testServer *httptest.Server) *gitea.Client {
+	client, err := gitea.NewClient(http.DefaultClient, "token", testServer.URL)
+	Ok(t, err)
+	return client
+}
+
+func TestNewClient_NoScheme(t *testing.T) {
+	_, err := gitea.NewClient(http.DefaultClient, "token", "gitea.example.com")
+	ErrEquals(t, `must have 'http://' or 'https://' in base url "gitea.example.com"`, err)
+}
+
+// Should follow pagination and include the previous names of renamed files.
+func TestClient_GetModifiedFiles(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2/files?page=1&limit=50":
+			writeFixture(t, w, "pull-files.json")
+		case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2/files?page=2&limit=50":
+			w.Write([]byte(`[{"filename": "main.tf", "status": "changed"}]`)) // nolint: errcheck
+		case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2/files?page=3&limit=50":
+			w.Write([]byte(`[]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	files, err := newClient(t, testServer).GetModifiedFiles(repo, models.PullRequest{Num: 2})
+	Ok(t, err)
+	Equals(t, []string{"main.tf", "modules/new/main.tf", "modules/old/main.tf"}, files)
+}
+
+func TestClient_CreateComment(t *testing.T) {
+	var created string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "POST /api/v1/repos/lkysow/atlantis-example/issues/2/comments":
+			Equals(t, "token token", r.Header.Get("Authorization"))
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			created = string(body)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 28}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	Ok(t, newClient(t, testServer).CreateComment(repo, 2, "comment"))
+	Equals(t, `{"body":"comment"}`, created)
+}
+
+func TestClient_FindAndUpdateComment(t *testing.T) {
+	var updated string
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/user":
+			writeFixture(t, w, "user.json")
+		case "GET /api/v1/repos/lkysow/atlantis-example/issues/2/comments":
+			writeFixture(t, w, "comments.json")
+		case "PATCH /api/v1/repos/lkysow/atlantis-example/issues/comments/26":
+			body, err := ioutil.ReadAll(r.Body)
+			Ok(t, err)
+			updated = string(body)
+			w.Write([]byte(`{"id": 26}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+	client := newClient(t, testServer)
+
+	// The most recent comment with the marker is by another user so it
+	// should be ignored.
+	comment, err := client.FindComment(repo, 2, "[//]: # (atlantis-sticky-comment:pull)")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 26, Body: "[//]: # (atlantis-sticky-comment:pull)\nRan Apply"}, comment)
+
+	comment, err = client.FindComment(repo, 2, "[//]: # (atlantis-sticky-comment:apply)")
+	Ok(t, err)
+	Assert(t, comment == nil, "expected no comment but got %v", comment)
+
+	Ok(t, client.UpdateComment(repo, 2, models.PullComment{ID: 26}, "new body"))
+	Equals(t, `{"body":"new body"}`, updated)
+}
+
+// Only plan comments written by the Atlantis user should be collapsed.
+func TestClient_HidePrevPlanComments(t *testing.T) {
+	var comments []map[string]interface{}
+	fixture, err := ioutil.ReadFile(filepath.Join("testdata", "comments.json"))
+	Ok(t, err)
+	Ok(t, json.Unmarshal(fixture, &comments))
+
+	updated := make(map[string]string)
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/user":
+			writeFixture(t, w, "user.json")
+		case "GET /api/v1/repos/lkysow/atlantis-example/issues/2/comments":
+			writeFixture(t, w, "comments.json")
+		case "PATCH /api/v1/repos/lkysow/atlantis-example/issues/comments/25":
+			var body map[string]string
+			Ok(t, json.NewDecoder(r.Body).Decode(&body))
+			updated[r.RequestURI] = body["body"]
+			w.Write([]byte(`{"id": 25}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	Ok(t, newClient(t, testServer).HidePrevPlanComments(repo, 2))
+	Equals(t, map[string]string{
+		"/api/v1/repos/lkysow/atlantis-example/issues/comments/25": common.OutdatedPlanComment(comments[1]["body"].(string), true),
+	}, updated)
+}
+
+// Only each reviewer's latest review should count and dismissed approvals
+// shouldn't count.
+func TestClient_GetApprovals(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2/reviews?page=1&limit=50":
+			writeFixture(t, w, "pull-reviews.json")
+		case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2/reviews?page=2&limit=50":
+			w.Write([]byte(`[]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	approvals, err := newClient(t, testServer).GetApprovals(repo, models.PullRequest{Num: 2})
+	Ok(t, err)
+	Equals(t, []models.Approval{
+		{User: models.User{Username: "reviewer"}, CommitSHA: "b52b8e254e956654dcdb394d0ccba9199f420427"},
+		{User: models.User{Username: "other"}, CommitSHA: "0a338874369017deba7c22e99e6000932724282f"},
+	}, approvals)
+}
+
+func TestClient_PullIsMergeable(t *testing.T) {
+	for _, mergeable := range []bool{true, false} {
+		t.Run(strconv.FormatBool(mergeable), func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.RequestURI {
+				case "GET /api/v1/repos/lkysow/atlantis-example/pulls/2":
+					var pull map[string]interface{}
+					fixture, err := ioutil.ReadFile(filepath.Join("testdata", "pull.json"))
+					Ok(t, err)
+					Ok(t, json.Unmarshal(fixture, &pull))
+					pull["mergeable"] = mergeable
+					Ok(t, json.NewEncoder(w).Encode(pull))
+				default:
+					t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			actual, err := newClient(t, testServer).PullIsMergeable(repo, models.PullRequest{Num: 2})
+			Ok(t, err)
+			Equals(t, mergeable, actual)
+		})
+	}
+}
+
+func TestClient_GetCommitChecks(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/repos/lkysow/atlantis-example/commits/sha/status?page=1&limit=50":
+			writeFixture(t, w, "combined-status.json")
+		case "GET /api/v1/repos/lkysow/atlantis-example/commits/sha/status?page=2&limit=50":
+			w.Write([]byte(`{"state": "failure", "statuses": []}`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	checks, err := newClient(t, testServer).GetCommitChecks(repo, models.PullRequest{Num: 2, HeadCommit: "sha"})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "ci/build", State: models.SuccessCommitStatus},
+		{Name: "ci/lint", State: models.FailedCommitStatus},
+		{Name: "atlantis/plan", State: models.PendingCommitStatus},
+	}, checks)
+}
+
+func TestClient_UpdateStatus(t *testing.T) {
+	cases := []struct {
+		state    models.CommitStatus
+		expState string
+	}{
+		{models.PendingCommitStatus, "pending"},
+		{models.SuccessCommitStatus, "success"},
+		{models.FailedCommitStatus, "failure"},
+	}
+	for _, c := range cases {
+		t.Run(c.expState, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.RequestURI {
+				case "POST /api/v1/repos/lkysow/atlantis-example/statuses/sha":
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					Equals(t, `{"context":"atlantis/plan","description":"Plan succeeded.","state":"`+c.expState+`","target_url":"https://atlantis.example.com"}`, string(body))
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"id": 4}`)) // nolint: errcheck
+				default:
+					t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			err := newClient(t, testServer).UpdateStatus(repo, models.PullRequest{Num: 2, HeadCommit: "sha"}, c.state, "atlantis/plan", "Plan succeeded.", "https://atlantis.example.com")
+			Ok(t, err)
+		})
+	}
+}
+
+func TestClient_MergePull(t *testing.T) {
+	cases := []struct {
+		description string
+		opts        models.PullRequestOptions
+		expBody     string
+	}{
+		{
+			description: "defaults",
+			opts:        models.PullRequestOptions{},
+			expBody:     `{"Do":"merge","MergeMessageField":"[Atlantis] Automatically merging after successful apply","delete_branch_after_merge":false}`,
+		},
+		{
+			description: "squash and delete source branch",
+			opts: models.PullRequestOptions{
+				MergeMethod:               "squash",
+				DeleteSourceBranchOnMerge: true,
+				CommitMessage:             "Merge #2",
+			},
+			expBody: `{"Do":"squash","MergeMessageField":"Merge #2","delete_branch_after_merge":true}`,
+		},
+		{
+			description: "rebase",
+			opts:        models.PullRequestOptions{MergeMethod: "rebase"},
+			expBody:     `{"Do":"rebase","MergeMessageField":"[Atlantis] Automatically merging after successful apply","delete_branch_after_merge":false}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method + " " + r.RequestURI {
+				case "POST /api/v1/repos/lkysow/atlantis-example/pulls/2/merge":
+					body, err := ioutil.ReadAll(r.Body)
+					Ok(t, err)
+					Equals(t, c.expBody, string(body))
+				default:
+					t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+				}
+			}))
+			defer testServer.Close()
+
+			Ok(t, newClient(t, testServer).MergePull(models.PullRequest{Num: 2, BaseRepo: repo}, c.opts))
+		})
+	}
+}
+
+func TestClient_MarkdownPullLink(t *testing.T) {
+	client, err := gitea.NewClient(http.DefaultClient, "token", gitea.BaseURL)
+	Ok(t, err)
+	s, _ := client.MarkdownPullLink(models.PullRequest{Num: 1})
+	Equals(t, "#1", s)
+}
+
+func TestClient_UserIsTeamMember(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/orgs/lkysow/teams/search?q=infra", "GET /api/v1/orgs/other/teams/search?q=infra":
+			writeFixture(t, w, "team-search.json")
+		case "GET /api/v1/orgs/lkysow/teams/search?q=missing":
+			w.Write([]byte(`{"data": [], "ok": true}`)) // nolint: errcheck
+		case "GET /api/v1/teams/9/members?page=1&limit=50":
+			writeFixture(t, w, "team-members.json")
+		case "GET /api/v1/teams/9/members?page=2&limit=50":
+			w.Write([]byte(`[]`)) // nolint: errcheck
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+	client := newClient(t, testServer)
+
+	isMember, err := client.UserIsTeamMember(repo, models.User{Username: "reviewer"}, "infra")
+	Ok(t, err)
+	Equals(t, true, isMember)
+
+	isMember, err = client.UserIsTeamMember(repo, models.User{Username: "someone"}, "other/infra")
+	Ok(t, err)
+	Equals(t, false, isMember)
+
+	_, err = client.UserIsTeamMember(repo, models.User{Username: "reviewer"}, "missing")
+	ErrEquals(t, `team "missing" not found in organization "lkysow"`, err)
+}
+
+func TestClient_Ping(t *testing.T) {
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.RequestURI {
+		case "GET /api/v1/user":
+			if r.Header.Get("Authorization") != "token token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			writeFixture(t, w, "user.json")
+		default:
+			t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer testServer.Close()
+
+	client := newClient(t, testServer)
+	Ok(t, client.Ping())
+
+	client.Token = "wrong"
+	ErrContains(t, "unexpected status code: 401", client.Ping())
+}
@@ -0,0 +1,29 @@
+// Package gitea holds code for Gitea and Forgejo, which is a fork of Gitea
+// with the same API and webhooks.
+package gitea
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+const BaseURL = "https://gitea.com"
+
+// ParseWebHook parses payload into the event for eventType, the value of the
+// X-Gitea-Event header. It returns nil if eventType isn't supported.
+func ParseWebHook(eventType string, payload []byte) (interface{}, error) {
+	var event interface{}
+	switch eventType {
+	case PullRequestEventHeader:
+		event = &PullRequestEvent{}
+	case IssueCommentEventHeader:
+		event = &IssueCommentEvent{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, errors.Wrap(err, "parsing json")
+	}
+	return event, nil
+}
@@ -0,0 +1,42 @@
+package gitea_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestParseWebHook(t *testing.T) {
+	payload, err := ioutil.ReadFile(filepath.Join("..", "..", "testdata", "gitea-issue-comment-event.json"))
+	Ok(t, err)
+
+	event, err := gitea.ParseWebHook(gitea.IssueCommentEventHeader, payload)
+	Ok(t, err)
+	comment, ok := event.(*gitea.IssueCommentEvent)
+	Assert(t, ok, "expected *gitea.IssueCommentEvent but got %T", event)
+	Equals(t, "created", comment.Action)
+	Equals(t, "atlantis plan", *comment.Comment.Body)
+
+	payload, err = ioutil.ReadFile(filepath.Join("..", "..", "testdata", "gitea-pull-event-opened.json"))
+	Ok(t, err)
+	event, err = gitea.ParseWebHook(gitea.PullRequestEventHeader, payload)
+	Ok(t, err)
+	pull, ok := event.(*gitea.PullRequestEvent)
+	Assert(t, ok, "expected *gitea.PullRequestEvent but got %T", event)
+	Equals(t, "opened", pull.Action)
+}
+
+// Unsupported events should be ignored.
+func TestParseWebHook_Unsupported(t *testing.T) {
+	event, err := gitea.ParseWebHook("push", []byte(`{"ref": "refs/heads/main"}`))
+	Ok(t, err)
+	Assert(t, event == nil, "expected nil but got %v", event)
+}
+
+func TestParseWebHook_InvalidJSON(t *testing.T) {
+	_, err := gitea.ParseWebHook(gitea.PullRequestEventHeader, []byte(`{`))
+	ErrContains(t, "parsing json", err)
+}
@@ -0,0 +1,91 @@
+package gitea
+
+const (
+	PullRequestEventHeader  = "pull_request"
+	IssueCommentEventHeader = "issue_comment"
+)
+
+type PullRequestEvent struct {
+	Action      string       `json:"action"`
+	PullRequest *PullRequest `json:"pull_request,omitempty" validate:"required"`
+	Sender      *User        `json:"sender,omitempty" validate:"required"`
+}
+
+type IssueCommentEvent struct {
+	Action string `json:"action"`
+	// IsPull is true if the comment is on a pull request rather than an
+	// issue.
+	IsPull     bool        `json:"is_pull"`
+	Issue      *Issue      `json:"issue,omitempty" validate:"required"`
+	Comment    *Comment    `json:"comment,omitempty" validate:"required"`
+	Repository *Repository `json:"repository,omitempty" validate:"required"`
+}
+
+type PullRequest struct {
+	Number         *int        `json:"number,omitempty" validate:"required"`
+	HTMLURL        *string     `json:"html_url,omitempty" validate:"required"`
+	State          *string     `json:"state,omitempty" validate:"required"`
+	Merged         bool        `json:"merged"`
+	MergeCommitSHA *string     `json:"merge_commit_sha,omitempty"`
+	Mergeable      bool        `json:"mergeable"`
+	User           *User       `json:"user,omitempty" validate:"required"`
+	Labels         []Label     `json:"labels,omitempty"`
+	Head           *BranchMeta `json:"head,omitempty" validate:"required"`
+	Base           *BranchMeta `json:"base,omitempty" validate:"required"`
+}
+type BranchMeta struct {
+	Ref  *string     `json:"ref,omitempty" validate:"required"`
+	SHA  *string     `json:"sha,omitempty" validate:"required"`
+	Repo *Repository `json:"repo,omitempty" validate:"required"`
+}
+type Label struct {
+	Name string `json:"name"`
+}
+
+type Repository struct {
+	FullName *string `json:"full_name,omitempty" validate:"required"`
+	CloneURL *string `json:"clone_url,omitempty" validate:"required"`
+}
+
+type User struct {
+	Login *string `json:"login,omitempty" validate:"required"`
+}
+
+type Issue struct {
+	Number *int `json:"number,omitempty" validate:"required"`
+}
+
+type Comment struct {
+	ID   *int64  `json:"id,omitempty" validate:"required"`
+	Body *string `json:"body,omitempty" validate:"required"`
+	User *User   `json:"user,omitempty" validate:"required"`
+}
+
+type ChangedFile struct {
+	Filename *string `json:"filename,omitempty" validate:"required"`
+	// PreviousFilename is only set if the file was renamed.
+	PreviousFilename string `json:"previous_filename,omitempty"`
+}
+
+type Review struct {
+	User      *User   `json:"user,omitempty" validate:"required"`
+	State     *string `json:"state,omitempty" validate:"required"`
+	Dismissed bool    `json:"dismissed"`
+	CommitID  string  `json:"commit_id"`
+}
+
+type CombinedStatus struct {
+	Statuses []CommitStatus `json:"statuses"`
+}
+type CommitStatus struct {
+	Context *string `json:"context,omitempty" validate:"required"`
+	Status  *string `json:"status,omitempty" validate:"required"`
+}
+
+type TeamSearchResults struct {
+	Data []Team `json:"data"`
+}
+type Team struct {
+	ID   *int64  `json:"id,omitempty" validate:"required"`
+	Name *string `json:"name,omitempty" validate:"required"`
+}
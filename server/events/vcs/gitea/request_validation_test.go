@@ -0,0 +1,31 @@
+package gitea_test
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+const signedBody = `{"action":"created","is_pull":true}`
+
+func TestValidateSignature(t *testing.T) {
+	sig := "7047f16ac75bd313213d745a1f7fde8644ad918a07582674f2d0fd614bb6bd6a"
+	Ok(t, gitea.ValidateSignature([]byte(signedBody), sig, []byte("mysecret")))
+}
+
+func TestValidateSignature_Invalid(t *testing.T) {
+	sig := "7047f16ac75bd313213d745a1f7fde8644ad918a07582674f2d0fd614bb6bd6a"
+	err := gitea.ValidateSignature([]byte(signedBody), sig, []byte("othersecret"))
+	ErrEquals(t, "payload signature check failed", err)
+}
+
+func TestValidateSignature_Missing(t *testing.T) {
+	err := gitea.ValidateSignature([]byte(signedBody), "", []byte("mysecret"))
+	ErrEquals(t, "missing signature", err)
+}
+
+func TestValidateSignature_NotHex(t *testing.T) {
+	err := gitea.ValidateSignature([]byte(signedBody), "sha256=abc", []byte("mysecret"))
+	ErrContains(t, `error decoding signature "sha256=abc"`, err)
+}
@@ -16,8 +16,10 @@ package vcs
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs/common"
@@ -34,19 +36,26 @@ const maxCommentLength = 65536
 
 // GithubClient is used to perform GitHub actions.
 type GithubClient struct {
-	user           string
+	credentials    GithubCredentials
 	client         *github.Client
 	v4MutateClient *graphql.Client
 	ctx            context.Context
 }
 
-// NewGithubClient returns a valid GitHub client.
+// NewGithubClient returns a valid GitHub client that authenticates as user
+// with the token pass.
 func NewGithubClient(hostname string, user string, pass string) (*GithubClient, error) {
-	tp := github.BasicAuthTransport{
-		Username: strings.TrimSpace(user),
-		Password: strings.TrimSpace(pass),
+	return NewGithubClientWithCredentials(hostname, &GithubUserCredentials{User: user, Token: pass})
+}
+
+// NewGithubClientWithCredentials returns a valid GitHub client that
+// authenticates with credentials.
+func NewGithubClientWithCredentials(hostname string, credentials GithubCredentials) (*GithubClient, error) {
+	httpClient, err := credentials.Client()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating GitHub http client")
 	}
-	client := github.NewClient(tp.Client())
+	client := github.NewClient(httpClient)
 	graphqlURL := "https://api.github.com/graphql"
 
 	// If we're using github.com then we don't need to do any additional configuration
@@ -75,12 +84,12 @@ func NewGithubClient(hostname string, user string, pass string) (*GithubClient,
 	// shurcooL's libraries completely.
 	v4MutateClient := graphql.NewClient(
 		graphqlURL,
-		tp.Client(),
+		httpClient,
 		graphql.WithHeader("Accept", "application/vnd.github.queen-beryl-preview+json"),
 	)
 
 	return &GithubClient{
-		user:           user,
+		credentials:    credentials,
 		client:         client,
 		v4MutateClient: v4MutateClient,
 		ctx:            context.Background(),
@@ -139,6 +148,49 @@ func (g *GithubClient) CreateComment(repo models.Repo, pullNum int, comment stri
 	return nil
 }
 
+// FindComment returns the most recent comment on the pull request containing
+// marker. Only comments by the authenticated user are considered so that
+// others can't plant the marker.
+func (g *GithubClient) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	user, err := g.credentials.GetUser()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting GitHub user")
+	}
+	var found *models.PullComment
+	nextPage := 0
+	for {
+		comments, resp, err := g.client.Issues.ListComments(g.ctx, repo.Owner, repo.Name, pullNum, &github.IssueListCommentsOptions{
+			Sort:        "created",
+			Direction:   "asc",
+			ListOptions: github.ListOptions{Page: nextPage, PerPage: 100},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range comments {
+			// Usernames aren't case sensitive.
+			if comment.User == nil || !strings.EqualFold(comment.User.GetLogin(), user) {
+				continue
+			}
+			if strings.Contains(comment.GetBody(), marker) {
+				found = &models.PullComment{ID: comment.GetID(), Body: comment.GetBody()}
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return found, nil
+}
+
+// UpdateComment replaces the body of comment.
+func (g *GithubClient) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	body = common.TruncateComment(body, maxCommentLength)
+	_, _, err := g.client.Issues.EditComment(g.ctx, repo.Owner, repo.Name, comment.ID, &github.IssueComment{Body: &body})
+	return err
+}
+
 func (g *GithubClient) HidePrevPlanComments(repo models.Repo, pullNum int) error {
 	var allComments []*github.IssueComment
 	nextPage := 0
@@ -158,23 +210,18 @@ func (g *GithubClient) HidePrevPlanComments(repo models.Repo, pullNum int) error
 		nextPage = resp.NextPage
 	}
 
+	user, err := g.credentials.GetUser()
+	if err != nil {
+		return errors.Wrap(err, "getting GitHub user")
+	}
 	for _, comment := range allComments {
 		// Using a case insensitive compare here because usernames aren't case
 		// sensitive and users may enter their atlantis users with different
 		// cases.
-		if comment.User != nil && !strings.EqualFold(comment.User.GetLogin(), g.user) {
-			continue
-		}
-		// Crude filtering: The comment templates typically include the command name
-		// somewhere in the first line. It's a bit of an assumption, but seems like
-		// a reasonable one, given we've already filtered the comments by the
-		// configured Atlantis user.
-		body := strings.Split(comment.GetBody(), "\n")
-		if len(body) == 0 {
+		if comment.User != nil && !strings.EqualFold(comment.User.GetLogin(), user) {
 			continue
 		}
-		firstLine := strings.ToLower(body[0])
-		if !strings.Contains(firstLine, models.PlanCommand.String()) {
+		if !common.IsPlanComment(comment.GetBody()) {
 			continue
 		}
 		var m struct {
@@ -200,8 +247,12 @@ func (g *GithubClient) HidePrevPlanComments(repo models.Repo, pullNum int) error
 	return nil
 }
 
-// PullIsApproved returns true if the pull request was approved.
-func (g *GithubClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
+// GetApprovals returns an approval for each user whose latest review of the
+// pull request is an approval. Reviews that are only comments don't change a
+// user's state. Each approval is associated with the commit that was reviewed.
+func (g *GithubClient) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
+	var usernames []string
+	latestReviews := make(map[string]*github.PullRequestReview)
 	nextPage := 0
 	for {
 		opts := github.ListOptions{
@@ -212,19 +263,36 @@ func (g *GithubClient) PullIsApproved(repo models.Repo, pull models.PullRequest)
 		}
 		pageReviews, resp, err := g.client.PullRequests.ListReviews(g.ctx, repo.Owner, repo.Name, pull.Num, &opts)
 		if err != nil {
-			return false, errors.Wrap(err, "getting reviews")
+			return nil, errors.Wrap(err, "getting reviews")
 		}
+		// Reviews are returned in chronological order.
 		for _, review := range pageReviews {
-			if review != nil && review.GetState() == "APPROVED" {
-				return true, nil
+			if review == nil || review.GetState() == "COMMENTED" {
+				continue
+			}
+			username := review.GetUser().GetLogin()
+			if _, ok := latestReviews[username]; !ok {
+				usernames = append(usernames, username)
 			}
+			latestReviews[username] = review
 		}
 		if resp.NextPage == 0 {
 			break
 		}
 		nextPage = resp.NextPage
 	}
-	return false, nil
+
+	var approvals []models.Approval
+	for _, username := range usernames {
+		review := latestReviews[username]
+		if review.GetState() == "APPROVED" {
+			approvals = append(approvals, models.Approval{
+				User:      models.User{Username: username},
+				CommitSHA: review.GetCommitID(),
+			})
+		}
+	}
+	return approvals, nil
 }
 
 // PullIsMergeable returns true if the pull request is mergeable.
@@ -249,6 +317,117 @@ func (g *GithubClient) PullIsMergeable(repo models.Repo, pull models.PullRequest
 	return true, nil
 }
 
+// GetCommitChecks returns the commit statuses and check runs of the pull
+// request's head commit. GitHub only returns the latest status for each
+// context and the latest run of each check.
+func (g *GithubClient) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	var checks []models.CommitCheck
+	nextPage := 0
+	for {
+		opts := github.ListOptions{
+			PerPage: 100,
+		}
+		if nextPage != 0 {
+			opts.Page = nextPage
+		}
+		combined, resp, err := g.client.Repositories.GetCombinedStatus(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting commit statuses")
+		}
+		for _, status := range combined.Statuses {
+			state := models.PendingCommitStatus
+			switch status.GetState() {
+			case "success":
+				state = models.SuccessCommitStatus
+			case "failure", "error":
+				state = models.FailedCommitStatus
+			}
+			checks = append(checks, models.CommitCheck{Name: status.GetContext(), State: state})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+
+	nextPage = 0
+	for {
+		opts := github.ListCheckRunsOptions{
+			ListOptions: github.ListOptions{
+				PerPage: 100,
+			},
+		}
+		if nextPage != 0 {
+			opts.Page = nextPage
+		}
+		runs, resp, err := g.client.Checks.ListCheckRunsForRef(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting check runs")
+		}
+		for _, run := range runs.CheckRuns {
+			check := models.CommitCheck{Name: run.GetName(), State: models.PendingCommitStatus}
+			if run.GetStatus() == "completed" {
+				switch run.GetConclusion() {
+				case "success":
+					check.State = models.SuccessCommitStatus
+				case "neutral", "skipped":
+					check.State = models.SuccessCommitStatus
+					check.Skipped = true
+				default:
+					check.State = models.FailedCommitStatus
+				}
+			}
+			checks = append(checks, check)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+
+	// Mark the checks that branch protection requires. Required checks that
+	// haven't been reported yet are pending.
+	required, err := g.requiredStatusChecks(repo, pull.BaseBranch)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range required {
+		found := false
+		for i := range checks {
+			if checks[i].Name == name {
+				checks[i].Required = true
+				found = true
+			}
+		}
+		if !found {
+			checks = append(checks, models.CommitCheck{Name: name, State: models.PendingCommitStatus, Required: true})
+		}
+	}
+	return checks, nil
+}
+
+// requiredStatusChecks returns the names of the status checks that branch
+// protection requires to pass before merging into branch. The branch
+// endpoint is used since it only needs read access, unlike the branch
+// protection endpoints.
+func (g *GithubClient) requiredStatusChecks(repo models.Repo, branch string) ([]string, error) {
+	req, err := g.client.NewRequest("GET", fmt.Sprintf("repos/%s/%s/branches/%s", repo.Owner, repo.Name, url.PathEscape(branch)), nil)
+	if err != nil {
+		return nil, err
+	}
+	var b struct {
+		Protection struct {
+			RequiredStatusChecks struct {
+				Contexts []string `json:"contexts"`
+			} `json:"required_status_checks"`
+		} `json:"protection"`
+	}
+	if _, err := g.client.Do(g.ctx, req, &b); err != nil {
+		return nil, errors.Wrapf(err, "getting branch %s", branch)
+	}
+	return b.Protection.RequiredStatusChecks.Contexts, nil
+}
+
 // GetPullRequest returns the pull request.
 func (g *GithubClient) GetPullRequest(repo models.Repo, num int) (*github.PullRequest, error) {
 	pull, _, err := g.client.PullRequests.Get(g.ctx, repo.Owner, repo.Name, num)
@@ -278,25 +457,116 @@ func (g *GithubClient) UpdateStatus(repo models.Repo, pull models.PullRequest, s
 	return err
 }
 
-// MergePull merges the pull request.
-func (g *GithubClient) MergePull(pull models.PullRequest) error {
-	// Users can set their repo to disallow certain types of merging.
-	// We detect which types aren't allowed and use the type that is.
-	repo, _, err := g.client.Repositories.Get(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name)
-	if err != nil {
-		return errors.Wrap(err, "fetching repo info")
+// maxCheckRunTextLength is the maximum number of chars allowed in a check
+// run's summary by GitHub.
+const maxCheckRunTextLength = 65535
+
+// maxCheckRunAnnotations is the maximum number of annotations GitHub accepts
+// in one request.
+const maxCheckRunAnnotations = 50
+
+// UpdateCheckRun creates or updates a check run on the pull request's head
+// commit. See https://developer.github.com/v3/checks/runs/.
+func (g *GithubClient) UpdateCheckRun(repo models.Repo, pull models.PullRequest, run models.CheckRun) error {
+	status := "completed"
+	var conclusion *string
+	var completedAt *github.Timestamp
+	switch run.Status {
+	case models.PendingCommitStatus:
+		status = "in_progress"
+	case models.SuccessCommitStatus:
+		conclusion = github.String("success")
+		completedAt = &github.Timestamp{Time: time.Now()}
+	default:
+		conclusion = github.String("failure")
+		completedAt = &github.Timestamp{Time: time.Now()}
 	}
-	const (
-		defaultMergeMethod = "merge"
-		rebaseMergeMethod  = "rebase"
-		squashMergeMethod  = "squash"
-	)
-	method := defaultMergeMethod
-	if !repo.GetAllowMergeCommit() {
-		if repo.GetAllowRebaseMerge() {
-			method = rebaseMergeMethod
-		} else if repo.GetAllowSquashMerge() {
-			method = squashMergeMethod
+
+	summary := run.Summary
+	if len(summary) > maxCheckRunTextLength {
+		summary = summary[:maxCheckRunTextLength-len(checkRunTruncatedSuffix)] + checkRunTruncatedSuffix
+	}
+	output := &github.CheckRunOutput{
+		Title:   github.String(run.Title),
+		Summary: github.String(summary),
+	}
+	for i, a := range run.Annotations {
+		if i == maxCheckRunAnnotations {
+			break
+		}
+		output.Annotations = append(output.Annotations, &github.CheckRunAnnotation{
+			Path:            github.String(a.Path),
+			StartLine:       github.Int(a.Line),
+			EndLine:         github.Int(a.Line),
+			AnnotationLevel: github.String("failure"),
+			Title:           github.String(a.Title),
+			Message:         github.String(a.Message),
+		})
+	}
+	var detailsURL *string
+	if run.DetailsURL != "" {
+		detailsURL = github.String(run.DetailsURL)
+	}
+
+	// A pending run means the command was started again so we start a new
+	// check run rather than updating the results of the last one.
+	if run.Status != models.PendingCommitStatus {
+		existing, _, err := g.client.Checks.ListCheckRunsForRef(g.ctx, repo.Owner, repo.Name, pull.HeadCommit, &github.ListCheckRunsOptions{
+			CheckName: github.String(run.Name),
+			Filter:    github.String("latest"),
+		})
+		if err != nil {
+			return errors.Wrapf(err, "listing check runs for %s", pull.HeadCommit)
+		}
+		if len(existing.CheckRuns) > 0 {
+			_, _, err = g.client.Checks.UpdateCheckRun(g.ctx, repo.Owner, repo.Name, existing.CheckRuns[0].GetID(), github.UpdateCheckRunOptions{
+				Name:        run.Name,
+				DetailsURL:  detailsURL,
+				ExternalID:  github.String(run.ExternalID),
+				Status:      github.String(status),
+				Conclusion:  conclusion,
+				CompletedAt: completedAt,
+				Output:      output,
+			})
+			return err
+		}
+	}
+	_, _, err := g.client.Checks.CreateCheckRun(g.ctx, repo.Owner, repo.Name, github.CreateCheckRunOptions{
+		Name:        run.Name,
+		HeadBranch:  pull.HeadBranch,
+		HeadSHA:     pull.HeadCommit,
+		DetailsURL:  detailsURL,
+		ExternalID:  github.String(run.ExternalID),
+		Status:      github.String(status),
+		Conclusion:  conclusion,
+		CompletedAt: completedAt,
+		Output:      output,
+	})
+	return err
+}
+
+// checkRunTruncatedSuffix is appended to check run summaries that were
+// truncated.
+const checkRunTruncatedSuffix = "\n```\n\n**Warning**: Output truncated."
+
+// MergePull merges the pull request. If opts doesn't specify a merge method,
+// we use the first method the repo allows out of merge, rebase and squash.
+func (g *GithubClient) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
+	method := opts.MergeMethod
+	if method == "" {
+		// Users can set their repo to disallow certain types of merging.
+		// We detect which types aren't allowed and use the type that is.
+		repo, _, err := g.client.Repositories.Get(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name)
+		if err != nil {
+			return errors.Wrap(err, "fetching repo info")
+		}
+		method = models.MergeMergeMethod
+		if !repo.GetAllowMergeCommit() {
+			if repo.GetAllowRebaseMerge() {
+				method = models.RebaseMergeMethod
+			} else if repo.GetAllowSquashMerge() {
+				method = models.SquashMergeMethod
+			}
 		}
 	}
 
@@ -309,7 +579,7 @@ func (g *GithubClient) MergePull(pull models.PullRequest) error {
 		pull.BaseRepo.Owner,
 		pull.BaseRepo.Name,
 		pull.Num,
-		common.AutomergeCommitMsg,
+		common.MergeCommitMsg(opts),
 		options)
 	if err != nil {
 		return errors.Wrap(err, "merging pull request")
@@ -317,10 +587,60 @@ func (g *GithubClient) MergePull(pull models.PullRequest) error {
 	if !mergeResult.GetMerged() {
 		return fmt.Errorf("could not merge pull request: %s", mergeResult.GetMessage())
 	}
+
+	if opts.DeleteSourceBranchOnMerge {
+		return g.deleteHeadBranch(pull)
+	}
 	return nil
 }
 
+// deleteHeadBranch deletes the head branch of pull. Branches of pull requests
+// from forks aren't deleted since they're in a different repo.
+func (g *GithubClient) deleteHeadBranch(pull models.PullRequest) error {
+	ghPull, err := g.GetPullRequest(pull.BaseRepo, pull.Num)
+	if err != nil {
+		return errors.Wrap(err, "getting pull request")
+	}
+	if ghPull.GetHead().GetRepo().GetFullName() != pull.BaseRepo.FullName {
+		return nil
+	}
+	_, err = g.client.Git.DeleteRef(g.ctx, pull.BaseRepo.Owner, pull.BaseRepo.Name, "heads/"+ghPull.GetHead().GetRef())
+	return errors.Wrapf(err, "deleting branch %s", ghPull.GetHead().GetRef())
+}
+
+// Ping makes a lightweight authenticated API call to verify that GitHub is
+// reachable and our credentials are valid. It gets the rate limits since,
+// unlike getting the authenticated user, that works with GitHub App
+// installation tokens and doesn't count against the rate limit.
+func (g *GithubClient) Ping() error {
+	_, _, err := g.client.RateLimits(g.ctx)
+	return err
+}
+
 // MarkdownPullLink specifies the string used in a pull request comment to reference another pull request.
 func (g *GithubClient) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return fmt.Sprintf("#%d", pull.Num), nil
 }
+
+// UserIsTeamMember returns true if user is an active member of team. team is
+// either the slug of a team in repo's organization or {org}/{team-slug}.
+// Membership of child teams counts as membership of the parent.
+func (g *GithubClient) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	org, slug := repo.Owner, team
+	if i := strings.Index(team, "/"); i >= 0 {
+		org, slug = team[:i], team[i+1:]
+	}
+	t, _, err := g.client.Teams.GetTeamBySlug(g.ctx, org, slug)
+	if err != nil {
+		return false, errors.Wrapf(err, "getting team %s/%s", org, slug)
+	}
+	membership, resp, err := g.client.Teams.GetTeamMembership(g.ctx, t.GetID(), user.Username)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrapf(err, "getting membership of %s in team %s/%s", user.Username, org, slug)
+	}
+	// Users who have been invited but haven't accepted have state pending.
+	return membership.GetState() == "active", nil
+}
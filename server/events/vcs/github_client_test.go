@@ -152,7 +152,8 @@ func TestGithubClient_PaginatesComments(t *testing.T) {
 ]`,
 		`[
 	{"node_id": "7", "body": "asd", "user": {"login": "user"}},
-	{"node_id": "8", "body": "asd plan \n asd", "user": {"login": "user"}}
+	{"node_id": "8", "body": "asd plan \n asd", "user": {"login": "user"}},
+	{"node_id": "9", "body": "[//]: # (atlantis-sticky-comment:plan)\nRan Plan", "user": {"login": "user"}}
 ]`,
 	}
 	minimizeResp := "{}"
@@ -380,7 +381,7 @@ func TestGithubClient_UpdateStatus(t *testing.T) {
 	}
 }
 
-func TestGithubClient_PullIsApproved(t *testing.T) {
+func TestGithubClient_GetApprovals(t *testing.T) {
 	respTemplate := `[
 		{
 			"id": %d,
@@ -448,7 +449,7 @@ func TestGithubClient_PullIsApproved(t *testing.T) {
 	Ok(t, err)
 	defer disableSSLVerification()()
 
-	approved, err := client.PullIsApproved(models.Repo{
+	approvals, err := client.GetApprovals(models.Repo{
 		FullName:          "owner/repo",
 		Owner:             "owner",
 		Name:              "repo",
@@ -462,7 +463,125 @@ func TestGithubClient_PullIsApproved(t *testing.T) {
 		Num: 1,
 	})
 	Ok(t, err)
-	Equals(t, false, approved)
+	Equals(t, 0, len(approvals))
+}
+
+// Test that only users whose latest review is an approval have approved, that
+// comments don't reset a user's review state and that approvals are associated
+// with the reviewed commit.
+func TestGithubClient_GetApprovals_LatestReview(t *testing.T) {
+	reviewTemplate := `{"id": %d, "user": {"login": %q}, "state": %q, "commit_id": %q}`
+	firstResp := "[" + strings.Join([]string{
+		fmt.Sprintf(reviewTemplate, 1, "alice", "APPROVED", "sha1"),
+		fmt.Sprintf(reviewTemplate, 2, "bob", "APPROVED", "sha1"),
+		fmt.Sprintf(reviewTemplate, 3, "carol", "CHANGES_REQUESTED", "sha1"),
+	}, ",") + "]"
+	secondResp := "[" + strings.Join([]string{
+		fmt.Sprintf(reviewTemplate, 4, "alice", "COMMENTED", "sha2"),
+		fmt.Sprintf(reviewTemplate, 5, "bob", "DISMISSED", "sha2"),
+		fmt.Sprintf(reviewTemplate, 6, "carol", "APPROVED", "sha2"),
+	}, ",") + "]"
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/pulls/1/reviews?per_page=300":
+				w.Header().Add("Link", `<https://api.github.com/resource?page=2>; rel="next",
+      <https://api.github.com/resource?page=2>; rel="last"`)
+				w.Write([]byte(firstResp)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/pulls/1/reviews?page=2&per_page=300":
+				w.Write([]byte(secondResp)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	approvals, err := client.GetApprovals(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.Github,
+			Hostname: "github.com",
+		},
+	}, models.PullRequest{
+		Num: 1,
+	})
+	Ok(t, err)
+	Equals(t, []models.Approval{
+		{User: models.User{Username: "alice"}, CommitSHA: "sha1"},
+		{User: models.User{Username: "carol"}, CommitSHA: "sha2"},
+	}, approvals)
+}
+
+func TestGithubClient_GetCommitChecks(t *testing.T) {
+	statusResp := `{"state": "failure", "statuses": [
+		{"context": "ci/build", "state": "success"},
+		{"context": "ci/lint", "state": "error"},
+		{"context": "atlantis/plan", "state": "pending"}
+	]}`
+	checkRunsResp := `{"total_count": 3, "check_runs": [
+		{"name": "test", "status": "completed", "conclusion": "success"},
+		{"name": "docs", "status": "completed", "conclusion": "skipped"},
+		{"name": "e2e", "status": "in_progress"},
+		{"name": "security", "status": "completed", "conclusion": "timed_out"},
+		{"name": "optional", "status": "completed", "conclusion": "neutral"}
+	]}`
+	branchResp := `{"name": "master", "protected": true, "protection": {"enabled": true,
+		"required_status_checks": {"enforcement_level": "non_admins", "contexts": ["ci/build", "test", "deploy"]}}}`
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v3/repos/owner/repo/commits/sha/status?per_page=100":
+				w.Write([]byte(statusResp)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/commits/sha/check-runs?per_page=100":
+				w.Write([]byte(checkRunsResp)) // nolint: errcheck
+			case "/api/v3/repos/owner/repo/branches/master":
+				w.Write([]byte(branchResp)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	checks, err := client.GetCommitChecks(models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Type:     models.Github,
+			Hostname: "github.com",
+		},
+	}, models.PullRequest{
+		Num:        1,
+		HeadCommit: "sha",
+		BaseBranch: "master",
+	})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "ci/build", State: models.SuccessCommitStatus, Required: true},
+		{Name: "ci/lint", State: models.FailedCommitStatus},
+		{Name: "atlantis/plan", State: models.PendingCommitStatus},
+		{Name: "test", State: models.SuccessCommitStatus, Required: true},
+		{Name: "docs", State: models.SuccessCommitStatus, Skipped: true},
+		{Name: "e2e", State: models.PendingCommitStatus},
+		{Name: "security", State: models.FailedCommitStatus},
+		{Name: "optional", State: models.SuccessCommitStatus, Skipped: true},
+		// Required checks that haven't been reported are pending.
+		{Name: "deploy", State: models.PendingCommitStatus, Required: true},
+	}, checks)
 }
 
 func TestGithubClient_PullIsMergeable(t *testing.T) {
@@ -635,7 +754,7 @@ func TestGithubClient_MergePullHandlesError(t *testing.T) {
 						},
 					},
 					Num: 1,
-				})
+				}, models.PullRequestOptions{})
 
 			if c.expErr == "" {
 				Ok(t, err)
@@ -758,8 +877,76 @@ func TestGithubClient_MergePullCorrectMethod(t *testing.T) {
 						},
 					},
 					Num: 1,
+				}, models.PullRequestOptions{})
+			Ok(t, err)
+		})
+	}
+}
+
+// Test that the merge method and commit message in the options are used and
+// that the head branch is deleted after merging.
+func TestGithubClient_MergePullWithOptions(t *testing.T) {
+	cases := map[string]struct {
+		headRepo  string
+		expDelete bool
+	}{
+		"same repo": {
+			headRepo:  "owner/repo",
+			expDelete: true,
+		},
+		"fork": {
+			headRepo:  "fork/repo",
+			expDelete: false,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			deleted := false
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.Method + " " + r.RequestURI {
+					case "PUT /api/v3/repos/owner/repo/pulls/1/merge":
+						body, err := ioutil.ReadAll(r.Body)
+						Ok(t, err)
+						Equals(t, "{\"commit_message\":\"Merge #1\",\"merge_method\":\"squash\"}\n", string(body))
+						w.Write([]byte(`{"merged":true}`)) // nolint: errcheck
+					case "GET /api/v3/repos/owner/repo/pulls/1":
+						fmt.Fprintf(w, `{"head": {"ref": "branch", "repo": {"full_name": %q}}}`, c.headRepo) // nolint: errcheck
+					case "DELETE /api/v3/repos/owner/repo/git/refs/heads%2Fbranch":
+						deleted = true
+						w.WriteHeader(http.StatusNoContent)
+					default:
+						t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			err = client.MergePull(
+				models.PullRequest{
+					BaseRepo: models.Repo{
+						FullName: "owner/repo",
+						Owner:    "owner",
+						Name:     "repo",
+						VCSHost: models.VCSHost{
+							Type:     models.Github,
+							Hostname: "github.com",
+						},
+					},
+					Num: 1,
+				}, models.PullRequestOptions{
+					MergeMethod:               "squash",
+					DeleteSourceBranchOnMerge: true,
+					CommitMessage:             "Merge #1",
 				})
 			Ok(t, err)
+			Equals(t, c.expDelete, deleted)
 		})
 	}
 }
@@ -773,6 +960,49 @@ func TestGithubClient_MarkdownPullLink(t *testing.T) {
 	Equals(t, exp, s)
 }
 
+func TestGithubClient_UserIsTeamMember(t *testing.T) {
+	cases := []struct {
+		team      string
+		user      string
+		expMember bool
+	}{
+		{"infra", "active-user", true},
+		{"other-org/infra", "active-user", true},
+		{"infra", "pending-user", false},
+		{"infra", "non-member", false},
+	}
+	for _, c := range cases {
+		t.Run(c.team+" "+c.user, func(t *testing.T) {
+			testServer := httptest.NewTLSServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					switch r.RequestURI {
+					case "/api/v3/orgs/owner/teams/infra", "/api/v3/orgs/other-org/teams/infra":
+						w.Write([]byte(`{"id": 1, "slug": "infra"}`)) // nolint: errcheck
+					case "/api/v3/teams/1/memberships/active-user":
+						w.Write([]byte(`{"state": "active", "role": "member"}`)) // nolint: errcheck
+					case "/api/v3/teams/1/memberships/pending-user":
+						w.Write([]byte(`{"state": "pending", "role": "member"}`)) // nolint: errcheck
+					case "/api/v3/teams/1/memberships/non-member":
+						http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+					default:
+						t.Errorf("got unexpected request at %q", r.RequestURI)
+						http.Error(w, "not found", http.StatusNotFound)
+					}
+				}))
+			defer testServer.Close()
+			testServerURL, err := url.Parse(testServer.URL)
+			Ok(t, err)
+			client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+			Ok(t, err)
+			defer disableSSLVerification()()
+
+			isMember, err := client.UserIsTeamMember(models.Repo{Owner: "owner"}, models.User{Username: c.user}, c.team)
+			Ok(t, err)
+			Equals(t, c.expMember, isMember)
+		})
+	}
+}
+
 // disableSSLVerification disables ssl verification for the global http client
 // and returns a function to be called in a defer that will re-enable it.
 func disableSSLVerification() func() {
@@ -783,3 +1013,151 @@ func disableSSLVerification() func() {
 		http.DefaultTransport.(*http.Transport).TLSClientConfig = orig
 	}
 }
+
+func TestGithubClient_Ping(t *testing.T) {
+	for _, c := range []struct {
+		status int
+		expErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusUnauthorized, true},
+	} {
+		testServer := httptest.NewTLSServer(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.RequestURI {
+				case "/api/v3/rate_limit":
+					w.WriteHeader(c.status)
+					w.Write([]byte(`{"resources": {"core": {"limit": 5000, "remaining": 4999}}}`)) // nolint: errcheck
+					return
+				default:
+					t.Errorf("got unexpected request at %q", r.RequestURI)
+					http.Error(w, "not found", http.StatusNotFound)
+					return
+				}
+			}))
+
+		testServerURL, err := url.Parse(testServer.URL)
+		Ok(t, err)
+		client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+		Ok(t, err)
+		defer disableSSLVerification()()
+
+		err = client.Ping()
+		Equals(t, c.expErr, err != nil)
+		testServer.Close()
+	}
+}
+
+// Pending check runs should start a new check run and completed check runs
+// should update the latest one.
+func TestGithubClient_UpdateCheckRun(t *testing.T) {
+	var created, updated map[string]interface{}
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "POST /api/v3/repos/owner/repo/check-runs":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				Ok(t, json.Unmarshal(body, &created))
+				w.Write([]byte(`{"id": 5}`)) // nolint: errcheck
+			case "GET /api/v3/repos/owner/repo/commits/sha/check-runs?check_name=atlantis%2Fplan%3A+project1&filter=latest":
+				w.Write([]byte(`{"total_count": 1, "check_runs": [{"id": 5}]}`)) // nolint: errcheck
+			case "PATCH /api/v3/repos/owner/repo/check-runs/5":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				Ok(t, json.Unmarshal(body, &updated))
+				w.Write([]byte(`{"id": 5}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+	Ok(t, err)
+	defer disableSSLVerification()()
+
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+	pull := models.PullRequest{Num: 1, HeadCommit: "sha", HeadBranch: "branch"}
+	err = client.UpdateCheckRun(repo, pull, models.CheckRun{
+		Name:       "atlantis/plan: project1",
+		Status:     models.PendingCommitStatus,
+		DetailsURL: "https://atlantis/jobs/1",
+		ExternalID: "id",
+		Title:      "Plan in progress...",
+		Summary:    "Plan in progress...",
+	})
+	Ok(t, err)
+	Equals(t, "atlantis/plan: project1", created["name"])
+	Equals(t, "sha", created["head_sha"])
+	Equals(t, "in_progress", created["status"])
+	Equals(t, "https://atlantis/jobs/1", created["details_url"])
+	Equals(t, nil, created["conclusion"])
+
+	err = client.UpdateCheckRun(repo, pull, models.CheckRun{
+		Name:       "atlantis/plan: project1",
+		Status:     models.FailedCommitStatus,
+		ExternalID: "id",
+		Title:      "Plan failed.",
+		Summary:    "error",
+		Annotations: []models.CheckRunAnnotation{
+			{Path: "dir/main.tf", Line: 3, Title: "Unsupported argument", Message: "Error: Unsupported argument"},
+		},
+	})
+	Ok(t, err)
+	Equals(t, "completed", updated["status"])
+	Equals(t, "failure", updated["conclusion"])
+	Equals(t, map[string]interface{}{
+		"title":   "Plan failed.",
+		"summary": "error",
+		"annotations": []interface{}{
+			map[string]interface{}{
+				"path":             "dir/main.tf",
+				"start_line":       float64(3),
+				"end_line":         float64(3),
+				"annotation_level": "failure",
+				"title":            "Unsupported argument",
+				"message":          "Error: Unsupported argument",
+			},
+		},
+	}, updated["output"])
+}
+
+func TestGithubClient_FindAndUpdateComment(t *testing.T) {
+	var edited string
+	testServer := httptest.NewTLSServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /api/v3/repos/owner/repo/issues/1/comments?direction=asc&per_page=100&sort=created":
+				w.Header().Set("Link", `<https://api.github.com/repos/owner/repo/issues/1/comments?page=2>; rel="next"`)
+				w.Write([]byte(`[{"id": 1, "body": "marker", "user": {"login": "user"}}, {"id": 2, "body": "other", "user": {"login": "user"}}]`)) // nolint: errcheck
+			case "GET /api/v3/repos/owner/repo/issues/1/comments?direction=asc&page=2&per_page=100&sort=created":
+				// Comments by other users with the marker should be ignored.
+				w.Write([]byte(`[{"id": 3, "body": "with marker", "user": {"login": "User"}}, {"id": 4, "body": "other", "user": {"login": "user"}}, {"id": 5, "body": "marker", "user": {"login": "attacker"}}]`)) // nolint: errcheck
+			case "PATCH /api/v3/repos/owner/repo/issues/comments/3":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				edited = string(body)
+				w.Write([]byte(`{"id": 3}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+	testServerURL, err := url.Parse(testServer.URL)
+	Ok(t, err)
+	client, err := vcs.NewGithubClient(testServerURL.Host, "user", "pass")
+	Ok(t, err)
+	defer disableSSLVerification()()
+	repo := models.Repo{FullName: "owner/repo", Owner: "owner", Name: "repo"}
+
+	comment, err := client.FindComment(repo, 1, "marker")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 3, Body: "with marker"}, comment)
+
+	Ok(t, client.UpdateComment(repo, 1, *comment, "new body"))
+	Equals(t, `{"body":"new body"}`+"\n", edited)
+}
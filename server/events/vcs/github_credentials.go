@@ -0,0 +1,324 @@
+package vcs
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v28/github"
+	"github.com/pkg/errors"
+)
+
+// githubAppTokenRefreshWindow is how long before an installation token expires
+// that we fetch a new one. It's long enough that a token handed to git won't
+// expire during a clone.
+const githubAppTokenRefreshWindow = 5 * time.Minute
+
+// GithubCredentials provides the credentials used to authenticate with GitHub.
+type GithubCredentials interface {
+	// Client returns an http client that authenticates its requests.
+	Client() (*http.Client, error)
+	// GetToken returns a token that can be used to authenticate git
+	// commands over HTTPS.
+	GetToken() (string, error)
+	// GetUser returns the login of the GitHub user Atlantis is running as.
+	GetUser() (string, error)
+}
+
+// GithubUserCredentials authenticates as a GitHub user with a personal
+// access token.
+type GithubUserCredentials struct {
+	User  string
+	Token string
+}
+
+// Client returns a client that uses basic auth.
+func (c *GithubUserCredentials) Client() (*http.Client, error) {
+	tp := github.BasicAuthTransport{
+		Username: strings.TrimSpace(c.User),
+		Password: strings.TrimSpace(c.Token),
+	}
+	return tp.Client(), nil
+}
+
+// GetToken returns the user's token.
+func (c *GithubUserCredentials) GetToken() (string, error) {
+	return c.Token, nil
+}
+
+// GetUser returns the user's login.
+func (c *GithubUserCredentials) GetUser() (string, error) {
+	return c.User, nil
+}
+
+// GithubAppCredentials authenticates as an installation of a GitHub App.
+// It signs JWTs with the app's private key and exchanges them for
+// installation tokens. Installation tokens expire after an hour so they're
+// cached and refreshed shortly before they expire.
+type GithubAppCredentials struct {
+	AppID    int64
+	Key      *rsa.PrivateKey
+	Hostname string
+	// InstallationID is the installation to authenticate as. If 0, the app
+	// must be installed exactly once and that installation is used.
+	InstallationID int64
+	// AppSlug is the URL-friendly name of the app. If empty, it's looked up
+	// using the app's JWT.
+	AppSlug string
+
+	// mu guards the fields above and the cached token. It's never held while
+	// calling GitHub so requests with a current token aren't blocked.
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	// refreshMu is held while fetching a new token so only one is fetched at
+	// a time.
+	refreshMu sync.Mutex
+}
+
+// NewGithubAppCredentials returns credentials for the app with ID appID and
+// the PEM encoded private key keyPEM.
+func NewGithubAppCredentials(hostname string, appID int64, keyPEM []byte, appSlug string) (*GithubAppCredentials, error) {
+	key, err := ParseGithubAppKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &GithubAppCredentials{
+		AppID:    appID,
+		Key:      key,
+		Hostname: hostname,
+		AppSlug:  appSlug,
+	}, nil
+}
+
+// ParseGithubAppKey parses a PEM encoded RSA private key as downloaded from
+// the GitHub App's settings page.
+func ParseGithubAppKey(keyPEM []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, errors.New("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing GitHub App private key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// Client returns a client that authenticates as the app's installation.
+func (c *GithubAppCredentials) Client() (*http.Client, error) {
+	return &http.Client{Transport: &githubTokenTransport{creds: c}}, nil
+}
+
+// GetToken returns an installation token, fetching a new one if the cached
+// token expires soon.
+func (c *GithubAppCredentials) GetToken() (string, error) {
+	if token, ok := c.cachedToken(); ok {
+		return token, nil
+	}
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	// Another caller may have fetched a token while we were waiting.
+	if token, ok := c.cachedToken(); ok {
+		return token, nil
+	}
+
+	client, err := c.appClient()
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	c.mu.Lock()
+	installationID := c.InstallationID
+	c.mu.Unlock()
+	if installationID == 0 {
+		installations, _, err := client.Apps.ListInstallations(ctx, nil)
+		if err != nil {
+			return "", errors.Wrap(err, "listing GitHub App installations")
+		}
+		if len(installations) != 1 {
+			return "", fmt.Errorf("expected GitHub App to be installed once but it was installed %d times", len(installations))
+		}
+		installationID = installations[0].GetID()
+	}
+	token, _, err := client.Apps.CreateInstallationToken(ctx, installationID, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "creating token for GitHub App installation %d", installationID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.InstallationID = installationID
+	c.token = token.GetToken()
+	c.expiresAt = token.GetExpiresAt()
+	return c.token, nil
+}
+
+// cachedToken returns the cached installation token and true if it doesn't
+// expire soon.
+func (c *GithubAppCredentials) cachedToken() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Until(c.expiresAt) > githubAppTokenRefreshWindow {
+		return c.token, true
+	}
+	return "", false
+}
+
+// GetUser returns the login of the app's bot user, ex. "atlantis[bot]".
+func (c *GithubAppCredentials) GetUser() (string, error) {
+	c.mu.Lock()
+	slug := c.AppSlug
+	c.mu.Unlock()
+	if slug != "" {
+		return slug + "[bot]", nil
+	}
+
+	client, err := c.appClient()
+	if err != nil {
+		return "", err
+	}
+	// go-github's App type doesn't include the slug so we make the
+	// request ourselves.
+	req, err := client.NewRequest("GET", "app", nil)
+	if err != nil {
+		return "", err
+	}
+	var app struct {
+		Slug string `json:"slug"`
+	}
+	if _, err := client.Do(context.Background(), req, &app); err != nil {
+		return "", errors.Wrap(err, "getting GitHub App")
+	}
+	c.mu.Lock()
+	c.AppSlug = app.Slug
+	c.mu.Unlock()
+	return app.Slug + "[bot]", nil
+}
+
+// appClient returns a client that authenticates as the app itself. It can
+// only be used for the app's own endpoints.
+func (c *GithubAppCredentials) appClient() (*github.Client, error) {
+	jwt, err := c.signJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return newGithubAPIClient(&http.Client{Transport: &bearerTransport{token: jwt}}, c.Hostname)
+}
+
+// signJWT returns a JWT for the app signed with RS256. GitHub rejects JWTs
+// that expire more than 10 minutes in the future so the JWT is only valid
+// for 9 minutes, and it's backdated to allow for clock drift.
+func (c *GithubAppCredentials) signJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]int64{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": c.AppID,
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", errors.Wrap(err, "signing GitHub App JWT")
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// githubTokenTransport authenticates requests with an installation token.
+type githubTokenTransport struct {
+	creds *GithubAppCredentials
+}
+
+func (t *githubTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.creds.GetToken()
+	if err != nil {
+		return nil, err
+	}
+	// RoundTrippers shouldn't modify the request so we clone it.
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "token "+token)
+	return http.DefaultTransport.RoundTrip(req2)
+}
+
+// bearerTransport authenticates requests with a JWT.
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(req2)
+}
+
+// GithubAppConfig is the configuration of a GitHub App created from a
+// manifest.
+type GithubAppConfig struct {
+	ID            int64  `json:"id"`
+	Slug          string `json:"slug"`
+	HTMLURL       string `json:"html_url"`
+	WebhookSecret string `json:"webhook_secret"`
+	PEM           string `json:"pem"`
+}
+
+// ExchangeGithubAppManifestCode completes the creation of a GitHub App from a
+// manifest. code is the temporary code GitHub passes to the manifest's
+// redirect URL. It returns the new app's ID, private key and webhook secret.
+// See https://developer.github.com/apps/building-github-apps/creating-github-apps-from-a-manifest/.
+func ExchangeGithubAppManifestCode(hostname string, code string) (*GithubAppConfig, error) {
+	client, err := newGithubAPIClient(nil, hostname)
+	if err != nil {
+		return nil, err
+	}
+	req, err := client.NewRequest("POST", fmt.Sprintf("app-manifests/%s/conversions", url.PathEscape(code)), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.fury-preview+json")
+	var app GithubAppConfig
+	if _, err := client.Do(context.Background(), req, &app); err != nil {
+		return nil, errors.Wrap(err, "converting GitHub App manifest code")
+	}
+	return &app, nil
+}
+
+// newGithubAPIClient returns a go-github client for hostname that sends
+// requests with httpClient.
+func newGithubAPIClient(httpClient *http.Client, hostname string) (*github.Client, error) {
+	client := github.NewClient(httpClient)
+	if hostname != "" && hostname != "github.com" {
+		baseURL := fmt.Sprintf("https://%s/api/v3/", hostname)
+		base, err := url.Parse(baseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid github hostname trying to parse %s", baseURL)
+		}
+		client.BaseURL = base
+	}
+	return client, nil
+}
@@ -139,20 +139,146 @@ func (g *GitlabClient) CreateComment(repo models.Repo, pullNum int, comment stri
 	return err
 }
 
+// FindComment returns the most recent note on the merge request containing
+// marker. System notes and notes by other users are ignored so that others
+// can't plant the marker.
+func (g *GitlabClient) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	user, _, err := g.Client.Users.CurrentUser()
+	if err != nil {
+		return nil, errors.Wrap(err, "getting GitLab user")
+	}
+	notes, err := g.listNotes(repo, pullNum)
+	if err != nil {
+		return nil, err
+	}
+	var found *models.PullComment
+	for _, note := range notes {
+		if !strings.EqualFold(note.Author.Username, user.Username) {
+			continue
+		}
+		if strings.Contains(note.Body, marker) {
+			found = &models.PullComment{ID: int64(note.ID), Body: note.Body}
+		}
+	}
+	return found, nil
+}
+
+// UpdateComment replaces the body of the note comment.
+func (g *GitlabClient) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	_, _, err := g.Client.Notes.UpdateMergeRequestNote(repo.FullName, pullNum, int(comment.ID), &gitlab.UpdateMergeRequestNoteOptions{Body: gitlab.String(body)})
+	return err
+}
+
+// HidePrevPlanComments collapses the plan notes Atlantis previously left on
+// the merge request. GitLab can't hide notes so they're edited instead. The
+// plan is kept in a collapsed section since it may be useful for auditing.
 func (g *GitlabClient) HidePrevPlanComments(repo models.Repo, pullNum int) error {
+	user, _, err := g.Client.Users.CurrentUser()
+	if err != nil {
+		return errors.Wrap(err, "getting GitLab user")
+	}
+	notes, err := g.listNotes(repo, pullNum)
+	if err != nil {
+		return err
+	}
+	for _, note := range notes {
+		if !strings.EqualFold(note.Author.Username, user.Username) || !common.IsPlanComment(note.Body) {
+			continue
+		}
+		body := common.OutdatedPlanComment(note.Body, g.SupportsCommonMark())
+		if err := g.UpdateComment(repo, pullNum, models.PullComment{ID: int64(note.ID)}, body); err != nil {
+			return errors.Wrapf(err, "hiding note %d", note.ID)
+		}
+	}
 	return nil
 }
 
-// PullIsApproved returns true if the merge request was approved.
-func (g *GitlabClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	approvals, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
+// listNotes returns the merge request's notes, oldest first. System notes,
+// ex. "added 1 commit", are skipped.
+func (g *GitlabClient) listNotes(repo models.Repo, pullNum int) ([]*gitlab.Note, error) {
+	var notes []*gitlab.Note
+	nextPage := 1
+	for {
+		page, resp, err := g.Client.Notes.ListMergeRequestNotes(repo.FullName, pullNum, &gitlab.ListMergeRequestNotesOptions{
+			ListOptions: gitlab.ListOptions{Page: nextPage, PerPage: 100},
+			OrderBy:     gitlab.String("created_at"),
+			Sort:        gitlab.String("asc"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, note := range page {
+			if !note.System {
+				notes = append(notes, note)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
+	}
+	return notes, nil
+}
+
+// GetApprovals returns the approvals of the merge request. GitLab doesn't
+// associate approvals with commits so, unlike on GitHub, approvals of earlier
+// commits can't be dismissed here. The project must be configured to remove
+// approvals when new commits are pushed for them not to count.
+func (g *GitlabClient) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
+	approvalState, _, err := g.Client.MergeRequests.GetMergeRequestApprovals(repo.FullName, pull.Num)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	if approvals.ApprovalsLeft > 0 {
-		return false, nil
+	var approvals []models.Approval
+	for _, approval := range approvalState.ApprovedBy {
+		if approval == nil || approval.User == nil {
+			continue
+		}
+		approvals = append(approvals, models.Approval{
+			User: models.User{Username: approval.User.Username},
+		})
+	}
+	return approvals, nil
+}
+
+// GetCommitChecks returns the statuses of the merge request's head commit.
+// These include the jobs of its pipelines as well as external statuses.
+// GitLab only returns the latest status for each name.
+func (g *GitlabClient) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	var checks []models.CommitCheck
+	nextPage := 0
+	for {
+		opts := gitlab.GetCommitStatusesOptions{
+			ListOptions: gitlab.ListOptions{
+				PerPage: 100,
+			},
+		}
+		if nextPage != 0 {
+			opts.Page = nextPage
+		}
+		statuses, resp, err := g.Client.Commits.GetCommitStatuses(repo.FullName, pull.HeadCommit, &opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting commit statuses")
+		}
+		for _, status := range statuses {
+			check := models.CommitCheck{Name: status.Name, State: models.PendingCommitStatus}
+			switch status.Status {
+			case "success":
+				check.State = models.SuccessCommitStatus
+			case "skipped":
+				check.State = models.SuccessCommitStatus
+				check.Skipped = true
+			case "failed", "canceled":
+				check.State = models.FailedCommitStatus
+			}
+			checks = append(checks, check)
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		nextPage = resp.NextPage
 	}
-	return true, nil
+	return checks, nil
 }
 
 // PullIsMergeable returns true if the merge request can be merged.
@@ -204,15 +330,28 @@ func (g *GitlabClient) GetMergeRequest(repoFullName string, pullNum int) (*gitla
 	return mr, err
 }
 
-// MergePull merges the merge request.
-func (g *GitlabClient) MergePull(pull models.PullRequest) error {
-	commitMsg := common.AutomergeCommitMsg
+// MergePull merges the merge request. GitLab can't rebase while merging
+// through its API so the rebase method isn't supported. Instead, projects
+// can set their merge method to fast-forward merge.
+func (g *GitlabClient) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
+	commitMsg := common.MergeCommitMsg(opts)
+	mergeOpts := &gitlab.AcceptMergeRequestOptions{
+		MergeCommitMessage: &commitMsg,
+	}
+	switch opts.MergeMethod {
+	case models.SquashMergeMethod:
+		mergeOpts.Squash = gitlab.Bool(true)
+		mergeOpts.SquashCommitMessage = &commitMsg
+	case models.RebaseMergeMethod:
+		return errors.New("the rebase merge method isn't supported by GitLab, set the project's merge method to fast-forward merge instead")
+	}
+	if opts.DeleteSourceBranchOnMerge {
+		mergeOpts.ShouldRemoveSourceBranch = gitlab.Bool(true)
+	}
 	_, _, err := g.Client.MergeRequests.AcceptMergeRequest(
 		pull.BaseRepo.FullName,
 		pull.Num,
-		&gitlab.AcceptMergeRequestOptions{
-			MergeCommitMessage: &commitMsg,
-		})
+		mergeOpts)
 	return errors.Wrap(err, "unable to merge merge request, it may not be in a mergeable state")
 }
 
@@ -221,6 +360,33 @@ func (g *GitlabClient) MarkdownPullLink(pull models.PullRequest) (string, error)
 	return fmt.Sprintf("#%d", pull.Num), nil
 }
 
+// UserIsTeamMember returns true if user is an active member of the group with
+// the full path team, ex. mygroup/mysubgroup. Members inherited from parent
+// groups are included.
+func (g *GitlabClient) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	opts := &gitlab.ListGroupMembersOptions{
+		Query: gitlab.String(user.Username),
+	}
+	members, _, err := g.Client.Groups.ListAllGroupMembers(team, opts)
+	if err != nil {
+		return false, errors.Wrapf(err, "listing members of group %s", team)
+	}
+	// The query matches on substrings so we need to check for an exact match.
+	for _, m := range members {
+		if m.Username == user.Username && m.State == "active" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Ping makes a lightweight authenticated API call to verify that GitLab is
+// reachable and our credentials are valid.
+func (g *GitlabClient) Ping() error {
+	_, _, err := g.Client.Users.CurrentUser()
+	return err
+}
+
 // GetVersion returns the version of the Gitlab server this client is using.
 func (g *GitlabClient) GetVersion() (*version.Version, error) {
 	req, err := g.Client.NewRequest("GET", "/version", nil, nil)
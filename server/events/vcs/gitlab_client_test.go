@@ -1,6 +1,7 @@
 package vcs
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -159,7 +160,7 @@ func TestGitlabClient_MergePull(t *testing.T) {
 					Owner:    "runatlantis",
 					Name:     "atlantis",
 				},
-			})
+			}, models.PullRequestOptions{})
 			if c.expErr == "" {
 				Ok(t, err)
 			} else {
@@ -170,6 +171,50 @@ func TestGitlabClient_MergePull(t *testing.T) {
 	}
 }
 
+func TestGitlabClient_MergePullWithOptions(t *testing.T) {
+	gotRequest := false
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/merge":
+				gotRequest = true
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				Equals(t, `{"merge_commit_message":"Merge !1","squash_commit_message":"Merge !1","squash":true,"should_remove_source_branch":true}`, string(body))
+				w.Write([]byte(mergeSuccess)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient := gitlab.NewClient(nil, "token")
+	Ok(t, internalClient.SetBaseURL(testServer.URL))
+	client := &GitlabClient{
+		Client:  internalClient,
+		Version: nil,
+	}
+	pull := models.PullRequest{
+		Num: 1,
+		BaseRepo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			Owner:    "runatlantis",
+			Name:     "atlantis",
+		},
+	}
+
+	err := client.MergePull(pull, models.PullRequestOptions{
+		MergeMethod:               "squash",
+		DeleteSourceBranchOnMerge: true,
+		CommitMessage:             "Merge !1",
+	})
+	Ok(t, err)
+	Assert(t, gotRequest, "expected to get the request")
+
+	err = client.MergePull(pull, models.PullRequestOptions{MergeMethod: "rebase"})
+	ErrEquals(t, "the rebase merge method isn't supported by GitLab, set the project's merge method to fast-forward merge instead", err)
+}
+
 func TestGitlabClient_UpdateStatus(t *testing.T) {
 	cases := []struct {
 		status   models.CommitStatus
@@ -232,6 +277,49 @@ func TestGitlabClient_UpdateStatus(t *testing.T) {
 	}
 }
 
+func TestGitlabClient_GetCommitChecks(t *testing.T) {
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/v4/projects/runatlantis%2Fatlantis/repository/commits/sha/statuses?per_page=100":
+				w.Write([]byte(`[
+					{"id": 1, "name": "build", "status": "success"},
+					{"id": 2, "name": "test", "status": "running"},
+					{"id": 3, "name": "lint", "status": "canceled"},
+					{"id": 4, "name": "docs", "status": "skipped"}
+				]`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request at %q", r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+
+	internalClient := gitlab.NewClient(nil, "token")
+	Ok(t, internalClient.SetBaseURL(testServer.URL))
+	client := &GitlabClient{
+		Client:  internalClient,
+		Version: nil,
+	}
+
+	repo := models.Repo{
+		FullName: "runatlantis/atlantis",
+		Owner:    "runatlantis",
+		Name:     "atlantis",
+	}
+	checks, err := client.GetCommitChecks(repo, models.PullRequest{
+		Num:        1,
+		BaseRepo:   repo,
+		HeadCommit: "sha",
+	})
+	Ok(t, err)
+	Equals(t, []models.CommitCheck{
+		{Name: "build", State: models.SuccessCommitStatus},
+		{Name: "test", State: models.PendingCommitStatus},
+		{Name: "lint", State: models.FailedCommitStatus},
+		{Name: "docs", State: models.SuccessCommitStatus, Skipped: true},
+	}, checks)
+}
+
 func TestGitlabClient_MarkdownPullLink(t *testing.T) {
 	gitlabClientUnderTest = true
 	defer func() { gitlabClientUnderTest = false }()
@@ -244,3 +332,76 @@ func TestGitlabClient_MarkdownPullLink(t *testing.T) {
 }
 
 var mergeSuccess = `{"id":22461274,"iid":13,"project_id":4580910,"title":"Update main.tf","description":"","state":"merged","created_at":"2019-01-15T18:27:29.375Z","updated_at":"2019-01-25T17:28:01.437Z","merged_by":{"id":1755902,"name":"Luke Kysow","username":"lkysow","state":"active","avatar_url":"https://secure.gravatar.com/avatar/25fd57e71590fe28736624ff24d41c5f?s=80\u0026d=identicon","web_url":"https://gitlab.com/lkysow"},"merged_at":"2019-01-25T17:28:01.459Z","closed_by":null,"closed_at":null,"target_branch":"patch-1","source_branch":"patch-1-merger","upvotes":0,"downvotes":0,"author":{"id":1755902,"name":"Luke Kysow","username":"lkysow","state":"active","avatar_url":"https://secure.gravatar.com/avatar/25fd57e71590fe28736624ff24d41c5f?s=80\u0026d=identicon","web_url":"https://gitlab.com/lkysow"},"assignee":null,"source_project_id":4580910,"target_project_id":4580910,"labels":[],"work_in_progress":false,"milestone":null,"merge_when_pipeline_succeeds":false,"merge_status":"can_be_merged","sha":"cb86d70f464632bdfbe1bb9bc0f2f9d847a774a0","merge_commit_sha":"c9b336f1c71d3e64810b8cfa2abcfab232d6bff6","user_notes_count":0,"discussion_locked":null,"should_remove_source_branch":null,"force_remove_source_branch":false,"web_url":"https://gitlab.com/lkysow/atlantis-example/merge_requests/13","time_stats":{"time_estimate":0,"total_time_spent":0,"human_time_estimate":null,"human_total_time_spent":null},"squash":false,"subscribed":true,"changes_count":"1","latest_build_started_at":null,"latest_build_finished_at":null,"first_deployed_to_production_at":null,"pipeline":null,"diff_refs":{"base_sha":"67cb91d3f6198189f433c045154a885784ba6977","head_sha":"cb86d70f464632bdfbe1bb9bc0f2f9d847a774a0","start_sha":"67cb91d3f6198189f433c045154a885784ba6977"},"merge_error":null,"approvals_before_merge":null}`
+
+func TestGitlabClient_FindAndUpdateComment(t *testing.T) {
+	var updated string
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /api/v4/user":
+				w.Write([]byte(`{"id": 1, "username": "atlantis"}`)) // nolint: errcheck
+			case "GET /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/notes?order_by=created_at&page=1&per_page=100&sort=asc":
+				w.Header().Set("X-Next-Page", "2")
+				w.Write([]byte(`[{"id": 1, "body": "marker", "author": {"username": "atlantis"}}, {"id": 2, "body": "added marker", "system": true, "author": {"username": "atlantis"}}]`)) // nolint: errcheck
+			case "GET /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/notes?order_by=created_at&page=2&per_page=100&sort=asc":
+				// Notes by other users with the marker should be ignored.
+				w.Write([]byte(`[{"id": 3, "body": "with marker", "author": {"username": "Atlantis"}}, {"id": 4, "body": "other", "author": {"username": "atlantis"}}, {"id": 5, "body": "marker", "author": {"username": "attacker"}}]`)) // nolint: errcheck
+			case "PUT /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/notes/3":
+				body, err := ioutil.ReadAll(r.Body)
+				Ok(t, err)
+				updated = string(body)
+				w.Write([]byte(`{"id": 3}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	internalClient := gitlab.NewClient(nil, "token")
+	Ok(t, internalClient.SetBaseURL(testServer.URL))
+	client := &GitlabClient{Client: internalClient}
+	repo := models.Repo{FullName: "runatlantis/atlantis"}
+
+	comment, err := client.FindComment(repo, 1, "marker")
+	Ok(t, err)
+	Equals(t, &models.PullComment{ID: 3, Body: "with marker"}, comment)
+
+	Ok(t, client.UpdateComment(repo, 1, *comment, "new body"))
+	Equals(t, `{"body":"new body"}`, updated)
+}
+
+// Only plan notes written by the Atlantis user should be collapsed.
+func TestGitlabClient_HidePrevPlanComments(t *testing.T) {
+	var updated []string
+	testServer := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method + " " + r.RequestURI {
+			case "GET /api/v4/user":
+				w.Write([]byte(`{"id": 1, "username": "atlantis"}`)) // nolint: errcheck
+			case "GET /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/notes?order_by=created_at&page=1&per_page=100&sort=asc":
+				w.Write([]byte(`[
+					{"id": 1, "body": "Ran Plan for dir: ` + "`.`" + `\n\noutput", "author": {"username": "Atlantis"}},
+					{"id": 2, "body": "Ran Apply for dir: ` + "`.`" + `", "author": {"username": "atlantis"}},
+					{"id": 3, "body": "Ran Plan for dir: ` + "`.`" + `", "author": {"username": "someone"}},
+					{"id": 4, "body": "**Outdated**: This plan was superseded by a newer plan.", "author": {"username": "atlantis"}}
+				]`)) // nolint: errcheck
+			case "PUT /api/v4/projects/runatlantis%2Fatlantis/merge_requests/1/notes/1":
+				var note struct{ Body string }
+				Ok(t, json.NewDecoder(r.Body).Decode(&note))
+				updated = append(updated, note.Body)
+				w.Write([]byte(`{"id": 1}`)) // nolint: errcheck
+			default:
+				t.Errorf("got unexpected request %s %q", r.Method, r.RequestURI)
+				http.Error(w, "not found", http.StatusNotFound)
+			}
+		}))
+	defer testServer.Close()
+
+	internalClient := gitlab.NewClient(nil, "token")
+	Ok(t, internalClient.SetBaseURL(testServer.URL))
+	client := &GitlabClient{Client: internalClient, Version: version.Must(version.NewVersion("12.0"))}
+
+	Ok(t, client.HidePrevPlanComments(models.Repo{FullName: "runatlantis/atlantis"}, 1))
+	Equals(t, []string{"**Outdated**: This plan was superseded by a newer plan.\n\n<details><summary>Show outdated plan</summary>\n\nRan Plan for dir: `.`\n\noutput\n</details>"}, updated)
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnyModelsCheckRun() models.CheckRun {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(models.CheckRun))(nil)).Elem()))
+	var nullValue models.CheckRun
+	return nullValue
+}
+
+func EqModelsCheckRun(value models.CheckRun) models.CheckRun {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue models.CheckRun
+	return nullValue
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnyPtrToModelsPullComment() *models.PullComment {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*(*models.PullComment))(nil)).Elem()))
+	var nullValue *models.PullComment
+	return nullValue
+}
+
+func EqPtrToModelsPullComment(value *models.PullComment) *models.PullComment {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue *models.PullComment
+	return nullValue
+}
@@ -0,0 +1,20 @@
+// Code generated by pegomock. DO NOT EDIT.
+package matchers
+
+import (
+	"reflect"
+	"github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+)
+
+func AnySliceOfModelsApproval() []models.Approval {
+	pegomock.RegisterMatcher(pegomock.NewAnyMatcher(reflect.TypeOf((*([]models.Approval))(nil)).Elem()))
+	var nullValue []models.Approval
+	return nullValue
+}
+
+func EqSliceOfModelsApproval(value []models.Approval) []models.Approval {
+	pegomock.RegisterMatcher(&pegomock.EqMatcher{Value: value})
+	var nullValue []models.Approval
+	return nullValue
+}
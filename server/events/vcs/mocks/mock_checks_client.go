@@ -0,0 +1,113 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events/vcs (interfaces: ChecksClient)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockChecksClient struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockChecksClient(options ...pegomock.Option) *MockChecksClient {
+	mock := &MockChecksClient{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockChecksClient) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockChecksClient) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockChecksClient) UpdateCheckRun(repo models.Repo, pull models.PullRequest, run models.CheckRun) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockChecksClient().")
+	}
+	params := []pegomock.Param{repo, pull, run}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("UpdateCheckRun", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockChecksClient) VerifyWasCalledOnce() *VerifierMockChecksClient {
+	return &VerifierMockChecksClient{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockChecksClient) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockChecksClient {
+	return &VerifierMockChecksClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockChecksClient) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockChecksClient {
+	return &VerifierMockChecksClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockChecksClient) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockChecksClient {
+	return &VerifierMockChecksClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockChecksClient struct {
+	mock                   *MockChecksClient
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockChecksClient) UpdateCheckRun(repo models.Repo, pull models.PullRequest, run models.CheckRun) *MockChecksClient_UpdateCheckRun_OngoingVerification {
+	params := []pegomock.Param{repo, pull, run}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "UpdateCheckRun", params, verifier.timeout)
+	return &MockChecksClient_UpdateCheckRun_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockChecksClient_UpdateCheckRun_OngoingVerification struct {
+	mock              *MockChecksClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockChecksClient_UpdateCheckRun_OngoingVerification) GetCapturedArguments() (models.Repo, models.PullRequest, models.CheckRun) {
+	repo, pull, run := c.GetAllCapturedArguments()
+	return repo[len(repo)-1], pull[len(pull)-1], run[len(run)-1]
+}
+
+func (c *MockChecksClient_UpdateCheckRun_OngoingVerification) GetAllCapturedArguments() (_param0 []models.Repo, _param1 []models.PullRequest, _param2 []models.CheckRun) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.Repo, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.Repo)
+		}
+		_param1 = make([]models.PullRequest, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(models.PullRequest)
+		}
+		_param2 = make([]models.CheckRun, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(models.CheckRun)
+		}
+	}
+	return
+}
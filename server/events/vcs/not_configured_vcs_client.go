@@ -35,21 +35,33 @@ func (a *NotConfiguredVCSClient) CreateComment(repo models.Repo, pullNum int, co
 func (a *NotConfiguredVCSClient) HidePrevPlanComments(repo models.Repo, pullNum int) error {
 	return nil
 }
-func (a *NotConfiguredVCSClient) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	return false, a.err()
+func (a *NotConfiguredVCSClient) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	return nil, a.err()
+}
+func (a *NotConfiguredVCSClient) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	return a.err()
+}
+func (a *NotConfiguredVCSClient) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
+	return nil, a.err()
 }
 func (a *NotConfiguredVCSClient) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
 	return false, a.err()
 }
+func (a *NotConfiguredVCSClient) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	return nil, a.err()
+}
 func (a *NotConfiguredVCSClient) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	return a.err()
 }
-func (a *NotConfiguredVCSClient) MergePull(pull models.PullRequest) error {
+func (a *NotConfiguredVCSClient) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
 	return a.err()
 }
 func (a *NotConfiguredVCSClient) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return "", a.err()
 }
+func (a *NotConfiguredVCSClient) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	return false, a.err()
+}
 func (a *NotConfiguredVCSClient) err() error {
 	return fmt.Errorf("atlantis was not configured to support repos from %s", a.Host.String())
 }
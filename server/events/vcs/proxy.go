@@ -25,7 +25,7 @@ type ClientProxy struct {
 	clients map[models.VCSHostType]Client
 }
 
-func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client) *ClientProxy {
+func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClient Client, bitbucketServerClient Client, azuredevopsClient Client, giteaClient Client) *ClientProxy {
 	if githubClient == nil {
 		githubClient = &NotConfiguredVCSClient{}
 	}
@@ -41,6 +41,9 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 	if azuredevopsClient == nil {
 		azuredevopsClient = &NotConfiguredVCSClient{}
 	}
+	if giteaClient == nil {
+		giteaClient = &NotConfiguredVCSClient{}
+	}
 	return &ClientProxy{
 		clients: map[models.VCSHostType]Client{
 			models.Github:          githubClient,
@@ -48,6 +51,7 @@ func NewClientProxy(githubClient Client, gitlabClient Client, bitbucketCloudClie
 			models.BitbucketCloud:  bitbucketCloudClient,
 			models.BitbucketServer: bitbucketServerClient,
 			models.AzureDevops:     azuredevopsClient,
+			models.Gitea:           giteaClient,
 		},
 	}
 }
@@ -64,22 +68,38 @@ func (d *ClientProxy) HidePrevPlanComments(repo models.Repo, pullNum int) error
 	return d.clients[repo.VCSHost.Type].HidePrevPlanComments(repo, pullNum)
 }
 
-func (d *ClientProxy) PullIsApproved(repo models.Repo, pull models.PullRequest) (bool, error) {
-	return d.clients[repo.VCSHost.Type].PullIsApproved(repo, pull)
+func (d *ClientProxy) FindComment(repo models.Repo, pullNum int, marker string) (*models.PullComment, error) {
+	return d.clients[repo.VCSHost.Type].FindComment(repo, pullNum, marker)
+}
+
+func (d *ClientProxy) UpdateComment(repo models.Repo, pullNum int, comment models.PullComment, body string) error {
+	return d.clients[repo.VCSHost.Type].UpdateComment(repo, pullNum, comment, body)
+}
+
+func (d *ClientProxy) GetApprovals(repo models.Repo, pull models.PullRequest) ([]models.Approval, error) {
+	return d.clients[repo.VCSHost.Type].GetApprovals(repo, pull)
 }
 
 func (d *ClientProxy) PullIsMergeable(repo models.Repo, pull models.PullRequest) (bool, error) {
 	return d.clients[repo.VCSHost.Type].PullIsMergeable(repo, pull)
 }
 
+func (d *ClientProxy) GetCommitChecks(repo models.Repo, pull models.PullRequest) ([]models.CommitCheck, error) {
+	return d.clients[repo.VCSHost.Type].GetCommitChecks(repo, pull)
+}
+
 func (d *ClientProxy) UpdateStatus(repo models.Repo, pull models.PullRequest, state models.CommitStatus, src string, description string, url string) error {
 	return d.clients[repo.VCSHost.Type].UpdateStatus(repo, pull, state, src, description, url)
 }
 
-func (d *ClientProxy) MergePull(pull models.PullRequest) error {
-	return d.clients[pull.BaseRepo.VCSHost.Type].MergePull(pull)
+func (d *ClientProxy) MergePull(pull models.PullRequest, opts models.PullRequestOptions) error {
+	return d.clients[pull.BaseRepo.VCSHost.Type].MergePull(pull, opts)
 }
 
 func (d *ClientProxy) MarkdownPullLink(pull models.PullRequest) (string, error) {
 	return d.clients[pull.BaseRepo.VCSHost.Type].MarkdownPullLink(pull)
 }
+
+func (d *ClientProxy) UserIsTeamMember(repo models.Repo, user models.User, team string) (bool, error) {
+	return d.clients[repo.VCSHost.Type].UserIsTeamMember(repo, user, team)
+}
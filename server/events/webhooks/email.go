@@ -0,0 +1,289 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// defaultEmailBatchWindow is how long to wait for another project's command
+// to start before sending an email.
+const defaultEmailBatchWindow = 10 * time.Second
+
+// defaultEmailIdleTimeout is how long to wait for a running project's command
+// to finish before sending an email anyway.
+const defaultEmailIdleTimeout = time.Hour
+
+// EmailWebhook sends webhooks as emails. Events for the same pull request
+// are batched so that running a command on many projects sends one email.
+// Started events aren't emailed. They hold the batch open until the project
+// finishes or no event has been received for IdleTimeout. Close sends the
+// batches that are still open.
+type EmailWebhook struct {
+	Client EmailClient
+	To     []string
+	// BatchWindow is how long to wait after the last project finished for
+	// another project's command to start before sending the email.
+	BatchWindow time.Duration
+	// IdleTimeout is how long to wait for another event while projects are
+	// running before sending the email anyway. It stops batches being held
+	// forever if a project's finished event is never received.
+	IdleTimeout time.Duration
+
+	mu      sync.Mutex
+	batches map[string]*emailBatch
+	closed  bool
+	// sending counts the batches that have been taken out of batches but
+	// haven't been emailed yet.
+	sending sync.WaitGroup
+}
+
+// emailBatch is the events for a pull request that haven't been emailed yet.
+type emailBatch struct {
+	log    *logging.SimpleLogger
+	events []Event
+	// running is how many projects have started but not finished.
+	running int
+	timer   *time.Timer
+	// sends counts the events received so a timer that fired while another
+	// event was being added doesn't flush the batch.
+	sends int
+}
+
+// NewEmail returns an EmailWebhook that sends emails to the addresses in to.
+func NewEmail(client EmailClient, to []string) *EmailWebhook {
+	return &EmailWebhook{
+		Client:      client,
+		To:          to,
+		BatchWindow: defaultEmailBatchWindow,
+		IdleTimeout: defaultEmailIdleTimeout,
+		batches:     make(map[string]*emailBatch),
+	}
+}
+
+// Send adds event to the batch for its pull request. The batch is emailed
+// once no project is running and no new project has started for
+// BatchWindow, or once no event has been received for IdleTimeout. Errors
+// sending the email are logged.
+func (e *EmailWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	key := fmt.Sprintf("%s#%d", event.Repo.ID(), event.Pull.Num)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.closed {
+		return fmt.Errorf("email webhook is closed, dropping %s event", event.Type)
+	}
+	if e.batches == nil {
+		e.batches = make(map[string]*emailBatch)
+	}
+	batch, ok := e.batches[key]
+	if !ok {
+		batch = &emailBatch{}
+		e.batches[key] = batch
+	}
+	batch.log = log
+	batch.sends++
+	if batch.timer != nil {
+		batch.timer.Stop()
+		batch.timer = nil
+	}
+
+	switch {
+	case isStartedEvent(event.Type):
+		batch.running++
+	case event.Type == PlanFinishedEvent || event.Type == ApplyFinishedEvent:
+		if batch.running > 0 {
+			batch.running--
+		}
+		batch.events = append(batch.events, event)
+	default:
+		batch.events = append(batch.events, event)
+	}
+
+	wait := e.BatchWindow
+	if batch.running > 0 {
+		wait = e.IdleTimeout
+		if wait <= 0 {
+			wait = defaultEmailIdleTimeout
+		}
+	}
+	sends := batch.sends
+	batch.timer = time.AfterFunc(wait, func() { e.flush(key, sends) })
+	return nil
+}
+
+// flush emails the batch for key unless another event was added to it after
+// the sends'th event.
+func (e *EmailWebhook) flush(key string, sends int) {
+	e.mu.Lock()
+	batch, ok := e.batches[key]
+	if !ok || batch.sends != sends {
+		e.mu.Unlock()
+		return
+	}
+	delete(e.batches, key)
+	e.sending.Add(1)
+	e.mu.Unlock()
+
+	e.sendBatch(batch)
+}
+
+// Close stops batching and emails the batches that haven't been sent yet
+// without waiting for their timers. It waits for those emails and any that
+// are already being sent. If ctx ends first, the remaining emails may not be
+// sent.
+func (e *EmailWebhook) Close(ctx context.Context) error {
+	e.mu.Lock()
+	e.closed = true
+	batches := e.batches
+	e.batches = nil
+	for _, batch := range batches {
+		if batch.timer != nil {
+			batch.timer.Stop()
+		}
+		e.sending.Add(1)
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for _, batch := range batches {
+			e.sendBatch(batch)
+		}
+		e.sending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "sending batched emails")
+	}
+}
+
+// sendBatch emails batch and marks it as sent. Errors are logged.
+func (e *EmailWebhook) sendBatch(batch *emailBatch) {
+	defer e.sending.Done()
+	if len(batch.events) == 0 {
+		return
+	}
+	if err := e.sendEmail(batch.events); err != nil {
+		batch.log.Warn("error sending email webhook: %s", err)
+	}
+}
+
+func (e *EmailWebhook) sendEmail(events []Event) error {
+	data := newEmailData(events)
+	var text bytes.Buffer
+	if err := emailTextTemplate.Execute(&text, data); err != nil {
+		return errors.Wrap(err, "rendering email text")
+	}
+	var html bytes.Buffer
+	if err := emailHTMLTemplate.Execute(&html, data); err != nil {
+		return errors.Wrap(err, "rendering email html")
+	}
+	return e.Client.SendEmail(e.To, data.Subject, text.String(), html.String())
+}
+
+// emailData is the data for the email templates.
+type emailData struct {
+	Subject      string
+	RepoFullName string
+	PullNum      int
+	PullURL      string
+	Events       []emailEvent
+}
+
+// emailEvent is an event in an email.
+type emailEvent struct {
+	Title       string
+	Success     bool
+	ProjectName string
+	Directory   string
+	Workspace   string
+	User        string
+	Resources   string
+	Error       string
+	JobURL      string
+}
+
+func newEmailData(events []Event) emailData {
+	first := events[0]
+	data := emailData{
+		RepoFullName: first.Repo.FullName,
+		PullNum:      first.Pull.Num,
+		PullURL:      first.Pull.URL,
+	}
+	sameType := true
+	success := true
+	for _, event := range events {
+		sameType = sameType && event.Type == first.Type
+		success = success && event.Success
+		data.Events = append(data.Events, emailEvent{
+			Title:       eventTitle(event.Type, event.Success),
+			Success:     event.Success,
+			ProjectName: event.ProjectName,
+			Directory:   event.Directory,
+			Workspace:   event.Workspace,
+			User:        event.User.Username,
+			Resources:   resourceSummary(event),
+			Error:       event.Error,
+			JobURL:      event.JobURL,
+		})
+	}
+	title := "Atlantis updates"
+	if sameType {
+		title = eventTitle(first.Type, success)
+	}
+	data.Subject = fmt.Sprintf("%s for %s#%d", title, data.RepoFullName, data.PullNum)
+	return data
+}
+
+var emailTextTemplate = template.Must(template.New("").Parse(`{{ .Subject }}
+{{ .PullURL }}
+{{ range .Events }}
+{{ .Title }}{{ if .ProjectName }} for project {{ .ProjectName }}{{ end }}
+{{- if .Directory }}
+  Directory: {{ .Directory }}{{ end }}
+{{- if .Workspace }}
+  Workspace: {{ .Workspace }}{{ end }}
+{{- if .User }}
+  User: {{ .User }}{{ end }}
+{{- if .Resources }}
+  Resources: {{ .Resources }}{{ end }}
+{{- if .JobURL }}
+  Output: {{ .JobURL }}{{ end }}
+{{- if .Error }}
+  Error: {{ .Error }}{{ end }}
+{{ end }}`))
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("").Parse(`<html>
+<body>
+<p><a href="{{ .PullURL }}">{{ .RepoFullName }}#{{ .PullNum }}</a></p>
+<table cellpadding="4">
+<tr><th align="left">Event</th><th align="left">Project</th><th align="left">Directory</th><th align="left">Workspace</th><th align="left">User</th><th align="left">Resources</th><th align="left">Output</th></tr>
+{{- range .Events }}
+<tr>
+<td style="color: {{ if .Success }}#2eb886{{ else }}#a30200{{ end }}">{{ .Title }}</td>
+<td>{{ .ProjectName }}</td>
+<td>{{ .Directory }}</td>
+<td>{{ .Workspace }}</td>
+<td>{{ .User }}</td>
+<td>{{ .Resources }}</td>
+<td>{{ if .JobURL }}<a href="{{ .JobURL }}">View output</a>{{ end }}</td>
+</tr>
+{{- if .Error }}
+<tr><td colspan="7"><pre>{{ .Error }}</pre></td></tr>
+{{- end }}
+{{- end }}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_email_client.go EmailClient
+
+// EmailClient sends emails.
+type EmailClient interface {
+	// IsConfigured returns true if the client knows which server to send
+	// emails through.
+	IsConfigured() bool
+	// SendEmail sends an email to the addresses in to. text and html are
+	// alternative versions of the body.
+	SendEmail(to []string, subject string, text string, html string) error
+}
+
+// defaultSMTPTimeout is how long sending an email can take before it's
+// abandoned.
+const defaultSMTPTimeout = 30 * time.Second
+
+// SMTPClient sends emails through an SMTP server. If the server supports
+// STARTTLS, the connection is upgraded before authenticating.
+type SMTPClient struct {
+	// Addr is the host:port of the SMTP server.
+	Addr string
+	// Username and Password are used to authenticate with the server. If
+	// Username is empty, no authentication is done.
+	Username string
+	Password string
+	// From is the address emails are sent from.
+	From string
+	// TLSConfig is used for STARTTLS. If nil, the server's certificate is
+	// verified against the host in Addr.
+	TLSConfig *tls.Config
+	// RequireTLS makes sending fail if the server doesn't support STARTTLS
+	// rather than sending the email unencrypted.
+	RequireTLS bool
+	// Timeout is how long connecting to the server and sending the email can
+	// take. If 0, it's 30 seconds.
+	Timeout time.Duration
+}
+
+// NewSMTPClient returns a client that sends emails from the address from
+// through the SMTP server at addr. If requireTLS is true, emails are only
+// sent if the server supports STARTTLS.
+func NewSMTPClient(addr string, username string, password string, from string, requireTLS bool) *SMTPClient {
+	return &SMTPClient{
+		Addr:       addr,
+		Username:   username,
+		Password:   password,
+		From:       from,
+		RequireTLS: requireTLS,
+		Timeout:    defaultSMTPTimeout,
+	}
+}
+
+// IsConfigured returns true if Addr is set.
+func (s *SMTPClient) IsConfigured() bool {
+	return s.Addr != ""
+}
+
+// SendEmail sends an email through the SMTP server.
+func (s *SMTPClient) SendEmail(to []string, subject string, text string, html string) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return errors.Wrapf(err, "parsing SMTP server address %q", s.Addr)
+	}
+	msg, err := buildEmail(s.From, to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = defaultSMTPTimeout
+	}
+	conn, err := net.DialTimeout("tcp", s.Addr, timeout)
+	if err != nil {
+		return errors.Wrap(err, "connecting to SMTP server")
+	}
+	// The deadline covers the whole conversation so a server that stops
+	// responding can't block the sender forever.
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		conn.Close() // nolint: errcheck
+		return errors.Wrap(err, "setting SMTP connection deadline")
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close() // nolint: errcheck
+		return errors.Wrap(err, "connecting to SMTP server")
+	}
+	defer c.Close() // nolint: errcheck
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		tlsConfig := s.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{ServerName: host}
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return errors.Wrap(err, "starting TLS")
+		}
+	} else if s.RequireTLS {
+		return errors.Errorf("SMTP server %q doesn't support STARTTLS", s.Addr)
+	}
+	if s.Username != "" {
+		// PlainAuth refuses to send credentials over an unencrypted
+		// connection unless the server is on localhost.
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return errors.Wrap(err, "authenticating with SMTP server")
+		}
+	}
+	if err := c.Mail(s.From); err != nil {
+		return errors.Wrapf(err, "setting sender %q", s.From)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return errors.Wrapf(err, "adding recipient %q", addr)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return errors.Wrap(err, "starting email body")
+	}
+	if _, err := w.Write(msg); err != nil {
+		return errors.Wrap(err, "writing email body")
+	}
+	if err := w.Close(); err != nil {
+		return errors.Wrap(err, "sending email")
+	}
+	return c.Quit()
+}
+
+// buildEmail returns a multipart/alternative email with text and html
+// bodies.
+func buildEmail(from string, to []string, subject string, text string, html string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", text},
+		{"text/html; charset=utf-8", html},
+	}
+	for _, part := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "creating email part")
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err := qw.Write([]byte(part.content)); err != nil {
+			return nil, errors.Wrap(err, "writing email part")
+		}
+		if err := qw.Close(); err != nil {
+			return nil, errors.Wrap(err, "writing email part")
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, errors.Wrap(err, "writing email")
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes()) // nolint: errcheck
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,287 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestEmailWebhook_BatchesProjects(t *testing.T) {
+	t.Log("Applies of multiple projects in a pull should be sent in one email")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockEmailClient()
+	hook := webhooks.NewEmail(client, []string{"ops@example.com"})
+	hook.BatchWindow = 50 * time.Millisecond
+	log := logging.NewNoopLogger()
+
+	event := func(eventType string, dir string, success bool) webhooks.Event {
+		return webhooks.Event{
+			Type:      eventType,
+			Repo:      models.Repo{FullName: "owner/repo"},
+			Pull:      models.PullRequest{Num: 1, URL: "https://github.com/owner/repo/pull/1"},
+			User:      models.User{Username: "lkysow"},
+			Directory: dir,
+			Workspace: "default",
+			Success:   success,
+			Output:    "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+		}
+	}
+	Ok(t, hook.Send(log, event(webhooks.ApplyStartedEvent, "dir1", true)))
+	Ok(t, hook.Send(log, event(webhooks.ApplyFinishedEvent, "dir1", true)))
+	Ok(t, hook.Send(log, event(webhooks.ApplyStartedEvent, "dir2", true)))
+
+	// No email should be sent while the second project is applying.
+	time.Sleep(3 * hook.BatchWindow)
+	client.VerifyWasCalled(Never()).SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString())
+
+	Ok(t, hook.Send(log, event(webhooks.ApplyFinishedEvent, "dir2", false)))
+	to, subject, text, html := client.VerifyWasCalledEventually(Once(), time.Second).
+		SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString()).GetCapturedArguments()
+	Equals(t, []string{"ops@example.com"}, to)
+	Equals(t, "Apply failed for owner/repo#1", subject)
+	Assert(t, strings.Contains(text, "Apply succeeded\n  Directory: dir1"), "text should contain dir1 but was %q", text)
+	Assert(t, strings.Contains(text, "Apply failed\n  Directory: dir2"), "text should contain dir2 but was %q", text)
+	Assert(t, strings.Contains(text, "Resources: 1 added, 0 changed, 0 destroyed"), "text should contain the resource counts but was %q", text)
+	Assert(t, strings.Contains(html, `<a href="https://github.com/owner/repo/pull/1">owner/repo#1</a>`), "html should link to the pull but was %q", html)
+}
+
+func TestEmailWebhook_SeparatePulls(t *testing.T) {
+	t.Log("Events for different pulls should be sent in different emails")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockEmailClient()
+	hook := webhooks.NewEmail(client, []string{"ops@example.com"})
+	hook.BatchWindow = 10 * time.Millisecond
+	log := logging.NewNoopLogger()
+
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.LockReleasedEvent, Pull: models.PullRequest{Num: 1}}))
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.LockReleasedEvent, Pull: models.PullRequest{Num: 2}}))
+	client.VerifyWasCalledEventually(Times(2), time.Second).SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString())
+}
+
+func TestEmailWebhook_IdleTimeout(t *testing.T) {
+	t.Log("If a project never finishes, the batch should be sent once no events are received for the idle timeout")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockEmailClient()
+	hook := webhooks.NewEmail(client, []string{"ops@example.com"})
+	hook.BatchWindow = 10 * time.Millisecond
+	hook.IdleTimeout = 100 * time.Millisecond
+	log := logging.NewNoopLogger()
+
+	pull := models.PullRequest{Num: 1}
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyStartedEvent, Pull: pull, Directory: "dir1"}))
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyStartedEvent, Pull: pull, Directory: "dir2"}))
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent, Pull: pull, Directory: "dir1", Success: true}))
+
+	time.Sleep(5 * hook.BatchWindow)
+	client.VerifyWasCalled(Never()).SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString())
+	_, subject, _, _ := client.VerifyWasCalledEventually(Once(), time.Second).
+		SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString()).GetCapturedArguments()
+	Equals(t, "Apply succeeded for #1", subject)
+
+	// The batch should have been removed so later events start a new one.
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.LockReleasedEvent, Pull: pull}))
+	client.VerifyWasCalledEventually(Times(2), time.Second).SendEmail(AnyStringSlice(), AnyString(), AnyString(), AnyString())
+}
+
+func TestEmailWebhook_Close(t *testing.T) {
+	t.Log("Closing should send the open batches without waiting for their timers")
+	server := newSMTPStandIn(t)
+	defer server.Close()
+
+	hook := webhooks.NewEmail(webhooks.NewSMTPClient(server.Addr(), "", "", "atlantis@example.com", false), []string{"ops@example.com"})
+	hook.BatchWindow = time.Hour
+	log := logging.NewNoopLogger()
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent, Repo: models.Repo{FullName: "owner/repo"}, Pull: models.PullRequest{Num: 1}, Success: true}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	Ok(t, hook.Close(ctx))
+	received := server.Received(t)
+	Assert(t, strings.Contains(received.data, "Subject: Apply succeeded for owner/repo#1\r\n"), "unexpected email %q", received.data)
+
+	// Events sent after closing are dropped.
+	ErrContains(t, "is closed", hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	// Closing again shouldn't send anything or block.
+	Ok(t, hook.Close(ctx))
+}
+
+func TestEmailWebhook_CloseTimeout(t *testing.T) {
+	t.Log("Closing shouldn't wait longer than its context for a slow SMTP server")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	defer listener.Close() // nolint: errcheck
+	// Accept the connection but never greet the client.
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			defer conn.Close() // nolint: errcheck
+			time.Sleep(time.Second)
+		}
+	}()
+
+	hook := webhooks.NewEmail(webhooks.NewSMTPClient(listener.Addr().String(), "", "", "atlantis@example.com", false), []string{"ops@example.com"})
+	hook.BatchWindow = time.Hour
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ErrContains(t, "context deadline exceeded", hook.Close(ctx))
+}
+
+func TestSMTPClient_SendEmail(t *testing.T) {
+	server := newSMTPStandIn(t)
+	defer server.Close()
+
+	client := webhooks.NewSMTPClient(server.Addr(), "user", "pass", "atlantis@example.com", false)
+	Equals(t, true, client.IsConfigured())
+	err := client.SendEmail([]string{"a@example.com", "b@example.com"}, "Apply succeeded for owner/repo#1", "text body", "<p>html body</p>")
+	Ok(t, err)
+
+	received := server.Received(t)
+	Equals(t, "\x00user\x00pass", received.auth)
+	Equals(t, "atlantis@example.com", received.from)
+	Equals(t, []string{"a@example.com", "b@example.com"}, received.to)
+	for _, exp := range []string{
+		"From: atlantis@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: Apply succeeded for owner/repo#1\r\n",
+		"Content-Type: multipart/alternative;",
+		"Content-Type: text/plain; charset=utf-8",
+		"text body",
+		"Content-Type: text/html; charset=utf-8",
+		"<p>html body</p>",
+	} {
+		Assert(t, strings.Contains(received.data, exp), "expected email to contain %q but was %q", exp, received.data)
+	}
+}
+
+func TestSMTPClient_RequireTLS(t *testing.T) {
+	t.Log("If TLS is required, emails shouldn't be sent to a server that doesn't support STARTTLS")
+	server := newSMTPStandIn(t)
+	defer server.Close()
+
+	client := webhooks.NewSMTPClient(server.Addr(), "", "", "atlantis@example.com", true)
+	err := client.SendEmail([]string{"a@example.com"}, "subject", "text", "html")
+	ErrEquals(t, fmt.Sprintf("SMTP server %q doesn't support STARTTLS", server.Addr()), err)
+}
+
+func TestSMTPClient_Timeout(t *testing.T) {
+	t.Log("Sending should fail if the server doesn't respond")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	defer listener.Close() // nolint: errcheck
+
+	client := webhooks.NewSMTPClient(listener.Addr().String(), "", "", "atlantis@example.com", false)
+	client.Timeout = 50 * time.Millisecond
+	err = client.SendEmail([]string{"a@example.com"}, "subject", "text", "html")
+	ErrContains(t, "connecting to SMTP server", err)
+}
+
+func TestSMTPClient_NotConfigured(t *testing.T) {
+	Equals(t, false, webhooks.NewSMTPClient("", "", "", "", false).IsConfigured())
+}
+
+// smtpMessage is an email received by the SMTP stand-in.
+type smtpMessage struct {
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+// smtpStandIn is a minimal SMTP server for testing. It accepts one
+// connection and records the email sent over it.
+type smtpStandIn struct {
+	listener net.Listener
+	received chan smtpMessage
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	Ok(t, err)
+	s := &smtpStandIn{
+		listener: listener,
+		received: make(chan smtpMessage, 1),
+	}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *smtpStandIn) Close() {
+	s.listener.Close() // nolint: errcheck
+}
+
+// Received waits for an email to be received.
+func (s *smtpStandIn) Received(t *testing.T) smtpMessage {
+	select {
+	case msg := <-s.received:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for email")
+	}
+	return smtpMessage{}
+}
+
+func (s *smtpStandIn) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	c := textproto.NewConn(conn)
+	defer c.Close() // nolint: errcheck
+
+	var msg smtpMessage
+	c.PrintfLine("220 localhost ESMTP") // nolint: errcheck
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		arg := strings.TrimSpace(strings.TrimPrefix(line, strings.SplitN(line, " ", 2)[0]))
+		switch cmd {
+		case "EHLO":
+			c.PrintfLine("250-localhost\r\n250 AUTH PLAIN") // nolint: errcheck
+		case "AUTH":
+			decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(arg, "PLAIN "))
+			msg.auth = string(decoded)
+			c.PrintfLine("235 2.7.0 Authentication successful") // nolint: errcheck
+		case "MAIL":
+			msg.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+			c.PrintfLine("250 OK") // nolint: errcheck
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+			c.PrintfLine("250 OK") // nolint: errcheck
+		case "DATA":
+			c.PrintfLine("354 Go ahead") // nolint: errcheck
+			data, err := c.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			// ReadDotBytes converts line endings to \n so convert them back.
+			msg.data = strings.Replace(string(data), "\n", "\r\n", -1)
+			c.PrintfLine("250 OK") // nolint: errcheck
+		case "QUIT":
+			c.PrintfLine("221 Bye") // nolint: errcheck
+			s.received <- msg
+			return
+		default:
+			c.PrintfLine("502 Command not implemented") // nolint: errcheck
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// Event types that webhooks can be sent for.
+const (
+	PlanStartedEvent   = "plan_started"
+	PlanFinishedEvent  = "plan_finished"
+	ApplyStartedEvent  = "apply_started"
+	ApplyFinishedEvent = "apply_finished"
+	LockAcquiredEvent  = "lock_acquired"
+	LockReleasedEvent  = "lock_released"
+	AutomergeEvent     = "automerge"
+)
+
+// EventTypes are all the event types.
+var EventTypes = []string{
+	PlanStartedEvent,
+	PlanFinishedEvent,
+	ApplyStartedEvent,
+	ApplyFinishedEvent,
+	LockAcquiredEvent,
+	LockReleasedEvent,
+	AutomergeEvent,
+}
+
+// eventTitles describe each event type in messages.
+var eventTitles = map[string]string{
+	PlanStartedEvent:   "Plan started",
+	PlanFinishedEvent:  "Plan",
+	ApplyStartedEvent:  "Apply started",
+	ApplyFinishedEvent: "Apply",
+	LockAcquiredEvent:  "Lock acquired",
+	LockReleasedEvent:  "Lock released",
+	AutomergeEvent:     "Automerge",
+}
+
+// applySummaryRegex matches the line at the end of a successful apply that
+// counts the resources that were changed.
+var applySummaryRegex = regexp.MustCompile(`Apply complete! Resources: (.*)\.`)
+
+// Event is something that happened in Atlantis that webhooks can be sent
+// for.
+type Event struct {
+	// Type is one of the event types, ex. PlanFinishedEvent.
+	Type string
+	Repo models.Repo
+	Pull models.PullRequest
+	// User is who caused the event, ex. who commented atlantis apply. It can
+	// be empty, ex. if a lock was discarded via the UI without
+	// authentication.
+	User models.User
+	// ProjectName, Directory and Workspace identify the project. They're
+	// empty for automerge events. ProjectName is also empty for projects
+	// without a name.
+	ProjectName string
+	Directory   string
+	Workspace   string
+	// Success is true if the command or automerge succeeded. It's always true
+	// for started and lock events.
+	Success bool
+	// Output is the output of a finished plan or apply, with secrets
+	// redacted.
+	Output string
+	// PlanSummary is the summary line of a finished plan, ex.
+	// "Plan: 1 to add, 0 to change, 0 to destroy.".
+	PlanSummary string
+	// Error is why the command or automerge failed.
+	Error string
+	// JobURL is the URL of the page showing the output of the plan or apply.
+	// It's empty for other events or if it's unknown.
+	JobURL string
+}
+
+func isEventType(t string) bool {
+	for _, e := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinishedEvent returns true if events of type t say whether something
+// succeeded or failed.
+func isFinishedEvent(t string) bool {
+	return t == PlanFinishedEvent || t == ApplyFinishedEvent || t == AutomergeEvent
+}
+
+// isStartedEvent returns true if events of type t are sent before a command
+// runs.
+func isStartedEvent(t string) bool {
+	return t == PlanStartedEvent || t == ApplyStartedEvent
+}
+
+// eventTitle describes an event of type t in messages, ex. "Apply failed".
+func eventTitle(t string, success bool) string {
+	title, ok := eventTitles[t]
+	if !ok {
+		title = t
+	}
+	if !isFinishedEvent(t) {
+		return title
+	}
+	if success {
+		return title + " succeeded"
+	}
+	return title + " failed"
+}
+
+// resourceSummary returns how many resources a finished plan or apply adds,
+// changes and destroys, or an empty string if it isn't known.
+func resourceSummary(event Event) string {
+	switch event.Type {
+	case PlanFinishedEvent:
+		return strings.TrimPrefix(event.PlanSummary, "Plan: ")
+	case ApplyFinishedEvent:
+		if match := applySummaryRegex.FindStringSubmatch(event.Output); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,272 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// HTTPPayloadVersion is the version of the JSON payload sent by HTTP
+	// webhooks. It's incremented when fields are removed or changed so
+	// receivers can tell payloads apart.
+	HTTPPayloadVersion = 1
+	// HTTPSignatureHeader is the header that holds the HMAC-SHA256 signature
+	// of the timestamp and payload if a secret is configured. Its value is of
+	// the form "sha256=<hex digest>".
+	HTTPSignatureHeader = "X-Atlantis-Signature"
+	// HTTPTimestampHeader is the header that holds the Unix time the request
+	// was sent at. It's signed with the payload so receivers can reject
+	// replayed requests.
+	HTTPTimestampHeader = "X-Atlantis-Timestamp"
+	// HTTPEventHeader is the header that holds the event that caused the
+	// webhook, ex. apply_finished.
+	HTTPEventHeader = "X-Atlantis-Event"
+
+	defaultHTTPQueueSize   = 100
+	defaultHTTPMaxAttempts = 3
+	defaultHTTPBackoff     = time.Second
+	defaultHTTPTimeout     = 10 * time.Second
+)
+
+// HTTPWebhook sends webhooks as JSON POST requests to a URL. Payloads are
+// queued and delivered asynchronously so slow receivers don't block the
+// command that triggered the webhook.
+type HTTPWebhook struct {
+	Client *http.Client
+	URL    string
+	// Headers are added to every request.
+	Headers map[string]string
+	// Secret is used to sign payloads. If empty, payloads aren't signed.
+	Secret string
+	// MaxAttempts is how many times delivering a payload is attempted before
+	// giving up.
+	MaxAttempts int
+	// Backoff is how long to wait before the first retry. It doubles after
+	// each retry.
+	Backoff time.Duration
+
+	queue     chan httpDelivery
+	startOnce sync.Once
+	// done is closed once the queue is closed and drained.
+	done chan struct{}
+	// mu guards closed and stops Send writing to the queue after it's
+	// closed.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// httpDelivery is a payload waiting to be delivered.
+type httpDelivery struct {
+	log     *logging.SimpleLogger
+	payload HTTPPayload
+}
+
+// HTTPPayload is the JSON body sent by HTTP webhooks.
+type HTTPPayload struct {
+	Version     int             `json:"version"`
+	Event       string          `json:"event"`
+	ProjectName string          `json:"project_name"`
+	Workspace   string          `json:"workspace"`
+	Directory   string          `json:"directory"`
+	Success     bool            `json:"success"`
+	User        string          `json:"user"`
+	Output      string          `json:"output"`
+	PlanSummary string          `json:"plan_summary"`
+	Error       string          `json:"error"`
+	JobURL      string          `json:"job_url"`
+	Repo        HTTPPayloadRepo `json:"repo"`
+	Pull        HTTPPayloadPull `json:"pull"`
+}
+
+// HTTPPayloadRepo is the repo in an HTTPPayload.
+type HTTPPayloadRepo struct {
+	FullName string `json:"full_name"`
+	Hostname string `json:"hostname"`
+}
+
+// HTTPPayloadPull is the pull request in an HTTPPayload.
+type HTTPPayloadPull struct {
+	Num        int    `json:"num"`
+	URL        string `json:"url"`
+	Author     string `json:"author"`
+	HeadCommit string `json:"head_commit"`
+	HeadBranch string `json:"head_branch"`
+	BaseBranch string `json:"base_branch"`
+}
+
+// NewHTTP returns an HTTPWebhook that posts to url. Its queue holds at most
+// queueSize payloads. If queueSize is 0, a default size is used.
+func NewHTTP(url string, headers map[string]string, secret string, queueSize int) *HTTPWebhook {
+	if queueSize <= 0 {
+		queueSize = defaultHTTPQueueSize
+	}
+	return &HTTPWebhook{
+		Client:      &http.Client{Timeout: defaultHTTPTimeout},
+		URL:         url,
+		Headers:     headers,
+		Secret:      secret,
+		MaxAttempts: defaultHTTPMaxAttempts,
+		Backoff:     defaultHTTPBackoff,
+		queue:       make(chan httpDelivery, queueSize),
+		done:        make(chan struct{}),
+	}
+}
+
+// Send queues the webhook for delivery. It returns an error if the queue is
+// full or the webhook was closed.
+func (h *HTTPWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	h.startOnce.Do(func() { go h.deliverQueued() })
+
+	delivery := httpDelivery{
+		log:     log,
+		payload: newHTTPPayload(event),
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.closed {
+		return fmt.Errorf("webhook to %s is closed, dropping %s event", h.URL, event.Type)
+	}
+	select {
+	case h.queue <- delivery:
+		return nil
+	default:
+		return fmt.Errorf("queue for webhook to %s is full, dropping %s event", h.URL, event.Type)
+	}
+}
+
+// Close stops new webhooks being queued and waits for the queued ones to be
+// delivered. If ctx ends first, the remaining webhooks aren't delivered.
+func (h *HTTPWebhook) Close(ctx context.Context) error {
+	h.startOnce.Do(func() { go h.deliverQueued() })
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.queue)
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-h.done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "delivering queued webhooks to %s", h.URL)
+	}
+}
+
+// deliverQueued delivers queued payloads, one at a time, until the queue is
+// closed.
+func (h *HTTPWebhook) deliverQueued() {
+	defer close(h.done)
+	for d := range h.queue {
+		if err := h.deliver(d.payload); err != nil {
+			d.log.Warn("error sending webhook to %s: %s", h.URL, err)
+		}
+	}
+}
+
+// deliver posts payload to the URL, retrying with backoff on network errors
+// and server errors.
+func (h *HTTPWebhook) deliver(payload HTTPPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshalling payload")
+	}
+
+	backoff := h.Backoff
+	for attempt := 1; ; attempt++ {
+		retryable, err := h.post(payload.Event, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= h.MaxAttempts {
+			return errors.Wrapf(err, "giving up after %d attempt(s)", attempt)
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post makes one request. If it fails, retryable is true if the request
+// should be tried again.
+func (h *HTTPWebhook) post(event string, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, errors.Wrap(err, "creating request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HTTPEventHeader, event)
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HTTPTimestampHeader, timestamp)
+	if h.Secret != "" {
+		req.Header.Set(HTTPSignatureHeader, HTTPSignature(h.Secret, timestamp, body))
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	// The body must be read to the end for the connection to be reused.
+	io.Copy(ioutil.Discard, resp.Body) // nolint: errcheck
+	resp.Body.Close()                  // nolint: errcheck
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retryable = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retryable, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+}
+
+// HTTPSignature returns the value of the HTTPSignatureHeader for timestamp,
+// the value of the HTTPTimestampHeader, and body signed with secret. The
+// signed message is the timestamp, a ".", then the body. Receivers can
+// compute it to verify payloads came from Atlantis.
+func HTTPSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + ".")) // nolint: errcheck
+	mac.Write(body)                    // nolint: errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newHTTPPayload(event Event) HTTPPayload {
+	return HTTPPayload{
+		Version:     HTTPPayloadVersion,
+		Event:       event.Type,
+		ProjectName: event.ProjectName,
+		Workspace:   event.Workspace,
+		Directory:   event.Directory,
+		Success:     event.Success,
+		User:        event.User.Username,
+		Output:      event.Output,
+		PlanSummary: event.PlanSummary,
+		Error:       event.Error,
+		JobURL:      event.JobURL,
+		Repo: HTTPPayloadRepo{
+			FullName: event.Repo.FullName,
+			Hostname: event.Repo.VCSHost.Hostname,
+		},
+		Pull: HTTPPayloadPull{
+			Num:        event.Pull.Num,
+			URL:        event.Pull.URL,
+			Author:     event.Pull.Author,
+			HeadCommit: event.Pull.HeadCommit,
+			HeadBranch: event.Pull.HeadBranch,
+			BaseBranch: event.Pull.BaseBranch,
+		},
+	}
+}
@@ -0,0 +1,243 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+// receivedRequest is a request received by a test server.
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver starts a test server that responds with the given status codes,
+// in order, and then 200. Received requests are sent on the returned channel.
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, chan receivedRequest) {
+	requests := make(chan receivedRequest, 10)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		Ok(t, err)
+		requests <- receivedRequest{header: r.Header, body: body}
+		call := int(atomic.AddInt32(&calls, 1))
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+		}
+	}))
+	return server, requests
+}
+
+// nextRequest waits for a request to be received.
+func nextRequest(t *testing.T, requests chan receivedRequest) receivedRequest {
+	select {
+	case req := <-requests:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for webhook")
+	}
+	return receivedRequest{}
+}
+
+// noRequest asserts that no request is received.
+func noRequest(t *testing.T, requests chan receivedRequest) {
+	select {
+	case <-requests:
+		t.Fatal("expected no more webhooks")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestHTTPWebhook_Send(t *testing.T) {
+	server, requests := receiver(t)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, map[string]string{"Authorization": "Bearer token"}, "secret", 0)
+	err := hook.Send(logging.NewNoopLogger(), webhooks.Event{
+		Type:        webhooks.PlanFinishedEvent,
+		ProjectName: "project",
+		Workspace:   "production",
+		Directory:   "dir",
+		Success:     true,
+		User:        models.User{Username: "lkysow"},
+		Output:      "output",
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+		Error:       "",
+		JobURL:      "https://atlantis/jobs/id",
+		Repo: models.Repo{
+			FullName: "runatlantis/atlantis",
+			VCSHost:  models.VCSHost{Hostname: "github.com"},
+		},
+		Pull: models.PullRequest{
+			Num:        1,
+			URL:        "url",
+			Author:     "author",
+			HeadCommit: "sha",
+			HeadBranch: "branch",
+			BaseBranch: "master",
+		},
+	})
+	Ok(t, err)
+
+	req := nextRequest(t, requests)
+	Equals(t, "application/json", req.header.Get("Content-Type"))
+	Equals(t, "plan_finished", req.header.Get(webhooks.HTTPEventHeader))
+	Equals(t, "Bearer token", req.header.Get("Authorization"))
+	timestamp, err := strconv.ParseInt(req.header.Get(webhooks.HTTPTimestampHeader), 10, 64)
+	Ok(t, err)
+	Assert(t, time.Since(time.Unix(timestamp, 0)) < time.Minute, "exp timestamp to be recent but was %d", timestamp)
+	Equals(t, webhooks.HTTPSignature("secret", req.header.Get(webhooks.HTTPTimestampHeader), req.body), req.header.Get(webhooks.HTTPSignatureHeader))
+
+	var payload webhooks.HTTPPayload
+	Ok(t, json.Unmarshal(req.body, &payload))
+	Equals(t, webhooks.HTTPPayload{
+		Version:     1,
+		Event:       "plan_finished",
+		ProjectName: "project",
+		Workspace:   "production",
+		Directory:   "dir",
+		Success:     true,
+		User:        "lkysow",
+		Output:      "output",
+		PlanSummary: "Plan: 1 to add, 0 to change, 0 to destroy.",
+		JobURL:      "https://atlantis/jobs/id",
+		Repo: webhooks.HTTPPayloadRepo{
+			FullName: "runatlantis/atlantis",
+			Hostname: "github.com",
+		},
+		Pull: webhooks.HTTPPayloadPull{
+			Num:        1,
+			URL:        "url",
+			Author:     "author",
+			HeadCommit: "sha",
+			HeadBranch: "branch",
+			BaseBranch: "master",
+		},
+	}, payload)
+}
+
+func TestHTTPWebhook_SendNoSecret(t *testing.T) {
+	server, requests := receiver(t)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 0)
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	req := nextRequest(t, requests)
+	Equals(t, "", req.header.Get(webhooks.HTTPSignatureHeader))
+}
+
+func TestHTTPWebhook_Retries(t *testing.T) {
+	t.Log("Server errors should be retried until MaxAttempts")
+	server, requests := receiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, nil, "secret", 0)
+	hook.Backoff = time.Millisecond
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	first := nextRequest(t, requests)
+	second := nextRequest(t, requests)
+	third := nextRequest(t, requests)
+	noRequest(t, requests)
+
+	// Retries send the same payload.
+	Equals(t, first.body, second.body)
+	Equals(t, first.body, third.body)
+}
+
+func TestHTTPWebhook_RetriesUntilSuccess(t *testing.T) {
+	server, requests := receiver(t, http.StatusTooManyRequests)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 0)
+	hook.Backoff = time.Millisecond
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	nextRequest(t, requests)
+	nextRequest(t, requests)
+	noRequest(t, requests)
+}
+
+func TestHTTPWebhook_NoRetryOnClientError(t *testing.T) {
+	server, requests := receiver(t, http.StatusBadRequest)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 0)
+	hook.Backoff = time.Millisecond
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	nextRequest(t, requests)
+	noRequest(t, requests)
+}
+
+func TestHTTPWebhook_QueueFull(t *testing.T) {
+	t.Log("Send shouldn't block when the receiver is slow and the queue is full")
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 1)
+	log := logging.NewNoopLogger()
+	// The first payload is picked up by the delivery goroutine and blocks.
+	// The second fills the queue so a third must be dropped.
+	Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent})
+	}
+	ErrContains(t, "is full", err)
+}
+
+func TestHTTPSignature(t *testing.T) {
+	// The timestamp is signed so it can't be changed to replay a request.
+	sig := webhooks.HTTPSignature("secret", "1600000000", []byte(`{"event":"apply_finished"}`))
+	Equals(t, "sha256=", sig[:7])
+	Assert(t, sig != webhooks.HTTPSignature("secret", "1600000001", []byte(`{"event":"apply_finished"}`)), "exp signature to depend on the timestamp")
+}
+
+func TestHTTPWebhook_Close(t *testing.T) {
+	t.Log("Closing should wait for queued webhooks to be delivered")
+	server, requests := receiver(t)
+	defer server.Close()
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 0)
+	log := logging.NewNoopLogger()
+	for i := 0; i < 3; i++ {
+		Ok(t, hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	}
+	Ok(t, hook.Close(context.Background()))
+	Equals(t, 3, len(requests))
+
+	// Webhooks sent after closing are dropped.
+	ErrContains(t, "is closed", hook.Send(log, webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	// Closing again shouldn't panic.
+	Ok(t, hook.Close(context.Background()))
+}
+
+func TestHTTPWebhook_CloseTimeout(t *testing.T) {
+	t.Log("Closing shouldn't wait longer than its context for a slow receiver")
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	hook := webhooks.NewHTTP(server.URL, nil, "", 0)
+	Ok(t, hook.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ErrContains(t, "context deadline exceeded", hook.Close(ctx))
+}
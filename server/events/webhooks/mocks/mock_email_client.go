@@ -0,0 +1,148 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events/webhooks (interfaces: EmailClient)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	"reflect"
+	"time"
+)
+
+type MockEmailClient struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockEmailClient(options ...pegomock.Option) *MockEmailClient {
+	mock := &MockEmailClient{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockEmailClient) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockEmailClient) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockEmailClient) IsConfigured() bool {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEmailClient().")
+	}
+	params := []pegomock.Param{}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("IsConfigured", params, []reflect.Type{reflect.TypeOf((*bool)(nil)).Elem()})
+	var ret0 bool
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(bool)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockEmailClient) SendEmail(to []string, subject string, text string, html string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockEmailClient().")
+	}
+	params := []pegomock.Param{to, subject, text, html}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("SendEmail", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockEmailClient) VerifyWasCalledOnce() *VerifierMockEmailClient {
+	return &VerifierMockEmailClient{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockEmailClient) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockEmailClient {
+	return &VerifierMockEmailClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockEmailClient) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockEmailClient {
+	return &VerifierMockEmailClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockEmailClient) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockEmailClient {
+	return &VerifierMockEmailClient{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockEmailClient struct {
+	mock                   *MockEmailClient
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockEmailClient) IsConfigured() *MockEmailClient_IsConfigured_OngoingVerification {
+	params := []pegomock.Param{}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "IsConfigured", params, verifier.timeout)
+	return &MockEmailClient_IsConfigured_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEmailClient_IsConfigured_OngoingVerification struct {
+	mock              *MockEmailClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEmailClient_IsConfigured_OngoingVerification) GetCapturedArguments() {
+}
+
+func (c *MockEmailClient_IsConfigured_OngoingVerification) GetAllCapturedArguments() {
+}
+
+func (verifier *VerifierMockEmailClient) SendEmail(to []string, subject string, text string, html string) *MockEmailClient_SendEmail_OngoingVerification {
+	params := []pegomock.Param{to, subject, text, html}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SendEmail", params, verifier.timeout)
+	return &MockEmailClient_SendEmail_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockEmailClient_SendEmail_OngoingVerification struct {
+	mock              *MockEmailClient
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockEmailClient_SendEmail_OngoingVerification) GetCapturedArguments() ([]string, string, string, string) {
+	to, subject, text, html := c.GetAllCapturedArguments()
+	return to[len(to)-1], subject[len(subject)-1], text[len(text)-1], html[len(html)-1]
+}
+
+func (c *MockEmailClient_SendEmail_OngoingVerification) GetAllCapturedArguments() (_param0 [][]string, _param1 []string, _param2 []string, _param3 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([][]string, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.([]string)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+		_param3 = make([]string, len(params[3]))
+		for u, param := range params[3] {
+			_param3[u] = param.(string)
+		}
+	}
+	return
+}
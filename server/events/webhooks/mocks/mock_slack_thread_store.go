@@ -0,0 +1,163 @@
+// Code generated by pegomock. DO NOT EDIT.
+// Source: github.com/runatlantis/atlantis/server/events/webhooks (interfaces: SlackThreadStore)
+
+package mocks
+
+import (
+	pegomock "github.com/petergtz/pegomock"
+	models "github.com/runatlantis/atlantis/server/events/models"
+	"reflect"
+	"time"
+)
+
+type MockSlackThreadStore struct {
+	fail func(message string, callerSkip ...int)
+}
+
+func NewMockSlackThreadStore(options ...pegomock.Option) *MockSlackThreadStore {
+	mock := &MockSlackThreadStore{}
+	for _, option := range options {
+		option.Apply(mock)
+	}
+	return mock
+}
+
+func (mock *MockSlackThreadStore) SetFailHandler(fh pegomock.FailHandler) { mock.fail = fh }
+func (mock *MockSlackThreadStore) FailHandler() pegomock.FailHandler      { return mock.fail }
+
+func (mock *MockSlackThreadStore) GetSlackThread(pull models.PullRequest, channel string) (string, error) {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSlackThreadStore().")
+	}
+	params := []pegomock.Param{pull, channel}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("GetSlackThread", params, []reflect.Type{reflect.TypeOf((*string)(nil)).Elem(), reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 string
+	var ret1 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(string)
+		}
+		if result[1] != nil {
+			ret1 = result[1].(error)
+		}
+	}
+	return ret0, ret1
+}
+
+func (mock *MockSlackThreadStore) SetSlackThread(pull models.PullRequest, channel string, ts string) error {
+	if mock == nil {
+		panic("mock must not be nil. Use myMock := NewMockSlackThreadStore().")
+	}
+	params := []pegomock.Param{pull, channel, ts}
+	result := pegomock.GetGenericMockFrom(mock).Invoke("SetSlackThread", params, []reflect.Type{reflect.TypeOf((*error)(nil)).Elem()})
+	var ret0 error
+	if len(result) != 0 {
+		if result[0] != nil {
+			ret0 = result[0].(error)
+		}
+	}
+	return ret0
+}
+
+func (mock *MockSlackThreadStore) VerifyWasCalledOnce() *VerifierMockSlackThreadStore {
+	return &VerifierMockSlackThreadStore{
+		mock:                   mock,
+		invocationCountMatcher: pegomock.Times(1),
+	}
+}
+
+func (mock *MockSlackThreadStore) VerifyWasCalled(invocationCountMatcher pegomock.Matcher) *VerifierMockSlackThreadStore {
+	return &VerifierMockSlackThreadStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+	}
+}
+
+func (mock *MockSlackThreadStore) VerifyWasCalledInOrder(invocationCountMatcher pegomock.Matcher, inOrderContext *pegomock.InOrderContext) *VerifierMockSlackThreadStore {
+	return &VerifierMockSlackThreadStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		inOrderContext:         inOrderContext,
+	}
+}
+
+func (mock *MockSlackThreadStore) VerifyWasCalledEventually(invocationCountMatcher pegomock.Matcher, timeout time.Duration) *VerifierMockSlackThreadStore {
+	return &VerifierMockSlackThreadStore{
+		mock:                   mock,
+		invocationCountMatcher: invocationCountMatcher,
+		timeout:                timeout,
+	}
+}
+
+type VerifierMockSlackThreadStore struct {
+	mock                   *MockSlackThreadStore
+	invocationCountMatcher pegomock.Matcher
+	inOrderContext         *pegomock.InOrderContext
+	timeout                time.Duration
+}
+
+func (verifier *VerifierMockSlackThreadStore) GetSlackThread(pull models.PullRequest, channel string) *MockSlackThreadStore_GetSlackThread_OngoingVerification {
+	params := []pegomock.Param{pull, channel}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "GetSlackThread", params, verifier.timeout)
+	return &MockSlackThreadStore_GetSlackThread_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSlackThreadStore_GetSlackThread_OngoingVerification struct {
+	mock              *MockSlackThreadStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSlackThreadStore_GetSlackThread_OngoingVerification) GetCapturedArguments() (models.PullRequest, string) {
+	pull, channel := c.GetAllCapturedArguments()
+	return pull[len(pull)-1], channel[len(channel)-1]
+}
+
+func (c *MockSlackThreadStore_GetSlackThread_OngoingVerification) GetAllCapturedArguments() (_param0 []models.PullRequest, _param1 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.PullRequest, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.PullRequest)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+	}
+	return
+}
+
+func (verifier *VerifierMockSlackThreadStore) SetSlackThread(pull models.PullRequest, channel string, ts string) *MockSlackThreadStore_SetSlackThread_OngoingVerification {
+	params := []pegomock.Param{pull, channel, ts}
+	methodInvocations := pegomock.GetGenericMockFrom(verifier.mock).Verify(verifier.inOrderContext, verifier.invocationCountMatcher, "SetSlackThread", params, verifier.timeout)
+	return &MockSlackThreadStore_SetSlackThread_OngoingVerification{mock: verifier.mock, methodInvocations: methodInvocations}
+}
+
+type MockSlackThreadStore_SetSlackThread_OngoingVerification struct {
+	mock              *MockSlackThreadStore
+	methodInvocations []pegomock.MethodInvocation
+}
+
+func (c *MockSlackThreadStore_SetSlackThread_OngoingVerification) GetCapturedArguments() (models.PullRequest, string, string) {
+	pull, channel, ts := c.GetAllCapturedArguments()
+	return pull[len(pull)-1], channel[len(channel)-1], ts[len(ts)-1]
+}
+
+func (c *MockSlackThreadStore_SetSlackThread_OngoingVerification) GetAllCapturedArguments() (_param0 []models.PullRequest, _param1 []string, _param2 []string) {
+	params := pegomock.GetGenericMockFrom(c.mock).GetInvocationParams(c.methodInvocations)
+	if len(params) > 0 {
+		_param0 = make([]models.PullRequest, len(params[0]))
+		for u, param := range params[0] {
+			_param0[u] = param.(models.PullRequest)
+		}
+		_param1 = make([]string, len(params[1]))
+		for u, param := range params[1] {
+			_param1[u] = param.(string)
+		}
+		_param2 = make([]string, len(params[2]))
+		for u, param := range params[2] {
+			_param2[u] = param.(string)
+		}
+	}
+	return
+}
@@ -15,21 +15,53 @@ package webhooks
 
 import (
 	"regexp"
+	"sync"
 
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
+//go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_slack_thread_store.go SlackThreadStore
+
+// SlackThreadStore stores the Slack thread of each pull request so all the
+// messages about a pull request are posted in one thread.
+type SlackThreadStore interface {
+	// GetSlackThread returns the timestamp of the thread for pull in channel
+	// or an empty string if there isn't one yet.
+	GetSlackThread(pull models.PullRequest, channel string) (string, error)
+	// SetSlackThread stores ts as the timestamp of the thread for pull in
+	// channel.
+	SetSlackThread(pull models.PullRequest, channel string, ts string) error
+}
+
 // SlackWebhook sends webhooks to Slack.
 type SlackWebhook struct {
 	Client         SlackClient
 	WorkspaceRegex *regexp.Regexp
 	Channel        string
+	// Threads stores the thread of each pull request. If nil, every message
+	// is posted to the channel directly.
+	Threads SlackThreadStore
+
+	// mu guards threadLocks.
+	mu sync.Mutex
+	// threadLocks serialize sends for each pull request so two events for the
+	// same pull request can't both start a thread. Events for different pull
+	// requests are sent concurrently.
+	threadLocks map[string]*threadLock
+}
+
+// threadLock is the lock for a pull request's thread. refs counts the sends
+// using it so it can be removed once none are.
+type threadLock struct {
+	mu   sync.Mutex
+	refs int
 }
 
-func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebhook, error) {
+func NewSlack(r *regexp.Regexp, channel string, client SlackClient, threads SlackThreadStore) (*SlackWebhook, error) {
 	if err := client.AuthTest(); err != nil {
 		return nil, fmt.Errorf("testing slack authentication: %s. Verify your slack-token is valid", err)
 	}
@@ -46,13 +78,63 @@ func NewSlack(r *regexp.Regexp, channel string, client SlackClient) (*SlackWebho
 		Client:         client,
 		WorkspaceRegex: r,
 		Channel:        channel,
+		Threads:        threads,
 	}, nil
 }
 
-// Send sends the webhook to Slack if the workspace matches the regex.
-func (s *SlackWebhook) Send(log *logging.SimpleLogger, applyResult ApplyResult) error {
-	if !s.WorkspaceRegex.MatchString(applyResult.Workspace) {
+// Send sends the webhook to Slack if the workspace matches the regex. If
+// Threads is set, it's posted in the pull request's thread.
+func (s *SlackWebhook) Send(log *logging.SimpleLogger, event Event) error {
+	if !s.WorkspaceRegex.MatchString(event.Workspace) {
 		return nil
 	}
-	return s.Client.PostMessage(s.Channel, applyResult)
+	if s.Threads == nil {
+		_, err := s.Client.PostMessage(s.Channel, "", event)
+		return err
+	}
+
+	unlock := s.lockThread(event.Pull)
+	defer unlock()
+	threadTS, err := s.Threads.GetSlackThread(event.Pull, s.Channel)
+	if err != nil {
+		return errors.Wrap(err, "getting slack thread")
+	}
+	ts, err := s.Client.PostMessage(s.Channel, threadTS, event)
+	if err != nil {
+		return err
+	}
+	if threadTS == "" {
+		// The first message about a pull request starts its thread.
+		if err := s.Threads.SetSlackThread(event.Pull, s.Channel, ts); err != nil {
+			return errors.Wrap(err, "saving slack thread")
+		}
+	}
+	return nil
+}
+
+// lockThread locks the thread of pull and returns the function to unlock it.
+func (s *SlackWebhook) lockThread(pull models.PullRequest) func() {
+	key := fmt.Sprintf("%s#%d", pull.BaseRepo.ID(), pull.Num)
+	s.mu.Lock()
+	if s.threadLocks == nil {
+		s.threadLocks = make(map[string]*threadLock)
+	}
+	l, ok := s.threadLocks[key]
+	if !ok {
+		l = &threadLock{}
+		s.threadLocks[key] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(s.threadLocks, key)
+		}
+	}
 }
@@ -15,13 +15,17 @@ package webhooks
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/nlopes/slack"
 )
 
 const (
-	slackSuccessColour = "good"
-	slackFailureColour = "danger"
+	slackSuccessColour    = "good"
+	slackFailureColour    = "danger"
+	slackInProgressColour = "#439FE0"
+	// maxSlackErrorLen is how much of an error is included in a message.
+	maxSlackErrorLen = 1000
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_slack_client.go SlackClient
@@ -31,7 +35,10 @@ type SlackClient interface {
 	AuthTest() error
 	TokenIsSet() bool
 	ChannelExists(channelName string) (bool, error)
-	PostMessage(channel string, applyResult ApplyResult) error
+	// PostMessage posts a message about event to channel and returns its
+	// timestamp. If threadTS is set, the message is posted as a reply in
+	// that thread.
+	PostMessage(channel string, threadTS string, event Event) (string, error)
 }
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_underlying_slack_client.go UnderlyingSlackClient
@@ -78,53 +85,68 @@ func (d *DefaultSlackClient) ChannelExists(channelName string) (bool, error) {
 	return false, nil
 }
 
-func (d *DefaultSlackClient) PostMessage(channel string, applyResult ApplyResult) error {
+func (d *DefaultSlackClient) PostMessage(channel string, threadTS string, event Event) (string, error) {
 	params := slack.NewPostMessageParameters()
-	params.Attachments = d.createAttachments(applyResult)
+	params.Attachments = d.createAttachments(event)
 	params.AsUser = true
 	params.EscapeText = false
-	_, _, err := d.Slack.PostMessage(channel, "", params)
-	return err
+	params.ThreadTimestamp = threadTS
+	_, ts, err := d.Slack.PostMessage(channel, "", params)
+	return ts, err
 }
 
-func (d *DefaultSlackClient) createAttachments(applyResult ApplyResult) []slack.Attachment {
-	var colour string
-	var successWord string
-	if applyResult.Success {
-		colour = slackSuccessColour
-		successWord = "succeeded"
-	} else {
-		colour = slackFailureColour
-		successWord = "failed"
+func (d *DefaultSlackClient) createAttachments(event Event) []slack.Attachment {
+	colour := slackInProgressColour
+	if isFinishedEvent(event.Type) {
+		if event.Success {
+			colour = slackSuccessColour
+		} else {
+			colour = slackFailureColour
+		}
 	}
 
-	text := fmt.Sprintf("Apply %s for <%s|%s>", successWord, applyResult.Pull.URL, applyResult.Repo.FullName)
-	directory := applyResult.Directory
+	text := fmt.Sprintf("%s for <%s|%s#%d>", eventTitle(event.Type, event.Success), event.Pull.URL, event.Repo.FullName, event.Pull.Num)
+	directory := event.Directory
 	// Since "." looks weird, replace it with "/" to make it clear this is the root.
 	if directory == "." {
 		directory = "/"
 	}
 
+	var fields []slack.AttachmentField
+	addField := func(title string, value string, short bool) {
+		if value != "" {
+			fields = append(fields, slack.AttachmentField{Title: title, Value: value, Short: short})
+		}
+	}
+	addField("Project", event.ProjectName, true)
+	addField("Workspace", event.Workspace, true)
+	addField("User", event.User.Username, true)
+	addField("Directory", directory, true)
+	addField("Resources", resourceSummary(event), false)
+	if event.JobURL != "" {
+		addField("Output", fmt.Sprintf("<%s|View output>", event.JobURL), true)
+	}
+	if event.Error != "" {
+		addField("Error", fmt.Sprintf("```%s```", truncate(event.Error, maxSlackErrorLen)), false)
+	}
+
 	attachment := slack.Attachment{
-		Color: colour,
-		Text:  text,
-		Fields: []slack.AttachmentField{
-			{
-				Title: "Workspace",
-				Value: applyResult.Workspace,
-				Short: true,
-			},
-			{
-				Title: "User",
-				Value: applyResult.User.Username,
-				Short: true,
-			},
-			{
-				Title: "Directory",
-				Value: directory,
-				Short: true,
-			},
-		},
+		Color:  colour,
+		Text:   text,
+		Fields: fields,
 	}
 	return []slack.Attachment{attachment}
 }
+
+// truncate shortens s to at most max bytes followed by "...". It cuts
+// before a multi-byte character rather than through it so s stays valid
+// UTF-8.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
+	}
+	return s[:max] + "..."
+}
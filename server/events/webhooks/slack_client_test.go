@@ -16,12 +16,14 @@ package webhooks_test
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/nlopes/slack"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks/mocks/matchers"
 
 	. "github.com/petergtz/pegomock"
 	. "github.com/runatlantis/atlantis/testing"
@@ -29,7 +31,7 @@ import (
 
 var underlying *mocks.MockUnderlyingSlackClient
 var client webhooks.DefaultSlackClient
-var result webhooks.ApplyResult
+var result webhooks.Event
 
 func TestAuthTest_Success(t *testing.T) {
 	t.Log("When the underlying client succeeds, function should succeed")
@@ -97,7 +99,7 @@ func TestPostMessage_Success(t *testing.T) {
 	expParams := slack.NewPostMessageParameters()
 	expParams.Attachments = []slack.Attachment{{
 		Color: "good",
-		Text:  "Apply succeeded for <url|runatlantis/atlantis>",
+		Text:  "Apply succeeded for <url|runatlantis/atlantis#1>",
 		Fields: []slack.AttachmentField{
 			{
 				Title: "Workspace",
@@ -111,7 +113,7 @@ func TestPostMessage_Success(t *testing.T) {
 			},
 			{
 				Title: "Directory",
-				Value: result.Directory,
+				Value: "/",
 				Short: true,
 			},
 		},
@@ -120,53 +122,149 @@ func TestPostMessage_Success(t *testing.T) {
 	expParams.EscapeText = false
 
 	channel := "somechannel"
-	err := client.PostMessage(channel, result)
+	When(underlying.PostMessage(channel, "", expParams)).ThenReturn(channel, "1234.5678", nil)
+	ts, err := client.PostMessage(channel, "", result)
 	Ok(t, err)
+	Equals(t, "1234.5678", ts)
 	underlying.VerifyWasCalledOnce().PostMessage(channel, "", expParams)
 
 	t.Log("When apply fails, function should succeed and indicate failure")
 	result.Success = false
+	result.Error = "error"
 	expParams.Attachments[0].Color = "danger"
-	expParams.Attachments[0].Text = "Apply failed for <url|runatlantis/atlantis>"
+	expParams.Attachments[0].Text = "Apply failed for <url|runatlantis/atlantis#1>"
+	expParams.Attachments[0].Fields = append(expParams.Attachments[0].Fields, slack.AttachmentField{
+		Title: "Error",
+		Value: "```error```",
+	})
 
-	err = client.PostMessage(channel, result)
+	_, err = client.PostMessage(channel, "", result)
 	Ok(t, err)
 	underlying.VerifyWasCalledOnce().PostMessage(channel, "", expParams)
 }
 
-func TestPostMessage_Error(t *testing.T) {
-	t.Log("When the underlying slack client errors, an error should be returned")
+func TestPostMessage_Thread(t *testing.T) {
+	t.Log("When a thread is given, the message should be posted in it")
 	setup(t)
+	_, err := client.PostMessage("somechannel", "1234.5678", result)
+	Ok(t, err)
+	_, _, params := underlying.VerifyWasCalledOnce().PostMessage(AnyString(), AnyString(), matchers.AnySlackPostMessageParameters()).GetCapturedArguments()
+	Equals(t, "1234.5678", params.ThreadTimestamp)
+}
 
-	expParams := slack.NewPostMessageParameters()
-	expParams.Attachments = []slack.Attachment{{
-		Color: "good",
-		Text:  "Apply succeeded for <url|runatlantis/atlantis>",
-		Fields: []slack.AttachmentField{
-			{
-				Title: "Workspace",
-				Value: result.Workspace,
-				Short: true,
+func TestPostMessage_Events(t *testing.T) {
+	cases := []struct {
+		description string
+		event       webhooks.Event
+		expColour   string
+		expText     string
+		expFields   []slack.AttachmentField
+	}{
+		{
+			"plan started",
+			webhooks.Event{
+				Type:      webhooks.PlanStartedEvent,
+				Workspace: "default",
+				Directory: "dir",
 			},
-			{
-				Title: "User",
-				Value: result.User.Username,
-				Short: true,
+			"#439FE0",
+			"Plan started for <url|runatlantis/atlantis#1>",
+			[]slack.AttachmentField{
+				{Title: "Workspace", Value: "default", Short: true},
+				{Title: "Directory", Value: "dir", Short: true},
 			},
-			{
-				Title: "Directory",
-				Value: result.Directory,
-				Short: true,
+		},
+		{
+			"plan finished",
+			webhooks.Event{
+				Type:        webhooks.PlanFinishedEvent,
+				ProjectName: "project",
+				Workspace:   "default",
+				Success:     true,
+				PlanSummary: "Plan: 1 to add, 2 to change, 3 to destroy.",
+				JobURL:      "https://atlantis/jobs/id",
+			},
+			"good",
+			"Plan succeeded for <url|runatlantis/atlantis#1>",
+			[]slack.AttachmentField{
+				{Title: "Project", Value: "project", Short: true},
+				{Title: "Workspace", Value: "default", Short: true},
+				{Title: "Resources", Value: "1 to add, 2 to change, 3 to destroy."},
+				{Title: "Output", Value: "<https://atlantis/jobs/id|View output>", Short: true},
 			},
 		},
-	}}
-	expParams.AsUser = true
-	expParams.EscapeText = false
+		{
+			"apply finished",
+			webhooks.Event{
+				Type:    webhooks.ApplyFinishedEvent,
+				Success: true,
+				Output:  "aws_instance.a: Creating...\n\nApply complete! Resources: 1 added, 0 changed, 0 destroyed.\n",
+			},
+			"good",
+			"Apply succeeded for <url|runatlantis/atlantis#1>",
+			[]slack.AttachmentField{
+				{Title: "Resources", Value: "1 added, 0 changed, 0 destroyed"},
+			},
+		},
+		{
+			"automerge failed",
+			webhooks.Event{
+				Type:  webhooks.AutomergeEvent,
+				Error: "err",
+			},
+			"danger",
+			"Automerge failed for <url|runatlantis/atlantis#1>",
+			[]slack.AttachmentField{
+				{Title: "Error", Value: "```err```"},
+			},
+		},
+		{
+			"long error",
+			webhooks.Event{
+				Type:  webhooks.AutomergeEvent,
+				Error: strings.Repeat("a", 999) + "é",
+			},
+			"danger",
+			"Automerge failed for <url|runatlantis/atlantis#1>",
+			[]slack.AttachmentField{
+				// The error is cut before the multi-byte character rather
+				// than through it.
+				{Title: "Error", Value: "```" + strings.Repeat("a", 999) + "...```"},
+			},
+		},
+		{
+			"lock released",
+			webhooks.Event{
+				Type:    webhooks.LockReleasedEvent,
+				Success: true,
+			},
+			"#439FE0",
+			"Lock released for <url|runatlantis/atlantis#1>",
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			setup(t)
+			c.event.Repo = result.Repo
+			c.event.Pull = result.Pull
+			_, err := client.PostMessage("somechannel", "", c.event)
+			Ok(t, err)
+			_, _, params := underlying.VerifyWasCalledOnce().PostMessage(AnyString(), AnyString(), matchers.AnySlackPostMessageParameters()).GetCapturedArguments()
+			Equals(t, 1, len(params.Attachments))
+			Equals(t, c.expColour, params.Attachments[0].Color)
+			Equals(t, c.expText, params.Attachments[0].Text)
+			Equals(t, c.expFields, params.Attachments[0].Fields)
+		})
+	}
+}
 
-	channel := "somechannel"
-	When(underlying.PostMessage(channel, "", expParams)).ThenReturn("", "", errors.New(""))
+func TestPostMessage_Error(t *testing.T) {
+	t.Log("When the underlying slack client errors, an error should be returned")
+	setup(t)
+	When(underlying.PostMessage(AnyString(), AnyString(), matchers.AnySlackPostMessageParameters())).ThenReturn("", "", errors.New(""))
 
-	err := client.PostMessage(channel, result)
+	_, err := client.PostMessage("somechannel", "", result)
 	Assert(t, err != nil, "expected error")
 }
 
@@ -177,8 +275,10 @@ func setup(t *testing.T) {
 		Slack: underlying,
 		Token: "sometoken",
 	}
-	result = webhooks.ApplyResult{
+	result = webhooks.Event{
+		Type:      webhooks.ApplyFinishedEvent,
 		Workspace: "production",
+		Directory: ".",
 		Repo: models.Repo{
 			FullName: "runatlantis/atlantis",
 		},
@@ -16,8 +16,10 @@ package webhooks_test
 import (
 	"regexp"
 	"testing"
+	"time"
 
 	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -37,13 +39,13 @@ func TestSend_PostMessage(t *testing.T) {
 		WorkspaceRegex: regex,
 		Channel:        channel,
 	}
-	result := webhooks.ApplyResult{
+	result := webhooks.Event{
 		Workspace: "production",
 	}
 
 	t.Log("PostMessage should be called, doesn't matter if it errors or not")
 	_ = hook.Send(logging.NewNoopLogger(), result)
-	client.VerifyWasCalledOnce().PostMessage(channel, result)
+	client.VerifyWasCalledOnce().PostMessage(channel, "", result)
 }
 
 func TestSend_NoopSuccess(t *testing.T) {
@@ -59,10 +61,102 @@ func TestSend_NoopSuccess(t *testing.T) {
 		WorkspaceRegex: regex,
 		Channel:        channel,
 	}
-	result := webhooks.ApplyResult{
+	result := webhooks.Event{
 		Workspace: "production",
 	}
 	err = hook.Send(logging.NewNoopLogger(), result)
 	Ok(t, err)
-	client.VerifyWasCalled(Never()).PostMessage(channel, result)
+	client.VerifyWasCalled(Never()).PostMessage(channel, "", result)
+}
+
+func TestSend_Threads(t *testing.T) {
+	t.Log("The first message for a pull should start a thread that later messages are posted in")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	threads := mocks.NewMockSlackThreadStore()
+
+	channel := "somechannel"
+	hook := webhooks.SlackWebhook{
+		Client:         client,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		Channel:        channel,
+		Threads:        threads,
+	}
+	pull := models.PullRequest{Num: 1}
+	planResult := webhooks.Event{
+		Type: webhooks.PlanFinishedEvent,
+		Pull: pull,
+	}
+	When(threads.GetSlackThread(pull, channel)).ThenReturn("", nil)
+	When(client.PostMessage(channel, "", planResult)).ThenReturn("1234.5678", nil)
+	Ok(t, hook.Send(logging.NewNoopLogger(), planResult))
+	threads.VerifyWasCalledOnce().SetSlackThread(pull, channel, "1234.5678")
+
+	applyResult := webhooks.Event{
+		Type: webhooks.ApplyFinishedEvent,
+		Pull: pull,
+	}
+	When(threads.GetSlackThread(pull, channel)).ThenReturn("1234.5678", nil)
+	When(client.PostMessage(channel, "1234.5678", applyResult)).ThenReturn("2345.6789", nil)
+	Ok(t, hook.Send(logging.NewNoopLogger(), applyResult))
+	client.VerifyWasCalledOnce().PostMessage(channel, "1234.5678", applyResult)
+	threads.VerifyWasCalled(Never()).SetSlackThread(pull, channel, "2345.6789")
+}
+
+// blockingThreadStore is a SlackThreadStore whose GetSlackThread blocks for
+// pull requests in block until release is closed.
+type blockingThreadStore struct {
+	block   map[int]bool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingThreadStore) GetSlackThread(pull models.PullRequest, channel string) (string, error) {
+	if b.block[pull.Num] {
+		b.started <- struct{}{}
+		<-b.release
+	}
+	return "", nil
+}
+
+func (b *blockingThreadStore) SetSlackThread(pull models.PullRequest, channel string, ts string) error {
+	return nil
+}
+
+func TestSend_ThreadsConcurrent(t *testing.T) {
+	t.Log("Sending for one pull request shouldn't wait for sends for other pull requests")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	threads := &blockingThreadStore{
+		block:   map[int]bool{1: true},
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	hook := &webhooks.SlackWebhook{
+		Client:         client,
+		WorkspaceRegex: regexp.MustCompile(".*"),
+		Channel:        "somechannel",
+		Threads:        threads,
+	}
+	log := logging.NewNoopLogger()
+
+	blocked := make(chan error)
+	go func() {
+		blocked <- hook.Send(log, webhooks.Event{Pull: models.PullRequest{Num: 1}})
+	}()
+	<-threads.started
+
+	sent := make(chan error)
+	go func() {
+		sent <- hook.Send(log, webhooks.Event{Pull: models.PullRequest{Num: 2}})
+	}()
+	select {
+	case err := <-sent:
+		Ok(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("send for pull 2 was blocked by pull 1")
+	}
+
+	close(threads.release)
+	Ok(t, <-blocked)
 }
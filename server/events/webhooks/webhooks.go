@@ -14,16 +14,23 @@
 package webhooks
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"regexp"
+	"strings"
 
 	"errors"
 
-	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
 const SlackKind = "slack"
+const HTTPKind = "http"
+const EmailKind = "email"
+
+// ApplyEvent is the original name of ApplyFinishedEvent. It's still accepted
+// in configs.
 const ApplyEvent = "apply"
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_sender.go Sender
@@ -31,17 +38,13 @@ const ApplyEvent = "apply"
 // Sender sends webhooks.
 type Sender interface {
 	// Send sends the webhook (if the implementation thinks it should).
-	Send(log *logging.SimpleLogger, applyResult ApplyResult) error
+	Send(log *logging.SimpleLogger, event Event) error
 }
 
-// ApplyResult is the result of a terraform apply.
-type ApplyResult struct {
-	Workspace string
-	Repo      models.Repo
-	Pull      models.PullRequest
-	User      models.User
-	Success   bool
-	Directory string
+// closer is implemented by senders that deliver webhooks in the background
+// and must be closed so queued webhooks aren't lost on shutdown.
+type closer interface {
+	Close(ctx context.Context) error
 }
 
 // MultiWebhookSender sends multiple webhooks for each one it's configured for.
@@ -50,25 +53,53 @@ type MultiWebhookSender struct {
 }
 
 type Config struct {
-	Event          string
+	// Event is a single event to send the webhook for. It's combined with
+	// Events.
+	Event string
+	// Events are the events to send the webhook for.
+	Events []string
+	// WorkspaceRegex, RepoRegex and ProjectRegex filter which events the
+	// webhook is sent for. Empty regexes match everything.
 	WorkspaceRegex string
+	RepoRegex      string
+	ProjectRegex   string
 	Kind           string
 	Channel        string
+	// URL, Headers and Secret only apply to http webhooks.
+	URL     string
+	Headers map[string]string
+	Secret  string
+	// To are the addresses that email webhooks are sent to.
+	To []string
 }
 
-func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookSender, error) {
+// NewMultiWebhookSender returns a sender for configs. client is used for
+// slack webhooks and threads stores their threads. If threads is nil, slack
+// messages aren't threaded. emailClient is used for email webhooks.
+func NewMultiWebhookSender(configs []Config, client SlackClient, threads SlackThreadStore, emailClient EmailClient) (*MultiWebhookSender, error) {
 	var webhooks []Sender
 	for _, c := range configs {
 		r, err := regexp.Compile(c.WorkspaceRegex)
 		if err != nil {
 			return nil, err
 		}
-		if c.Kind == "" || c.Event == "" {
-			return nil, errors.New("must specify \"kind\" and \"event\" keys for webhooks")
+		repoRegex, err := regexp.Compile(c.RepoRegex)
+		if err != nil {
+			return nil, err
+		}
+		projectRegex, err := regexp.Compile(c.ProjectRegex)
+		if err != nil {
+			return nil, err
+		}
+		if c.Kind == "" || (c.Event == "" && len(c.Events) == 0) {
+			return nil, errors.New("must specify \"kind\" and \"event\" or \"events\" keys for webhooks")
 		}
-		if c.Event != ApplyEvent {
-			return nil, fmt.Errorf("\"event: %s\" not supported. Only \"event: %s\" is supported right now", c.Event, ApplyEvent)
+		events, err := eventSet(c)
+		if err != nil {
+			return nil, err
 		}
+
+		var sender Sender
 		switch c.Kind {
 		case SlackKind:
 			if !client.TokenIsSet() {
@@ -77,14 +108,51 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 			if c.Channel == "" {
 				return nil, errors.New("must specify \"channel\" if using a webhook of \"kind: slack\"")
 			}
-			slack, err := NewSlack(r, c.Channel, client)
+			slack, err := NewSlack(r, c.Channel, client, threads)
 			if err != nil {
 				return nil, err
 			}
-			webhooks = append(webhooks, slack)
+			sender = slack
+		case HTTPKind:
+			if c.URL == "" {
+				return nil, errors.New("must specify \"url\" if using a webhook of \"kind: http\"")
+			}
+			u, err := url.Parse(c.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("\"url: %s\" must be an absolute http or https URL", c.URL)
+			}
+			sender = NewHTTP(c.URL, c.Headers, c.Secret, 0)
+		case EmailKind:
+			if emailClient == nil || !emailClient.IsConfigured() {
+				return nil, errors.New("must specify top-level \"smtp-host\" if using a webhook of \"kind: email\"")
+			}
+			if len(c.To) == 0 {
+				return nil, errors.New("must specify \"to\" if using a webhook of \"kind: email\"")
+			}
+			for e := range events {
+				if isStartedEvent(e) {
+					return nil, fmt.Errorf("\"event: %s\" not supported for webhooks of \"kind: email\"", e)
+				}
+			}
+			// The email webhook needs started events to know when projects
+			// are still running so it can batch their results.
+			if events[PlanFinishedEvent] {
+				events[PlanStartedEvent] = true
+			}
+			if events[ApplyFinishedEvent] {
+				events[ApplyStartedEvent] = true
+			}
+			sender = NewEmail(emailClient, c.To)
 		default:
-			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\" is supported right now", c.Kind, SlackKind)
+			return nil, fmt.Errorf("\"kind: %s\" not supported. Only \"kind: %s\", \"kind: %s\" and \"kind: %s\" are supported right now", c.Kind, SlackKind, HTTPKind, EmailKind)
 		}
+		webhooks = append(webhooks, &FilteredSender{
+			Sender:         sender,
+			Events:         events,
+			WorkspaceRegex: r,
+			RepoRegex:      repoRegex,
+			ProjectRegex:   projectRegex,
+		})
 	}
 
 	return &MultiWebhookSender{
@@ -92,12 +160,82 @@ func NewMultiWebhookSender(configs []Config, client SlackClient) (*MultiWebhookS
 	}, nil
 }
 
+// eventSet returns the set of events the webhook configured by c is sent for.
+func eventSet(c Config) (map[string]bool, error) {
+	names := c.Events
+	if c.Event != "" {
+		names = append([]string{c.Event}, names...)
+	}
+	events := make(map[string]bool)
+	for _, name := range names {
+		if name == ApplyEvent {
+			name = ApplyFinishedEvent
+		}
+		if !isEventType(name) {
+			return nil, fmt.Errorf("\"event: %s\" not supported. Supported events are: %s", name, strings.Join(EventTypes, ", "))
+		}
+		events[name] = true
+	}
+	return events, nil
+}
+
 // Send sends the webhook using its Webhooks.
-func (w *MultiWebhookSender) Send(log *logging.SimpleLogger, result ApplyResult) error {
+func (w *MultiWebhookSender) Send(log *logging.SimpleLogger, event Event) error {
+	for _, w := range w.Webhooks {
+		if err := w.Send(log, event); err != nil {
+			log.Warn("error sending webhook: %s", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the Webhooks that deliver in the background and waits for
+// their queued webhooks to be delivered or ctx to end. It returns the first
+// error.
+func (w *MultiWebhookSender) Close(ctx context.Context) error {
+	var firstErr error
 	for _, w := range w.Webhooks {
-		if err := w.Send(log, result); err != nil {
-			log.Warn("error sending slack webhook: %s", err)
+		c, ok := w.(closer)
+		if !ok {
+			continue
 		}
+		if err := c.Close(ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FilteredSender sends webhooks using Sender only for events that match its
+// filters.
+type FilteredSender struct {
+	Sender Sender
+	// Events is the set of event types to send.
+	Events map[string]bool
+	// WorkspaceRegex, RepoRegex and ProjectRegex must match the event's
+	// workspace, repo ID (ex. github.com/owner/repo) and project name. Events
+	// without a project name, ex. lock events, only pass ProjectRegex if it
+	// matches the empty string.
+	WorkspaceRegex *regexp.Regexp
+	RepoRegex      *regexp.Regexp
+	ProjectRegex   *regexp.Regexp
+}
+
+// Send sends the webhook if event matches the filters.
+func (f *FilteredSender) Send(log *logging.SimpleLogger, event Event) error {
+	if !f.Events[event.Type] ||
+		!f.WorkspaceRegex.MatchString(event.Workspace) ||
+		!f.RepoRegex.MatchString(event.Repo.ID()) ||
+		!f.ProjectRegex.MatchString(event.ProjectName) {
+		return nil
+	}
+	return f.Sender.Send(log, event)
+}
+
+// Close closes Sender if it delivers webhooks in the background.
+func (f *FilteredSender) Close(ctx context.Context) error {
+	if c, ok := f.Sender.(closer); ok {
+		return c.Close(ctx)
 	}
 	return nil
 }
@@ -14,10 +14,13 @@
 package webhooks_test
 
 import (
+	"context"
+	"regexp"
 	"strings"
 	"testing"
 
 	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/webhooks/mocks"
 	"github.com/runatlantis/atlantis/server/logging"
@@ -51,9 +54,19 @@ func TestNewWebhooksManager_InvalidRegex(t *testing.T) {
 	invalidRegex := "("
 	configs := validConfigs()
 	configs[0].WorkspaceRegex = invalidRegex
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Assert(t, err != nil, "expected error")
 	Assert(t, strings.Contains(err.Error(), "error parsing regexp"), "expected regex error")
+
+	configs = validConfigs()
+	configs[0].RepoRegex = invalidRegex
+	_, err = webhooks.NewMultiWebhookSender(configs, client, nil, nil)
+	ErrContains(t, "error parsing regexp", err)
+
+	configs = validConfigs()
+	configs[0].ProjectRegex = invalidRegex
+	_, err = webhooks.NewMultiWebhookSender(configs, client, nil, nil)
+	ErrContains(t, "error parsing regexp", err)
 }
 
 func TestNewWebhooksManager_NoEvent(t *testing.T) {
@@ -62,9 +75,9 @@ func TestNewWebhooksManager_NoEvent(t *testing.T) {
 	client := mocks.NewMockSlackClient()
 	configs := validConfigs()
 	configs[0].Event = ""
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "must specify \"kind\" and \"event\" keys for webhooks", err.Error())
+	Equals(t, "must specify \"kind\" and \"event\" or \"events\" keys for webhooks", err.Error())
 }
 
 func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
@@ -76,9 +89,45 @@ func TestNewWebhooksManager_UnsupportedEvent(t *testing.T) {
 	unsupportedEvent := "badevent"
 	configs := validConfigs()
 	configs[0].Event = unsupportedEvent
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"event: badevent\" not supported. Only \"event: apply\" is supported right now", err.Error())
+	Equals(t, "\"event: badevent\" not supported. Supported events are: plan_started, plan_finished, apply_started, apply_finished, lock_acquired, lock_released, automerge", err.Error())
+}
+
+func TestNewWebhooksManager_SlackEvents(t *testing.T) {
+	t.Log("Slack webhooks should support events other than apply")
+	RegisterMockTestingT(t)
+	client := mocks.NewMockSlackClient()
+	When(client.TokenIsSet()).ThenReturn(true)
+	When(client.ChannelExists(validChannel)).ThenReturn(true, nil)
+
+	configs := validConfigs()
+	configs[0].Events = []string{webhooks.PlanStartedEvent, webhooks.PlanFinishedEvent}
+	m, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
+}
+
+func TestNewWebhooksManager_Events(t *testing.T) {
+	t.Log("The event and events keys should be combined and apply should be an alias of apply_finished")
+	configs := []webhooks.Config{
+		{
+			Event:  webhooks.ApplyEvent,
+			Events: []string{webhooks.PlanFinishedEvent, webhooks.LockAcquiredEvent},
+			Kind:   webhooks.HTTPKind,
+			URL:    "https://example.com/hooks",
+		},
+	}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil, nil, nil)
+	Ok(t, err)
+	Equals(t, 1, len(m.Webhooks))                            // nolint: staticcheck
+	filtered, ok := m.Webhooks[0].(*webhooks.FilteredSender) // nolint: staticcheck
+	Assert(t, ok, "expected a FilteredSender")
+	Equals(t, map[string]bool{
+		webhooks.ApplyFinishedEvent: true,
+		webhooks.PlanFinishedEvent:  true,
+		webhooks.LockAcquiredEvent:  true,
+	}, filtered.Events)
 }
 
 func TestNewWebhooksManager_NoKind(t *testing.T) {
@@ -87,9 +136,9 @@ func TestNewWebhooksManager_NoKind(t *testing.T) {
 	client := mocks.NewMockSlackClient()
 	configs := validConfigs()
 	configs[0].Kind = ""
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "must specify \"kind\" and \"event\" keys for webhooks", err.Error())
+	Equals(t, "must specify \"kind\" and \"event\" or \"events\" keys for webhooks", err.Error())
 }
 
 func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
@@ -101,9 +150,128 @@ func TestNewWebhooksManager_UnsupportedKind(t *testing.T) {
 	unsupportedKind := "badkind"
 	configs := validConfigs()
 	configs[0].Kind = unsupportedKind
-	_, err := webhooks.NewMultiWebhookSender(configs, client)
+	_, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Assert(t, err != nil, "expected error")
-	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\" is supported right now", err.Error())
+	Equals(t, "\"kind: badkind\" not supported. Only \"kind: slack\", \"kind: http\" and \"kind: email\" are supported right now", err.Error())
+}
+
+func TestNewWebhooksManager_HTTPKind(t *testing.T) {
+	cases := []struct {
+		description string
+		url         string
+		expErr      string
+	}{
+		{
+			"no url",
+			"",
+			"must specify \"url\" if using a webhook of \"kind: http\"",
+		},
+		{
+			"relative url",
+			"/hooks",
+			"\"url: /hooks\" must be an absolute http or https URL",
+		},
+		{
+			"unsupported scheme",
+			"ftp://example.com/hooks",
+			"\"url: ftp://example.com/hooks\" must be an absolute http or https URL",
+		},
+		{
+			"valid url",
+			"https://example.com/hooks",
+			"",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			configs := []webhooks.Config{
+				{
+					Event:          validEvent,
+					WorkspaceRegex: validRegex,
+					Kind:           webhooks.HTTPKind,
+					URL:            c.url,
+				},
+			}
+			// The slack client isn't needed for http webhooks.
+			m, err := webhooks.NewMultiWebhookSender(configs, nil, nil, nil)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
+		})
+	}
+}
+
+func TestNewWebhooksManager_EmailKind(t *testing.T) {
+	cases := []struct {
+		description   string
+		configured    bool
+		to            []string
+		events        []string
+		expErr        string
+		expEventTypes []string
+	}{
+		{
+			"smtp not configured",
+			false,
+			[]string{"ops@example.com"},
+			[]string{webhooks.ApplyFinishedEvent},
+			"must specify top-level \"smtp-host\" if using a webhook of \"kind: email\"",
+			nil,
+		},
+		{
+			"no recipients",
+			true,
+			nil,
+			[]string{webhooks.ApplyFinishedEvent},
+			"must specify \"to\" if using a webhook of \"kind: email\"",
+			nil,
+		},
+		{
+			"started event",
+			true,
+			[]string{"ops@example.com"},
+			[]string{webhooks.ApplyStartedEvent},
+			"\"event: apply_started\" not supported for webhooks of \"kind: email\"",
+			nil,
+		},
+		{
+			"started events are added for batching",
+			true,
+			[]string{"ops@example.com"},
+			[]string{webhooks.PlanFinishedEvent, webhooks.ApplyFinishedEvent, webhooks.AutomergeEvent},
+			"",
+			[]string{webhooks.PlanStartedEvent, webhooks.PlanFinishedEvent, webhooks.ApplyStartedEvent, webhooks.ApplyFinishedEvent, webhooks.AutomergeEvent},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			emailClient := mocks.NewMockEmailClient()
+			When(emailClient.IsConfigured()).ThenReturn(c.configured)
+			configs := []webhooks.Config{
+				{
+					Events: c.events,
+					Kind:   webhooks.EmailKind,
+					To:     c.to,
+				},
+			}
+			m, err := webhooks.NewMultiWebhookSender(configs, nil, nil, emailClient)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			filtered := m.Webhooks[0].(*webhooks.FilteredSender) // nolint: staticcheck
+			expEvents := make(map[string]bool)
+			for _, e := range c.expEventTypes {
+				expEvents[e] = true
+			}
+			Equals(t, expEvents, filtered.Events)
+		})
+	}
 }
 
 func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
@@ -111,13 +279,13 @@ func TestNewWebhooksManager_NoConfigSuccess(t *testing.T) {
 	t.Log("passing any client should succeed")
 	var emptyConfigs []webhooks.Config
 	emptyToken := ""
-	m, err := webhooks.NewMultiWebhookSender(emptyConfigs, webhooks.NewSlackClient(emptyToken))
+	m, err := webhooks.NewMultiWebhookSender(emptyConfigs, webhooks.NewSlackClient(emptyToken), nil, nil)
 	Ok(t, err)
 	Assert(t, m != nil, "manager shouldn't be nil")
 	Equals(t, 0, len(m.Webhooks)) // nolint: staticcheck
 
 	t.Log("passing nil client should succeed")
-	m, err = webhooks.NewMultiWebhookSender(emptyConfigs, nil)
+	m, err = webhooks.NewMultiWebhookSender(emptyConfigs, nil, nil, nil)
 	Ok(t, err)
 	Assert(t, m != nil, "manager shouldn't be nil")
 	Equals(t, 0, len(m.Webhooks)) // nolint: staticcheck
@@ -130,7 +298,7 @@ func TestNewWebhooksManager_SingleConfigSuccess(t *testing.T) {
 	When(client.ChannelExists(validChannel)).ThenReturn(true, nil)
 
 	configs := validConfigs()
-	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	m, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Ok(t, err)
 	Assert(t, m != nil, "manager shouldn't be nil")
 	Equals(t, 1, len(m.Webhooks)) // nolint: staticcheck
@@ -148,7 +316,7 @@ func TestNewWebhooksManager_MultipleConfigSuccess(t *testing.T) {
 	for i := 0; i < nConfigs; i++ {
 		configs = append(configs, validConfig)
 	}
-	m, err := webhooks.NewMultiWebhookSender(configs, client)
+	m, err := webhooks.NewMultiWebhookSender(configs, client, nil, nil)
 	Ok(t, err)
 	Assert(t, m != nil, "manager shouldn't be nil")
 	Equals(t, nConfigs, len(m.Webhooks)) // nolint: staticcheck
@@ -162,7 +330,7 @@ func TestSend_SingleSuccess(t *testing.T) {
 		Webhooks: []webhooks.Sender{sender},
 	}
 	logger := logging.NewNoopLogger()
-	result := webhooks.ApplyResult{}
+	result := webhooks.Event{}
 	manager.Send(logger, result) // nolint: errcheck
 	sender.VerifyWasCalledOnce().Send(logger, result)
 }
@@ -179,10 +347,113 @@ func TestSend_MultipleSuccess(t *testing.T) {
 		Webhooks: []webhooks.Sender{senders[0], senders[1], senders[2]},
 	}
 	logger := logging.NewNoopLogger()
-	result := webhooks.ApplyResult{}
+	result := webhooks.Event{}
 	err := manager.Send(logger, result)
 	Ok(t, err)
 	for _, s := range senders {
 		s.VerifyWasCalledOnce().Send(logger, result)
 	}
 }
+
+func TestClose(t *testing.T) {
+	t.Log("Closing should deliver queued http webhooks and skip senders that don't queue")
+	RegisterMockTestingT(t)
+	server, requests := receiver(t)
+	defer server.Close()
+	configs := []webhooks.Config{
+		{Event: webhooks.ApplyFinishedEvent, Kind: webhooks.HTTPKind, URL: server.URL},
+	}
+	m, err := webhooks.NewMultiWebhookSender(configs, nil, nil, nil)
+	Ok(t, err)
+	m.Webhooks = append(m.Webhooks, mocks.NewMockSender())
+
+	Ok(t, m.Send(logging.NewNoopLogger(), webhooks.Event{Type: webhooks.ApplyFinishedEvent}))
+	Ok(t, m.Close(context.Background()))
+	Equals(t, 1, len(requests))
+}
+
+func TestFilteredSender_Send(t *testing.T) {
+	event := webhooks.Event{
+		Type:        webhooks.PlanFinishedEvent,
+		Repo:        models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com"}},
+		ProjectName: "project",
+		Workspace:   "production",
+	}
+	cases := []struct {
+		description    string
+		events         map[string]bool
+		workspaceRegex string
+		repoRegex      string
+		projectRegex   string
+		expSent        bool
+	}{
+		{
+			"all match",
+			map[string]bool{webhooks.PlanFinishedEvent: true},
+			"",
+			"",
+			"",
+			true,
+		},
+		{
+			"event doesn't match",
+			map[string]bool{webhooks.ApplyFinishedEvent: true},
+			"",
+			"",
+			"",
+			false,
+		},
+		{
+			"workspace doesn't match",
+			map[string]bool{webhooks.PlanFinishedEvent: true},
+			"staging",
+			"",
+			"",
+			false,
+		},
+		{
+			"repo matches",
+			map[string]bool{webhooks.PlanFinishedEvent: true},
+			"",
+			"^github.com/owner/",
+			"",
+			true,
+		},
+		{
+			"repo doesn't match",
+			map[string]bool{webhooks.PlanFinishedEvent: true},
+			"",
+			"^github.com/other/",
+			"",
+			false,
+		},
+		{
+			"project doesn't match",
+			map[string]bool{webhooks.PlanFinishedEvent: true},
+			"",
+			"",
+			"^other$",
+			false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			sender := mocks.NewMockSender()
+			filtered := webhooks.FilteredSender{
+				Sender:         sender,
+				Events:         c.events,
+				WorkspaceRegex: regexp.MustCompile(c.workspaceRegex),
+				RepoRegex:      regexp.MustCompile(c.repoRegex),
+				ProjectRegex:   regexp.MustCompile(c.projectRegex),
+			}
+			logger := logging.NewNoopLogger()
+			Ok(t, filtered.Send(logger, event))
+			if c.expSent {
+				sender.VerifyWasCalledOnce().Send(logger, event)
+			} else {
+				sender.VerifyWasCalled(Never()).Send(logger, event)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ package events
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -23,6 +24,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -38,6 +40,18 @@ type WorkingDir interface {
 	// a boolean indicating if we should warn users that the branch we're
 	// merging into has been updated since we cloned it.
 	Clone(log *logging.SimpleLogger, baseRepo models.Repo, headRepo models.Repo, p models.PullRequest, workspace string) (string, bool, error)
+	// HasDiverged returns true if p's base branch has commits that aren't
+	// in the repo checked out in cloneDir, ie. if what we checked out isn't
+	// up to date with the base branch.
+	HasDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) (bool, error)
+	// ReadBaseFile returns the contents of path, relative to the repo root,
+	// at the tip of p's base branch or nil if it doesn't exist there. Unlike
+	// the files in cloneDir, the pull request can't change it.
+	ReadBaseFile(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, path string) ([]byte, error)
+	// ListBaseFiles returns the paths, relative to the repo root, of the
+	// files directly in dir at the tip of p's base branch. It returns nil if
+	// dir doesn't exist there.
+	ListBaseFiles(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, dir string) ([]string, error)
 	// GetWorkingDir returns the path to the workspace for this repo and pull.
 	// If workspace does not exist on disk, error will be of type os.IsNotExist.
 	GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error)
@@ -55,6 +69,10 @@ type FileWorkspace struct {
 	// If this is false, then we will check out the head branch from the pull
 	// request.
 	CheckoutMerge bool
+	// GithubCredentials, if set, is used to get the token GitHub repos are
+	// cloned with instead of the credentials in their clone URLs. It's used
+	// for GitHub App tokens which expire.
+	GithubCredentials vcs.GithubCredentials
 	// TestingOverrideHeadCloneURL can be used during testing to override the
 	// URL of the head repo to be cloned. If it's empty then we clone normally.
 	TestingOverrideHeadCloneURL string
@@ -85,8 +103,12 @@ func (w *FileWorkspace) Clone(
 		// If doing a merge, then HEAD won't be at the pull request's HEAD
 		// because we'll already have performed a merge. Instead, we'll check
 		// HEAD^2 since that will be the commit before our merge.
+		// Merged pull requests are checked out at their merge commit.
 		pullHead := "HEAD"
-		if w.CheckoutMerge {
+		wantCommit := p.HeadCommit
+		if p.State == models.MergedPullState {
+			wantCommit = p.MergeCommit
+		} else if w.CheckoutMerge {
 			pullHead = "HEAD^2"
 		}
 		revParseCmd := exec.Command("git", "rev-parse", pullHead) // #nosec
@@ -100,12 +122,12 @@ func (w *FileWorkspace) Clone(
 
 		// We're prefix matching here because BitBucket doesn't give us the full
 		// commit, only a 12 character prefix.
-		if strings.HasPrefix(currCommit, p.HeadCommit) {
-			log.Debug("repo is at correct commit %q so will not re-clone", p.HeadCommit)
-			return cloneDir, w.warnDiverged(log, cloneDir), nil
+		if strings.HasPrefix(currCommit, wantCommit) {
+			log.Debug("repo is at correct commit %q so will not re-clone", wantCommit)
+			return cloneDir, w.warnDiverged(log, cloneDir, headRepo, p), nil
 		}
 
-		log.Debug("repo was already cloned but is not at correct commit, wanted %q got %q", p.HeadCommit, currCommit)
+		log.Debug("repo was already cloned but is not at correct commit, wanted %q got %q", wantCommit, currCommit)
 		// We'll fall through to re-clone.
 	}
 
@@ -120,8 +142,8 @@ func (w *FileWorkspace) Clone(
 // Then users won't be getting the merge functionality they expected.
 // If there are any errors we return false since we prefer things to succeed
 // vs. stopping the plan/apply.
-func (w *FileWorkspace) warnDiverged(log *logging.SimpleLogger, cloneDir string) bool {
-	if !w.CheckoutMerge {
+func (w *FileWorkspace) warnDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) bool {
+	if !w.CheckoutMerge || p.State == models.MergedPullState {
 		// It only makes sense to warn that master has diverged if we're using
 		// the checkout merge strategy. If we're just checking out the branch,
 		// then it doesn't matter what's going on with master because we've
@@ -129,24 +151,11 @@ func (w *FileWorkspace) warnDiverged(log *logging.SimpleLogger, cloneDir string)
 		return false
 	}
 
-	// Bring our remote refs up to date.
-	remoteUpdateCmd := exec.Command("git", "remote", "update")
-	remoteUpdateCmd.Dir = cloneDir
-	outputRemoteUpdate, err := remoteUpdateCmd.CombinedOutput()
-	if err != nil {
-		log.Warn("getting remote update failed: %s", string(outputRemoteUpdate))
-		return false
-	}
-
-	// Check if remote master branch has diverged.
-	statusUnoCmd := exec.Command("git", "status", "--untracked-files=no")
-	statusUnoCmd.Dir = cloneDir
-	outputStatusUno, err := statusUnoCmd.CombinedOutput()
+	hasDiverged, err := w.HasDiverged(log, cloneDir, headRepo, p)
 	if err != nil {
-		log.Warn("getting repo status has failed: %s", string(outputStatusUno))
+		log.Warn("checking if remote master branch has diverged failed: %s", err)
 		return false
 	}
-	hasDiverged := strings.Contains(string(outputStatusUno), "have diverged")
 	if hasDiverged {
 		log.Info("remote master branch is ahead and thereby has new commits, it is recommended to pull new commits")
 	} else {
@@ -155,6 +164,142 @@ func (w *FileWorkspace) warnDiverged(log *logging.SimpleLogger, cloneDir string)
 	return hasDiverged
 }
 
+// HasDiverged returns true if p's base branch has commits that aren't in the
+// repo checked out in cloneDir. With the merge checkout strategy, that's the
+// base branch as of when we cloned merged with the pull request. Otherwise
+// it's the pull request's head branch.
+func (w *FileWorkspace) HasDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) (bool, error) {
+	// Without the merge checkout strategy, origin is the head repo. If the
+	// token in origin's URL can expire, we fetch by URL with a new token
+	// instead.
+	headCloneURL, _, err := w.cloneURLs(headRepo, p)
+	if err != nil {
+		return false, err
+	}
+	baseRemote, err := w.baseRemote(headRepo, p)
+	if err != nil {
+		return false, err
+	}
+	headRemote := "origin"
+	if w.GithubCredentials != nil {
+		headRemote = headCloneURL
+	}
+	if !w.CheckoutMerge {
+		// We clone the head branch with --depth=1 so we need to fetch its
+		// history to know if it contains the base branch's commits.
+		if _, err := os.Stat(filepath.Join(cloneDir, ".git", "shallow")); err == nil {
+			if err := w.runGit(log, cloneDir, headRepo, p, "fetch", "--unshallow", headRemote); err != nil {
+				return false, err
+			}
+		}
+	}
+	if err := w.runGit(log, cloneDir, headRepo, p, "fetch", baseRemote, fmt.Sprintf("+refs/heads/%s", p.BaseBranch)); err != nil {
+		return false, err
+	}
+
+	// merge-base --is-ancestor exits 0 if the fetched base branch is an
+	// ancestor of HEAD and 1 if it isn't.
+	isAncestorCmd := exec.Command("git", "merge-base", "--is-ancestor", "FETCH_HEAD", "HEAD") // #nosec
+	isAncestorCmd.Dir = cloneDir
+	output, err := isAncestorCmd.CombinedOutput()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("running %s: %s: %s", strings.Join(isAncestorCmd.Args, " "), string(output), err)
+	}
+	return false, nil
+}
+
+// baseBranchRef is the ref ReadBaseFile and ListBaseFiles fetch the base
+// branch into.
+const baseBranchRef = "refs/atlantis/base"
+
+// ReadBaseFile returns the contents of path, relative to the repo root, at the
+// tip of p's base branch or nil if it doesn't exist there.
+func (w *FileWorkspace) ReadBaseFile(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, path string) ([]byte, error) {
+	if err := w.fetchBase(log, cloneDir, headRepo, p); err != nil {
+		return nil, err
+	}
+
+	lsTreeCmd := exec.Command("git", "ls-tree", "--name-only", baseBranchRef, "--", path) // #nosec
+	lsTreeCmd.Dir = cloneDir
+	output, err := lsTreeCmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %s: %s", strings.Join(lsTreeCmd.Args, " "), string(output), err)
+	}
+	if strings.TrimSpace(string(output)) == "" {
+		return nil, nil
+	}
+	catFileCmd := exec.Command("git", "cat-file", "blob", fmt.Sprintf("%s:%s", baseBranchRef, path)) // #nosec
+	catFileCmd.Dir = cloneDir
+	contents, err := catFileCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %s", strings.Join(catFileCmd.Args, " "), err)
+	}
+	return contents, nil
+}
+
+// ListBaseFiles returns the paths, relative to the repo root, of the files
+// directly in dir at the tip of p's base branch or nil if dir doesn't exist
+// there.
+func (w *FileWorkspace) ListBaseFiles(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, dir string) ([]string, error) {
+	if err := w.fetchBase(log, cloneDir, headRepo, p); err != nil {
+		return nil, err
+	}
+
+	args := []string{"ls-tree", "-z", baseBranchRef}
+	if dir = filepath.ToSlash(filepath.Clean(dir)); dir != "." {
+		// The trailing slash lists the directory's entries rather than the
+		// directory itself.
+		args = append(args, "--", dir+"/")
+	}
+	lsTreeCmd := exec.Command("git", args...) // #nosec
+	lsTreeCmd.Dir = cloneDir
+	output, err := lsTreeCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("running %s: %s", strings.Join(lsTreeCmd.Args, " "), err)
+	}
+	var files []string
+	// Each entry is "<mode> <type> <object>\t<path>".
+	for _, entry := range strings.Split(string(output), "\x00") {
+		tab := strings.Index(entry, "\t")
+		if tab == -1 {
+			continue
+		}
+		if fields := strings.Fields(entry[:tab]); len(fields) == 3 && fields[1] == "blob" {
+			files = append(files, entry[tab+1:])
+		}
+	}
+	return files, nil
+}
+
+// fetchBase fetches the tip of p's base branch into baseBranchRef.
+func (w *FileWorkspace) fetchBase(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) error {
+	baseRemote, err := w.baseRemote(headRepo, p)
+	if err != nil {
+		return err
+	}
+	fetchArgs := []string{"fetch", baseRemote, fmt.Sprintf("+refs/heads/%s:%s", p.BaseBranch, baseBranchRef)}
+	// Don't fetch the base branch's history into a shallow clone.
+	if _, err := os.Stat(filepath.Join(cloneDir, ".git", "shallow")); err == nil {
+		fetchArgs = append(fetchArgs, "--depth=1")
+	}
+	return w.runGit(log, cloneDir, headRepo, p, fetchArgs...)
+}
+
+// baseRemote returns the remote to fetch p's base branch from. With the merge
+// checkout strategy, origin is the base repo. Otherwise origin is the head
+// repo, which might be a fork, so we fetch the base branch by URL. If the
+// token in origin's URL can expire, we fetch by URL with a new token instead.
+func (w *FileWorkspace) baseRemote(headRepo models.Repo, p models.PullRequest) (string, error) {
+	if w.CheckoutMerge && w.GithubCredentials == nil {
+		return "origin", nil
+	}
+	_, baseCloneURL, err := w.cloneURLs(headRepo, p)
+	return baseCloneURL, err
+}
+
 func (w *FileWorkspace) forceClone(log *logging.SimpleLogger,
 	cloneDir string,
 	headRepo models.Repo,
@@ -171,18 +316,25 @@ func (w *FileWorkspace) forceClone(log *logging.SimpleLogger,
 		return errors.Wrap(err, "creating new workspace")
 	}
 
-	// During testing, we mock some of this out.
-	headCloneURL := headRepo.CloneURL
-	if w.TestingOverrideHeadCloneURL != "" {
-		headCloneURL = w.TestingOverrideHeadCloneURL
-	}
-	baseCloneURL := p.BaseRepo.CloneURL
-	if w.TestingOverrideBaseCloneURL != "" {
-		baseCloneURL = w.TestingOverrideBaseCloneURL
+	headCloneURL, baseCloneURL, err := w.cloneURLs(headRepo, p)
+	if err != nil {
+		return err
 	}
 
 	var cmds [][]string
-	if w.CheckoutMerge {
+	if p.State == models.MergedPullState {
+		// The pull request has already been merged so we check out its merge
+		// commit on the base branch. If the VCS host didn't tell us the merge
+		// commit, we use the tip of the base branch.
+		cmds = [][]string{
+			{
+				"git", "clone", "--branch", p.BaseBranch, "--single-branch", baseCloneURL, cloneDir,
+			},
+		}
+		if p.MergeCommit != "" {
+			cmds = append(cmds, []string{"git", "checkout", "-q", p.MergeCommit})
+		}
+	} else if w.CheckoutMerge {
 		// NOTE: We can't do a shallow clone when we're merging because we'll
 		// get merge conflicts if our clone doesn't have the commits that the
 		// branch we're merging branched off at.
@@ -216,27 +368,75 @@ func (w *FileWorkspace) forceClone(log *logging.SimpleLogger,
 	}
 
 	for _, args := range cmds {
-		cmd := exec.Command(args[0], args[1:]...) // nolint: gosec
-		cmd.Dir = cloneDir
-		// The git merge command requires these env vars are set.
-		cmd.Env = append(os.Environ(), []string{
-			"EMAIL=atlantis@runatlantis.io",
-			"GIT_AUTHOR_NAME=atlantis",
-			"GIT_COMMITTER_NAME=atlantis",
-		}...)
-
-		cmdStr := w.sanitizeGitCredentials(strings.Join(cmd.Args, " "), p.BaseRepo, headRepo)
-		output, err := cmd.CombinedOutput()
-		sanitizedOutput := w.sanitizeGitCredentials(string(output), p.BaseRepo, headRepo)
-		if err != nil {
-			sanitizedErrMsg := w.sanitizeGitCredentials(err.Error(), p.BaseRepo, headRepo)
-			return fmt.Errorf("running %s: %s: %s", cmdStr, sanitizedOutput, sanitizedErrMsg)
+		if err := w.runGit(log, cloneDir, headRepo, p, args[1:]...); err != nil {
+			return err
 		}
-		log.Debug("ran: %s. Output: %s", cmdStr, strings.TrimSuffix(sanitizedOutput, "\n"))
 	}
 	return nil
 }
 
+// cloneURLs returns the URLs to clone the head and base repos from.
+func (w *FileWorkspace) cloneURLs(headRepo models.Repo, p models.PullRequest) (string, string, error) {
+	headCloneURL, err := w.authedCloneURL(headRepo)
+	if err != nil {
+		return "", "", err
+	}
+	baseCloneURL, err := w.authedCloneURL(p.BaseRepo)
+	if err != nil {
+		return "", "", err
+	}
+
+	// During testing, we mock some of this out.
+	if w.TestingOverrideHeadCloneURL != "" {
+		headCloneURL = w.TestingOverrideHeadCloneURL
+	}
+	if w.TestingOverrideBaseCloneURL != "" {
+		baseCloneURL = w.TestingOverrideBaseCloneURL
+	}
+	return headCloneURL, baseCloneURL, nil
+}
+
+// authedCloneURL returns repo's clone URL. If GithubCredentials is set, the
+// credentials in the URLs of GitHub repos are replaced with a current token.
+func (w *FileWorkspace) authedCloneURL(repo models.Repo) (string, error) {
+	if w.GithubCredentials == nil || repo.VCSHost.Type != models.Github {
+		return repo.CloneURL, nil
+	}
+	token, err := w.GithubCredentials.GetToken()
+	if err != nil {
+		return "", errors.Wrap(err, "getting GitHub token to clone with")
+	}
+	cloneURL, err := url.Parse(repo.CloneURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing clone url of %s", repo.FullName)
+	}
+	cloneURL.User = url.UserPassword("x-access-token", token)
+	return cloneURL.String(), nil
+}
+
+// runGit runs git with args in dir. Credentials in the clone URLs of the base
+// and head repos are sanitized from what we log and return.
+func (w *FileWorkspace) runGit(log *logging.SimpleLogger, dir string, headRepo models.Repo, p models.PullRequest, args ...string) error {
+	cmd := exec.Command("git", args...) // nolint: gosec
+	cmd.Dir = dir
+	// The git merge command requires these env vars are set.
+	cmd.Env = append(os.Environ(), []string{
+		"EMAIL=atlantis@runatlantis.io",
+		"GIT_AUTHOR_NAME=atlantis",
+		"GIT_COMMITTER_NAME=atlantis",
+	}...)
+
+	cmdStr := w.sanitizeGitCredentials(strings.Join(cmd.Args, " "), p.BaseRepo, headRepo)
+	output, err := cmd.CombinedOutput()
+	sanitizedOutput := w.sanitizeGitCredentials(string(output), p.BaseRepo, headRepo)
+	if err != nil {
+		sanitizedErrMsg := w.sanitizeGitCredentials(err.Error(), p.BaseRepo, headRepo)
+		return fmt.Errorf("running %s: %s: %s", cmdStr, sanitizedOutput, sanitizedErrMsg)
+	}
+	log.Debug("ran: %s. Output: %s", cmdStr, strings.TrimSuffix(sanitizedOutput, "\n"))
+	return nil
+}
+
 // GetWorkingDir returns the path to the workspace for this repo and pull.
 func (w *FileWorkspace) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error) {
 	repoDir := w.cloneDir(r, p, workspace)
@@ -278,5 +478,11 @@ func (w *FileWorkspace) cloneDir(r models.Repo, p models.PullRequest, workspace
 // in s with the sanitized versions.
 func (w *FileWorkspace) sanitizeGitCredentials(s string, base models.Repo, head models.Repo) string {
 	baseReplaced := strings.Replace(s, base.CloneURL, base.SanitizedCloneURL, -1)
-	return strings.Replace(baseReplaced, head.CloneURL, head.SanitizedCloneURL, -1)
+	sanitized := strings.Replace(baseReplaced, head.CloneURL, head.SanitizedCloneURL, -1)
+	if w.GithubCredentials != nil {
+		if token, err := w.GithubCredentials.GetToken(); err == nil && token != "" {
+			sanitized = strings.Replace(sanitized, token, "<redacted>", -1)
+		}
+	}
+	return sanitized
 }
@@ -2,8 +2,11 @@ package events_test
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/runatlantis/atlantis/server/events"
@@ -302,6 +305,55 @@ func TestClone_RecloneWrongCommit(t *testing.T) {
 	Equals(t, expCommit, actCommit)
 }
 
+// Test that merged pull requests are checked out at their merge commit on the
+// base branch regardless of the checkout strategy.
+func TestClone_Merged(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+
+	// Merge a commit into master and then advance master past it.
+	runCmd(t, repoDir, "git", "checkout", "master")
+	runCmd(t, repoDir, "touch", "merged-file")
+	runCmd(t, repoDir, "git", "add", "merged-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "merge-commit")
+	mergeCommit := strings.TrimSpace(runCmd(t, repoDir, "git", "rev-parse", "HEAD"))
+	runCmd(t, repoDir, "touch", "later-file")
+	runCmd(t, repoDir, "git", "add", "later-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "later-commit")
+
+	for _, checkoutMerge := range []bool{false, true} {
+		t.Run(fmt.Sprintf("checkout merge %t", checkoutMerge), func(t *testing.T) {
+			dataDir, cleanup2 := TempDir(t)
+			defer cleanup2()
+
+			overrideURL := fmt.Sprintf("file://%s", repoDir)
+			wd := &events.FileWorkspace{
+				DataDir:                     dataDir,
+				CheckoutMerge:               checkoutMerge,
+				TestingOverrideHeadCloneURL: overrideURL,
+				TestingOverrideBaseCloneURL: overrideURL,
+			}
+			pull := models.PullRequest{
+				HeadBranch:  "branch",
+				BaseBranch:  "master",
+				State:       models.MergedPullState,
+				MergeCommit: mergeCommit,
+			}
+			cloneDir, hasDiverged, err := wd.Clone(nil, models.Repo{}, models.Repo{}, pull, "default")
+			Ok(t, err)
+			Equals(t, false, hasDiverged)
+			Equals(t, mergeCommit, strings.TrimSpace(runCmd(t, cloneDir, "git", "rev-parse", "HEAD")))
+
+			// Cloning again shouldn't re-clone.
+			runCmd(t, cloneDir, "touch", "proof")
+			_, _, err = wd.Clone(nil, models.Repo{}, models.Repo{}, pull, "default")
+			Ok(t, err)
+			_, err = os.Stat(filepath.Join(cloneDir, "proof"))
+			Ok(t, err)
+		})
+	}
+}
+
 // Test that if the branch we're merging into has diverged and we're using
 // checkout-strategy=merge, we warn the user (see #804).
 func TestClone_MasterHasDiverged(t *testing.T) {
@@ -373,6 +425,175 @@ func TestClone_MasterHasDiverged(t *testing.T) {
 	Equals(t, hasDiverged, false)
 }
 
+// Test that HasDiverged works with the branch checkout strategy, ie. when we
+// only cloned the head branch.
+func TestHasDiverged_CheckoutBranch(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               false,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+	}
+	pull := models.PullRequest{
+		HeadBranch: "branch",
+		BaseBranch: "master",
+	}
+	cloneDir, _, err := wd.Clone(nil, models.Repo{}, models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	hasDiverged, err := wd.HasDiverged(nil, cloneDir, models.Repo{}, pull)
+	Ok(t, err)
+	Equals(t, false, hasDiverged)
+
+	// Now add a commit to master that isn't on the branch.
+	runCmd(t, repoDir, "git", "checkout", "master")
+	runCmd(t, repoDir, "touch", "master-file")
+	runCmd(t, repoDir, "git", "add", "master-file")
+	runCmd(t, repoDir, "git", "commit", "-m", "master-commit")
+
+	hasDiverged, err = wd.HasDiverged(nil, cloneDir, models.Repo{}, pull)
+	Ok(t, err)
+	Equals(t, true, hasDiverged)
+}
+
+// Test that ReadBaseFile reads files from the base branch rather than the
+// branch that was cloned.
+func TestReadBaseFile(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+	runCmd(t, repoDir, "git", "checkout", "branch")
+	runCmd(t, repoDir, "sh", "-c", "echo '* @branch' > CODEOWNERS")
+	runCmd(t, repoDir, "git", "add", "CODEOWNERS")
+	runCmd(t, repoDir, "git", "commit", "-m", "branch-codeowners")
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               false,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+	}
+	pull := models.PullRequest{
+		HeadBranch: "branch",
+		BaseBranch: "master",
+	}
+	cloneDir, _, err := wd.Clone(nil, models.Repo{}, models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	contents, err := wd.ReadBaseFile(nil, cloneDir, models.Repo{}, pull, "CODEOWNERS")
+	Ok(t, err)
+	Assert(t, contents == nil, "exp nil since the file isn't on the base branch, got %q", contents)
+
+	runCmd(t, repoDir, "git", "checkout", "master")
+	runCmd(t, repoDir, "sh", "-c", "echo '* @master' > CODEOWNERS")
+	runCmd(t, repoDir, "git", "add", "CODEOWNERS")
+	runCmd(t, repoDir, "git", "commit", "-m", "master-codeowners")
+
+	contents, err = wd.ReadBaseFile(nil, cloneDir, models.Repo{}, pull, "CODEOWNERS")
+	Ok(t, err)
+	Equals(t, "* @master\n", string(contents))
+}
+
+// Test that ListBaseFiles lists the files on the base branch rather than the
+// branch that was cloned.
+func TestListBaseFiles(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	dataDir, cleanup2 := TempDir(t)
+	defer cleanup2()
+	runCmd(t, repoDir, "mkdir", "-p", "project/modules")
+	runCmd(t, repoDir, "touch", "project/main.tf", "project/modules/main.tf")
+	runCmd(t, repoDir, "git", "add", "project")
+	runCmd(t, repoDir, "git", "commit", "-m", "project")
+	runCmd(t, repoDir, "git", "checkout", "-b", "pr-branch")
+	runCmd(t, repoDir, "touch", "project/README.md")
+	runCmd(t, repoDir, "git", "add", "project")
+	runCmd(t, repoDir, "git", "commit", "-m", "readme")
+
+	overrideURL := fmt.Sprintf("file://%s", repoDir)
+	wd := &events.FileWorkspace{
+		DataDir:                     dataDir,
+		CheckoutMerge:               false,
+		TestingOverrideHeadCloneURL: overrideURL,
+		TestingOverrideBaseCloneURL: overrideURL,
+	}
+	pull := models.PullRequest{
+		HeadBranch: "pr-branch",
+		BaseBranch: "master",
+	}
+	cloneDir, _, err := wd.Clone(nil, models.Repo{}, models.Repo{}, pull, "default")
+	Ok(t, err)
+
+	files, err := wd.ListBaseFiles(nil, cloneDir, models.Repo{}, pull, "project")
+	Ok(t, err)
+	Equals(t, []string{"project/main.tf"}, files)
+
+	files, err = wd.ListBaseFiles(nil, cloneDir, models.Repo{}, pull, "missing")
+	Ok(t, err)
+	Equals(t, []string(nil), files)
+}
+
+// Test that GitHub repos are cloned with a token from GithubCredentials and
+// that the token is redacted from errors.
+func TestClone_GithubCredentials(t *testing.T) {
+	var gotUser, gotPass string
+	gitServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="GitHub"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		gotUser, gotPass = user, pass
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer gitServer.Close()
+
+	dataDir, cleanup := TempDir(t)
+	defer cleanup()
+	wd := &events.FileWorkspace{
+		DataDir:           dataDir,
+		GithubCredentials: &fakeGithubCredentials{token: "installation-token"},
+	}
+	repo := models.Repo{
+		FullName: "owner/repo",
+		CloneURL: gitServer.URL + "/owner/repo.git",
+		VCSHost:  models.VCSHost{Type: models.Github},
+	}
+	_, _, err := wd.Clone(nil, repo, repo, models.PullRequest{
+		BaseRepo:   repo,
+		HeadBranch: "branch",
+	}, "default")
+	Assert(t, err != nil, "expected clone to fail")
+	Equals(t, "x-access-token", gotUser)
+	Equals(t, "installation-token", gotPass)
+	Assert(t, !strings.Contains(err.Error(), "installation-token"), "expected token to be redacted from %q", err.Error())
+}
+
+type fakeGithubCredentials struct {
+	token string
+}
+
+func (f *fakeGithubCredentials) Client() (*http.Client, error) {
+	return http.DefaultClient, nil
+}
+
+func (f *fakeGithubCredentials) GetToken() (string, error) {
+	return f.token, nil
+}
+
+func (f *fakeGithubCredentials) GetUser() (string, error) {
+	return "atlantis[bot]", nil
+}
+
 func initRepo(t *testing.T) (string, func()) {
 	repoDir, cleanup := TempDir(t)
 	runCmd(t, repoDir, "git", "init")
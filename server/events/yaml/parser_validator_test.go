@@ -364,7 +364,7 @@ workflows:
 							WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
 							Enabled:      true,
 						},
-						ApplyRequirements: []string{"approved"},
+						ApplyRequirements: []valid.ApplyRequirement{{Name: "approved"}},
 					},
 				},
 				Workflows: map[string]valid.Workflow{
@@ -402,7 +402,7 @@ workflows:
 							WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
 							Enabled:      false,
 						},
-						ApplyRequirements: []string{"approved"},
+						ApplyRequirements: []valid.ApplyRequirement{{Name: "approved"}},
 					},
 				},
 				Workflows: map[string]valid.Workflow{
@@ -440,7 +440,7 @@ workflows:
 							WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
 							Enabled:      false,
 						},
-						ApplyRequirements: []string{"mergeable"},
+						ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
 					},
 				},
 				Workflows: map[string]valid.Workflow{
@@ -478,7 +478,7 @@ workflows:
 							WhenModified: []string{"**/*.tf*", "**/terragrunt.hcl"},
 							Enabled:      false,
 						},
-						ApplyRequirements: []string{"mergeable", "approved"},
+						ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}, {Name: "approved"}},
 					},
 				},
 				Workflows: map[string]valid.Workflow{
@@ -954,7 +954,98 @@ func TestParseGlobalCfg(t *testing.T) {
 			input: `repos:
 - id: /.*/
   apply_requirements: [invalid]`,
-			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\" and \"mergeable\" are supported.).).",
+			expErr: "repos: (0: (apply_requirements: \"invalid\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"codeowners\", \"undiverged\" and \"checks_passing\" are supported.).).",
+		},
+		"invalid permissions command": {
+			input: `repos:
+- id: /.*/
+  permissions:
+    destroy:
+      users: [alice]`,
+			expErr: "repos: (0: (permissions: \"destroy\" is not a valid command, only \"plan\", \"apply\" and \"unlock\" are supported.).).",
+		},
+		"permissions without users or teams": {
+			input: `repos:
+- id: /.*/
+  permissions:
+    apply:
+      users: []`,
+			expErr: "repos: (0: (permissions: apply must specify at least one of users or teams.).).",
+		},
+		"permissions": {
+			input: `repos:
+- id: github.com/owner/repo
+  permissions:
+    apply:
+      users: [alice]
+      teams: [infra]
+    unlock:
+      teams: [owner/admins]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID: "github.com/owner/repo",
+						Permissions: map[string]valid.Permission{
+							"apply": {
+								Users: []string{"alice"},
+								Teams: []string{"infra"},
+							},
+							"unlock": {
+								Teams: []string{"owner/admins"},
+							},
+						},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+			},
+		},
+		"apply after merge": {
+			input: `repos:
+- id: github.com/owner/repo
+  apply_after_merge: true`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID:              "github.com/owner/repo",
+						ApplyAfterMerge: Bool(true),
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+			},
+		},
+		"labels": {
+			input: `repos:
+- id: github.com/owner/repo
+  labels:
+    atlantis/skip:
+      skip_autoplan: true
+    atlantis/plan-all:
+      plan_all: true
+    needs-sre:
+      apply_requirements:
+      - approved:
+          count: 1
+          from: [sre]`,
+			exp: valid.GlobalCfg{
+				Repos: []valid.Repo{
+					defaultCfg.Repos[0],
+					{
+						ID: "github.com/owner/repo",
+						Labels: map[string]valid.LabelBehavior{
+							"atlantis/skip":     {SkipAutoplan: true},
+							"atlantis/plan-all": {PlanAll: true},
+							"needs-sre": {
+								ApplyRequirements: []valid.ApplyRequirement{
+									{Name: "approved", ApprovalCount: 1, ApprovalsFrom: []string{"sre"}},
+								},
+							},
+						},
+					},
+				},
+				Workflows: defaultCfg.Workflows,
+			},
 		},
 		"no workflows key": {
 			input: `repos: []`,
@@ -1047,7 +1138,7 @@ workflows:
 					defaultCfg.Repos[0],
 					{
 						ID:                   "github.com/owner/repo",
-						ApplyRequirements:    []string{"approved", "mergeable"},
+						ApplyRequirements:    []valid.ApplyRequirement{{Name: "approved"}, {Name: "mergeable"}},
 						Workflow:             &customWorkflow1,
 						AllowedOverrides:     []string{"apply_requirements", "workflow"},
 						AllowCustomWorkflows: Bool(true),
@@ -1112,7 +1203,7 @@ workflows:
 				Repos: []valid.Repo{
 					{
 						IDRegex:           regexp.MustCompile(".*"),
-						ApplyRequirements: []string{},
+						ApplyRequirements: []valid.ApplyRequirement{},
 						Workflow: &valid.Workflow{
 							Name: "default",
 							Apply: valid.Stage{
@@ -1258,7 +1349,7 @@ func TestParserValidator_ParseGlobalCfgJSON(t *testing.T) {
 					valid.NewGlobalCfg(false, false, false).Repos[0],
 					{
 						IDRegex:              regexp.MustCompile(".*"),
-						ApplyRequirements:    []string{"mergeable", "approved"},
+						ApplyRequirements:    []valid.ApplyRequirement{{Name: "mergeable"}, {Name: "approved"}},
 						Workflow:             &customWorkflow,
 						AllowedOverrides:     []string{"workflow", "apply_requirements"},
 						AllowCustomWorkflows: Bool(true),
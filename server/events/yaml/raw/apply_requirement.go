@@ -0,0 +1,171 @@
+package raw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+const (
+	ApprovalCountKey = "count"
+	ApprovalFromKey  = "from"
+)
+
+// ApplyRequirement represents a single apply requirement. In YAML, it can be
+// set as
+// 1. A single string for a requirement without options:
+//    - approved
+//    - mergeable
+// 2. A map for the approved requirement with options:
+//    - approved:
+//        count: 2
+//        from: ["@alice", "@org/sre"]
+type ApplyRequirement struct {
+	// Key will be set in case #1 above.
+	Key *string
+	// Map will be set in case #2 above. There could be multiple keys so we
+	// validate there's only one later.
+	Map map[string]ApprovalOptions
+}
+
+// ApprovalOptions are the options of the approved requirement.
+type ApprovalOptions struct {
+	Count *int     `yaml:"count,omitempty" json:"count,omitempty"`
+	From  []string `yaml:"from,omitempty" json:"from,omitempty"`
+}
+
+func (a *ApplyRequirement) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return a.unmarshalGeneric(unmarshal)
+}
+
+func (a ApplyRequirement) MarshalYAML() (interface{}, error) {
+	return a.marshalGeneric(), nil
+}
+
+func (a *ApplyRequirement) UnmarshalJSON(data []byte) error {
+	return a.unmarshalGeneric(func(i interface{}) error {
+		return json.Unmarshal(data, i)
+	})
+}
+
+func (a ApplyRequirement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.marshalGeneric())
+}
+
+// Validate returns an error if the requirement isn't supported or its options
+// are invalid.
+func (a ApplyRequirement) Validate() error {
+	if a.Key != nil {
+		r := *a.Key
+		switch r {
+		case ApprovedApplyRequirement, MergeableApplyRequirement, CodeOwnersApplyRequirement, UndivergedApplyRequirement, ChecksPassingApplyRequirement:
+		default:
+			return fmt.Errorf("%q is not a valid apply_requirement, only %q, %q, %q, %q and %q are supported",
+				r, ApprovedApplyRequirement, MergeableApplyRequirement, CodeOwnersApplyRequirement, UndivergedApplyRequirement, ChecksPassingApplyRequirement)
+		}
+		return nil
+	}
+
+	var keys []string
+	for k := range a.Map {
+		keys = append(keys, k)
+	}
+	// Sort so tests can be deterministic.
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		return errors.New("apply_requirement element is empty")
+	}
+	if len(keys) > 1 {
+		return fmt.Errorf("apply_requirement element can only contain a single key, found %d: %s",
+			len(keys), strings.Join(keys, ","))
+	}
+	if keys[0] != ApprovedApplyRequirement {
+		return fmt.Errorf("%q apply_requirement does not support options, only %q does", keys[0], ApprovedApplyRequirement)
+	}
+	opts := a.Map[keys[0]]
+	if opts.Count != nil && *opts.Count < 1 {
+		return fmt.Errorf("%s.%s must be at least 1, got %d", ApprovedApplyRequirement, ApprovalCountKey, *opts.Count)
+	}
+	for _, f := range opts.From {
+		if strings.TrimPrefix(f, "@") == "" {
+			return fmt.Errorf("%s.%s cannot contain empty users or teams", ApprovedApplyRequirement, ApprovalFromKey)
+		}
+	}
+	return nil
+}
+
+func (a ApplyRequirement) ToValid() valid.ApplyRequirement {
+	// This will trigger in case #1 (see ApplyRequirement docs).
+	if a.Key != nil {
+		return valid.ApplyRequirement{
+			Name: *a.Key,
+		}
+	}
+
+	// This will trigger in case #2 (see ApplyRequirement docs). After
+	// validation we assume there's only one key.
+	for name, opts := range a.Map {
+		v := valid.ApplyRequirement{
+			Name:          name,
+			ApprovalsFrom: opts.From,
+		}
+		if opts.Count != nil {
+			v.ApprovalCount = *opts.Count
+		}
+		return v
+	}
+
+	panic("apply_requirement was not valid. This is a bug!")
+}
+
+// unmarshalGeneric is used by UnmarshalJSON and UnmarshalYAML to unmarshal
+// an apply requirement into one of its two forms. It takes a parameter
+// unmarshal that is a function that tries to unmarshal the current element
+// into a given object.
+func (a *ApplyRequirement) unmarshalGeneric(unmarshal func(interface{}) error) error {
+	// First try to unmarshal as a single string, ex.
+	// apply_requirements: [approved, mergeable]
+	// We validate if it's a legal string later.
+	var singleString string
+	err := unmarshal(&singleString)
+	if err == nil {
+		a.Key = &singleString
+		return nil
+	}
+
+	// This represents a requirement with options, ex.
+	//   approved:
+	//     count: 2
+	// We validate if there's a single key in the map later.
+	var reqMap map[string]ApprovalOptions
+	err = unmarshal(&reqMap)
+	if err == nil {
+		a.Map = reqMap
+		return nil
+	}
+	return err
+}
+
+func (a ApplyRequirement) marshalGeneric() interface{} {
+	if a.Key != nil {
+		return a.Key
+	}
+	return a.Map
+}
+
+// applyReqsToValid converts reqs to their valid form. It preserves nil since
+// we treat nil requirements differently from empty requirements.
+func applyReqsToValid(reqs []ApplyRequirement) []valid.ApplyRequirement {
+	if reqs == nil {
+		return nil
+	}
+	v := make([]valid.ApplyRequirement, 0, len(reqs))
+	for _, r := range reqs {
+		v = append(v, r.ToValid())
+	}
+	return v
+}
@@ -0,0 +1,236 @@
+package raw_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestApplyRequirement_YAMLMarshalling(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.ApplyRequirement
+		expErr      string
+	}{
+		{
+			description: "single string",
+			input:       `approved`,
+			exp: raw.ApplyRequirement{
+				Key: String("approved"),
+			},
+		},
+		{
+			description: "approved with options",
+			input: `
+approved:
+  count: 2
+  from: ["@alice", "@org/sre"]`,
+			exp: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {
+						Count: Int(2),
+						From:  []string{"@alice", "@org/sre"},
+					},
+				},
+			},
+		},
+		{
+			description: "approved with only count",
+			input: `
+approved:
+  count: 2`,
+			exp: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {
+						Count: Int(2),
+					},
+				},
+			},
+		},
+		{
+			description: "unknown option",
+			input: `
+approved:
+  users: [alice]`,
+			expErr: "yaml: unmarshal errors:\n  line 3: field users not found in type raw.ApprovalOptions",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var got raw.ApplyRequirement
+			err := yaml.UnmarshalStrict([]byte(c.input), &got)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.exp, got)
+
+			bytes, err := yaml.Marshal(got)
+			Ok(t, err)
+
+			var got2 raw.ApplyRequirement
+			err = yaml.UnmarshalStrict(bytes, &got2)
+			Ok(t, err)
+			Equals(t, got, got2)
+		})
+	}
+}
+
+func TestApplyRequirement_JSONMarshalling(t *testing.T) {
+	var got []raw.ApplyRequirement
+	err := json.Unmarshal([]byte(`["mergeable", {"approved": {"count": 2, "from": ["@org/sre"]}}]`), &got)
+	Ok(t, err)
+	Equals(t, []raw.ApplyRequirement{
+		{Key: String("mergeable")},
+		{Map: map[string]raw.ApprovalOptions{"approved": {Count: Int(2), From: []string{"@org/sre"}}}},
+	}, got)
+
+	bytes, err := json.Marshal(got)
+	Ok(t, err)
+	Equals(t, `["mergeable",{"approved":{"count":2,"from":["@org/sre"]}}]`, string(bytes))
+}
+
+func TestApplyRequirement_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.ApplyRequirement
+		expErr      string
+	}{
+		{
+			description: "approved",
+			input:       raw.ApplyRequirement{Key: String("approved")},
+		},
+		{
+			description: "mergeable",
+			input:       raw.ApplyRequirement{Key: String("mergeable")},
+		},
+		{
+			description: "codeowners",
+			input:       raw.ApplyRequirement{Key: String("codeowners")},
+		},
+		{
+			description: "undiverged",
+			input:       raw.ApplyRequirement{Key: String("undiverged")},
+		},
+		{
+			description: "checks_passing",
+			input:       raw.ApplyRequirement{Key: String("checks_passing")},
+		},
+		{
+			description: "unsupported string",
+			input:       raw.ApplyRequirement{Key: String("unsupported")},
+			expErr:      "\"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"codeowners\", \"undiverged\" and \"checks_passing\" are supported",
+		},
+		{
+			description: "approved with options",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {Count: Int(2), From: []string{"@org/sre"}},
+				},
+			},
+		},
+		{
+			description: "empty",
+			input:       raw.ApplyRequirement{},
+			expErr:      "apply_requirement element is empty",
+		},
+		{
+			description: "multiple keys",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved":  {},
+					"mergeable": {},
+				},
+			},
+			expErr: "apply_requirement element can only contain a single key, found 2: approved,mergeable",
+		},
+		{
+			description: "options for mergeable",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"mergeable": {Count: Int(2)},
+				},
+			},
+			expErr: "\"mergeable\" apply_requirement does not support options, only \"approved\" does",
+		},
+		{
+			description: "count less than 1",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {Count: Int(0)},
+				},
+			},
+			expErr: "approved.count must be at least 1, got 0",
+		},
+		{
+			description: "empty from",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {From: []string{"@"}},
+				},
+			},
+			expErr: "approved.from cannot contain empty users or teams",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+				return
+			}
+			ErrEquals(t, c.expErr, err)
+		})
+	}
+}
+
+func TestApplyRequirement_ToValid(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.ApplyRequirement
+		exp         valid.ApplyRequirement
+	}{
+		{
+			description: "single string",
+			input:       raw.ApplyRequirement{Key: String("mergeable")},
+			exp:         valid.ApplyRequirement{Name: "mergeable"},
+		},
+		{
+			description: "approved with options",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {Count: Int(2), From: []string{"@org/sre"}},
+				},
+			},
+			exp: valid.ApplyRequirement{
+				Name:          "approved",
+				ApprovalCount: 2,
+				ApprovalsFrom: []string{"@org/sre"},
+			},
+		},
+		{
+			description: "approved with only from",
+			input: raw.ApplyRequirement{
+				Map: map[string]raw.ApprovalOptions{
+					"approved": {From: []string{"@alice"}},
+				},
+			},
+			exp: valid.ApplyRequirement{
+				Name:          "approved",
+				ApprovalsFrom: []string{"@alice"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			Equals(t, c.exp, c.input.ToValid())
+		})
+	}
+}
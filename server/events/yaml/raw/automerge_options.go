@@ -0,0 +1,55 @@
+package raw
+
+import (
+	"fmt"
+	"text/template"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// AutomergeOptions is the raw schema for how pull requests are merged when
+// automerge is enabled. It can be set in both the repo-level and server-side
+// repo config.
+type AutomergeOptions struct {
+	MergeMethod        *string `yaml:"merge_method,omitempty" json:"merge_method,omitempty"`
+	DeleteSourceBranch *bool   `yaml:"delete_source_branch,omitempty" json:"delete_source_branch,omitempty"`
+	CommitMessage      *string `yaml:"commit_message,omitempty" json:"commit_message,omitempty"`
+}
+
+func (a AutomergeOptions) Validate() error {
+	methodValid := func(value interface{}) error {
+		method := value.(*string)
+		if method == nil {
+			return nil
+		}
+		switch *method {
+		case valid.MergeMergeMethod, valid.SquashMergeMethod, valid.RebaseMergeMethod:
+			return nil
+		}
+		return fmt.Errorf("%q is not a valid merge_method, only %q, %q and %q are supported", *method, valid.MergeMergeMethod, valid.SquashMergeMethod, valid.RebaseMergeMethod)
+	}
+
+	msgValid := func(value interface{}) error {
+		msg := value.(*string)
+		if msg == nil {
+			return nil
+		}
+		_, err := template.New("").Parse(*msg)
+		return errors.Wrap(err, "parsing template")
+	}
+
+	return validation.ValidateStruct(&a,
+		validation.Field(&a.MergeMethod, validation.By(methodValid)),
+		validation.Field(&a.CommitMessage, validation.By(msgValid)),
+	)
+}
+
+func (a AutomergeOptions) ToValid() valid.AutomergeOptions {
+	return valid.AutomergeOptions{
+		MergeMethod:        a.MergeMethod,
+		DeleteSourceBranch: a.DeleteSourceBranch,
+		CommitMessage:      a.CommitMessage,
+	}
+}
@@ -0,0 +1,107 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestAutomergeOptions_UnmarshalYAML(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.AutomergeOptions
+	}{
+		{
+			description: "omit unset fields",
+			input:       "",
+			exp:         raw.AutomergeOptions{},
+		},
+		{
+			description: "all fields set",
+			input: `
+merge_method: squash
+delete_source_branch: true
+commit_message: "Merge #{{ .Num }}"
+`,
+			exp: raw.AutomergeOptions{
+				MergeMethod:        String("squash"),
+				DeleteSourceBranch: Bool(true),
+				CommitMessage:      String("Merge #{{ .Num }}"),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var a raw.AutomergeOptions
+			err := yaml.UnmarshalStrict([]byte(c.input), &a)
+			Ok(t, err)
+			Equals(t, c.exp, a)
+		})
+	}
+}
+
+func TestAutomergeOptions_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.AutomergeOptions
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.AutomergeOptions{},
+			expErr:      "",
+		},
+		{
+			description: "valid merge methods",
+			input: raw.AutomergeOptions{
+				MergeMethod:   String("rebase"),
+				CommitMessage: String("Merge {{ .HeadBranch }}"),
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid merge method",
+			input: raw.AutomergeOptions{
+				MergeMethod: String("fast-forward"),
+			},
+			expErr: "merge_method: \"fast-forward\" is not a valid merge_method, only \"merge\", \"squash\" and \"rebase\" are supported.",
+		},
+		{
+			description: "invalid commit message template",
+			input: raw.AutomergeOptions{
+				CommitMessage: String("Merge {{ .Num"),
+			},
+			expErr: "commit_message: parsing template: template: :1: unclosed action.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestAutomergeOptions_ToValid(t *testing.T) {
+	Equals(t, valid.AutomergeOptions{}, raw.AutomergeOptions{}.ToValid())
+	Equals(t, valid.AutomergeOptions{
+		MergeMethod:        String("merge"),
+		DeleteSourceBranch: Bool(false),
+		CommitMessage:      String("msg"),
+	}, raw.AutomergeOptions{
+		MergeMethod:        String("merge"),
+		DeleteSourceBranch: Bool(false),
+		CommitMessage:      String("msg"),
+	}.ToValid())
+}
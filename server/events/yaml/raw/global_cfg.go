@@ -18,11 +18,21 @@ type GlobalCfg struct {
 
 // Repo is the raw schema for repos in the server-side repo config.
 type Repo struct {
-	ID                   string   `yaml:"id" json:"id"`
-	ApplyRequirements    []string `yaml:"apply_requirements" json:"apply_requirements"`
-	Workflow             *string  `yaml:"workflow,omitempty" json:"workflow,omitempty"`
-	AllowedOverrides     []string `yaml:"allowed_overrides" json:"allowed_overrides"`
-	AllowCustomWorkflows *bool    `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
+	ID                   string                   `yaml:"id" json:"id"`
+	ApplyRequirements    []ApplyRequirement       `yaml:"apply_requirements" json:"apply_requirements"`
+	Workflow             *string                  `yaml:"workflow,omitempty" json:"workflow,omitempty"`
+	AllowedOverrides     []string                 `yaml:"allowed_overrides" json:"allowed_overrides"`
+	AllowCustomWorkflows *bool                    `yaml:"allow_custom_workflows,omitempty" json:"allow_custom_workflows,omitempty"`
+	Permissions          map[string]Permission    `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+	AutomergeOptions     *AutomergeOptions        `yaml:"automerge_options,omitempty" json:"automerge_options,omitempty"`
+	ApplyAfterMerge      *bool                    `yaml:"apply_after_merge,omitempty" json:"apply_after_merge,omitempty"`
+	Labels               map[string]LabelBehavior `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// Permission is the raw schema for who is allowed to run a command.
+type Permission struct {
+	Users []string `yaml:"users" json:"users"`
+	Teams []string `yaml:"teams" json:"teams"`
 }
 
 func (g GlobalCfg) Validate() error {
@@ -113,6 +123,19 @@ func (r Repo) Validate() error {
 		return nil
 	}
 
+	permissionsValid := func(value interface{}) error {
+		permissions := value.(map[string]Permission)
+		for cmd, p := range permissions {
+			if cmd != valid.PlanCommandKey && cmd != valid.ApplyCommandKey && cmd != valid.UnlockCommandKey {
+				return fmt.Errorf("%q is not a valid command, only %q, %q and %q are supported", cmd, valid.PlanCommandKey, valid.ApplyCommandKey, valid.UnlockCommandKey)
+			}
+			if len(p.Users) == 0 && len(p.Teams) == 0 {
+				return fmt.Errorf("%s must specify at least one of users or teams", cmd)
+			}
+		}
+		return nil
+	}
+
 	workflowExists := func(value interface{}) error {
 		// We validate workflows in ParserValidator.validateRepoWorkflows
 		// because we need the list of workflows to validate.
@@ -124,6 +147,9 @@ func (r Repo) Validate() error {
 		validation.Field(&r.AllowedOverrides, validation.By(overridesValid)),
 		validation.Field(&r.ApplyRequirements, validation.By(validApplyReq)),
 		validation.Field(&r.Workflow, validation.By(workflowExists)),
+		validation.Field(&r.Permissions, validation.By(permissionsValid)),
+		validation.Field(&r.AutomergeOptions),
+		validation.Field(&r.Labels),
 	)
 }
 
@@ -146,12 +172,40 @@ func (r Repo) ToValid(workflows map[string]valid.Workflow) valid.Repo {
 		workflow = &ptr
 	}
 
+	var permissions map[string]valid.Permission
+	if r.Permissions != nil {
+		permissions = make(map[string]valid.Permission)
+		for cmd, p := range r.Permissions {
+			permissions[cmd] = valid.Permission{
+				Users: p.Users,
+				Teams: p.Teams,
+			}
+		}
+	}
+
+	var automergeOpts valid.AutomergeOptions
+	if r.AutomergeOptions != nil {
+		automergeOpts = r.AutomergeOptions.ToValid()
+	}
+
+	var labels map[string]valid.LabelBehavior
+	if r.Labels != nil {
+		labels = make(map[string]valid.LabelBehavior)
+		for label, b := range r.Labels {
+			labels[label] = b.ToValid()
+		}
+	}
+
 	return valid.Repo{
 		ID:                   id,
 		IDRegex:              idRegex,
-		ApplyRequirements:    r.ApplyRequirements,
+		ApplyRequirements:    applyReqsToValid(r.ApplyRequirements),
 		Workflow:             workflow,
 		AllowedOverrides:     r.AllowedOverrides,
 		AllowCustomWorkflows: r.AllowCustomWorkflows,
+		Permissions:          permissions,
+		AutomergeOptions:     automergeOpts,
+		ApplyAfterMerge:      r.ApplyAfterMerge,
+		Labels:               labels,
 	}
 }
@@ -0,0 +1,32 @@
+package raw
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+)
+
+// LabelBehavior is the raw schema for what Atlantis does when a pull request
+// has a label. It's set in the server-side repo config under the labels key.
+type LabelBehavior struct {
+	SkipAutoplan      *bool              `yaml:"skip_autoplan,omitempty" json:"skip_autoplan,omitempty"`
+	PlanAll           *bool              `yaml:"plan_all,omitempty" json:"plan_all,omitempty"`
+	ApplyRequirements []ApplyRequirement `yaml:"apply_requirements,omitempty" json:"apply_requirements,omitempty"`
+}
+
+func (l LabelBehavior) Validate() error {
+	return validation.ValidateStruct(&l,
+		validation.Field(&l.ApplyRequirements, validation.By(validApplyReq)),
+	)
+}
+
+func (l LabelBehavior) ToValid() valid.LabelBehavior {
+	var v valid.LabelBehavior
+	if l.SkipAutoplan != nil {
+		v.SkipAutoplan = *l.SkipAutoplan
+	}
+	if l.PlanAll != nil {
+		v.PlanAll = *l.PlanAll
+	}
+	v.ApplyRequirements = applyReqsToValid(l.ApplyRequirements)
+	return v
+}
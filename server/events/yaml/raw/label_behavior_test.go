@@ -0,0 +1,107 @@
+package raw_test
+
+import (
+	"testing"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/runatlantis/atlantis/server/events/yaml/raw"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	. "github.com/runatlantis/atlantis/testing"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestLabelBehavior_UnmarshalYAML(t *testing.T) {
+	cases := []struct {
+		description string
+		input       string
+		exp         raw.LabelBehavior
+	}{
+		{
+			description: "omit unset fields",
+			input:       "",
+			exp:         raw.LabelBehavior{},
+		},
+		{
+			description: "all fields set",
+			input: `
+skip_autoplan: true
+plan_all: false
+apply_requirements:
+- approved:
+    from: ["@org/sre"]
+`,
+			exp: raw.LabelBehavior{
+				SkipAutoplan: Bool(true),
+				PlanAll:      Bool(false),
+				ApplyRequirements: []raw.ApplyRequirement{
+					{Map: map[string]raw.ApprovalOptions{"approved": {From: []string{"@org/sre"}}}},
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			var l raw.LabelBehavior
+			err := yaml.UnmarshalStrict([]byte(c.input), &l)
+			Ok(t, err)
+			Equals(t, c.exp, l)
+		})
+	}
+}
+
+func TestLabelBehavior_Validate(t *testing.T) {
+	cases := []struct {
+		description string
+		input       raw.LabelBehavior
+		expErr      string
+	}{
+		{
+			description: "nothing set",
+			input:       raw.LabelBehavior{},
+			expErr:      "",
+		},
+		{
+			description: "valid apply requirements",
+			input: raw.LabelBehavior{
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("approved")}},
+			},
+			expErr: "",
+		},
+		{
+			description: "invalid apply requirements",
+			input: raw.LabelBehavior{
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("reviewed")}},
+			},
+			expErr: "apply_requirements: \"reviewed\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"codeowners\", \"undiverged\" and \"checks_passing\" are supported.",
+		},
+	}
+	validation.ErrorTag = "yaml"
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			err := c.input.Validate()
+			if c.expErr == "" {
+				Ok(t, err)
+			} else {
+				ErrEquals(t, c.expErr, err)
+			}
+		})
+	}
+}
+
+func TestLabelBehavior_ToValid(t *testing.T) {
+	Equals(t, valid.LabelBehavior{}, raw.LabelBehavior{}.ToValid())
+	Equals(t, valid.LabelBehavior{
+		SkipAutoplan: true,
+		PlanAll:      true,
+		ApplyRequirements: []valid.ApplyRequirement{
+			{Name: "approved", ApprovalCount: 2},
+		},
+	}, raw.LabelBehavior{
+		SkipAutoplan: Bool(true),
+		PlanAll:      Bool(true),
+		ApplyRequirements: []raw.ApplyRequirement{
+			{Map: map[string]raw.ApprovalOptions{"approved": {Count: Int(2)}}},
+		},
+	}.ToValid())
+}
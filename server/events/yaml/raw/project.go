@@ -13,19 +13,22 @@ import (
 )
 
 const (
-	DefaultWorkspace          = "default"
-	ApprovedApplyRequirement  = "approved"
-	MergeableApplyRequirement = "mergeable"
+	DefaultWorkspace              = "default"
+	ApprovedApplyRequirement      = "approved"
+	MergeableApplyRequirement     = "mergeable"
+	CodeOwnersApplyRequirement    = "codeowners"
+	UndivergedApplyRequirement    = "undiverged"
+	ChecksPassingApplyRequirement = "checks_passing"
 )
 
 type Project struct {
-	Name              *string   `yaml:"name,omitempty"`
-	Dir               *string   `yaml:"dir,omitempty"`
-	Workspace         *string   `yaml:"workspace,omitempty"`
-	Workflow          *string   `yaml:"workflow,omitempty"`
-	TerraformVersion  *string   `yaml:"terraform_version,omitempty"`
-	Autoplan          *Autoplan `yaml:"autoplan,omitempty"`
-	ApplyRequirements []string  `yaml:"apply_requirements,omitempty"`
+	Name              *string            `yaml:"name,omitempty"`
+	Dir               *string            `yaml:"dir,omitempty"`
+	Workspace         *string            `yaml:"workspace,omitempty"`
+	Workflow          *string            `yaml:"workflow,omitempty"`
+	TerraformVersion  *string            `yaml:"terraform_version,omitempty"`
+	Autoplan          *Autoplan          `yaml:"autoplan,omitempty"`
+	ApplyRequirements []ApplyRequirement `yaml:"apply_requirements,omitempty"`
 }
 
 func (p Project) Validate() error {
@@ -90,7 +93,7 @@ func (p Project) ToValid() valid.Project {
 	}
 
 	// There are no default apply requirements.
-	v.ApplyRequirements = p.ApplyRequirements
+	v.ApplyRequirements = applyReqsToValid(p.ApplyRequirements)
 
 	v.Name = p.Name
 
@@ -107,10 +110,10 @@ func validProjectName(name string) bool {
 }
 
 func validApplyReq(value interface{}) error {
-	reqs := value.([]string)
+	reqs := value.([]ApplyRequirement)
 	for _, r := range reqs {
-		if r != ApprovedApplyRequirement && r != MergeableApplyRequirement {
-			return fmt.Errorf("%q is not a valid apply_requirement, only %q and %q are supported", r, ApprovedApplyRequirement, MergeableApplyRequirement)
+		if err := r.Validate(); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -53,7 +53,24 @@ apply_requirements:
 					WhenModified: []string{},
 					Enabled:      Bool(false),
 				},
-				ApplyRequirements: []string{"mergeable"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("mergeable")}},
+			},
+		},
+		{
+			description: "approved apply requirement with options",
+			input: `
+dir: mydir
+apply_requirements:
+- mergeable
+- approved:
+    count: 2
+    from: ["@org/sre"]`,
+			exp: raw.Project{
+				Dir: String("mydir"),
+				ApplyRequirements: []raw.ApplyRequirement{
+					{Key: String("mergeable")},
+					{Map: map[string]raw.ApprovalOptions{"approved": {Count: Int(2), From: []string{"@org/sre"}}}},
+				},
 			},
 		},
 	}
@@ -99,15 +116,15 @@ func TestProject_Validate(t *testing.T) {
 			description: "apply reqs with unsupported",
 			input: raw.Project{
 				Dir:               String("."),
-				ApplyRequirements: []string{"unsupported"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("unsupported")}},
 			},
-			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\" and \"mergeable\" are supported.",
+			expErr: "apply_requirements: \"unsupported\" is not a valid apply_requirement, only \"approved\", \"mergeable\", \"codeowners\", \"undiverged\" and \"checks_passing\" are supported.",
 		},
 		{
 			description: "apply reqs with approved requirement",
 			input: raw.Project{
 				Dir:               String("."),
-				ApplyRequirements: []string{"approved"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("approved")}},
 			},
 			expErr: "",
 		},
@@ -115,7 +132,7 @@ func TestProject_Validate(t *testing.T) {
 			description: "apply reqs with mergeable requirement",
 			input: raw.Project{
 				Dir:               String("."),
-				ApplyRequirements: []string{"mergeable"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("mergeable")}},
 			},
 			expErr: "",
 		},
@@ -123,7 +140,7 @@ func TestProject_Validate(t *testing.T) {
 			description: "apply reqs with mergeable and approved requirements",
 			input: raw.Project{
 				Dir:               String("."),
-				ApplyRequirements: []string{"mergeable", "approved"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("mergeable")}, {Key: String("approved")}},
 			},
 			expErr: "",
 		},
@@ -249,7 +266,7 @@ func TestProject_ToValid(t *testing.T) {
 					WhenModified: []string{"hi"},
 					Enabled:      Bool(false),
 				},
-				ApplyRequirements: []string{"approved"},
+				ApplyRequirements: []raw.ApplyRequirement{{Key: String("approved")}},
 				Name:              String("myname"),
 			},
 			exp: valid.Project{
@@ -261,7 +278,7 @@ func TestProject_ToValid(t *testing.T) {
 					WhenModified: []string{"hi"},
 					Enabled:      false,
 				},
-				ApplyRequirements: []string{"approved"},
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "approved"}},
 				Name:              String("myname"),
 			},
 		},
@@ -12,10 +12,11 @@ const DefaultAutomerge = false
 
 // RepoCfg is the raw schema for repo-level atlantis.yaml config.
 type RepoCfg struct {
-	Version   *int                `yaml:"version,omitempty"`
-	Projects  []Project           `yaml:"projects,omitempty"`
-	Workflows map[string]Workflow `yaml:"workflows,omitempty"`
-	Automerge *bool               `yaml:"automerge,omitempty"`
+	Version          *int                `yaml:"version,omitempty"`
+	Projects         []Project           `yaml:"projects,omitempty"`
+	Workflows        map[string]Workflow `yaml:"workflows,omitempty"`
+	Automerge        *bool               `yaml:"automerge,omitempty"`
+	AutomergeOptions *AutomergeOptions   `yaml:"automerge_options,omitempty"`
 }
 
 func (r RepoCfg) Validate() error {
@@ -33,6 +34,7 @@ func (r RepoCfg) Validate() error {
 		validation.Field(&r.Version, validation.By(equals2)),
 		validation.Field(&r.Projects),
 		validation.Field(&r.Workflows),
+		validation.Field(&r.AutomergeOptions),
 	)
 }
 
@@ -52,10 +54,16 @@ func (r RepoCfg) ToValid() valid.RepoCfg {
 		automerge = *r.Automerge
 	}
 
+	var automergeOpts valid.AutomergeOptions
+	if r.AutomergeOptions != nil {
+		automergeOpts = r.AutomergeOptions.ToValid()
+	}
+
 	return valid.RepoCfg{
-		Version:   *r.Version,
-		Projects:  validProjects,
-		Workflows: validWorkflows,
-		Automerge: automerge,
+		Version:          *r.Version,
+		Projects:         validProjects,
+		Workflows:        validWorkflows,
+		Automerge:        automerge,
+		AutomergeOptions: automergeOpts,
 	}
 }
@@ -144,7 +144,7 @@ workflows:
 							WhenModified: []string{},
 							Enabled:      Bool(false),
 						},
-						ApplyRequirements: []string{"mergeable"},
+						ApplyRequirements: []raw.ApplyRequirement{{Key: String("mergeable")}},
 					},
 				},
 				Workflows: map[string]raw.Workflow{
@@ -16,6 +16,20 @@ const WorkflowKey = "workflow"
 const AllowedOverridesKey = "allowed_overrides"
 const AllowCustomWorkflowsKey = "allow_custom_workflows"
 const DefaultWorkflowName = "default"
+const PermissionsKey = "permissions"
+const AutomergeOptionsKey = "automerge_options"
+const ApplyAfterMergeKey = "apply_after_merge"
+const LabelsKey = "labels"
+
+// Methods that can be used to merge pull requests when automerging.
+const MergeMergeMethod = "merge"
+const SquashMergeMethod = "squash"
+const RebaseMergeMethod = "rebase"
+
+// Commands that can be restricted under the permissions key.
+const PlanCommandKey = "plan"
+const ApplyCommandKey = "apply"
+const UnlockCommandKey = "unlock"
 
 // GlobalCfg is the final parsed version of server-side repo config.
 type GlobalCfg struct {
@@ -31,14 +45,86 @@ type Repo struct {
 	// IDRegex is the regex match for this config.
 	// If ID is set then this will be nil.
 	IDRegex              *regexp.Regexp
-	ApplyRequirements    []string
+	ApplyRequirements    []ApplyRequirement
 	Workflow             *Workflow
 	AllowedOverrides     []string
 	AllowCustomWorkflows *bool
+	// Permissions maps command names, ex. apply, to who is allowed to run
+	// them. Commands that aren't in the map can be run by anyone.
+	Permissions map[string]Permission
+	// AutomergeOptions configure how pull requests are merged when automerge
+	// is enabled.
+	AutomergeOptions AutomergeOptions
+	// ApplyAfterMerge is true if Atlantis should plan and apply the projects
+	// modified by a pull request on the base branch once it's merged.
+	ApplyAfterMerge *bool
+	// Labels maps pull request labels to what Atlantis does when a pull
+	// request has them.
+	Labels map[string]LabelBehavior
+}
+
+// LabelBehavior is what Atlantis does when a pull request has a label.
+type LabelBehavior struct {
+	// SkipAutoplan is true if pull requests with the label aren't
+	// autoplanned.
+	SkipAutoplan bool
+	// PlanAll is true if autoplan should plan every project of pull requests
+	// with the label instead of only the modified ones.
+	PlanAll bool
+	// ApplyRequirements are added to the apply requirements of every project
+	// of pull requests with the label.
+	ApplyRequirements []ApplyRequirement
+}
+
+// Permission restricts who is allowed to run a command.
+type Permission struct {
+	// Users are the VCS usernames allowed to run the command.
+	Users []string
+	// Teams are the VCS teams or groups whose members are allowed to run the
+	// command.
+	Teams []string
+}
+
+// ApplyRequirement is a requirement that must be satisfied before apply can
+// be run.
+type ApplyRequirement struct {
+	// Name is the requirement, ex. approved or mergeable.
+	Name string
+	// ApprovalCount is how many approvals the approved requirement needs. If
+	// it's 0, one approval is needed.
+	ApprovalCount int
+	// ApprovalsFrom are the users, ex. @alice, and teams, ex. @org/sre, that
+	// approvals must come from for the approved requirement. If it's empty,
+	// approvals from anyone count.
+	ApprovalsFrom []string
+}
+
+// String returns the requirement as it could be written in config, ex.
+// approved or approved{count: 2, from: [@org/sre]}.
+func (a ApplyRequirement) String() string {
+	var opts []string
+	if a.ApprovalCount > 0 {
+		opts = append(opts, fmt.Sprintf("count: %d", a.ApprovalCount))
+	}
+	if len(a.ApprovalsFrom) > 0 {
+		opts = append(opts, fmt.Sprintf("from: [%s]", strings.Join(a.ApprovalsFrom, ",")))
+	}
+	if len(opts) == 0 {
+		return a.Name
+	}
+	return fmt.Sprintf("%s{%s}", a.Name, strings.Join(opts, ", "))
+}
+
+func applyReqsString(reqs []ApplyRequirement) string {
+	var strs []string
+	for _, r := range reqs {
+		strs = append(strs, r.String())
+	}
+	return strings.Join(strs, ",")
 }
 
 type MergedProjectCfg struct {
-	ApplyRequirements []string
+	ApplyRequirements []ApplyRequirement
 	Workflow          Workflow
 	RepoRelDir        string
 	Workspace         string
@@ -46,6 +132,7 @@ type MergedProjectCfg struct {
 	AutoplanEnabled   bool
 	TerraformVersion  *version.Version
 	RepoCfgVersion    int
+	AutomergeOptions  AutomergeOptions
 }
 
 // DefaultApplyStage is the Atlantis default apply stage.
@@ -83,13 +170,13 @@ func NewGlobalCfg(allowRepoCfg bool, mergeableReq bool, approvedReq bool) Global
 	}
 	// Must construct slices here instead of using a `var` declaration because
 	// we treat nil slices differently.
-	applyReqs := []string{}
+	applyReqs := []ApplyRequirement{}
 	allowedOverrides := []string{}
 	if mergeableReq {
-		applyReqs = append(applyReqs, MergeableApplyReq)
+		applyReqs = append(applyReqs, ApplyRequirement{Name: MergeableApplyReq})
 	}
 	if approvedReq {
-		applyReqs = append(applyReqs, ApprovedApplyReq)
+		applyReqs = append(applyReqs, ApplyRequirement{Name: ApprovedApplyReq})
 	}
 
 	allowCustomWorkflows := false
@@ -130,6 +217,24 @@ func (r Repo) IDString() string {
 	return "/" + r.IDRegex.String() + "/"
 }
 
+// MatchingPermission returns who is allowed to run command on the repo with
+// id repoID. If multiple repo configs restrict command, the last one wins.
+// rule describes which config the permission came from. ok is false if
+// anyone can run command.
+func (g GlobalCfg) MatchingPermission(repoID string, command string) (perm Permission, rule string, ok bool) {
+	for _, repo := range g.Repos {
+		if !repo.IDMatches(repoID) {
+			continue
+		}
+		if p, exists := repo.Permissions[command]; exists {
+			perm = p
+			rule = fmt.Sprintf("%s.%s of repo config with id %s", PermissionsKey, command, repo.IDString())
+			ok = true
+		}
+	}
+	return
+}
+
 // MergeProjectCfg merges proj and rCfg with the global config to return a
 // final config. It assumes that all configs have been validated.
 func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, proj Project, rCfg RepoCfg) MergedProjectCfg {
@@ -140,7 +245,7 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		switch key {
 		case ApplyRequirementsKey:
 			if proj.ApplyRequirements != nil {
-				log.Debug("overriding server-defined %s with repo settings: [%s]", ApplyRequirementsKey, strings.Join(proj.ApplyRequirements, ","))
+				log.Debug("overriding server-defined %s with repo settings: [%s]", ApplyRequirementsKey, applyReqsString(proj.ApplyRequirements))
 				applyReqs = proj.ApplyRequirements
 			}
 		case WorkflowKey:
@@ -168,8 +273,12 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		}
 	}
 
+	// The repo's automerge options aren't restricted by allowed_overrides,
+	// just like the automerge key itself.
+	automergeOpts := g.matchingAutomergeOptions(repoID).Merge(rCfg.AutomergeOptions)
+
 	log.Debug("final settings: %s: [%s], %s: %s",
-		ApplyRequirementsKey, strings.Join(applyReqs, ","), WorkflowKey, workflow.Name)
+		ApplyRequirementsKey, applyReqsString(applyReqs), WorkflowKey, workflow.Name)
 
 	return MergedProjectCfg{
 		ApplyRequirements: applyReqs,
@@ -180,6 +289,7 @@ func (g GlobalCfg) MergeProjectCfg(log logging.SimpleLogging, repoID string, pro
 		AutoplanEnabled:   proj.Autoplan.Enabled,
 		TerraformVersion:  proj.TerraformVersion,
 		RepoCfgVersion:    rCfg.Version,
+		AutomergeOptions:  automergeOpts,
 	}
 }
 
@@ -196,7 +306,60 @@ func (g GlobalCfg) DefaultProjCfg(log logging.SimpleLogging, repoID string, repo
 		Name:              "",
 		AutoplanEnabled:   DefaultAutoPlanEnabled,
 		TerraformVersion:  nil,
+		AutomergeOptions:  g.matchingAutomergeOptions(repoID),
+	}
+}
+
+// matchingAutomergeOptions returns the automerge options for repoID. Options
+// from later repo configs override earlier ones field by field.
+func (g GlobalCfg) matchingAutomergeOptions(repoID string) AutomergeOptions {
+	var opts AutomergeOptions
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) {
+			opts = opts.Merge(repo.AutomergeOptions)
+		}
+	}
+	return opts
+}
+
+// ApplyAfterMerge returns true if pull requests for the repo with id repoID
+// should be applied on their base branch once they're merged. If multiple
+// repo configs set it, the last one wins.
+func (g GlobalCfg) ApplyAfterMerge(repoID string) bool {
+	applyAfterMerge := false
+	for _, repo := range g.Repos {
+		if repo.IDMatches(repoID) && repo.ApplyAfterMerge != nil {
+			applyAfterMerge = *repo.ApplyAfterMerge
+		}
+	}
+	return applyAfterMerge
+}
+
+// MatchingLabelBehavior returns what Atlantis should do for a pull request
+// with labels in the repo with id repoID. The behaviors of all the labels are
+// combined. If multiple repo configs define the same label, the last one wins.
+func (g GlobalCfg) MatchingLabelBehavior(repoID string, labels []string) LabelBehavior {
+	behaviors := make(map[string]LabelBehavior)
+	for _, repo := range g.Repos {
+		if !repo.IDMatches(repoID) {
+			continue
+		}
+		for label, b := range repo.Labels {
+			behaviors[label] = b
+		}
+	}
+
+	var combined LabelBehavior
+	for _, label := range labels {
+		b, ok := behaviors[label]
+		if !ok {
+			continue
+		}
+		combined.SkipAutoplan = combined.SkipAutoplan || b.SkipAutoplan
+		combined.PlanAll = combined.PlanAll || b.PlanAll
+		combined.ApplyRequirements = append(combined.ApplyRequirements, b.ApplyRequirements...)
 	}
+	return combined
 }
 
 // ValidateRepoCfg validates that rCfg for repo with id repoID is valid based
@@ -265,7 +428,7 @@ func (g GlobalCfg) ValidateRepoCfg(rCfg RepoCfg, repoID string) error {
 }
 
 // getMatchingCfg returns the key settings for repoID.
-func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (applyReqs []string, workflow Workflow, allowedOverrides []string, allowCustomWorkflows bool) {
+func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (applyReqs []ApplyRequirement, workflow Workflow, allowedOverrides []string, allowCustomWorkflows bool) {
 	toLog := make(map[string]string)
 	traceF := func(repoIdx int, repoID string, key string, val interface{}) string {
 		from := "default server config"
@@ -278,6 +441,8 @@ func (g GlobalCfg) getMatchingCfg(log logging.SimpleLogging, repoID string) (app
 			valStr = fmt.Sprintf("%q", v)
 		case []string:
 			valStr = fmt.Sprintf("[%s]", strings.Join(v, ","))
+		case []ApplyRequirement:
+			valStr = fmt.Sprintf("[%s]", applyReqsString(v))
 		case bool:
 			valStr = fmt.Sprintf("%t", v)
 		default:
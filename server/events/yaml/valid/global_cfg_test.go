@@ -39,7 +39,7 @@ func TestNewGlobalCfg(t *testing.T) {
 		Repos: []valid.Repo{
 			{
 				IDRegex:              regexp.MustCompile(".*"),
-				ApplyRequirements:    []string{},
+				ApplyRequirements:    []valid.ApplyRequirement{},
 				Workflow:             &expDefaultWorkflow,
 				AllowedOverrides:     []string{},
 				AllowCustomWorkflows: Bool(false),
@@ -102,10 +102,10 @@ func TestNewGlobalCfg(t *testing.T) {
 				exp.Repos[0].AllowedOverrides = []string{"apply_requirements", "workflow"}
 			}
 			if c.mergeableReq {
-				exp.Repos[0].ApplyRequirements = append(exp.Repos[0].ApplyRequirements, "mergeable")
+				exp.Repos[0].ApplyRequirements = append(exp.Repos[0].ApplyRequirements, valid.ApplyRequirement{Name: "mergeable"})
 			}
 			if c.approvedReq {
-				exp.Repos[0].ApplyRequirements = append(exp.Repos[0].ApplyRequirements, "approved")
+				exp.Repos[0].ApplyRequirements = append(exp.Repos[0].ApplyRequirements, valid.ApplyRequirement{Name: "approved"})
 			}
 			Equals(t, exp, act)
 
@@ -216,7 +216,7 @@ func TestGlobalCfg_ValidateRepoCfg(t *testing.T) {
 					{
 						Dir:               ".",
 						Workspace:         "default",
-						ApplyRequirements: []string{""},
+						ApplyRequirements: []valid.ApplyRequirement{{Name: ""}},
 					},
 				},
 			},
@@ -275,7 +275,7 @@ workflows:
 			},
 			repoWorkflows: nil,
 			exp: valid.MergedProjectCfg{
-				ApplyRequirements: []string{},
+				ApplyRequirements: []valid.ApplyRequirement{},
 				Workflow: valid.Workflow{
 					Name:  "custom",
 					Apply: valid.DefaultApplyStage,
@@ -304,11 +304,11 @@ repos:
 			proj: valid.Project{
 				Dir:               ".",
 				Workspace:         "default",
-				ApplyRequirements: []string{"mergeable"},
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
 			},
 			repoWorkflows: nil,
 			exp: valid.MergedProjectCfg{
-				ApplyRequirements: []string{"mergeable"},
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "mergeable"}},
 				Workflow: valid.Workflow{
 					Name:  "default",
 					Apply: valid.DefaultApplyStage,
@@ -338,7 +338,7 @@ repos:
 			},
 			repoWorkflows: nil,
 			exp: valid.MergedProjectCfg{
-				ApplyRequirements: []string{"approved", "mergeable"},
+				ApplyRequirements: []valid.ApplyRequirement{{Name: "approved"}, {Name: "mergeable"}},
 				Workflow: valid.Workflow{
 					Name:  "default",
 					Apply: valid.DefaultApplyStage,
@@ -364,7 +364,7 @@ repos:
 			},
 			repoWorkflows: nil,
 			exp: valid.MergedProjectCfg{
-				ApplyRequirements: []string{},
+				ApplyRequirements: []valid.ApplyRequirement{},
 				Workflow: valid.Workflow{
 					Name:  "default",
 					Apply: valid.DefaultApplyStage,
@@ -397,6 +397,150 @@ repos:
 	}
 }
 
+func TestGlobalCfg_MatchingPermission(t *testing.T) {
+	global := valid.NewGlobalCfg(false, false, false)
+	global.Repos = append(global.Repos,
+		valid.Repo{
+			IDRegex: regexp.MustCompile("github.com/owner/.*"),
+			Permissions: map[string]valid.Permission{
+				"apply":  {Teams: []string{"infra"}},
+				"unlock": {Teams: []string{"infra"}},
+			},
+		},
+		valid.Repo{
+			ID: "github.com/owner/repo",
+			Permissions: map[string]valid.Permission{
+				"apply": {Users: []string{"alice"}},
+			},
+		},
+	)
+
+	// Commands without permissions aren't restricted.
+	_, _, ok := global.MatchingPermission("github.com/owner/repo", "plan")
+	Equals(t, false, ok)
+	_, _, ok = global.MatchingPermission("github.com/other/repo", "apply")
+	Equals(t, false, ok)
+
+	// The last matching repo config wins.
+	perm, rule, ok := global.MatchingPermission("github.com/owner/repo", "apply")
+	Equals(t, true, ok)
+	Equals(t, valid.Permission{Users: []string{"alice"}}, perm)
+	Equals(t, "permissions.apply of repo config with id github.com/owner/repo", rule)
+
+	// But only if it restricts the command.
+	perm, rule, ok = global.MatchingPermission("github.com/owner/repo", "unlock")
+	Equals(t, true, ok)
+	Equals(t, valid.Permission{Teams: []string{"infra"}}, perm)
+	Equals(t, "permissions.unlock of repo config with id /github.com/owner/.*/", rule)
+}
+
+func TestGlobalCfg_MergeProjectCfg_AutomergeOptions(t *testing.T) {
+	global := valid.NewGlobalCfg(false, false, false)
+	global.Repos = append(global.Repos,
+		valid.Repo{
+			IDRegex: regexp.MustCompile(".*"),
+			AutomergeOptions: valid.AutomergeOptions{
+				MergeMethod:        String("squash"),
+				DeleteSourceBranch: Bool(true),
+			},
+		},
+		valid.Repo{
+			ID: "github.com/owner/repo",
+			AutomergeOptions: valid.AutomergeOptions{
+				DeleteSourceBranch: Bool(false),
+			},
+		},
+	)
+	logger := logging.NewNoopLogger()
+
+	// Server-side options are merged field by field.
+	merged := global.DefaultProjCfg(logger, "github.com/owner/repo", ".", "default")
+	Equals(t, valid.AutomergeOptions{
+		MergeMethod:        String("squash"),
+		DeleteSourceBranch: Bool(false),
+	}, merged.AutomergeOptions)
+
+	// The repo's options override the server-side ones.
+	rCfg := valid.RepoCfg{
+		AutomergeOptions: valid.AutomergeOptions{
+			MergeMethod:   String("merge"),
+			CommitMessage: String("Merge #{{ .Num }}"),
+		},
+	}
+	merged = global.MergeProjectCfg(logger, "github.com/owner/repo", valid.Project{Dir: ".", Workspace: "default"}, rCfg)
+	Equals(t, valid.AutomergeOptions{
+		MergeMethod:        String("merge"),
+		DeleteSourceBranch: Bool(false),
+		CommitMessage:      String("Merge #{{ .Num }}"),
+	}, merged.AutomergeOptions)
+}
+
+func TestGlobalCfg_ApplyAfterMerge(t *testing.T) {
+	global := valid.NewGlobalCfg(false, false, false)
+	global.Repos = append(global.Repos,
+		valid.Repo{
+			IDRegex:         regexp.MustCompile("github.com/owner/.*"),
+			ApplyAfterMerge: Bool(true),
+		},
+		valid.Repo{
+			ID:              "github.com/owner/disabled",
+			ApplyAfterMerge: Bool(false),
+		},
+		valid.Repo{
+			ID: "github.com/owner/unset",
+		},
+	)
+
+	Equals(t, false, global.ApplyAfterMerge("github.com/other/repo"))
+	Equals(t, true, global.ApplyAfterMerge("github.com/owner/repo"))
+	// The last matching repo config that sets it wins.
+	Equals(t, false, global.ApplyAfterMerge("github.com/owner/disabled"))
+	Equals(t, true, global.ApplyAfterMerge("github.com/owner/unset"))
+}
+
+func TestGlobalCfg_MatchingLabelBehavior(t *testing.T) {
+	global := valid.NewGlobalCfg(false, false, false)
+	global.Repos = append(global.Repos,
+		valid.Repo{
+			IDRegex: regexp.MustCompile("github.com/owner/.*"),
+			Labels: map[string]valid.LabelBehavior{
+				"atlantis/skip":     {SkipAutoplan: true},
+				"atlantis/plan-all": {PlanAll: true},
+				"needs-sre": {
+					ApplyRequirements: []valid.ApplyRequirement{
+						{Name: "approved", ApprovalCount: 1, ApprovalsFrom: []string{"sre"}},
+					},
+				},
+			},
+		},
+		valid.Repo{
+			ID: "github.com/owner/override",
+			Labels: map[string]valid.LabelBehavior{
+				"atlantis/skip": {},
+			},
+		},
+	)
+
+	// Repos without label config and pulls without matching labels get the
+	// default behavior.
+	Equals(t, valid.LabelBehavior{}, global.MatchingLabelBehavior("github.com/other/repo", []string{"atlantis/skip"}))
+	Equals(t, valid.LabelBehavior{}, global.MatchingLabelBehavior("github.com/owner/repo", nil))
+	Equals(t, valid.LabelBehavior{}, global.MatchingLabelBehavior("github.com/owner/repo", []string{"bug"}))
+
+	// The behaviors of all the labels are combined.
+	Equals(t, valid.LabelBehavior{
+		SkipAutoplan: true,
+		PlanAll:      true,
+		ApplyRequirements: []valid.ApplyRequirement{
+			{Name: "approved", ApprovalCount: 1, ApprovalsFrom: []string{"sre"}},
+		},
+	}, global.MatchingLabelBehavior("github.com/owner/repo", []string{"atlantis/skip", "needs-sre", "atlantis/plan-all"}))
+
+	// The last matching repo config that defines a label wins.
+	Equals(t, valid.LabelBehavior{}, global.MatchingLabelBehavior("github.com/owner/override", []string{"atlantis/skip"}))
+	Equals(t, valid.LabelBehavior{PlanAll: true}, global.MatchingLabelBehavior("github.com/owner/override", []string{"atlantis/skip", "atlantis/plan-all"}))
+}
+
 func TestRepo_IDMatches(t *testing.T) {
 	// Test exact matches.
 	Equals(t, false, (valid.Repo{ID: "github.com/owner/repo"}).IDMatches("github.com/runatlantis/atlantis"))
@@ -11,6 +11,9 @@ type RepoCfg struct {
 	Projects  []Project
 	Workflows map[string]Workflow
 	Automerge bool
+	// AutomergeOptions configure how the pull request is merged when
+	// automerge is enabled.
+	AutomergeOptions AutomergeOptions
 }
 
 func (r RepoCfg) FindProjectsByDirWorkspace(repoRelDir string, workspace string) []Project {
@@ -50,7 +53,7 @@ type Project struct {
 	WorkflowName      *string
 	TerraformVersion  *version.Version
 	Autoplan          Autoplan
-	ApplyRequirements []string
+	ApplyRequirements []ApplyRequirement
 }
 
 // GetName returns the name of the project or an empty string if there is no
@@ -62,6 +65,34 @@ func (p Project) GetName() string {
 	return ""
 }
 
+// AutomergeOptions configure how pull requests are merged when automerge is
+// enabled. Fields that are nil haven't been set.
+type AutomergeOptions struct {
+	// MergeMethod is one of merge, squash or rebase. If it's not set, the
+	// VCS host's default is used.
+	MergeMethod *string
+	// DeleteSourceBranch is true if the pull request's branch should be
+	// deleted after it's merged.
+	DeleteSourceBranch *bool
+	// CommitMessage is a text/template for the merge commit message. It's
+	// executed with the pull request, ex. {{ .Num }} or {{ .HeadBranch }}.
+	CommitMessage *string
+}
+
+// Merge returns o with the fields that are set in other overriding it.
+func (o AutomergeOptions) Merge(other AutomergeOptions) AutomergeOptions {
+	if other.MergeMethod != nil {
+		o.MergeMethod = other.MergeMethod
+	}
+	if other.DeleteSourceBranch != nil {
+		o.DeleteSourceBranch = other.DeleteSourceBranch
+	}
+	if other.CommitMessage != nil {
+		o.CommitMessage = other.CommitMessage
+	}
+	return o
+}
+
 type Autoplan struct {
 	WhenModified []string
 	Enabled      bool
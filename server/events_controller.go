@@ -18,6 +18,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v28/github"
 	"github.com/mcdafydd/go-azuredevops/azuredevops"
@@ -28,6 +30,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	gitlab "github.com/xanzy/go-gitlab"
 )
@@ -42,6 +46,11 @@ const bitbucketCloudRequestIDHeader = "X-Request-UUID"
 const bitbucketServerRequestIDHeader = "X-Request-ID"
 const bitbucketServerSignatureHeader = "X-Hub-Signature"
 
+// Forgejo sends the same headers as Gitea.
+const giteaHeader = "X-Gitea-Event"
+const giteaRequestIDHeader = "X-Gitea-Delivery"
+const giteaSignatureHeader = "X-Gitea-Signature"
+
 // EventsController handles all webhook requests which signify 'events' in the
 // VCS host, ex. GitHub.
 type EventsController struct {
@@ -83,11 +92,36 @@ type EventsController struct {
 	// Azure DevOps Team Project. If empty, no request validation is done.
 	AzureDevopsWebhookBasicPassword []byte
 	AzureDevopsRequestValidator     AzureDevopsRequestValidator
+	// GiteaWebhookSecret is the secret added to this webhook via the Gitea
+	// UI that identifies this call as coming from Gitea. If empty, no
+	// request validation is done.
+	GiteaWebhookSecret []byte
+	// ReplanOnBaseBranchPush is true if pushes to a branch should re-plan the
+	// open pull requests into it. It's only useful with the merge checkout
+	// strategy since otherwise plans don't include the base branch.
+	ReplanOnBaseBranchPush bool
+	// GlobalCfg is the server-side repo config. It's used to check which
+	// label changes should trigger autoplan.
+	GlobalCfg valid.GlobalCfg
+	// OpenedPulls records the labels of recently opened pull requests. VCS
+	// hosts send a label event for each label a pull request is opened with
+	// and those shouldn't autoplan again. If nil, every label change that
+	// affects autoplan autoplans.
+	OpenedPulls *OpenedPulls
 }
 
 // Post handles POST webhook requests.
 func (e *EventsController) Post(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get(githubHeader) != "" {
+	// Gitea also sends GitHub's headers so it has to be checked first.
+	if r.Header.Get(giteaHeader) != "" {
+		if !e.supportsHost(models.Gitea) {
+			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+			return
+		}
+		e.Logger.Debug("handling Gitea post")
+		e.handleGiteaPost(w, r)
+		return
+	} else if r.Header.Get(githubHeader) != "" {
 		if !e.supportsHost(models.Github) {
 			e.respond(w, logging.Debug, http.StatusBadRequest, "Ignoring request since not configured to support GitHub")
 			return
@@ -153,6 +187,12 @@ func (e *EventsController) handleGithubPost(w http.ResponseWriter, r *http.Reque
 	case *github.PullRequestEvent:
 		e.Logger.Debug("handling as pull request event")
 		e.HandleGithubPullRequestEvent(w, event, githubReqID)
+	case *github.PushEvent:
+		e.Logger.Debug("handling as push event")
+		e.HandleGithubPushEvent(w, event, githubReqID)
+	case *github.CheckRunEvent:
+		e.Logger.Debug("handling as check run event")
+		e.HandleGithubCheckRunEvent(w, event, githubReqID)
 	default:
 		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event %s", githubReqID)
 	}
@@ -244,6 +284,40 @@ func (e *EventsController) handleAzureDevopsPost(w http.ResponseWriter, r *http.
 	}
 }
 
+func (e *EventsController) handleGiteaPost(w http.ResponseWriter, r *http.Request) {
+	eventType := r.Header.Get(giteaHeader)
+	reqID := giteaRequestIDHeader + "=" + r.Header.Get(giteaRequestIDHeader)
+	defer r.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Unable to read body: %s %s", err, reqID)
+		return
+	}
+	if len(e.GiteaWebhookSecret) > 0 {
+		if err := gitea.ValidateSignature(body, r.Header.Get(giteaSignatureHeader), e.GiteaWebhookSecret); err != nil {
+			e.respond(w, logging.Warn, http.StatusBadRequest, errors.Wrap(err, "request did not pass validation").Error())
+			return
+		}
+	}
+	e.Logger.Debug("request valid")
+
+	event, err := gitea.ParseWebHook(eventType, body)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing webhook: %v %s", err, reqID)
+		return
+	}
+	switch event := event.(type) {
+	case *gitea.IssueCommentEvent:
+		e.Logger.Debug("handling as comment event")
+		e.HandleGiteaCommentEvent(w, event, reqID)
+	case *gitea.PullRequestEvent:
+		e.Logger.Debug("handling as pull request event")
+		e.HandleGiteaPullRequestEvent(w, event, reqID)
+	default:
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring unsupported event type %s %s", eventType, reqID)
+	}
+}
+
 // HandleGithubCommentEvent handles comment events from GitHub where Atlantis
 // commands can come from. It's exported to make testing easier.
 func (e *EventsController) HandleGithubCommentEvent(w http.ResponseWriter, event *github.IssueCommentEvent, githubReqID string) {
@@ -263,6 +337,58 @@ func (e *EventsController) HandleGithubCommentEvent(w http.ResponseWriter, event
 	e.handleCommentEvent(w, baseRepo, nil, nil, user, pullNum, event.Comment.GetBody(), models.Github)
 }
 
+// HandleGithubCheckRunEvent plans the project again when a user re-runs one
+// of Atlantis's check runs. It's exported to make testing easier.
+func (e *EventsController) HandleGithubCheckRunEvent(w http.ResponseWriter, event *github.CheckRunEvent, githubReqID string) {
+	if event.GetAction() != "rerequested" {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring check run event since action was not rerequested %s", githubReqID)
+		return
+	}
+
+	baseRepo, user, pullNum, cmd, err := e.Parser.ParseGithubCheckRunEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing event: %v %s", err, githubReqID)
+		return
+	}
+	e.Logger.Info("parsed check run as %s", cmd)
+	e.runCommentCommand(w, baseRepo, nil, nil, user, pullNum, events.CommentParseResult{Command: cmd})
+}
+
+// HandleGiteaCommentEvent handles comment events from Gitea where Atlantis
+// commands can come from. It's exported to make testing easier.
+func (e *EventsController) HandleGiteaCommentEvent(w http.ResponseWriter, event *gitea.IssueCommentEvent, reqID string) {
+	if event.Action != "created" {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since action was not created %s", reqID)
+		return
+	}
+	if !event.IsPull {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment event since it was on an issue %s", reqID)
+		return
+	}
+
+	baseRepo, user, pullNum, err := e.Parser.ParseGiteaIssueCommentEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Failed parsing event: %v %s", err, reqID)
+		return
+	}
+
+	// We pass in nil for maybeHeadRepo because the head repo data isn't
+	// available in Gitea's comment events.
+	e.handleCommentEvent(w, baseRepo, nil, nil, user, pullNum, *event.Comment.Body, models.Gitea)
+}
+
+// HandleGiteaPullRequestEvent handles pull request events from Gitea. It's
+// exported to make testing easier.
+func (e *EventsController) HandleGiteaPullRequestEvent(w http.ResponseWriter, event *gitea.PullRequestEvent, reqID string) {
+	pull, pullEventType, baseRepo, headRepo, user, err := e.Parser.ParseGiteaPullEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing pull data: %s %s", err, reqID)
+		return
+	}
+	e.Logger.Info("identified event as type %q", pullEventType.String())
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, nil)
+}
+
 // HandleBitbucketCloudCommentEvent handles comment events from Bitbucket.
 func (e *EventsController) HandleBitbucketCloudCommentEvent(w http.ResponseWriter, body []byte, reqID string) {
 	pull, baseRepo, headRepo, user, comment, err := e.Parser.ParseBitbucketCloudPullCommentEvent(body)
@@ -291,7 +417,7 @@ func (e *EventsController) handleBitbucketCloudPullRequestEvent(w http.ResponseW
 	}
 	pullEventType := e.Parser.GetBitbucketCloudPullEventType(eventType)
 	e.Logger.Info("identified event as type %q", pullEventType.String())
-	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, nil)
 }
 
 func (e *EventsController) handleBitbucketServerPullRequestEvent(w http.ResponseWriter, eventType string, body []byte, reqID string) {
@@ -302,7 +428,7 @@ func (e *EventsController) handleBitbucketServerPullRequestEvent(w http.Response
 	}
 	pullEventType := e.Parser.GetBitbucketServerPullEventType(eventType)
 	e.Logger.Info("identified event as type %q", pullEventType.String())
-	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, nil)
 }
 
 // HandleGithubPullRequestEvent will delete any locks associated with the pull
@@ -315,10 +441,16 @@ func (e *EventsController) HandleGithubPullRequestEvent(w http.ResponseWriter, p
 		return
 	}
 	e.Logger.Info("identified event as type %q", pullEventType.String())
-	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+	var changedLabels []string
+	if pullEventType == models.LabeledPullEvent {
+		changedLabels = []string{pullEvent.GetLabel().GetName()}
+	}
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, changedLabels)
 }
 
-func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType) {
+// handlePullRequestEvent handles a pull request event. changedLabels are the
+// labels that were added or removed if eventType is LabeledPullEvent.
+func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRepo models.Repo, headRepo models.Repo, pull models.PullRequest, user models.User, eventType models.PullRequestEventType, changedLabels []string) {
 	if !e.RepoWhitelistChecker.IsWhitelisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
 		// If the repo isn't whitelisted and we receive an opened pull request
 		// event we comment back on the pull request that the repo isn't
@@ -336,6 +468,9 @@ func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRep
 	switch eventType {
 	case models.OpenedPullEvent, models.UpdatedPullEvent:
 		// If the pull request was opened or updated, we will try to autoplan.
+		if eventType == models.OpenedPullEvent && e.OpenedPulls != nil {
+			e.OpenedPulls.Opened(baseRepo, pull)
+		}
 
 		// Respond with success and then actually execute the command asynchronously.
 		// We use a goroutine so that this function returns and the connection is
@@ -351,6 +486,21 @@ func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRep
 		}
 		return
 	case models.ClosedPullEvent:
+		// If the pull request was merged, repos configured to apply after
+		// merge are applied on the base branch. That cleans up the pull
+		// request once it's done so we don't clean it up here too.
+		if pull.State == models.MergedPullState && e.GlobalCfg.ApplyAfterMerge(baseRepo.ID()) {
+			e.Logger.Info("applying merged pull request")
+			if !e.TestingMode {
+				go e.CommandRunner.RunApplyAfterMergeCommand(baseRepo, pull, user)
+			} else {
+				// When testing we want to wait for everything to complete.
+				e.CommandRunner.RunApplyAfterMergeCommand(baseRepo, pull, user)
+			}
+			fmt.Fprintln(w, "Applying merged pull request")
+			return
+		}
+
 		// If the pull request was closed, we delete locks.
 		if err := e.PullCleaner.CleanUpPull(baseRepo, pull); err != nil {
 			e.respond(w, logging.Error, http.StatusInternalServerError, "Error cleaning pull request: %s", err)
@@ -359,6 +509,29 @@ func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRep
 		e.Logger.Info("deleted locks and workspace for repo %s, pull %d", baseRepo.FullName, pull.Num)
 		fmt.Fprintln(w, "Pull request cleaned successfully")
 		return
+	case models.LabeledPullEvent:
+		// Adding or removing a label that changes what autoplan does, ex.
+		// removing a label that skips autoplan, means we should autoplan again.
+		// Other label changes are ignored.
+		behavior := e.GlobalCfg.MatchingLabelBehavior(baseRepo.ID(), changedLabels)
+		if !behavior.SkipAutoplan && !behavior.PlanAll {
+			e.respond(w, logging.Debug, http.StatusOK, "Ignoring pull request label change that doesn't affect autoplan")
+			return
+		}
+		if e.OpenedPulls != nil && e.OpenedPulls.OpenedWith(baseRepo, pull, changedLabels) {
+			e.respond(w, logging.Debug, http.StatusOK, "Ignoring pull request labels that were added when it was opened since autoplan already used them")
+			return
+		}
+		fmt.Fprintln(w, "Processing...")
+
+		e.Logger.Info("executing autoplan since labels %v changed", changedLabels)
+		if !e.TestingMode {
+			go e.CommandRunner.RunAutoplanCommand(baseRepo, headRepo, pull, user)
+		} else {
+			// When testing we want to wait for everything to complete.
+			e.CommandRunner.RunAutoplanCommand(baseRepo, headRepo, pull, user)
+		}
+		return
 	case models.OtherPullEvent:
 		// Else we ignore the event.
 		e.respond(w, logging.Debug, http.StatusOK, "Ignoring non-actionable pull request event")
@@ -366,6 +539,43 @@ func (e *EventsController) handlePullRequestEvent(w http.ResponseWriter, baseRep
 	}
 }
 
+// HandleGithubPushEvent re-plans pull requests into the branch that was
+// pushed to. It's exported to make testing easier.
+func (e *EventsController) HandleGithubPushEvent(w http.ResponseWriter, event *github.PushEvent, githubReqID string) {
+	repo, branch, err := e.Parser.ParseGithubPushEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing push data: %s %s", err, githubReqID)
+		return
+	}
+	e.handlePushEvent(w, repo, branch)
+}
+
+func (e *EventsController) handlePushEvent(w http.ResponseWriter, repo models.Repo, branch string) {
+	if !e.ReplanOnBaseBranchPush {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring push event since re-planning is only done with the merge checkout strategy")
+		return
+	}
+	if branch == "" {
+		e.respond(w, logging.Debug, http.StatusOK, "Ignoring push event that isn't to a branch")
+		return
+	}
+	if !e.RepoWhitelistChecker.IsWhitelisted(repo.FullName, repo.VCSHost.Hostname) {
+		e.respond(w, logging.Debug, http.StatusForbidden,
+			"Ignoring push event from non-whitelisted repo \"%s/%s\"",
+			repo.VCSHost.Hostname, repo.FullName)
+		return
+	}
+
+	fmt.Fprintln(w, "Processing...")
+	e.Logger.Info("re-planning pull requests into %s", branch)
+	if !e.TestingMode {
+		go e.CommandRunner.RunBaseBranchPushCommand(repo, branch)
+	} else {
+		// When testing we want to wait for everything to complete.
+		e.CommandRunner.RunBaseBranchPushCommand(repo, branch)
+	}
+}
+
 func (e *EventsController) handleGitlabPost(w http.ResponseWriter, r *http.Request) {
 	event, err := e.GitlabRequestParserValidator.ParseAndValidate(r, e.GitlabWebhookSecret)
 	if err != nil {
@@ -381,6 +591,9 @@ func (e *EventsController) handleGitlabPost(w http.ResponseWriter, r *http.Reque
 	case gitlab.MergeEvent:
 		e.Logger.Debug("handling as pull request event")
 		e.HandleGitlabMergeRequestEvent(w, event)
+	case gitlab.PushEvent:
+		e.Logger.Debug("handling as push event")
+		e.HandleGitlabPushEvent(w, event)
 	case gitlab.CommitCommentEvent:
 		e.Logger.Debug("comments on commits are not supported, only comments on merge requests")
 		e.respond(w, logging.Debug, http.StatusOK, "Ignoring comment on commit event")
@@ -414,7 +627,12 @@ func (e *EventsController) handleCommentEvent(w http.ResponseWriter, baseRepo mo
 		return
 	}
 	e.Logger.Info("parsed comment as %s", parseResult.Command)
+	e.runCommentCommand(w, baseRepo, maybeHeadRepo, maybePull, user, pullNum, parseResult)
+}
 
+// runCommentCommand runs the command in parseResult if the repo is
+// whitelisted, or comments back with parseResult's response.
+func (e *EventsController) runCommentCommand(w http.ResponseWriter, baseRepo models.Repo, maybeHeadRepo *models.Repo, maybePull *models.PullRequest, user models.User, pullNum int, parseResult events.CommentParseResult) {
 	// At this point we know it's a command we're not supposed to ignore, so now
 	// we check if this repo is allowed to run commands in the first place.
 	if !e.RepoWhitelistChecker.IsWhitelisted(baseRepo.FullName, baseRepo.VCSHost.Hostname) {
@@ -458,7 +676,22 @@ func (e *EventsController) HandleGitlabMergeRequestEvent(w http.ResponseWriter,
 		return
 	}
 	e.Logger.Info("identified event as type %q", pullEventType.String())
-	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+	var changedLabels []string
+	if pullEventType == models.LabeledPullEvent {
+		changedLabels = gitlabChangedLabels(event)
+	}
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, changedLabels)
+}
+
+// HandleGitlabPushEvent re-plans merge requests into the branch that was
+// pushed to. It's exported to make testing easier.
+func (e *EventsController) HandleGitlabPushEvent(w http.ResponseWriter, event gitlab.PushEvent) {
+	repo, branch, err := e.Parser.ParseGitlabPushEvent(event)
+	if err != nil {
+		e.respond(w, logging.Error, http.StatusBadRequest, "Error parsing webhook: %s", err)
+		return
+	}
+	e.handlePushEvent(w, repo, branch)
 }
 
 // HandleAzureDevopsPullRequestCommentedEvent handles comment events from Azure DevOps where Atlantis
@@ -520,7 +753,33 @@ func (e *EventsController) HandleAzureDevopsPullRequestEvent(w http.ResponseWrit
 		return
 	}
 	e.Logger.Info("identified event as type %q", pullEventType.String())
-	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType)
+	e.handlePullRequestEvent(w, baseRepo, headRepo, pull, user, pullEventType, nil)
+}
+
+// gitlabChangedLabels returns the names of the labels that were added to or
+// removed from the merge request in event.
+func gitlabChangedLabels(event gitlab.MergeEvent) []string {
+	previous := make(map[string]bool)
+	for _, label := range event.Changes.Labels.Previous {
+		previous[label.Name] = true
+	}
+	current := make(map[string]bool)
+	for _, label := range event.Changes.Labels.Current {
+		current[label.Name] = true
+	}
+
+	var changed []string
+	for _, label := range event.Changes.Labels.Current {
+		if !previous[label.Name] {
+			changed = append(changed, label.Name)
+		}
+	}
+	for _, label := range event.Changes.Labels.Previous {
+		if !current[label.Name] {
+			changed = append(changed, label.Name)
+		}
+	}
+	return changed
 }
 
 // supportsHost returns true if h is in e.SupportedVCSHosts and false otherwise.
@@ -552,3 +811,68 @@ func (e *EventsController) commentNotWhitelisted(baseRepo models.Repo, pullNum i
 		e.Logger.Err("unable to comment on pull request: %s", err)
 	}
 }
+
+// openedPullsWindow is how long after a pull request is opened that label
+// events for the labels it was opened with are ignored.
+const openedPullsWindow = time.Minute
+
+// OpenedPulls records the labels that pull requests were opened with.
+type OpenedPulls struct {
+	mu    sync.Mutex
+	pulls map[string]openedPull
+}
+
+// openedPull is a pull request that was recently opened.
+type openedPull struct {
+	at     time.Time
+	labels []string
+}
+
+// NewOpenedPulls returns an empty OpenedPulls.
+func NewOpenedPulls() *OpenedPulls {
+	return &OpenedPulls{pulls: make(map[string]openedPull)}
+}
+
+// Opened records that pull was opened with its current labels.
+func (o *OpenedPulls) Opened(repo models.Repo, pull models.PullRequest) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	for key, p := range o.pulls {
+		if now.Sub(p.at) > openedPullsWindow {
+			delete(o.pulls, key)
+		}
+	}
+	o.pulls[openedPullKey(repo, pull)] = openedPull{at: now, labels: pull.Labels}
+}
+
+// OpenedWith returns true if pull was opened recently with all of labels
+// and still has them, i.e. the label event is for labels added when the pull
+// request was opened. Removing one of the labels isn't ignored.
+func (o *OpenedPulls) OpenedWith(repo models.Repo, pull models.PullRequest, labels []string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	opened, ok := o.pulls[openedPullKey(repo, pull)]
+	if !ok || time.Since(opened.at) > openedPullsWindow || len(labels) == 0 {
+		return false
+	}
+	for _, label := range labels {
+		if !containsString(opened.labels, label) || !containsString(pull.Labels, label) {
+			return false
+		}
+	}
+	return true
+}
+
+func openedPullKey(repo models.Repo, pull models.PullRequest) string {
+	return fmt.Sprintf("%s#%d", repo.ID(), pull.Num)
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -369,9 +369,9 @@ func TestGitHubWorkflow(t *testing.T) {
 
 			if c.ExpAutomerge {
 				// Verify that the merge API call was made.
-				vcsClient.VerifyWasCalledOnce().MergePull(matchers.AnyModelsPullRequest())
+				vcsClient.VerifyWasCalledOnce().MergePull(matchers.AnyModelsPullRequest(), matchers.AnyModelsPullRequestOptions())
 			} else {
-				vcsClient.VerifyWasCalled(Never()).MergePull(matchers.AnyModelsPullRequest())
+				vcsClient.VerifyWasCalled(Never()).MergePull(matchers.AnyModelsPullRequest(), matchers.AnyModelsPullRequestOptions())
 			}
 		})
 	}
@@ -444,7 +444,6 @@ func setupE2E(t *testing.T, repoDir string) (server.EventsController, *vcsmocks.
 			},
 			PullApprovedChecker: e2eVCSClient,
 			WorkingDir:          workingDir,
-			Webhooks:            &mockWebhookSender{},
 			WorkingDirLocker:    locker,
 		},
 		EventParser:              eventParser,
@@ -454,6 +453,7 @@ func setupE2E(t *testing.T, repoDir string) (server.EventsController, *vcsmocks.
 		CommitStatusUpdater:      e2eStatusUpdater,
 		MarkdownRenderer:         &events.MarkdownRenderer{},
 		Logger:                   logger,
+		Webhooks:                 &mockWebhookSender{},
 		AllowForkPRs:             allowForkPRs,
 		AllowForkPRsFlag:         "allow-fork-prs",
 		ProjectCommandBuilder: &events.DefaultProjectCommandBuilder{
@@ -470,6 +470,7 @@ func setupE2E(t *testing.T, repoDir string) (server.EventsController, *vcsmocks.
 		PendingPlanFinder: &events.DefaultPendingPlanFinder{},
 		GlobalAutomerge:   false,
 		WorkingDir:        workingDir,
+		WorkingDirLocker:  locker,
 	}
 
 	repoWhitelistChecker, err := events.NewRepoWhitelistChecker("*")
@@ -506,7 +507,7 @@ func (m *mockLockURLGenerator) GenerateLockURL(lockID string) string {
 
 type mockWebhookSender struct{}
 
-func (w *mockWebhookSender) Send(log *logging.SimpleLogger, result webhooks.ApplyResult) error {
+func (w *mockWebhookSender) Send(log *logging.SimpleLogger, event webhooks.Event) error {
 	return nil
 }
 
@@ -15,12 +15,16 @@ package server_test
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -31,6 +35,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/mocks"
 	. "github.com/runatlantis/atlantis/testing"
@@ -40,6 +45,8 @@ import (
 const githubHeader = "X-Github-Event"
 const gitlabHeader = "X-Gitlab-Event"
 const azuredevopsHeader = "Request-Id"
+const giteaHeader = "X-Gitea-Event"
+const giteaSignatureHeader = "X-Gitea-Signature"
 
 var secret = []byte("secret")
 
@@ -360,6 +367,118 @@ func TestPost_GithubCommentSuccess(t *testing.T) {
 	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
 }
 
+func TestPost_UnsupportedVCSGitea(t *testing.T) {
+	t.Log("when the request is for an unsupported vcs a 400 is returned")
+	e, _, _, _, _, _, _, _ := setup(t)
+	e.SupportedVCSHosts = nil
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(giteaHeader, "issue_comment")
+	// Gitea also sends GitHub's header.
+	req.Header.Set(githubHeader, "issue_comment")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Ignoring request since not configured to support Gitea")
+}
+
+func TestPost_InvalidGiteaSecret(t *testing.T) {
+	t.Log("when the gitea payload can't be validated a 400 is returned")
+	e, _, _, _, _, _, _, _ := setupGitea(t)
+	req := giteaRequest(t, "issue_comment", `{"action": "created"}`)
+	req.Header.Set(giteaSignatureHeader, "abcd")
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "request did not pass validation: payload signature check failed")
+}
+
+func TestPost_UnsupportedGiteaEvent(t *testing.T) {
+	t.Log("when the event type is an unsupported gitea event we ignore it")
+	e, _, _, _, _, _, _, _ := setupGitea(t)
+	req := giteaRequest(t, "push", `{"ref": "refs/heads/main"}`)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring unsupported event type push")
+}
+
+func TestPost_GiteaCommentNotCreated(t *testing.T) {
+	t.Log("when the event is a gitea comment but it's not a created event we ignore it")
+	e, _, _, p, _, _, _, _ := setupGitea(t)
+	req := giteaRequest(t, "issue_comment", `{"action": "edited", "is_pull": true}`)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring comment event since action was not created")
+	p.VerifyWasCalled(Never()).ParseGiteaIssueCommentEvent(matchers.AnyPtrToGiteaIssueCommentEvent())
+}
+
+func TestPost_GiteaCommentOnIssue(t *testing.T) {
+	t.Log("when the event is a gitea comment on an issue we ignore it")
+	e, _, _, p, _, _, _, _ := setupGitea(t)
+	req := giteaRequest(t, "issue_comment", `{"action": "created", "is_pull": false}`)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring comment event since it was on an issue")
+	p.VerifyWasCalled(Never()).ParseGiteaIssueCommentEvent(matchers.AnyPtrToGiteaIssueCommentEvent())
+}
+
+func TestPost_GiteaCommentSuccess(t *testing.T) {
+	t.Log("when the event is a gitea comment with a valid command we call the command handler")
+	e, _, _, p, cr, _, _, cp := setupGitea(t)
+	event, err := ioutil.ReadFile(filepath.Join("events", "testdata", "gitea-issue-comment-event.json"))
+	Ok(t, err)
+	req := giteaRequest(t, "issue_comment", string(event))
+	baseRepo := models.Repo{}
+	user := models.User{}
+	cmd := events.CommentCommand{}
+	When(p.ParseGiteaIssueCommentEvent(matchers.AnyPtrToGiteaIssueCommentEvent())).ThenReturn(baseRepo, user, 2, nil)
+	When(cp.Parse("atlantis plan", models.Gitea)).ThenReturn(events.CommentParseResult{Command: &cmd})
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 2, &cmd)
+}
+
+func TestPost_GiteaPullRequestInvalid(t *testing.T) {
+	t.Log("when the event is a gitea pull request with invalid data we return a 400")
+	e, _, _, p, _, _, _, _ := setupGitea(t)
+	req := giteaRequest(t, "pull_request", `{"action": "opened"}`)
+	When(p.ParseGiteaPullEvent(matchers.AnyPtrToGiteaPullRequestEvent())).ThenReturn(models.PullRequest{}, models.OpenedPullEvent, models.Repo{}, models.Repo{}, models.User{}, errors.New("err"))
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusBadRequest, "Error parsing pull data: err")
+}
+
+func TestPost_GithubCheckRunNotRerequested(t *testing.T) {
+	t.Log("when the event is a github check run that wasn't re-run we ignore it")
+	e, v, _, p, cr, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "check_run")
+	event := `{"action": "completed"}`
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Ignoring check run event since action was not rerequested")
+	p.VerifyWasCalled(Never()).ParseGithubCheckRunEvent(matchers.AnyPtrToGithubCheckRunEvent())
+	cr.VerifyWasCalled(Never()).RunCommentCommand(matchers.AnyModelsRepo(), matchers.AnyPtrToModelsRepo(), matchers.AnyPtrToModelsPullRequest(), matchers.AnyModelsUser(), AnyInt(), matchers.AnyPtrToEventsCommentCommand())
+}
+
+func TestPost_GithubCheckRunSuccess(t *testing.T) {
+	t.Log("when a check run is re-run we plan its project")
+	e, v, _, p, cr, _, _, _ := setup(t)
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req.Header.Set(githubHeader, "check_run")
+	event := `{"action": "rerequested"}`
+	When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+	baseRepo := models.Repo{}
+	user := models.User{Username: "user"}
+	cmd := events.CommentCommand{Name: models.PlanCommand, RepoRelDir: "dir", Workspace: "default"}
+	When(p.ParseGithubCheckRunEvent(matchers.AnyPtrToGithubCheckRunEvent())).ThenReturn(baseRepo, user, 1, &cmd, nil)
+	w := httptest.NewRecorder()
+	e.Post(w, req)
+	responseContains(t, w, http.StatusOK, "Processing...")
+
+	cr.VerifyWasCalledOnce().RunCommentCommand(baseRepo, nil, nil, user, 1, &cmd)
+}
+
 func TestPost_GithubPullRequestInvalid(t *testing.T) {
 	t.Log("when the event is a github pull request with invalid data we return a 400")
 	e, v, _, p, _, _, _, _ := setup(t)
@@ -736,6 +855,250 @@ func TestPost_PullOpenedOrUpdated(t *testing.T) {
 	}
 }
 
+func TestPost_PullLabeled(t *testing.T) {
+	cases := []struct {
+		Description string
+		HostType    models.VCSHostType
+		Label       string
+		ExpAutoplan bool
+	}{
+		{
+			"github label that skips autoplan",
+			models.Github,
+			"atlantis/skip",
+			true,
+		},
+		{
+			"gitlab label that plans all",
+			models.Gitlab,
+			"atlantis/plan-all",
+			true,
+		},
+		{
+			"github label that doesn't affect autoplan",
+			models.Github,
+			"needs-sre",
+			false,
+		},
+		{
+			"gitlab unconfigured label",
+			models.Gitlab,
+			"bug",
+			false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.Description, func(t *testing.T) {
+			e, v, gl, p, cr, _, _, _ := setup(t)
+			e.GlobalCfg = valid.NewGlobalCfg(false, false, false)
+			e.GlobalCfg.Repos = append(e.GlobalCfg.Repos, valid.Repo{
+				IDRegex: regexp.MustCompile(".*"),
+				Labels: map[string]valid.LabelBehavior{
+					"atlantis/skip":     {SkipAutoplan: true},
+					"atlantis/plan-all": {PlanAll: true},
+					"needs-sre": {
+						ApplyRequirements: []valid.ApplyRequirement{{Name: "approved", ApprovalCount: 1}},
+					},
+				},
+			})
+			repo := models.Repo{}
+			pull := models.PullRequest{Labels: []string{c.Label}}
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			switch c.HostType {
+			case models.Gitlab:
+				req.Header.Set(gitlabHeader, "value")
+				var event gitlab.MergeEvent
+				event.ObjectAttributes.Action = "update"
+				event.Changes.Labels.Current = []gitlab.Label{{Name: c.Label}}
+				When(gl.ParseAndValidate(req, secret)).ThenReturn(event, nil)
+				When(p.ParseGitlabMergeRequestEvent(event)).ThenReturn(pull, models.LabeledPullEvent, repo, repo, models.User{}, nil)
+			case models.Github:
+				req.Header.Set(githubHeader, "pull_request")
+				event := fmt.Sprintf(`{"action": "labeled", "label": {"name": %q}}`, c.Label)
+				When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+				When(p.ParseGithubPullEvent(matchers.AnyPtrToGithubPullRequestEvent())).ThenReturn(pull, models.LabeledPullEvent, repo, repo, models.User{}, nil)
+			}
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			if c.ExpAutoplan {
+				responseContains(t, w, http.StatusOK, "Processing...")
+				cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, pull, models.User{})
+			} else {
+				responseContains(t, w, http.StatusOK, "Ignoring pull request label change that doesn't affect autoplan")
+				cr.VerifyWasCalled(Never()).RunAutoplanCommand(matchers.AnyModelsRepo(), matchers.AnyModelsRepo(), matchers.AnyModelsPullRequest(), matchers.AnyModelsUser())
+			}
+		})
+	}
+}
+
+// GitHub sends a labeled event for each label a pull request is opened with.
+// Autoplan already used them so they shouldn't autoplan again.
+func TestPost_PullLabeledWhenOpened(t *testing.T) {
+	e, v, _, p, cr, _, _, _ := setup(t)
+	e.GlobalCfg = valid.NewGlobalCfg(false, false, false)
+	e.GlobalCfg.Repos = append(e.GlobalCfg.Repos, valid.Repo{
+		IDRegex: regexp.MustCompile(".*"),
+		Labels: map[string]valid.LabelBehavior{
+			"atlantis/skip": {SkipAutoplan: true},
+		},
+	})
+	repo := models.Repo{}
+	post := func(event string, pull models.PullRequest, eventType models.PullRequestEventType) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+		req.Header.Set(githubHeader, "pull_request")
+		When(v.Validate(req, secret)).ThenReturn([]byte(event), nil)
+		When(p.ParseGithubPullEvent(matchers.AnyPtrToGithubPullRequestEvent())).ThenReturn(pull, eventType, repo, repo, models.User{}, nil)
+		w := httptest.NewRecorder()
+		e.Post(w, req)
+		return w
+	}
+
+	labeled := models.PullRequest{Num: 1, Labels: []string{"atlantis/skip"}}
+	w := post(`{"action": "opened"}`, labeled, models.OpenedPullEvent)
+	responseContains(t, w, http.StatusOK, "Processing...")
+	w = post(`{"action": "labeled", "label": {"name": "atlantis/skip"}}`, labeled, models.LabeledPullEvent)
+	responseContains(t, w, http.StatusOK, "Ignoring pull request labels that were added when it was opened")
+	cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, labeled, models.User{})
+
+	// Removing the label should still autoplan.
+	unlabeled := models.PullRequest{Num: 1}
+	w = post(`{"action": "unlabeled", "label": {"name": "atlantis/skip"}}`, unlabeled, models.LabeledPullEvent)
+	responseContains(t, w, http.StatusOK, "Processing...")
+	cr.VerifyWasCalledOnce().RunAutoplanCommand(repo, repo, unlabeled, models.User{})
+}
+
+func TestPost_PullClosed(t *testing.T) {
+	cases := []struct {
+		description     string
+		state           models.PullRequestState
+		applyAfterMerge bool
+		expRunCalled    bool
+	}{
+		{
+			description:     "closed without merging",
+			state:           models.ClosedPullState,
+			applyAfterMerge: true,
+			expRunCalled:    false,
+		},
+		{
+			description:  "merged",
+			state:        models.MergedPullState,
+			expRunCalled: false,
+		},
+		{
+			description:     "merged with apply after merge",
+			state:           models.MergedPullState,
+			applyAfterMerge: true,
+			expRunCalled:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, v, _, p, cr, cleaner, _, _ := setup(t)
+			applyAfterMerge := c.applyAfterMerge
+			e.GlobalCfg = valid.NewGlobalCfg(false, false, false)
+			e.GlobalCfg.Repos = append(e.GlobalCfg.Repos, valid.Repo{
+				IDRegex:         regexp.MustCompile(".*"),
+				ApplyAfterMerge: &applyAfterMerge,
+			})
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			req.Header.Set(githubHeader, "pull_request")
+			When(v.Validate(req, secret)).ThenReturn([]byte(`{"action": "closed"}`), nil)
+			repo := models.Repo{}
+			pull := models.PullRequest{State: c.state}
+			When(p.ParseGithubPullEvent(matchers.AnyPtrToGithubPullRequestEvent())).ThenReturn(pull, models.ClosedPullEvent, repo, repo, models.User{}, nil)
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			if c.expRunCalled {
+				// Applying after merge cleans up the pull request so it
+				// shouldn't be cleaned up twice.
+				responseContains(t, w, http.StatusOK, "Applying merged pull request")
+				cr.VerifyWasCalledOnce().RunApplyAfterMergeCommand(repo, pull, models.User{})
+				cleaner.VerifyWasCalled(Never()).CleanUpPull(repo, pull)
+			} else {
+				responseContains(t, w, http.StatusOK, "Pull request cleaned successfully")
+				cr.VerifyWasCalled(Never()).RunApplyAfterMergeCommand(repo, pull, models.User{})
+				cleaner.VerifyWasCalledOnce().CleanUpPull(repo, pull)
+			}
+		})
+	}
+}
+
+func TestPost_PushEvent(t *testing.T) {
+	repo := models.Repo{FullName: "owner/repo", VCSHost: models.VCSHost{Hostname: "github.com", Type: models.Github}}
+	cases := []struct {
+		description  string
+		hostType     models.VCSHostType
+		replan       bool
+		branch       string
+		expResp      string
+		expRunCalled bool
+	}{
+		{
+			description:  "github push to branch",
+			hostType:     models.Github,
+			replan:       true,
+			branch:       "master",
+			expResp:      "Processing...",
+			expRunCalled: true,
+		},
+		{
+			description:  "gitlab push to branch",
+			hostType:     models.Gitlab,
+			replan:       true,
+			branch:       "master",
+			expResp:      "Processing...",
+			expRunCalled: true,
+		},
+		{
+			description:  "not using merge checkout strategy",
+			hostType:     models.Github,
+			replan:       false,
+			branch:       "master",
+			expResp:      "Ignoring push event since re-planning is only done with the merge checkout strategy",
+			expRunCalled: false,
+		},
+		{
+			description:  "push to tag",
+			hostType:     models.Gitlab,
+			replan:       true,
+			branch:       "",
+			expResp:      "Ignoring push event that isn't to a branch",
+			expRunCalled: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			e, v, gl, p, cr, _, _, _ := setup(t)
+			e.ReplanOnBaseBranchPush = c.replan
+			req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+			switch c.hostType {
+			case models.Gitlab:
+				req.Header.Set(gitlabHeader, "Push Hook")
+				var event gitlab.PushEvent
+				event.Ref = "refs/heads/master"
+				When(gl.ParseAndValidate(req, secret)).ThenReturn(event, nil)
+				When(p.ParseGitlabPushEvent(event)).ThenReturn(repo, c.branch, nil)
+			case models.Github:
+				req.Header.Set(githubHeader, "push")
+				When(v.Validate(req, secret)).ThenReturn([]byte(`{"ref": "refs/heads/master"}`), nil)
+				When(p.ParseGithubPushEvent(matchers.AnyPtrToGithubPushEvent())).ThenReturn(repo, c.branch, nil)
+			}
+			w := httptest.NewRecorder()
+			e.Post(w, req)
+			responseContains(t, w, http.StatusOK, c.expResp)
+			if c.expRunCalled {
+				cr.VerifyWasCalledOnce().RunBaseBranchPushCommand(repo, c.branch)
+			} else {
+				cr.VerifyWasCalled(Never()).RunBaseBranchPushCommand(matchers.AnyModelsRepo(), AnyString())
+			}
+		})
+	}
+}
+
 func setup(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValidator, *mocks.MockGitlabRequestParserValidator, *emocks.MockEventParsing, *emocks.MockCommandRunner, *emocks.MockPullCleaner, *vcsmocks.MockClient, *emocks.MockCommentParsing) {
 	RegisterMockTestingT(t)
 	v := mocks.NewMockGithubRequestValidator()
@@ -761,6 +1124,30 @@ func setup(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValid
 		GitlabRequestParserValidator: gl,
 		RepoWhitelistChecker:         repoWhitelistChecker,
 		VCSClient:                    vcsmock,
+		OpenedPulls:                  server.NewOpenedPulls(),
 	}
 	return e, v, gl, p, cr, c, vcsmock, cp
 }
+
+// setupGitea is like setup but also supports Gitea webhooks signed with
+// secret.
+func setupGitea(t *testing.T) (server.EventsController, *mocks.MockGithubRequestValidator, *mocks.MockGitlabRequestParserValidator, *emocks.MockEventParsing, *emocks.MockCommandRunner, *emocks.MockPullCleaner, *vcsmocks.MockClient, *emocks.MockCommentParsing) {
+	e, v, gl, p, cr, c, vcsmock, cp := setup(t)
+	e.SupportedVCSHosts = append(e.SupportedVCSHosts, models.Gitea)
+	e.GiteaWebhookSecret = secret
+	return e, v, gl, p, cr, c, vcsmock, cp
+}
+
+// giteaRequest builds a Gitea webhook request for body that's signed with
+// secret.
+func giteaRequest(t *testing.T, eventType string, body string) *http.Request {
+	req, err := http.NewRequest("POST", "/events", bytes.NewBufferString(body))
+	Ok(t, err)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(body)) // nolint: errcheck
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(giteaHeader, eventType)
+	req.Header.Set(githubHeader, eventType)
+	req.Header.Set(giteaSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
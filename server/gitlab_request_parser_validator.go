@@ -58,6 +58,7 @@ type DefaultGitlabRequestParserValidator struct{}
 func (d *DefaultGitlabRequestParserValidator) ParseAndValidate(r *http.Request, secret []byte) (interface{}, error) {
 	const mergeEventHeader = "Merge Request Hook"
 	const noteEventHeader = "Note Hook"
+	const pushEventHeader = "Push Hook"
 
 	// Validate secret if specified.
 	headerSecret := r.Header.Get(secretHeader)
@@ -79,6 +80,12 @@ func (d *DefaultGitlabRequestParserValidator) ParseAndValidate(r *http.Request,
 			return nil, err
 		}
 		return m, nil
+	case pushEventHeader:
+		var p gitlab.PushEvent
+		if err := json.Unmarshal(bytes, &p); err != nil {
+			return nil, err
+		}
+		return p, nil
 	case noteEventHeader:
 		// First, parse a small part of the json to determine if this is a
 		// comment on a merge request or a commit.
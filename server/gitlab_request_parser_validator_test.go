@@ -138,6 +138,39 @@ func TestValidate_ValidMergeCommentEvent(t *testing.T) {
 	Equals(t, "Gitlab Test", b.(gitlab.MergeCommentEvent).Project.Name)
 }
 
+func TestValidate_ValidPushEvent(t *testing.T) {
+	t.Log("If the push event is valid it should be returned")
+	RegisterMockTestingT(t)
+	buf := bytes.NewBufferString(pushEventJSON)
+	req, err := http.NewRequest("POST", "http://localhost/event", buf)
+	Ok(t, err)
+	req.Header.Set("X-Gitlab-Event", "Push Hook")
+	b, err := parser.ParseAndValidate(req, nil)
+	Ok(t, err)
+	Equals(t, "refs/heads/master", b.(gitlab.PushEvent).Ref)
+	Equals(t, "lkysow/atlantis-example", b.(gitlab.PushEvent).Project.PathWithNamespace)
+}
+
+var pushEventJSON = `{
+  "object_kind": "push",
+  "before": "95790bf891e76fee5e1747ab589903a6a1f80f22",
+  "after": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+  "ref": "refs/heads/master",
+  "checkout_sha": "da1560886d4f094c3e6c9ef40349f7d38b5d27d7",
+  "user_username": "lkysow",
+  "project_id": 4580910,
+  "project": {
+    "name": "atlantis-example",
+    "web_url": "https://gitlab.com/lkysow/atlantis-example",
+    "git_http_url": "https://gitlab.com/lkysow/atlantis-example.git",
+    "namespace": "lkysow",
+    "path_with_namespace": "lkysow/atlantis-example",
+    "default_branch": "master"
+  },
+  "commits": [],
+  "total_commits_count": 1
+}`
+
 var mergeEventJSON = `{
   "object_kind": "merge_request",
   "event_type": "merge_request",
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// healthCheckTimeout is how long we wait for a single check before
+	// considering it failed.
+	healthCheckTimeout = 5 * time.Second
+	// MinFreeDiskBytes is the minimum free space in the data dir before we
+	// consider Atlantis degraded. Terraform plans, plugins and clones all live
+	// in the data dir.
+	MinFreeDiskBytes = 100 * 1024 * 1024
+
+	healthStatusOK       = "ok"
+	healthStatusDegraded = "degraded"
+	healthStatusError    = "error"
+)
+
+// HealthCheck is a single named check run by the /healthz and /readyz
+// endpoints.
+type HealthCheck struct {
+	// Name identifies the check in the JSON response, ex. "boltdb".
+	Name string
+	// Check returns an error if the check failed.
+	Check func() error
+}
+
+// healthResponse is the JSON returned by /healthz and /readyz.
+type healthResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+type healthCheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Healthz is the liveness check. It runs the HealthChecks which only depend
+// on this process, ex. BoltDB. It returns a 503 if any check fails.
+func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
+	s.respondHealth(w, s.HealthChecks)
+}
+
+// Readyz is the readiness check. It runs the HealthChecks as well as the
+// ReadinessChecks which depend on the environment, ex. disk space, terraform
+// and the VCS APIs. It returns a 503 if any check fails so that load
+// balancers stop sending webhooks to this instance.
+func (s *Server) Readyz(w http.ResponseWriter, _ *http.Request) {
+	s.respondHealth(w, append(append([]HealthCheck{}, s.HealthChecks...), s.ReadinessChecks...))
+}
+
+func (s *Server) respondHealth(w http.ResponseWriter, checks []HealthCheck) {
+	resp := runHealthChecks(checks, healthCheckTimeout)
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprintf(w, "Error creating status json response: %s", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if resp.Status != healthStatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	w.Write(data) // nolint: errcheck
+}
+
+// runHealthChecks runs checks concurrently. A check that doesn't complete
+// within timeout is considered failed.
+func runHealthChecks(checks []HealthCheck, timeout time.Duration) healthResponse {
+	resp := healthResponse{Status: healthStatusOK}
+	if len(checks) == 0 {
+		return resp
+	}
+
+	resp.Checks = make(map[string]healthCheckResult)
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c HealthCheck) {
+			defer wg.Done()
+			result := healthCheckResult{Status: healthStatusOK}
+			if err := runHealthCheck(c, timeout); err != nil {
+				result = healthCheckResult{Status: healthStatusError, Error: err.Error()}
+			}
+			mux.Lock()
+			defer mux.Unlock()
+			resp.Checks[c.Name] = result
+			if result.Status != healthStatusOK {
+				resp.Status = healthStatusDegraded
+			}
+		}(c)
+	}
+	wg.Wait()
+	return resp
+}
+
+func runHealthCheck(c HealthCheck, timeout time.Duration) error {
+	// Buffered so the check's goroutine can exit even if we time out.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- c.Check()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(timeout):
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// DiskSpaceCheck returns a check that fails if dir has less than minFreeBytes
+// of free space available.
+func DiskSpaceCheck(dir string, minFreeBytes uint64) func() error {
+	return func() error {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(dir, &stat); err != nil {
+			return fmt.Errorf("getting free disk space of %q: %s", dir, err)
+		}
+		// Bavail is the number of blocks available to unprivileged users.
+		free := uint64(stat.Bavail) * uint64(stat.Bsize) // nolint: unconvert
+		if free < minFreeBytes {
+			return fmt.Errorf("only %dMB free in %q, need at least %dMB", free/1024/1024, dir, minFreeBytes/1024/1024)
+		}
+		return nil
+	}
+}
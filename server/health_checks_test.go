@@ -0,0 +1,91 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestHealthz_Checks(t *testing.T) {
+	s := server.Server{
+		HealthChecks: []server.HealthCheck{
+			{Name: "boltdb", Check: func() error { return nil }},
+		},
+		ReadinessChecks: []server.HealthCheck{
+			{Name: "github", Check: func() error { return errors.New("401 Bad credentials") }},
+		},
+	}
+	req, _ := http.NewRequest("GET", "/healthz", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Healthz(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+	body, _ := ioutil.ReadAll(w.Result().Body)
+	Equals(t,
+		`{
+  "status": "ok",
+  "checks": {
+    "boltdb": {
+      "status": "ok"
+    }
+  }
+}`, string(body))
+}
+
+func TestReadyz_Degraded(t *testing.T) {
+	s := server.Server{
+		HealthChecks: []server.HealthCheck{
+			{Name: "boltdb", Check: func() error { return nil }},
+		},
+		ReadinessChecks: []server.HealthCheck{
+			{Name: "github", Check: func() error { return errors.New("401 Bad credentials") }},
+		},
+	}
+	req, _ := http.NewRequest("GET", "/readyz", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Readyz(w, req)
+	Equals(t, http.StatusServiceUnavailable, w.Result().StatusCode)
+	Equals(t, "application/json", w.Result().Header["Content-Type"][0])
+
+	var resp struct {
+		Status string `json:"status"`
+		Checks map[string]struct {
+			Status string `json:"status"`
+			Error  string `json:"error"`
+		} `json:"checks"`
+	}
+	Ok(t, json.NewDecoder(w.Result().Body).Decode(&resp))
+	Equals(t, "degraded", resp.Status)
+	Equals(t, 2, len(resp.Checks))
+	Equals(t, "ok", resp.Checks["boltdb"].Status)
+	Equals(t, "error", resp.Checks["github"].Status)
+	Equals(t, "401 Bad credentials", resp.Checks["github"].Error)
+}
+
+func TestReadyz_OK(t *testing.T) {
+	s := server.Server{
+		ReadinessChecks: []server.HealthCheck{
+			{Name: "terraform", Check: func() error { return nil }},
+		},
+	}
+	req, _ := http.NewRequest("GET", "/readyz", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Readyz(w, req)
+	Equals(t, http.StatusOK, w.Result().StatusCode)
+}
+
+func TestDiskSpaceCheck(t *testing.T) {
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+
+	Ok(t, server.DiskSpaceCheck(tmp, 1)())
+	ErrContains(t, "free in", server.DiskSpaceCheck(tmp, math.MaxUint64)())
+	ErrContains(t, "getting free disk space", server.DiskSpaceCheck("/does/not/exist", 1)())
+}
@@ -0,0 +1,266 @@
+// Package jobs buffers the output of commands while they're running so it can
+// be streamed to the UI.
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+const (
+	// maxJobLines is the number of lines we buffer per job. Once a job has
+	// output more lines than this, the oldest lines are dropped so that long
+	// running commands don't use unbounded memory.
+	maxJobLines = 10000
+	// subscriberBufferSize is how many lines can be queued for a subscriber
+	// before it's considered too slow and is disconnected.
+	subscriberBufferSize = 1000
+	// maxHeldLines is how many lines are held back waiting for the end of a
+	// private key. If it hasn't ended by then, the held lines are masked.
+	maxHeldLines = 1000
+	// redactedText replaces private keys that never end. It's the same as
+	// the text the Redactor replaces secrets with.
+	redactedText = "[redacted]"
+)
+
+// ansiEscapeRegex matches terminal escape sequences which can't be displayed
+// in the browser.
+var ansiEscapeRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// privateKeyBeginRegex and privateKeyEndRegex match the start and end of PEM
+// encoded private keys. Keys span many lines so lines are held back from the
+// Redactor until the key ends.
+var privateKeyBeginRegex = regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)
+var privateKeyEndRegex = regexp.MustCompile(`-----END [A-Z ]*PRIVATE KEY-----`)
+
+// Redactor redacts secrets from output.
+type Redactor interface {
+	Redact(s string) string
+}
+
+// JobID returns the ID of the job for the project named projectName at
+// repoRelDir and workspace in pull. It's the same for every command run on
+// the project so that links to the job always show the latest output.
+func JobID(pull models.PullRequest, repoRelDir string, workspace string, projectName string) string {
+	key := fmt.Sprintf("%s/%s/%d/%s/%s/%s", pull.BaseRepo.VCSHost.Hostname, pull.BaseRepo.FullName, pull.Num, repoRelDir, workspace, projectName)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Info describes a job.
+type Info struct {
+	ID           string
+	RepoFullName string
+	PullNum      int
+	PullURL      string
+	RepoRelDir   string
+	Workspace    string
+	ProjectName  string
+	// Command is the command being run, ex. plan.
+	Command   string
+	StartTime time.Time
+	// Complete is true once the command has finished.
+	Complete bool
+}
+
+type job struct {
+	info  Info
+	pull  models.PullRequest
+	lines []string
+	// held are lines that haven't been redacted yet because they might be
+	// part of a secret that spans many lines.
+	held        []string
+	subscribers map[chan string]struct{}
+}
+
+// Manager tracks jobs. It's safe for concurrent use.
+type Manager struct {
+	// Redactor redacts secrets from the output before it's buffered. Secrets
+	// that span many lines, ex. private keys, are redacted as a whole. If
+	// nil, the output isn't redacted.
+	Redactor Redactor
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// NewManager returns a new Manager.
+func NewManager(redactor Redactor) *Manager {
+	return &Manager{
+		Redactor: redactor,
+		jobs:     make(map[string]*job),
+	}
+}
+
+// StartJob starts a new job for the project in ctx. Any previous output for
+// the project is discarded and its subscribers are disconnected.
+func (m *Manager) StartJob(ctx models.ProjectCommandContext, cmdName models.CommandName) {
+	id := JobID(ctx.Pull, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if prev, ok := m.jobs[id]; ok {
+		m.closeSubscribers(prev)
+	}
+	m.jobs[id] = &job{
+		info: Info{
+			ID:           id,
+			RepoFullName: ctx.Pull.BaseRepo.FullName,
+			PullNum:      ctx.Pull.Num,
+			PullURL:      ctx.Pull.URL,
+			RepoRelDir:   ctx.RepoRelDir,
+			Workspace:    ctx.Workspace,
+			ProjectName:  ctx.ProjectName,
+			Command:      cmdName.String(),
+			StartTime:    time.Now(),
+		},
+		pull:        ctx.Pull,
+		subscribers: make(map[chan string]struct{}),
+	}
+}
+
+// WriteLine adds line to the output of the job for the project in ctx and
+// sends it to any subscribers. Lines that might be part of a private key are
+// held back until the key ends so it can be redacted as a whole. It does
+// nothing if the job hasn't been started.
+func (m *Manager) WriteLine(ctx models.ProjectCommandContext, line string) {
+	line = ansiEscapeRegex.ReplaceAllString(line, "")
+
+	id := JobID(ctx.Pull, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok || j.info.Complete {
+		return
+	}
+	j.held = append(j.held, line)
+	if unendedKeyStart(strings.Join(j.held, "\n")) >= 0 && len(j.held) < maxHeldLines {
+		return
+	}
+	m.releaseHeld(j)
+}
+
+// releaseHeld redacts the lines held by j and adds them to its output. If
+// they contain a private key that hasn't ended, it's masked. It must be called
+// with m.mu held.
+func (m *Manager) releaseHeld(j *job) {
+	if len(j.held) == 0 {
+		return
+	}
+	out := strings.Join(j.held, "\n")
+	j.held = nil
+	if start := unendedKeyStart(out); start >= 0 {
+		out = out[:start] + redactedText
+	}
+	if m.Redactor != nil {
+		out = m.Redactor.Redact(out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		m.addLine(j, line)
+	}
+}
+
+// addLine adds line to j's output and sends it to j's subscribers. It must be
+// called with m.mu held.
+func (m *Manager) addLine(j *job, line string) {
+	j.lines = append(j.lines, line)
+	if len(j.lines) > maxJobLines {
+		j.lines = j.lines[len(j.lines)-maxJobLines:]
+	}
+	for ch := range j.subscribers {
+		select {
+		case ch <- line:
+		default:
+			// The subscriber isn't keeping up. Disconnect it rather than
+			// blocking the command.
+			delete(j.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// unendedKeyStart returns the index in out of the start of a private key that
+// doesn't end, or -1 if there isn't one.
+func unendedKeyStart(out string) int {
+	begins := privateKeyBeginRegex.FindAllStringIndex(out, -1)
+	if len(begins) == 0 {
+		return -1
+	}
+	last := begins[len(begins)-1]
+	if privateKeyEndRegex.MatchString(out[last[1]:]) {
+		return -1
+	}
+	return last[0]
+}
+
+// CompleteJob marks the job for the project in ctx as complete and
+// disconnects its subscribers.
+func (m *Manager) CompleteJob(ctx models.ProjectCommandContext) {
+	id := JobID(ctx.Pull, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j, ok := m.jobs[id]; ok {
+		m.releaseHeld(j)
+		j.info.Complete = true
+		m.closeSubscribers(j)
+	}
+}
+
+// CleanUpPull deletes all the jobs for pull.
+func (m *Manager) CleanUpPull(pull models.PullRequest) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, j := range m.jobs {
+		if j.pull.BaseRepo.FullName == pull.BaseRepo.FullName &&
+			j.pull.BaseRepo.VCSHost.Hostname == pull.BaseRepo.VCSHost.Hostname &&
+			j.pull.Num == pull.Num {
+			m.closeSubscribers(j)
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Subscribe returns the job with id, the output so far and a channel that
+// receives new lines of output. The channel is closed when the job completes
+// or is restarted. Callers must call unsubscribe when done. If the job is
+// already complete, the channel is nil. ok is false if there is no job with
+// id.
+func (m *Manager) Subscribe(id string) (info Info, lines []string, ch <-chan string, unsubscribe func(), ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return Info{}, nil, nil, func() {}, false
+	}
+	lines = append([]string{}, j.lines...)
+	if j.info.Complete {
+		return j.info, lines, nil, func() {}, true
+	}
+
+	sub := make(chan string, subscriberBufferSize)
+	j.subscribers[sub] = struct{}{}
+	unsubscribe = func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		// The channel may have already been closed if the job completed.
+		if _, ok := j.subscribers[sub]; ok {
+			delete(j.subscribers, sub)
+			close(sub)
+		}
+	}
+	return j.info, lines, sub, unsubscribe, true
+}
+
+// closeSubscribers must be called with m.mu held.
+func (m *Manager) closeSubscribers(j *job) {
+	for ch := range j.subscribers {
+		delete(j.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// JobsPathPrefix is the path prefix of the job view. The job's ID is appended
+// to it.
+const JobsPathPrefix = "/jobs/"
+
+// JobsController handles requests to view the output of running commands.
+type JobsController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	Logger          *logging.SimpleLogger
+	JobsManager     *jobs.Manager
+	JobTemplate     TemplateWriter
+}
+
+// GetJob is the GET /jobs/{id} route. It renders a page that displays the
+// output of the job, streamed from GetJobStream.
+func (j *JobsController) GetJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		j.respond(w, logging.Warn, http.StatusBadRequest, "No job id in request")
+		return
+	}
+	info, _, _, unsubscribe, ok := j.JobsManager.Subscribe(id)
+	unsubscribe()
+	if !ok {
+		j.respond(w, logging.Info, http.StatusNotFound, "No job found with id %q. Jobs are deleted when the pull request is closed or Atlantis restarts.", id)
+		return
+	}
+
+	err := j.JobTemplate.Execute(w, JobData{
+		Info:            info,
+		StreamURL:       j.AtlantisURL.Path + JobsPathPrefix + id + "/stream",
+		AtlantisVersion: j.AtlantisVersion,
+		CleanedBasePath: j.AtlantisURL.Path,
+	})
+	if err != nil {
+		j.Logger.Err(err.Error())
+	}
+}
+
+// GetJobStream is the GET /jobs/{id}/stream route. It streams the output of
+// the job as server-sent events. Each line of output is sent as a message and
+// a "complete" event is sent once the command finishes.
+func (j *JobsController) GetJobStream(w http.ResponseWriter, r *http.Request) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		j.respond(w, logging.Warn, http.StatusBadRequest, "No job id in request")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		j.respond(w, logging.Error, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+	_, lines, ch, unsubscribe, ok := j.JobsManager.Subscribe(id)
+	if !ok {
+		j.respond(w, logging.Info, http.StatusNotFound, "No job found with id %q", id)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stops proxies like nginx from buffering the response.
+	w.Header().Set("X-Accel-Buffering", "no")
+	for _, line := range lines {
+		writeSSEMessage(w, line)
+	}
+	flusher.Flush()
+
+	if ch != nil {
+		for {
+			select {
+			case line, open := <-ch:
+				if !open {
+					// The channel is closed if the job completes, is restarted or
+					// we were too slow. In the latter two cases the browser will
+					// reconnect and get the full output again.
+					info, _, _, unsub, stillExists := j.JobsManager.Subscribe(id)
+					unsub()
+					if stillExists && !info.Complete {
+						return
+					}
+					writeSSEComplete(w)
+					flusher.Flush()
+					return
+				}
+				writeSSEMessage(w, line)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+	writeSSEComplete(w)
+	flusher.Flush()
+}
+
+// writeSSEMessage writes line as a server-sent event message. Lines can't
+// contain newlines in the data field so any are split into separate data
+// fields which the browser joins back together.
+func writeSSEMessage(w http.ResponseWriter, line string) {
+	for _, l := range strings.Split(line, "\n") {
+		fmt.Fprintf(w, "data: %s\n", strings.TrimSuffix(l, "\r"))
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func writeSSEComplete(w http.ResponseWriter) {
+	fmt.Fprint(w, "event: complete\ndata: \n\n")
+}
+
+func (j *JobsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	j.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
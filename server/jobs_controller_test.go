@@ -0,0 +1,136 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
+	"github.com/runatlantis/atlantis/server/logging"
+	sMocks "github.com/runatlantis/atlantis/server/mocks"
+	"github.com/runatlantis/atlantis/server/mocks/matchers"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+var jobCtx = models.ProjectCommandContext{
+	Pull: models.PullRequest{
+		Num: 1,
+		BaseRepo: models.Repo{
+			FullName: "owner/repo",
+			VCSHost:  models.VCSHost{Hostname: "github.com"},
+		},
+	},
+	RepoRelDir: "dir",
+	Workspace:  "default",
+}
+
+func newJobsController(manager *jobs.Manager, tmpl server.TemplateWriter) *server.JobsController {
+	atlantisURL, _ := url.Parse("https://example.com/basepath")
+	return &server.JobsController{
+		AtlantisVersion: "1.0.0",
+		AtlantisURL:     atlantisURL,
+		Logger:          logging.NewNoopLogger(),
+		JobsManager:     manager,
+		JobTemplate:     tmpl,
+	}
+}
+
+func TestGetJob_NotFound(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpl := sMocks.NewMockTemplateWriter()
+	jc := newJobsController(jobs.NewManager(nil), tmpl)
+	req, _ := http.NewRequest("GET", "/jobs/abc", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+	w := httptest.NewRecorder()
+	jc.GetJob(w, req)
+	responseContains(t, w, http.StatusNotFound, "No job found with id \"abc\"")
+	tmpl.VerifyWasCalled(Never()).Execute(matchers.AnyIoWriter(), AnyInterface())
+}
+
+func TestGetJob_Success(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmpl := sMocks.NewMockTemplateWriter()
+	manager := jobs.NewManager(nil)
+	manager.StartJob(jobCtx, models.PlanCommand)
+	jc := newJobsController(manager, tmpl)
+	id := jobs.JobID(jobCtx.Pull, "dir", "default", "")
+	req, _ := http.NewRequest("GET", "/jobs/"+id, nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	jc.GetJob(w, req)
+
+	_, data := tmpl.VerifyWasCalledOnce().Execute(matchers.AnyIoWriter(), AnyInterface()).GetCapturedArguments()
+	jobData := data.(server.JobData)
+	Equals(t, "/basepath/jobs/"+id+"/stream", jobData.StreamURL)
+	Equals(t, "owner/repo", jobData.Info.RepoFullName)
+	Equals(t, "plan", jobData.Info.Command)
+	Equals(t, "/basepath", jobData.CleanedBasePath)
+}
+
+func TestGetJobStream_Complete(t *testing.T) {
+	manager := jobs.NewManager(nil)
+	manager.StartJob(jobCtx, models.PlanCommand)
+	manager.WriteLine(jobCtx, "line 1")
+	manager.WriteLine(jobCtx, "line 2")
+	manager.CompleteJob(jobCtx)
+	jc := newJobsController(manager, nil)
+	id := jobs.JobID(jobCtx.Pull, "dir", "default", "")
+	req, _ := http.NewRequest("GET", "/jobs/"+id+"/stream", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": id})
+	w := httptest.NewRecorder()
+	jc.GetJobStream(w, req)
+
+	Equals(t, http.StatusOK, w.Code)
+	Equals(t, "text/event-stream", w.Header().Get("Content-Type"))
+	Equals(t, "data: line 1\n\ndata: line 2\n\nevent: complete\ndata: \n\n", w.Body.String())
+}
+
+func TestGetJobStream_Running(t *testing.T) {
+	manager := jobs.NewManager(nil)
+	manager.StartJob(jobCtx, models.PlanCommand)
+	manager.WriteLine(jobCtx, "line 1")
+	jc := newJobsController(manager, nil)
+	id := jobs.JobID(jobCtx.Pull, "dir", "default", "")
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jc.GetJobStream(w, mux.SetURLVars(r, map[string]string{"id": id}))
+	}))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL)
+	Ok(t, err)
+	defer resp.Body.Close() // nolint: errcheck
+
+	// Read the existing output before writing more so we know we're
+	// subscribed.
+	buf := make([]byte, 1024)
+	n, err := resp.Body.Read(buf)
+	Ok(t, err)
+	Equals(t, "data: line 1\n\n", string(buf[:n]))
+
+	manager.WriteLine(jobCtx, "line 2")
+	manager.CompleteJob(jobCtx)
+	var body strings.Builder
+	for {
+		n, err = resp.Body.Read(buf)
+		body.Write(buf[:n])
+		if err != nil {
+			break
+		}
+	}
+	Equals(t, "data: line 2\n\nevent: complete\ndata: \n\n", body.String())
+}
+
+func TestGetJobStream_NotFound(t *testing.T) {
+	jc := newJobsController(jobs.NewManager(nil), nil)
+	req, _ := http.NewRequest("GET", "/jobs/abc/stream", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "abc"})
+	w := httptest.NewRecorder()
+	jc.GetJobStream(w, req)
+	responseContains(t, w, http.StatusNotFound, "No job found")
+}
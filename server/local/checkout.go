@@ -0,0 +1,118 @@
+package local
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// checkoutWorkingDir implements events.WorkingDir with the user's checkout
+// instead of clones. Every workspace uses the checkout and nothing is ever
+// deleted.
+type checkoutWorkingDir struct {
+	RepoDir string
+}
+
+func (c *checkoutWorkingDir) Clone(log *logging.SimpleLogger, baseRepo models.Repo, headRepo models.Repo, p models.PullRequest, workspace string) (string, bool, error) {
+	return c.RepoDir, false, nil
+}
+
+func (c *checkoutWorkingDir) HasDiverged(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest) (bool, error) {
+	return false, nil
+}
+
+// ReadBaseFile reads path from the base ref in the checkout rather than
+// fetching it.
+func (c *checkoutWorkingDir) ReadBaseFile(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, path string) ([]byte, error) {
+	found, err := runGit(c.RepoDir, "ls-tree", "--name-only", p.BaseBranch, "--", path)
+	if err != nil || found == "" {
+		return nil, err
+	}
+	contents, err := runGit(c.RepoDir, "cat-file", "blob", p.BaseBranch+":"+path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(contents), nil
+}
+
+// ListBaseFiles lists the files in dir on the base ref in the checkout rather
+// than fetching it.
+func (c *checkoutWorkingDir) ListBaseFiles(log *logging.SimpleLogger, cloneDir string, headRepo models.Repo, p models.PullRequest, dir string) ([]string, error) {
+	args := []string{"ls-tree", "-z", p.BaseBranch}
+	if dir = filepath.ToSlash(filepath.Clean(dir)); dir != "." {
+		args = append(args, "--", dir+"/")
+	}
+	output, err := runGit(c.RepoDir, args...)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range strings.Split(output, "\x00") {
+		tab := strings.Index(entry, "\t")
+		if tab == -1 {
+			continue
+		}
+		if fields := strings.Fields(entry[:tab]); len(fields) == 3 && fields[1] == "blob" {
+			files = append(files, entry[tab+1:])
+		}
+	}
+	return files, nil
+}
+
+func (c *checkoutWorkingDir) GetWorkingDir(r models.Repo, p models.PullRequest, workspace string) (string, error) {
+	return c.RepoDir, nil
+}
+
+func (c *checkoutWorkingDir) GetPullDir(r models.Repo, p models.PullRequest) (string, error) {
+	return c.RepoDir, nil
+}
+
+func (c *checkoutWorkingDir) Delete(r models.Repo, p models.PullRequest) error {
+	return nil
+}
+
+func (c *checkoutWorkingDir) DeleteForWorkspace(r models.Repo, p models.PullRequest, workspace string) error {
+	return nil
+}
+
+// modifiedFilesClient is the VCS client for the checkout. Only getting the
+// modified files is supported since there's no pull request to comment on.
+type modifiedFilesClient struct {
+	vcs.NotConfiguredVCSClient
+	ModifiedFiles []string
+}
+
+func (m *modifiedFilesClient) GetModifiedFiles(repo models.Repo, pull models.PullRequest) ([]string, error) {
+	return m.ModifiedFiles, nil
+}
+
+// noopProjectLocker always acquires the lock since there's nobody else
+// planning the checkout.
+type noopProjectLocker struct{}
+
+func (noopProjectLocker) TryLock(log *logging.SimpleLogger, pull models.PullRequest, user models.User, workspace string, project models.Project) (*events.TryLockResponse, error) {
+	return &events.TryLockResponse{
+		LockAcquired: true,
+		UnlockFn:     func() error { return nil },
+	}, nil
+}
+
+// noopLockURLGenerator returns empty lock URLs since there's no server to
+// view locks on.
+type noopLockURLGenerator struct{}
+
+func (noopLockURLGenerator) GenerateLockURL(lockID string) string {
+	return ""
+}
+
+// noopStatusUpdater ignores status updates since there are no commit
+// statuses.
+type noopStatusUpdater struct{}
+
+func (noopStatusUpdater) UpdateProject(ctx models.ProjectCommandContext, cmdName models.CommandName, status models.CommitStatus, url string) error {
+	return nil
+}
@@ -0,0 +1,127 @@
+package local
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// hostTypes are the VCS hosts we can tell apart by hostname. Other hostnames
+// are treated as GitHub which only affects how results are rendered.
+var hostTypes = map[string]models.VCSHostType{
+	"github.com":    models.Github,
+	"gitlab.com":    models.Gitlab,
+	"bitbucket.org": models.BitbucketCloud,
+	"dev.azure.com": models.AzureDevops,
+	"gitea.com":     models.Gitea,
+}
+
+// modifiedFiles returns the files in repoDir that were modified since HEAD
+// diverged from baseRef, including uncommitted changes and untracked files.
+// Renamed files are returned under their old and new names like the VCS hosts
+// do.
+func modifiedFiles(repoDir string, baseRef string) ([]string, error) {
+	mergeBase, err := runGit(repoDir, "merge-base", baseRef, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	diff, err := runGit(repoDir, "diff", "--name-only", "--no-renames", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(repoDir, "ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, file := range strings.Split(diff+"\n"+untracked, "\n") {
+		if file == "" || seen[file] {
+			continue
+		}
+		seen[file] = true
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// remoteRepoID returns the ID of the repo at remoteURL, ex.
+// github.com/runatlantis/atlantis for git@github.com:runatlantis/atlantis.git.
+func remoteRepoID(remoteURL string) (string, error) {
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", fmt.Errorf("parsing remote url %q: %s", remoteURL, err)
+		}
+		host = u.Hostname()
+		path = u.Path
+	} else {
+		// The scp-like syntax, ex. git@github.com:owner/repo.git.
+		i := strings.Index(remoteURL, ":")
+		if i < 0 {
+			return "", fmt.Errorf("unable to determine repo from remote url %q", remoteURL)
+		}
+		host = remoteURL[:i]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		path = remoteURL[i+1:]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	// Azure DevOps repo URLs have a _git segment that's not part of the
+	// repo's name.
+	path = strings.Replace(path, "/_git/", "/", 1)
+	if host == "" || path == "" {
+		return "", fmt.Errorf("unable to determine repo from remote url %q", remoteURL)
+	}
+	return host + "/" + path, nil
+}
+
+// parseRepoID parses id, ex. github.com/owner/repo, into a repo.
+func parseRepoID(id string) (models.Repo, error) {
+	slash := strings.Index(id, "/")
+	if slash < 0 {
+		return models.Repo{}, fmt.Errorf("invalid repo %q, expected {hostname}/{owner}/{repo}", id)
+	}
+	hostname := id[:slash]
+	fullName := id[slash+1:]
+	owner, name := models.SplitRepoFullName(fullName)
+	if hostname == "" || owner == "" || name == "" {
+		return models.Repo{}, fmt.Errorf("invalid repo %q, expected {hostname}/{owner}/{repo}", id)
+	}
+	hostType, ok := hostTypes[hostname]
+	if !ok {
+		hostType = models.Github
+	}
+	return models.Repo{
+		FullName: fullName,
+		Owner:    owner,
+		Name:     name,
+		VCSHost: models.VCSHost{
+			Hostname: hostname,
+			Type:     hostType,
+		},
+	}, nil
+}
+
+// runGit runs git with args in dir and returns its trimmed output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) // #nosec
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var stderr string
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			stderr = strings.TrimSpace(string(exitErr.Stderr))
+		}
+		return "", fmt.Errorf("running git %s: %s: %s", strings.Join(args, " "), stderr, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
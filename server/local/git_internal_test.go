@@ -0,0 +1,74 @@
+package local
+
+import (
+	"testing"
+
+	"github.com/runatlantis/atlantis/server/events/models"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestRemoteRepoID(t *testing.T) {
+	cases := []struct {
+		remoteURL string
+		expID     string
+		expErr    string
+	}{
+		{
+			remoteURL: "git@github.com:runatlantis/atlantis.git",
+			expID:     "github.com/runatlantis/atlantis",
+		},
+		{
+			remoteURL: "https://github.com/runatlantis/atlantis.git",
+			expID:     "github.com/runatlantis/atlantis",
+		},
+		{
+			remoteURL: "https://user@gitlab.com/group/subgroup/repo",
+			expID:     "gitlab.com/group/subgroup/repo",
+		},
+		{
+			remoteURL: "ssh://git@bitbucket.corp:7999/proj/repo.git",
+			expID:     "bitbucket.corp/proj/repo",
+		},
+		{
+			remoteURL: "https://org@dev.azure.com/org/project/_git/repo",
+			expID:     "dev.azure.com/org/project/repo",
+		},
+		{
+			remoteURL: "/local/path",
+			expErr:    `unable to determine repo from remote url "/local/path"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.remoteURL, func(t *testing.T) {
+			id, err := remoteRepoID(c.remoteURL)
+			if c.expErr != "" {
+				ErrEquals(t, c.expErr, err)
+				return
+			}
+			Ok(t, err)
+			Equals(t, c.expID, id)
+		})
+	}
+}
+
+func TestParseRepoID(t *testing.T) {
+	repo, err := parseRepoID("gitea.com/owner/repo")
+	Ok(t, err)
+	Equals(t, models.Repo{
+		FullName: "owner/repo",
+		Owner:    "owner",
+		Name:     "repo",
+		VCSHost: models.VCSHost{
+			Hostname: "gitea.com",
+			Type:     models.Gitea,
+		},
+	}, repo)
+
+	// Unknown hosts are treated as GitHub.
+	repo, err = parseRepoID("github.mycorp.com/owner/repo")
+	Ok(t, err)
+	Equals(t, models.Github, repo.VCSHost.Type)
+
+	_, err = parseRepoID("github.com/repo")
+	ErrEquals(t, `invalid repo "github.com/repo", expected {hostname}/{owner}/{repo}`, err)
+}
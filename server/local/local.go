@@ -0,0 +1,257 @@
+// Package local runs the Atlantis workflow on a local checkout of a repo, without
+// a VCS host, so users can see what Atlantis will do before they push.
+package local
+
+import (
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/runtime"
+	"github.com/runatlantis/atlantis/server/events/terraform"
+	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// Config is the configuration for running Atlantis locally. The fields mean
+// the same as the server's flags of the same name.
+type Config struct {
+	DataDir          string
+	DefaultTFVersion string
+	// DefaultTFVersionFlag is the name of the flag DefaultTFVersion was set
+	// with. It's used in error messages.
+	DefaultTFVersionFlag string
+	RepoConfig           string
+	RepoConfigJSON       string
+	TFDownloadURL        string
+	AllowRepoConfig      bool
+	RequireMergeable     bool
+	RequireApproval      bool
+	RedactEnvVars        string
+	RedactPatterns       []string
+}
+
+// PlanOptions describe the checkout to plan.
+type PlanOptions struct {
+	// RepoDir is a directory in the checkout.
+	RepoDir string
+	// BaseRef is the git ref the changes would be merged into, ex. main.
+	// Files that changed since the checkout diverged from it are modified.
+	BaseRef string
+	// RepoID is the repo's ID as Atlantis sees it, ex. github.com/owner/repo.
+	// It's matched against the server-side repo config. If empty, it's
+	// determined from the checkout's origin remote.
+	RepoID string
+	// Labels are used as the labels of the pull request.
+	Labels []string
+}
+
+// PlanResult is the result of planning a checkout.
+type PlanResult struct {
+	Repo models.Repo
+	Pull models.PullRequest
+	// ModifiedFiles are the files, relative to the root of the checkout,
+	// that were modified since it diverged from the base ref.
+	ModifiedFiles []string
+	// Redactor masks secrets in the output. If nil, nothing is redacted.
+	Redactor *events.SecretRedactor
+	// Projects are the commands that were run, in the same order as the
+	// project results in Result.
+	Projects []models.ProjectCommandContext
+	Result   events.CommandResult
+}
+
+// Runner plans the projects Atlantis would autoplan if the checkout was pushed
+// and a pull request was opened for it.
+type Runner struct {
+	// GlobalCfg is the server-side repo config.
+	GlobalCfg      valid.GlobalCfg
+	InitStepRunner events.StepRunner
+	PlanStepRunner events.StepRunner
+	RunStepRunner  events.CustomStepRunner
+	EnvStepRunner  events.EnvStepRunner
+	// Redactor masks secrets in the results. If nil, nothing is redacted.
+	Redactor *events.SecretRedactor
+	Logger   *logging.SimpleLogger
+}
+
+// NewRunner returns a Runner that runs the same steps as the server
+// configured with config.
+func NewRunner(config Config, logger *logging.SimpleLogger) (*Runner, error) {
+	terraformClient, err := terraform.NewClient(
+		logger,
+		config.DataDir,
+		"",
+		"",
+		config.DefaultTFVersion,
+		config.DefaultTFVersionFlag,
+		config.TFDownloadURL,
+		&terraform.DefaultDownloader{})
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing terraform")
+	}
+
+	redactor, err := events.NewSecretRedactor(config.RedactPatterns, strings.Split(config.RedactEnvVars, ","))
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing secret redactor")
+	}
+
+	validator := &yaml.ParserValidator{}
+	globalCfg := valid.NewGlobalCfg(config.AllowRepoConfig, config.RequireMergeable, config.RequireApproval)
+	if config.RepoConfig != "" {
+		globalCfg, err = validator.ParseGlobalCfg(config.RepoConfig, globalCfg)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s file", config.RepoConfig)
+		}
+	} else if config.RepoConfigJSON != "" {
+		globalCfg, err = validator.ParseGlobalCfgJSON(config.RepoConfigJSON, globalCfg)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing repo config JSON")
+		}
+	}
+
+	defaultTfVersion := terraformClient.DefaultVersion()
+	runStepRunner := &runtime.RunStepRunner{
+		TerraformExecutor: terraformClient,
+		DefaultTFVersion:  defaultTfVersion,
+		TerraformBinDir:   terraformClient.TerraformBinDir(),
+	}
+	return &Runner{
+		GlobalCfg: globalCfg,
+		InitStepRunner: &runtime.InitStepRunner{
+			TerraformExecutor: terraformClient,
+			DefaultTFVersion:  defaultTfVersion,
+		},
+		PlanStepRunner: &runtime.PlanStepRunner{
+			TerraformExecutor: terraformClient,
+			DefaultTFVersion:  defaultTfVersion,
+			// There are no commit statuses to update when using remote
+			// operations.
+			CommitStatusUpdater: noopStatusUpdater{},
+			AsyncTFExec:         terraformClient,
+		},
+		RunStepRunner: runStepRunner,
+		EnvStepRunner: &runtime.EnvStepRunner{
+			RunStepRunner: runStepRunner,
+		},
+		Redactor: redactor,
+		Logger:   logger,
+	}, nil
+}
+
+// Plan runs plan for the projects in the checkout that Atlantis would
+// autoplan. Errors Atlantis would comment on the pull request, ex. an invalid
+// atlantis.yaml, are returned in the result rather than as an error.
+func (r *Runner) Plan(opts PlanOptions) (*PlanResult, error) {
+	dir, err := filepath.Abs(opts.RepoDir)
+	if err != nil {
+		return nil, err
+	}
+	repoDir, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	repo, err := r.repo(repoDir, opts.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	modifiedFiles, err := modifiedFiles(repoDir, opts.BaseRef)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := runGit(repoDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	headBranch, err := runGit(repoDir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	var username string
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	pull := models.PullRequest{
+		HeadCommit: headCommit,
+		HeadBranch: headBranch,
+		BaseBranch: opts.BaseRef,
+		Author:     username,
+		State:      models.OpenPullState,
+		BaseRepo:   repo,
+		Labels:     opts.Labels,
+	}
+	ctx := &events.CommandContext{
+		BaseRepo:      repo,
+		HeadRepo:      repo,
+		Pull:          pull,
+		User:          models.User{Username: username},
+		Log:           r.Logger,
+		PullMergeable: true,
+	}
+
+	workingDir := &checkoutWorkingDir{RepoDir: repoDir}
+	workingDirLocker := events.NewDefaultWorkingDirLocker()
+	builder := &events.DefaultProjectCommandBuilder{
+		ParserValidator:   &yaml.ParserValidator{},
+		ProjectFinder:     &events.DefaultProjectFinder{},
+		VCSClient:         &modifiedFilesClient{ModifiedFiles: modifiedFiles},
+		WorkingDir:        workingDir,
+		WorkingDirLocker:  workingDirLocker,
+		GlobalCfg:         r.GlobalCfg,
+		PendingPlanFinder: &events.DefaultPendingPlanFinder{},
+		CommentBuilder:    &events.CommentParser{},
+	}
+	projectCmdRunner := &events.DefaultProjectCommandRunner{
+		Locker:           noopProjectLocker{},
+		LockURLGenerator: noopLockURLGenerator{},
+		InitStepRunner:   r.InitStepRunner,
+		PlanStepRunner:   r.PlanStepRunner,
+		RunStepRunner:    r.RunStepRunner,
+		EnvStepRunner:    r.EnvStepRunner,
+		WorkingDir:       workingDir,
+		WorkingDirLocker: workingDirLocker,
+	}
+
+	res := &PlanResult{
+		Repo:          repo,
+		Pull:          pull,
+		ModifiedFiles: modifiedFiles,
+		Redactor:      r.Redactor,
+	}
+	if r.GlobalCfg.MatchingLabelBehavior(repo.ID(), pull.Labels).SkipAutoplan {
+		r.Logger.Info("skipping autoplan because the pull request has a label that skips it")
+		return res, nil
+	}
+	projectCmds, err := builder.BuildAutoplanCommands(ctx)
+	if err != nil {
+		res.Result.Error = err
+		return res, nil
+	}
+	res.Projects = projectCmds
+	for _, cmd := range projectCmds {
+		r.Logger.Info("planning project at dir: %q workspace: %q", cmd.RepoRelDir, cmd.Workspace)
+		res.Result.ProjectResults = append(res.Result.ProjectResults, projectCmdRunner.Plan(cmd))
+	}
+	return res, nil
+}
+
+// repo returns the repo with id or, if id is empty, the repo of repoDir's
+// origin remote.
+func (r *Runner) repo(repoDir string, id string) (models.Repo, error) {
+	if id == "" {
+		remoteURL, err := runGit(repoDir, "remote", "get-url", "origin")
+		if err != nil {
+			return models.Repo{}, errors.Wrap(err, "determining repo from origin remote")
+		}
+		id, err = remoteRepoID(remoteURL)
+		if err != nil {
+			return models.Repo{}, err
+		}
+	}
+	return parseRepoID(id)
+}
@@ -0,0 +1,246 @@
+package local_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
+	"github.com/runatlantis/atlantis/server/local"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestPlan_ModifiedProjects(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "project1/main.tf", "# changed")
+	runCmd(t, repoDir, "git", "commit", "-am", "change project1")
+	writeFile(t, repoDir, "project3/main.tf", "# new")
+
+	runner, _, planRunner, _ := newRunner(t, valid.NewGlobalCfg(false, false, false))
+	When(planRunner.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).ThenReturn("plan output", nil)
+
+	// Run from a subdirectory to check the root of the checkout is found.
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: filepath.Join(repoDir, "project2"),
+		BaseRef: "main",
+	})
+	Ok(t, err)
+	Equals(t, "github.com/runatlantis/atlantis-example", res.Repo.ID())
+	Equals(t, models.Github, res.Repo.VCSHost.Type)
+	Equals(t, "feature", res.Pull.HeadBranch)
+	Equals(t, "main", res.Pull.BaseBranch)
+	Equals(t, []string{"project1/main.tf", "project3/main.tf"}, res.ModifiedFiles)
+	Ok(t, res.Result.Error)
+	Equals(t, 2, len(res.Result.ProjectResults))
+	for i, dir := range []string{"project1", "project3"} {
+		projRes := res.Result.ProjectResults[i]
+		Equals(t, dir, projRes.RepoRelDir)
+		Equals(t, "default", projRes.Workspace)
+		Ok(t, projRes.Error)
+		Equals(t, "plan output", projRes.PlanSuccess.TerraformOutput)
+	}
+	Assert(t, strings.Contains(res.Markdown(), "Ran Plan for 2 projects"), "exp markdown to contain the summary, got %q", res.Markdown())
+}
+
+func TestPlan_RepoID(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "project1/main.tf", "# changed")
+
+	runner, _, _, _ := newRunner(t, valid.NewGlobalCfg(false, false, false))
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "main",
+		RepoID:  "gitlab.com/group/subgroup/repo",
+	})
+	Ok(t, err)
+	Equals(t, "group/subgroup/repo", res.Repo.FullName)
+	Equals(t, models.Gitlab, res.Repo.VCSHost.Type)
+}
+
+func TestPlan_ServerSideWorkflow(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "project1/main.tf", "# changed")
+	repoCfgDir, cleanupCfg := TempDir(t)
+	defer cleanupCfg()
+	repoCfgFile := filepath.Join(repoCfgDir, "repos.yaml")
+	writeFile(t, repoCfgDir, "repos.yaml", `
+repos:
+- id: github.com/runatlantis/atlantis-example
+  workflow: custom
+workflows:
+  custom:
+    plan:
+      steps:
+      - run: echo hi
+      - plan
+`)
+	globalCfg, err := (&yaml.ParserValidator{}).ParseGlobalCfg(repoCfgFile, valid.NewGlobalCfg(false, false, false))
+	Ok(t, err)
+
+	runner, _, planRunner, runRunner := newRunner(t, globalCfg)
+	When(runRunner.Run(matchers.AnyModelsProjectCommandContext(), EqString("echo hi"), AnyString(), matchers.AnyMapOfStringToString())).ThenReturn("hi", nil)
+	When(planRunner.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).ThenReturn("plan output", nil)
+
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "main",
+	})
+	Ok(t, err)
+	Equals(t, 1, len(res.Result.ProjectResults))
+	Equals(t, "hi\nplan output", res.Result.ProjectResults[0].PlanSuccess.TerraformOutput)
+
+	out, err := res.JSON()
+	Ok(t, err)
+	var planJSON local.PlanJSON
+	Ok(t, json.Unmarshal(out, &planJSON))
+	Equals(t, []local.StepJSON{
+		{Name: "run", RunCommand: "echo hi"},
+		{Name: "plan"},
+	}, planJSON.Projects[0].Steps)
+	Equals(t, "planned", planJSON.Projects[0].Status)
+}
+
+func TestPlan_SkipAutoplanLabel(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "project1/main.tf", "# changed")
+	repoCfgDir, cleanupCfg := TempDir(t)
+	defer cleanupCfg()
+	writeFile(t, repoCfgDir, "repos.yaml", `
+repos:
+- id: /.*/
+  labels:
+    atlantis/skip:
+      skip_autoplan: true
+`)
+	globalCfg, err := (&yaml.ParserValidator{}).ParseGlobalCfg(filepath.Join(repoCfgDir, "repos.yaml"), valid.NewGlobalCfg(false, false, false))
+	Ok(t, err)
+
+	runner, _, planRunner, _ := newRunner(t, globalCfg)
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "main",
+		Labels:  []string{"atlantis/skip"},
+	})
+	Ok(t, err)
+	Equals(t, []string{"project1/main.tf"}, res.ModifiedFiles)
+	Equals(t, 0, len(res.Result.ProjectResults))
+	planRunner.VerifyWasCalled(Never()).Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())
+}
+
+func TestPlan_Redacts(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "project1/main.tf", "# changed")
+
+	runner, _, planRunner, _ := newRunner(t, valid.NewGlobalCfg(false, false, false))
+	redactor, err := events.NewSecretRedactor([]string{`password=(\S+)`}, nil)
+	Ok(t, err)
+	runner.Redactor = redactor
+	When(planRunner.Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())).ThenReturn("password=hunter2", nil)
+
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "main",
+	})
+	Ok(t, err)
+	Assert(t, !strings.Contains(res.Markdown(), "hunter2"), "exp markdown to be redacted, got %q", res.Markdown())
+	Assert(t, strings.Contains(res.Markdown(), "password="+events.RedactedText), "exp markdown to contain redacted output, got %q", res.Markdown())
+
+	out, err := res.JSON()
+	Ok(t, err)
+	var planJSON local.PlanJSON
+	Ok(t, json.Unmarshal(out, &planJSON))
+	Equals(t, "password="+events.RedactedText, planJSON.Projects[0].Output)
+}
+
+func TestPlan_InvalidRepoCfg(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+	writeFile(t, repoDir, "atlantis.yaml", "version: 3\nprojects:\n- dir: project1\n  unknown: key\n")
+
+	runner, _, planRunner, _ := newRunner(t, valid.NewGlobalCfg(false, false, false))
+	res, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "main",
+	})
+	Ok(t, err)
+	ErrContains(t, "parsing atlantis.yaml", res.Result.Error)
+	Assert(t, res.Result.HasErrors(), "exp result to have errors")
+	planRunner.VerifyWasCalled(Never()).Run(matchers.AnyModelsProjectCommandContext(), matchers.AnySliceOfString(), AnyString(), matchers.AnyMapOfStringToString())
+}
+
+func TestPlan_UnknownBaseRef(t *testing.T) {
+	repoDir, cleanup := initRepo(t)
+	defer cleanup()
+
+	runner, _, _, _ := newRunner(t, valid.NewGlobalCfg(false, false, false))
+	_, err := runner.Plan(local.PlanOptions{
+		RepoDir: repoDir,
+		BaseRef: "doesnotexist",
+	})
+	ErrContains(t, "running git merge-base doesnotexist HEAD", err)
+}
+
+func newRunner(t *testing.T, globalCfg valid.GlobalCfg) (*local.Runner, *mocks.MockStepRunner, *mocks.MockStepRunner, *mocks.MockCustomStepRunner) {
+	RegisterMockTestingT(t)
+	initRunner := mocks.NewMockStepRunner()
+	planRunner := mocks.NewMockStepRunner()
+	runRunner := mocks.NewMockCustomStepRunner()
+	return &local.Runner{
+		GlobalCfg:      globalCfg,
+		InitStepRunner: initRunner,
+		PlanStepRunner: planRunner,
+		RunStepRunner:  runRunner,
+		EnvStepRunner:  mocks.NewMockEnvStepRunner(),
+		Logger:         logging.NewNoopLogger(),
+	}, initRunner, planRunner, runRunner
+}
+
+// initRepo creates a repo with three projects on its main branch and checks
+// out the feature branch.
+func initRepo(t *testing.T) (string, func()) {
+	repoDir, cleanup := TempDir(t)
+	runCmd(t, repoDir, "git", "init")
+	runCmd(t, repoDir, "git", "checkout", "-b", "main")
+	runCmd(t, repoDir, "git", "config", "--local", "user.email", "atlantisbot@runatlantis.io")
+	runCmd(t, repoDir, "git", "config", "--local", "user.name", "atlantisbot")
+	runCmd(t, repoDir, "git", "remote", "add", "origin", "git@github.com:runatlantis/atlantis-example.git")
+	for _, dir := range []string{"project1", "project2", "project3"} {
+		writeFile(t, repoDir, filepath.Join(dir, "main.tf"), "")
+	}
+	runCmd(t, repoDir, "git", "add", ".")
+	runCmd(t, repoDir, "git", "commit", "-m", "initial commit")
+	runCmd(t, repoDir, "git", "checkout", "-b", "feature")
+	return repoDir, cleanup
+}
+
+func writeFile(t *testing.T, dir string, name string, contents string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	Ok(t, os.MkdirAll(filepath.Dir(path), 0700))
+	Ok(t, ioutil.WriteFile(path, []byte(contents), 0600))
+}
+
+func runCmd(t *testing.T, dir string, name string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	Assert(t, err == nil, "err running %q: %s", strings.Join(append([]string{name}, args...), " "), out)
+	return string(out)
+}
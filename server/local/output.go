@@ -0,0 +1,100 @@
+package local
+
+import (
+	"encoding/json"
+
+	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/models"
+)
+
+// PlanJSON is the JSON representation of a PlanResult.
+type PlanJSON struct {
+	Repo          string        `json:"repo"`
+	HeadCommit    string        `json:"head_commit"`
+	BaseRef       string        `json:"base_ref"`
+	ModifiedFiles []string      `json:"modified_files"`
+	Error         string        `json:"error,omitempty"`
+	Projects      []ProjectJSON `json:"projects"`
+}
+
+// ProjectJSON is the JSON representation of a project that was planned.
+type ProjectJSON struct {
+	Directory   string `json:"directory"`
+	Workspace   string `json:"workspace"`
+	ProjectName string `json:"project_name,omitempty"`
+	// TerraformVersion is empty if the project uses the default version.
+	TerraformVersion string     `json:"terraform_version,omitempty"`
+	Steps            []StepJSON `json:"steps"`
+	Status           string     `json:"status"`
+	Output           string     `json:"output,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	Failure          string     `json:"failure,omitempty"`
+}
+
+// StepJSON is the JSON representation of a step of a project's plan
+// workflow.
+type StepJSON struct {
+	Name        string   `json:"name"`
+	ExtraArgs   []string `json:"extra_args,omitempty"`
+	RunCommand  string   `json:"run_command,omitempty"`
+	EnvVarName  string   `json:"env_var_name,omitempty"`
+	EnvVarValue string   `json:"env_var_value,omitempty"`
+}
+
+// Markdown renders the result the same way Atlantis comments it on pull
+// requests.
+func (p *PlanResult) Markdown() string {
+	renderer := &events.MarkdownRenderer{Redactor: p.Redactor}
+	return renderer.Render(p.Result, models.PlanCommand, "", false, p.Repo.VCSHost.Type)
+}
+
+// JSON returns the result as indented JSON. Secrets are redacted from the
+// output, errors, run commands and env var values.
+func (p *PlanResult) JSON() ([]byte, error) {
+	out := PlanJSON{
+		Repo:          p.Repo.ID(),
+		HeadCommit:    p.Pull.HeadCommit,
+		BaseRef:       p.Pull.BaseBranch,
+		ModifiedFiles: p.ModifiedFiles,
+		Projects:      []ProjectJSON{},
+	}
+	if out.ModifiedFiles == nil {
+		out.ModifiedFiles = []string{}
+	}
+	if p.Result.Error != nil {
+		out.Error = p.Redactor.Redact(p.Result.Error.Error())
+	}
+	for i, res := range p.Result.ProjectResults {
+		proj := ProjectJSON{
+			Directory:   res.RepoRelDir,
+			Workspace:   res.Workspace,
+			ProjectName: res.ProjectName,
+			Steps:       []StepJSON{},
+			Status:      res.PlanStatus().String(),
+			Failure:     p.Redactor.Redact(res.Failure),
+		}
+		if res.Error != nil {
+			proj.Error = p.Redactor.Redact(res.Error.Error())
+		}
+		if res.PlanSuccess != nil {
+			proj.Output = p.Redactor.Redact(res.PlanSuccess.TerraformOutput)
+		}
+		if i < len(p.Projects) {
+			cmd := p.Projects[i]
+			if cmd.TerraformVersion != nil {
+				proj.TerraformVersion = cmd.TerraformVersion.String()
+			}
+			for _, step := range cmd.Steps {
+				proj.Steps = append(proj.Steps, StepJSON{
+					Name:        step.StepName,
+					ExtraArgs:   step.ExtraArgs,
+					RunCommand:  p.Redactor.Redact(step.RunCommand),
+					EnvVarName:  step.EnvVarName,
+					EnvVarValue: p.Redactor.Redact(step.EnvVarValue),
+				})
+			}
+		}
+		out.Projects = append(out.Projects, proj)
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
@@ -12,6 +12,8 @@ import (
 	"github.com/runatlantis/atlantis/server/events/locking"
 	"github.com/runatlantis/atlantis/server/events/models"
 	"github.com/runatlantis/atlantis/server/events/vcs"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
+	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 	"github.com/runatlantis/atlantis/server/logging"
 )
 
@@ -26,6 +28,16 @@ type LocksController struct {
 	WorkingDir         events.WorkingDir
 	WorkingDirLocker   events.WorkingDirLocker
 	DB                 *db.BoltDB
+	// OutputsURLGenerator generates the link to the locked project's plan
+	// history. If nil, no link is shown.
+	OutputsURLGenerator events.OutputsURLGenerator
+	// CommandAuthorizer checks that the user discarding a lock is allowed to
+	// unlock. The user is the one authenticated to the UI. If nil, everyone
+	// is allowed.
+	CommandAuthorizer events.CommandAuthorizer
+	// Webhooks sends webhooks when locks are discarded. If nil, no webhooks
+	// are sent.
+	Webhooks events.WebhooksSender
 }
 
 // GetLock is the GET /locks/{id} route. It renders the lock detail view.
@@ -63,6 +75,11 @@ func (l *LocksController) GetLock(w http.ResponseWriter, r *http.Request) {
 		RepoOwner:       owner,
 		RepoName:        repo,
 	}
+	// Locks created by old versions of Atlantis won't have a BaseRepo so we
+	// can't generate the URL.
+	if l.OutputsURLGenerator != nil && lock.Pull.BaseRepo != (models.Repo{}) {
+		viewData.OutputsURL = l.OutputsURLGenerator.GenerateProjectOutputsURL(lock.Pull, lock.Project.Path, lock.Workspace)
+	}
 
 	err = l.LockDetailTemplate.Execute(w, viewData)
 	if err != nil {
@@ -84,6 +101,9 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		l.respond(w, logging.Warn, http.StatusBadRequest, "Invalid lock id %q. Failed with error: %s", id, err)
 		return
 	}
+	if !l.authorizeUnlock(w, r, idUnencoded) {
+		return
+	}
 	lock, err := l.Locker.Unlock(idUnencoded)
 	if err != nil {
 		l.respond(w, logging.Error, http.StatusInternalServerError, "deleting lock failed with: %s", err)
@@ -93,6 +113,7 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		l.respond(w, logging.Info, http.StatusNotFound, "No lock found at id %q", idUnencoded)
 		return
 	}
+	l.sendLockReleasedWebhook(r, *lock)
 
 	// NOTE: Because BaseRepo was added to the PullRequest model later, previous
 	// installations of Atlantis will have locks in their DB that do not have
@@ -113,8 +134,13 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Once the lock has been deleted, comment back on the pull request.
-		comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via the Atlantis UI.\n\n"+
-			"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace)
+		// If the UI requires authentication, we also say who discarded it.
+		discardedBy := ""
+		if user := AuthenticatedUser(r); user != "" {
+			discardedBy = fmt.Sprintf(" by `%s`", user)
+		}
+		comment := fmt.Sprintf("**Warning**: The plan for dir: `%s` workspace: `%s` was **discarded** via the Atlantis UI%s.\n\n"+
+			"To `apply` this plan you must run `plan` again.", lock.Project.Path, lock.Workspace, discardedBy)
 		err = l.VCSClient.CreateComment(lock.Pull.BaseRepo, lock.Pull.Num, comment)
 		if err != nil {
 			l.respond(w, logging.Error, http.StatusInternalServerError, "Failed commenting on pull request: %s", err)
@@ -126,6 +152,67 @@ func (l *LocksController) DeleteLock(w http.ResponseWriter, r *http.Request) {
 	l.respond(w, logging.Info, http.StatusOK, "Deleted lock id %q", id)
 }
 
+// sendLockReleasedWebhook sends the webhooks for lock being discarded by the
+// user making request r.
+func (l *LocksController) sendLockReleasedWebhook(r *http.Request, lock models.ProjectLock) {
+	if l.Webhooks == nil {
+		return
+	}
+	err := l.Webhooks.Send(l.Logger, webhooks.Event{
+		Type:      webhooks.LockReleasedEvent,
+		Repo:      lock.Pull.BaseRepo,
+		Pull:      lock.Pull,
+		User:      models.User{Username: AuthenticatedUser(r)},
+		Directory: lock.Project.Path,
+		Workspace: lock.Workspace,
+		Success:   true,
+	})
+	if err != nil {
+		l.Logger.Warn("unable to send %s webhook: %s", webhooks.LockReleasedEvent, err)
+	}
+}
+
+// authorizeUnlock returns true if the user making request r is allowed to
+// delete the lock at id. If not, it responds with why.
+func (l *LocksController) authorizeUnlock(w http.ResponseWriter, r *http.Request, id string) bool {
+	if l.CommandAuthorizer == nil {
+		return true
+	}
+	lock, err := l.Locker.GetLock(id)
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "getting lock failed with: %s", err)
+		return false
+	}
+	// If the lock doesn't exist, let Unlock handle it.
+	if lock == nil {
+		return true
+	}
+	user := models.User{Username: AuthenticatedUser(r)}
+	// Locks from old installations without BaseRepo set can't be matched to
+	// a repo config so they can only be deleted here if unlocking isn't
+	// restricted on any repo.
+	if lock.Pull.BaseRepo == (models.Repo{}) {
+		if l.CommandAuthorizer.Restricted(valid.UnlockCommandKey) {
+			l.respond(w, logging.Warn, http.StatusForbidden, "Lock %q isn't linked to a repo so we can't check if user `%s` is allowed to unlock it. Comment `atlantis unlock` on its pull request instead.", id, user.Username)
+			return false
+		}
+		return true
+	}
+	reason, err := l.CommandAuthorizer.Authorize(lock.Pull.BaseRepo, user, valid.UnlockCommandKey)
+	if err != nil {
+		l.respond(w, logging.Error, http.StatusInternalServerError, "Unable to determine if user is allowed to unlock: %s", err)
+		return false
+	}
+	if reason != "" {
+		if user.Username == "" {
+			reason += " Authentication must be enabled for the web UI to identify users."
+		}
+		l.respond(w, logging.Warn, http.StatusForbidden, "%s", reason)
+		return false
+	}
+	return true
+}
+
 // respond is a helper function to respond and log the response. lvl is the log
 // level to log at, code is the HTTP response code.
 func (l *LocksController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
@@ -17,8 +17,10 @@ import (
 	"github.com/runatlantis/atlantis/server/events"
 	"github.com/runatlantis/atlantis/server/events/locking/mocks"
 	mocks2 "github.com/runatlantis/atlantis/server/events/mocks"
+	"github.com/runatlantis/atlantis/server/events/mocks/matchers"
 	"github.com/runatlantis/atlantis/server/events/models"
 	vcsmocks "github.com/runatlantis/atlantis/server/events/vcs/mocks"
+	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/logging"
 	sMocks "github.com/runatlantis/atlantis/server/mocks"
 	. "github.com/runatlantis/atlantis/testing"
@@ -192,6 +194,29 @@ func TestDeleteLock_OldFormat(t *testing.T) {
 	cp.VerifyWasCalled(Never()).CreateComment(AnyRepo(), AnyInt(), AnyString())
 }
 
+func TestDeleteLock_OldFormatRestricted(t *testing.T) {
+	t.Log("If the lock doesn't have BaseRepo set and unlocking is restricted, the lock should not be deleted")
+	RegisterMockTestingT(t)
+
+	l := mocks.NewMockLocker()
+	authorizer := mocks2.NewMockCommandAuthorizer()
+	When(l.GetLock("id")).ThenReturn(&models.ProjectLock{Workspace: "workspace"}, nil)
+	When(authorizer.Restricted("unlock")).ThenReturn(true)
+	lc := server.LocksController{
+		Locker:            l,
+		Logger:            logging.NewNoopLogger(),
+		VCSClient:         vcsmocks.NewMockClient(),
+		CommandAuthorizer: authorizer,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	lc.DeleteLock(w, req)
+	responseContains(t, w, http.StatusForbidden, "Lock \"id\" isn't linked to a repo")
+	l.VerifyWasCalled(Never()).Unlock(AnyString())
+	authorizer.VerifyWasCalled(Never()).Authorize(AnyRepo(), matchers.AnyModelsUser(), AnyString())
+}
+
 func TestDeleteLock_CommentFailed(t *testing.T) {
 	t.Log("If the commenting fails we return an error")
 	RegisterMockTestingT(t)
@@ -266,3 +291,130 @@ func TestDeleteLock_CommentSuccess(t *testing.T) {
 			"To `apply` this plan you must run `plan` again.")
 	workingDir.VerifyWasCalledOnce().DeleteForWorkspace(pull.BaseRepo, pull, "workspace")
 }
+
+func TestDeleteLock_Webhook(t *testing.T) {
+	t.Log("We should send a lock_released webhook if the lock is deleted")
+	RegisterMockTestingT(t)
+
+	l := mocks.NewMockLocker()
+	sender := mocks2.NewMockWebhooksSender()
+	pull := models.PullRequest{
+		Num:      1,
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+	}
+	When(l.Unlock("id")).ThenReturn(&models.ProjectLock{
+		Pull:      pull,
+		Workspace: "workspace",
+		Project: models.Project{
+			Path:         "path",
+			RepoFullName: "owner/repo",
+		},
+	}, nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	logger := logging.NewNoopLogger()
+	lc := server.LocksController{
+		Locker:           l,
+		Logger:           logger,
+		VCSClient:        vcsmocks.NewMockClient(),
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		WorkingDir:       mocks2.NewMockWorkingDir(),
+		DB:               db,
+		Webhooks:         sender,
+	}
+	req, _ := http.NewRequest("GET", "", bytes.NewBuffer(nil))
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	lc.DeleteLock(w, req)
+	responseContains(t, w, http.StatusOK, "Deleted lock id \"id\"")
+	sender.VerifyWasCalledOnce().Send(logger, webhooks.Event{
+		Type:      webhooks.LockReleasedEvent,
+		Repo:      pull.BaseRepo,
+		Pull:      pull,
+		Directory: "path",
+		Workspace: "workspace",
+		Success:   true,
+	})
+}
+
+func TestDeleteLock_CommentWithAuthenticatedUser(t *testing.T) {
+	t.Log("If the UI requires authentication, the comment should say who deleted the lock")
+	RegisterMockTestingT(t)
+
+	cp := vcsmocks.NewMockClient()
+	l := mocks.NewMockLocker()
+	workingDir := mocks2.NewMockWorkingDir()
+	pull := models.PullRequest{
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+	}
+	When(l.Unlock("id")).ThenReturn(&models.ProjectLock{
+		Pull:      pull,
+		Workspace: "workspace",
+		Project: models.Project{
+			Path:         "path",
+			RepoFullName: "owner/repo",
+		},
+	}, nil)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	db, err := db.New(tmp)
+	Ok(t, err)
+	lc := server.LocksController{
+		Locker:           l,
+		Logger:           logging.NewNoopLogger(),
+		VCSClient:        cp,
+		WorkingDirLocker: events.NewDefaultWorkingDirLocker(),
+		WorkingDir:       workingDir,
+		DB:               db,
+	}
+	auth := server.AuthMiddleware{
+		Authenticator: &server.BasicAuthenticator{Username: "jdoe", Password: "pass"},
+		Logger:        logging.NewNoopLogger(),
+	}
+	req, _ := http.NewRequest("DELETE", "/locks?id=id", bytes.NewBuffer(nil))
+	req.SetBasicAuth("jdoe", "pass")
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	auth.ServeHTTP(w, req, lc.DeleteLock)
+	responseContains(t, w, http.StatusOK, "Deleted lock id \"id\"")
+	cp.VerifyWasCalled(Once()).CreateComment(pull.BaseRepo, pull.Num,
+		"**Warning**: The plan for dir: `path` workspace: `workspace` was **discarded** via the Atlantis UI by `jdoe`.\n\n"+
+			"To `apply` this plan you must run `plan` again.")
+}
+
+func TestDeleteLock_Unauthorized(t *testing.T) {
+	t.Log("If the user isn't allowed to unlock, the lock should not be deleted")
+	RegisterMockTestingT(t)
+
+	cp := vcsmocks.NewMockClient()
+	l := mocks.NewMockLocker()
+	authorizer := mocks2.NewMockCommandAuthorizer()
+	pull := models.PullRequest{
+		BaseRepo: models.Repo{FullName: "owner/repo"},
+	}
+	When(l.GetLock("id")).ThenReturn(&models.ProjectLock{
+		Pull:      pull,
+		Workspace: "workspace",
+	}, nil)
+	When(authorizer.Authorize(pull.BaseRepo, models.User{Username: "jdoe"}, "unlock")).ThenReturn("User `jdoe` is not allowed to run `unlock` on this repo.", nil)
+	lc := server.LocksController{
+		Locker:            l,
+		Logger:            logging.NewNoopLogger(),
+		VCSClient:         cp,
+		CommandAuthorizer: authorizer,
+	}
+	auth := server.AuthMiddleware{
+		Authenticator: &server.BasicAuthenticator{Username: "jdoe", Password: "pass"},
+		Logger:        logging.NewNoopLogger(),
+	}
+	req, _ := http.NewRequest("DELETE", "/locks?id=id", bytes.NewBuffer(nil))
+	req.SetBasicAuth("jdoe", "pass")
+	req = mux.SetURLVars(req, map[string]string{"id": "id"})
+	w := httptest.NewRecorder()
+	auth.ServeHTTP(w, req, lc.DeleteLock)
+	responseContains(t, w, http.StatusForbidden, "User `jdoe` is not allowed to run `unlock` on this repo.")
+	l.VerifyWasCalled(Never()).Unlock(AnyString())
+	cp.VerifyWasCalled(Never()).CreateComment(AnyRepo(), AnyInt(), AnyString())
+}
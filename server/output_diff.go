@@ -0,0 +1,100 @@
+package server
+
+import (
+	"strings"
+)
+
+// maxDiffCells is the largest number of cells in the LCS table we'll compute
+// when diffing outputs. Past this we fall back to showing all lines as
+// removed and added so huge plans don't use too much memory.
+const maxDiffCells = 4 * 1000 * 1000
+
+// DiffLine is a single line in a diff between two outputs.
+type DiffLine struct {
+	// Kind is "+" if the line was added, "-" if it was removed or " " if
+	// it's unchanged.
+	Kind string
+	Text string
+}
+
+// diffLines returns a line by line diff that turns prev into curr.
+func diffLines(prev string, curr string) []DiffLine {
+	a := strings.Split(prev, "\n")
+	b := strings.Split(curr, "\n")
+
+	// Trim the common prefix and suffix since most consecutive plans are
+	// mostly the same. This keeps the LCS table small.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var diff []DiffLine
+	for _, l := range a[:prefix] {
+		diff = append(diff, DiffLine{Kind: " ", Text: l})
+	}
+	diff = append(diff, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		diff = append(diff, DiffLine{Kind: " ", Text: l})
+	}
+	return diff
+}
+
+// diffMiddle diffs a and b using the longest common subsequence.
+func diffMiddle(a []string, b []string) []DiffLine {
+	var diff []DiffLine
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, l := range a {
+			diff = append(diff, DiffLine{Kind: "-", Text: l})
+		}
+		for _, l := range b {
+			diff = append(diff, DiffLine{Kind: "+", Text: l})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Kind: " ", Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Kind: "-", Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Kind: "+", Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Kind: "-", Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Kind: "+", Text: b[j]})
+	}
+	return diff
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/runatlantis/atlantis/server/events/db"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+// ProjectOutputsPath is the path of the project outputs view.
+const ProjectOutputsPath = "/outputs"
+
+// Query parameters for the project outputs view. Together they identify a
+// single project in a pull request.
+const (
+	outputsHostQueryParam      = "host"
+	outputsRepoQueryParam      = "repo"
+	outputsPullQueryParam      = "pull"
+	outputsDirQueryParam       = "dir"
+	outputsWorkspaceQueryParam = "workspace"
+)
+
+// OutputsController handles requests for the plan and apply history of
+// projects.
+type OutputsController struct {
+	AtlantisVersion string
+	AtlantisURL     *url.URL
+	Logger          *logging.SimpleLogger
+	OutputsTemplate TemplateWriter
+	DB              *db.BoltDB
+}
+
+// GetProjectOutputs is the GET /outputs route. It renders the outputs of
+// the project, newest first, along with the diff between each plan and the
+// plan before it.
+func (o *OutputsController) GetProjectOutputs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	repoFullName := query.Get(outputsRepoQueryParam)
+	dir := query.Get(outputsDirQueryParam)
+	workspace := query.Get(outputsWorkspaceQueryParam)
+	if repoFullName == "" || dir == "" || workspace == "" {
+		o.respond(w, logging.Warn, http.StatusBadRequest, "Missing %s, %s or %s query parameter", outputsRepoQueryParam, outputsDirQueryParam, outputsWorkspaceQueryParam)
+		return
+	}
+	pullNum, err := strconv.Atoi(query.Get(outputsPullQueryParam))
+	if err != nil {
+		o.respond(w, logging.Warn, http.StatusBadRequest, "Invalid %s query parameter: %s", outputsPullQueryParam, err)
+		return
+	}
+	pull := models.PullRequest{
+		Num: pullNum,
+		BaseRepo: models.Repo{
+			FullName: repoFullName,
+			VCSHost: models.VCSHost{
+				Hostname: query.Get(outputsHostQueryParam),
+			},
+		},
+	}
+
+	outputs, err := o.DB.GetProjectOutputs(pull, dir, workspace)
+	if err != nil {
+		o.respond(w, logging.Error, http.StatusInternalServerError, "Failed getting outputs: %s", err)
+		return
+	}
+
+	var outputData []ProjectOutputData
+	var projectName string
+	var prevPlan *models.ProjectOutput
+	for i := range outputs {
+		out := outputs[i]
+		projectName = out.ProjectName
+		data := ProjectOutputData{
+			Command:       out.Command.String(),
+			Status:        out.Status.String(),
+			HeadCommit:    out.HeadCommit,
+			Output:        out.Output,
+			Time:          out.Time,
+			TimeFormatted: out.Time.Local().Format("02-01-2006 15:04:05"),
+		}
+		if out.Command == models.PlanCommand {
+			if prevPlan != nil {
+				data.Diff = diffLines(prevPlan.Output, out.Output)
+				data.DiffCommit = prevPlan.HeadCommit
+			}
+			prevPlan = &outputs[i]
+		}
+		outputData = append(outputData, data)
+	}
+	// Show the newest first.
+	for i, j := 0, len(outputData)-1; i < j; i, j = i+1, j-1 {
+		outputData[i], outputData[j] = outputData[j], outputData[i]
+	}
+
+	err = o.OutputsTemplate.Execute(w, ProjectOutputsData{
+		RepoFullName:    repoFullName,
+		PullNum:         pullNum,
+		RepoRelDir:      dir,
+		Workspace:       workspace,
+		ProjectName:     projectName,
+		Outputs:         outputData,
+		AtlantisVersion: o.AtlantisVersion,
+		CleanedBasePath: o.AtlantisURL.Path,
+	})
+	if err != nil {
+		o.Logger.Err(err.Error())
+	}
+}
+
+func (o *OutputsController) respond(w http.ResponseWriter, lvl logging.LogLevel, responseCode int, format string, args ...interface{}) {
+	response := fmt.Sprintf(format, args...)
+	o.Logger.Log(lvl, response)
+	w.WriteHeader(responseCode)
+	fmt.Fprintln(w, response)
+}
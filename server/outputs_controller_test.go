@@ -0,0 +1,110 @@
+package server_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/petergtz/pegomock"
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/events/db"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/logging"
+	sMocks "github.com/runatlantis/atlantis/server/mocks"
+	"github.com/runatlantis/atlantis/server/mocks/matchers"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestGetProjectOutputs_BadRequest(t *testing.T) {
+	cases := []string{
+		"/outputs",
+		"/outputs?repo=owner/repo&dir=.&workspace=default",
+		"/outputs?repo=owner/repo&pull=abc&dir=.&workspace=default",
+		"/outputs?repo=owner/repo&pull=1&workspace=default",
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			o := server.OutputsController{
+				Logger: logging.NewNoopLogger(),
+			}
+			req, _ := http.NewRequest("GET", c, bytes.NewBuffer(nil))
+			w := httptest.NewRecorder()
+			o.GetProjectOutputs(w, req)
+			Equals(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestGetProjectOutputs_Success(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+
+	pull := models.PullRequest{
+		Num: 1,
+		BaseRepo: models.Repo{
+			FullName: "owner/repo",
+			VCSHost:  models.VCSHost{Hostname: "github.com", Type: models.Github},
+		},
+	}
+	now := time.Now()
+	outputs := []models.ProjectOutput{
+		{Command: models.PlanCommand, Status: models.PlannedPlanStatus, HeadCommit: "sha1", Output: "a\nb\nc", Time: now.Add(-3 * time.Minute)},
+		{Command: models.PlanCommand, Status: models.PlannedPlanStatus, HeadCommit: "sha2", Output: "a\nB\nc", Time: now.Add(-2 * time.Minute)},
+		{Command: models.ApplyCommand, Status: models.AppliedPlanStatus, HeadCommit: "sha2", Output: "applied", Time: now.Add(-time.Minute)},
+	}
+	for _, o := range outputs {
+		o.RepoRelDir = "dir"
+		o.Workspace = "default"
+		o.ProjectName = "project"
+		Ok(t, boltdb.AddProjectOutput(pull, o))
+	}
+
+	tmpl := sMocks.NewMockTemplateWriter()
+	u, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	o := server.OutputsController{
+		AtlantisVersion: "1.0.0",
+		AtlantisURL:     u,
+		Logger:          logging.NewNoopLogger(),
+		OutputsTemplate: tmpl,
+		DB:              boltdb,
+	}
+	req, _ := http.NewRequest("GET", "/outputs?host=github.com&repo=owner/repo&pull=1&dir=dir&workspace=default", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	o.GetProjectOutputs(w, req)
+	_, data := tmpl.VerifyWasCalledOnce().Execute(matchers.AnyIoWriter(), AnyInterface()).GetCapturedArguments()
+	outputsData := data.(server.ProjectOutputsData)
+
+	Equals(t, "owner/repo", outputsData.RepoFullName)
+	Equals(t, 1, outputsData.PullNum)
+	Equals(t, "dir", outputsData.RepoRelDir)
+	Equals(t, "default", outputsData.Workspace)
+	Equals(t, "project", outputsData.ProjectName)
+	Equals(t, "/basepath", outputsData.CleanedBasePath)
+	Equals(t, 3, len(outputsData.Outputs))
+
+	// Newest first.
+	Equals(t, "apply", outputsData.Outputs[0].Command)
+	Equals(t, "applied", outputsData.Outputs[0].Status)
+	Equals(t, 0, len(outputsData.Outputs[0].Diff))
+
+	Equals(t, "plan", outputsData.Outputs[1].Command)
+	Equals(t, "sha2", outputsData.Outputs[1].HeadCommit)
+	Equals(t, "sha1", outputsData.Outputs[1].DiffCommit)
+	Equals(t, []server.DiffLine{
+		{Kind: " ", Text: "a"},
+		{Kind: "-", Text: "b"},
+		{Kind: "+", Text: "B"},
+		{Kind: " ", Text: "c"},
+	}, outputsData.Outputs[1].Diff)
+
+	// The first plan has nothing to diff against.
+	Equals(t, "sha1", outputsData.Outputs[2].HeadCommit)
+	Equals(t, 0, len(outputsData.Outputs[2].Diff))
+}
@@ -2,8 +2,11 @@ package server
 
 import (
 	"net/url"
+	"strconv"
 
 	"github.com/gorilla/mux"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
 )
 
 // Router can be used to retrieve Atlantis URLs. It acts as an intermediary
@@ -33,3 +36,22 @@ func (r *Router) GenerateLockURL(lockID string) string {
 	// golang likes to double escape the lockURL path when using url.Parse().
 	return r.AtlantisURL.String() + lockURL.String()
 }
+
+// GenerateProjectOutputsURL returns a fully qualified URL to view the plan and
+// apply history of the project at repoRelDir and workspace in pull.
+func (r *Router) GenerateProjectOutputsURL(pull models.PullRequest, repoRelDir string, workspace string) string {
+	params := url.Values{
+		outputsHostQueryParam:      {pull.BaseRepo.VCSHost.Hostname},
+		outputsRepoQueryParam:      {pull.BaseRepo.FullName},
+		outputsPullQueryParam:      {strconv.Itoa(pull.Num)},
+		outputsDirQueryParam:       {repoRelDir},
+		outputsWorkspaceQueryParam: {workspace},
+	}
+	return r.AtlantisURL.String() + ProjectOutputsPath + "?" + params.Encode()
+}
+
+// GenerateProjectJobURL returns a fully qualified URL to view the output of
+// the command running for the project described by ctx.
+func (r *Router) GenerateProjectJobURL(ctx models.ProjectCommandContext) string {
+	return r.AtlantisURL.String() + JobsPathPrefix + jobs.JobID(ctx.Pull, ctx.RepoRelDir, ctx.Workspace, ctx.ProjectName)
+}
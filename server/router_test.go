@@ -6,6 +6,8 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/events/models"
+	"github.com/runatlantis/atlantis/server/jobs"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -60,3 +62,39 @@ func TestRouter_GenerateLockURL(t *testing.T) {
 		})
 	}
 }
+
+func TestRouter_GenerateProjectOutputsURL(t *testing.T) {
+	atlantisURL, err := server.ParseAtlantisURL("https://example.com/basepath/")
+	Ok(t, err)
+	router := &server.Router{AtlantisURL: atlantisURL}
+	pull := models.PullRequest{
+		Num: 1,
+		BaseRepo: models.Repo{
+			FullName: "lkysow/atlantis-example",
+			VCSHost:  models.VCSHost{Hostname: "github.com"},
+		},
+	}
+	Equals(t,
+		"https://example.com/basepath/outputs?dir=path%2Fto%2Fdir&host=github.com&pull=1&repo=lkysow%2Fatlantis-example&workspace=default",
+		router.GenerateProjectOutputsURL(pull, "path/to/dir", "default"))
+}
+
+func TestRouter_GenerateProjectJobURL(t *testing.T) {
+	atlantisURL, err := server.ParseAtlantisURL("https://example.com/basepath/")
+	Ok(t, err)
+	router := &server.Router{AtlantisURL: atlantisURL}
+	ctx := models.ProjectCommandContext{
+		Pull: models.PullRequest{
+			Num: 1,
+			BaseRepo: models.Repo{
+				FullName: "lkysow/atlantis-example",
+				VCSHost:  models.VCSHost{Hostname: "github.com"},
+			},
+		},
+		RepoRelDir: "path/to/dir",
+		Workspace:  "default",
+	}
+	Equals(t,
+		"https://example.com/basepath/jobs/"+jobs.JobID(ctx.Pull, "path/to/dir", "default", ""),
+		router.GenerateProjectJobURL(ctx))
+}
@@ -17,9 +17,9 @@ package server
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
@@ -45,8 +45,10 @@ import (
 	"github.com/runatlantis/atlantis/server/events/vcs"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketcloud"
 	"github.com/runatlantis/atlantis/server/events/vcs/bitbucketserver"
+	"github.com/runatlantis/atlantis/server/events/vcs/gitea"
 	"github.com/runatlantis/atlantis/server/events/webhooks"
 	"github.com/runatlantis/atlantis/server/events/yaml"
+	"github.com/runatlantis/atlantis/server/jobs"
 	"github.com/runatlantis/atlantis/server/logging"
 	"github.com/runatlantis/atlantis/server/static"
 	"github.com/urfave/cli"
@@ -62,8 +64,25 @@ const (
 	// route. ex:
 	//   mux.Router.Get(LockViewRouteName).URL(LockViewRouteIDQueryParam, "my id")
 	LockViewRouteIDQueryParam = "id"
+	// PullsRepoQueryParam filters the pulls view to a single repo, ex.
+	// /pulls?repo=runatlantis/atlantis.
+	PullsRepoQueryParam = "repo"
+	// PullsStatusQueryParam filters the pulls view to pull requests that
+	// have a project with that status, ex. /pulls?status=planned.
+	PullsStatusQueryParam = "status"
+	// webhooksShutdownTimeout is how long to wait for queued webhooks to be
+	// delivered when shutting down.
+	webhooksShutdownTimeout = 30 * time.Second
 )
 
+// pullStatusFilters are the statuses the pulls view can be filtered by.
+var pullStatusFilters = []string{
+	models.ErroredPlanStatus.String(),
+	models.PlannedPlanStatus.String(),
+	models.ErroredApplyStatus.String(),
+	models.AppliedPlanStatus.String(),
+}
+
 // Server runs the Atlantis web server.
 type Server struct {
 	AtlantisVersion    string
@@ -75,10 +94,25 @@ type Server struct {
 	Locker             locking.Locker
 	EventsController   *EventsController
 	LocksController    *LocksController
+	OutputsController  *OutputsController
+	JobsController     *JobsController
 	IndexTemplate      TemplateWriter
 	LockDetailTemplate TemplateWriter
+	PullsTemplate      TemplateWriter
+	DB                 *db.BoltDB
 	SSLCertFile        string
 	SSLKeyFile         string
+	// HealthChecks are run by both /healthz and /readyz. Like all checks,
+	// they must not have side effects since probes run them often.
+	HealthChecks []HealthCheck
+	// ReadinessChecks are only run by /readyz. They check disk space and
+	// external dependencies that a restart wouldn't fix.
+	ReadinessChecks []HealthCheck
+	// WebAuthenticator authenticates requests to the web UI and locks API.
+	// If nil, authentication is disabled.
+	WebAuthenticator WebAuthenticator
+	// Webhooks is closed on shutdown so queued webhooks are delivered.
+	Webhooks *webhooks.MultiWebhookSender
 }
 
 // Config holds config for server that isn't passed in by the user.
@@ -95,15 +129,34 @@ type Config struct {
 type WebhookConfig struct {
 	// Event is the type of event we should send this webhook for, ex. apply.
 	Event string `mapstructure:"event"`
+	// Events are the types of events we should send this webhook for, ex.
+	// [plan_finished, apply_finished]. They're combined with Event.
+	Events []string `mapstructure:"events"`
 	// WorkspaceRegex is a regex that is used to match against the workspace
 	// that is being modified for this event. If the regex matches, we'll
 	// send the webhook, ex. "production.*".
 	WorkspaceRegex string `mapstructure:"workspace-regex"`
+	// RepoRegex is a regex that is matched against the repo ID of the event,
+	// ex. "github.com/owner/.*".
+	RepoRegex string `mapstructure:"repo-regex"`
+	// ProjectRegex is a regex that is matched against the name of the project
+	// of the event.
+	ProjectRegex string `mapstructure:"project-regex"`
 	// Kind is the type of webhook we should send, ex. slack.
 	Kind string `mapstructure:"kind"`
 	// Channel is the channel to send this webhook to. It only applies to
 	// slack webhooks. Should be without '#'.
 	Channel string `mapstructure:"channel"`
+	// URL is the URL to POST to. It only applies to http webhooks.
+	URL string `mapstructure:"url"`
+	// Headers are added to each request. They only apply to http webhooks.
+	Headers map[string]string `mapstructure:"headers"`
+	// Secret is used to sign the payload with HMAC-SHA256. It only applies
+	// to http webhooks. If empty, payloads aren't signed.
+	Secret string `mapstructure:"secret"`
+	// To are the addresses to send emails to. It only applies to email
+	// webhooks.
+	To []string `mapstructure:"to"`
 }
 
 // NewServer returns a new server. If there are issues starting the server or
@@ -117,7 +170,23 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 	var bitbucketCloudClient *bitbucketcloud.Client
 	var bitbucketServerClient *bitbucketserver.Client
 	var azuredevopsClient *vcs.AzureDevopsClient
-	if userConfig.GithubUser != "" {
+	var giteaClient *gitea.Client
+	var githubAppCredentials *vcs.GithubAppCredentials
+	if userConfig.GithubAppID != 0 {
+		supportedVCSHosts = append(supportedVCSHosts, models.Github)
+		key, err := ioutil.ReadFile(userConfig.GithubAppKeyFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading GitHub App private key from %s", userConfig.GithubAppKeyFile)
+		}
+		githubAppCredentials, err = vcs.NewGithubAppCredentials(userConfig.GithubHostname, int64(userConfig.GithubAppID), key, userConfig.GithubAppSlug)
+		if err != nil {
+			return nil, err
+		}
+		githubClient, err = vcs.NewGithubClientWithCredentials(userConfig.GithubHostname, githubAppCredentials)
+		if err != nil {
+			return nil, err
+		}
+	} else if userConfig.GithubUser != "" {
 		supportedVCSHosts = append(supportedVCSHosts, models.Github)
 		var err error
 		githubClient, err = vcs.NewGithubClient(userConfig.GithubHostname, userConfig.GithubUser, userConfig.GithubToken)
@@ -163,6 +232,14 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, err
 		}
 	}
+	if userConfig.GiteaUser != "" {
+		supportedVCSHosts = append(supportedVCSHosts, models.Gitea)
+		var err error
+		giteaClient, err = gitea.NewClient(http.DefaultClient, userConfig.GiteaToken, userConfig.GiteaBaseURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "setting up Gitea client")
+		}
+	}
 
 	if userConfig.WriteGitCreds {
 		home, err := homedir.Dir()
@@ -195,6 +272,15 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				return nil, err
 			}
 		}
+		if userConfig.GiteaUser != "" {
+			giteaURL, err := url.Parse(userConfig.GiteaBaseURL)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parsing %s", userConfig.GiteaBaseURL)
+			}
+			if err := events.WriteGitCreds(userConfig.GiteaUser, userConfig.GiteaToken, giteaURL.Host, home, logger); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	var webhooksConfig []webhooks.Config
@@ -202,17 +288,35 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		config := webhooks.Config{
 			Channel:        c.Channel,
 			Event:          c.Event,
+			Events:         c.Events,
 			Kind:           c.Kind,
 			WorkspaceRegex: c.WorkspaceRegex,
+			RepoRegex:      c.RepoRegex,
+			ProjectRegex:   c.ProjectRegex,
+			URL:            c.URL,
+			Headers:        c.Headers,
+			Secret:         c.Secret,
+			To:             c.To,
 		}
 		webhooksConfig = append(webhooksConfig, config)
 	}
-	webhooksManager, err := webhooks.NewMultiWebhookSender(webhooksConfig, webhooks.NewSlackClient(userConfig.SlackToken))
+	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient, giteaClient)
+	redactor, err := events.NewSecretRedactor(userConfig.RedactPatterns, strings.Split(userConfig.RedactEnvVars, ","))
 	if err != nil {
-		return nil, errors.Wrap(err, "initializing webhooks")
+		return nil, errors.Wrap(err, "initializing secret redaction")
+	}
+	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName, Redactor: redactor}
+	// projectStatusUpdater sets each project's status while its commands run.
+	// Check runs are always per project but per project commit statuses
+	// clutter the pull request so they have to be enabled.
+	var projectStatusUpdater events.CommitStatusUpdater
+	if githubAppCredentials != nil {
+		// Only GitHub Apps can create check runs.
+		commitStatusUpdater.ChecksClient = githubClient
+		projectStatusUpdater = commitStatusUpdater
+	} else if userConfig.ProjectStatuses {
+		projectStatusUpdater = commitStatusUpdater
 	}
-	vcsClient := vcs.NewClientProxy(githubClient, gitlabClient, bitbucketCloudClient, bitbucketServerClient, azuredevopsClient)
-	commitStatusUpdater := &events.DefaultCommitStatusUpdater{Client: vcsClient, StatusName: userConfig.VCSStatusName}
 	terraformClient, err := terraform.NewClient(
 		logger,
 		userConfig.DataDir,
@@ -232,21 +336,42 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		GitlabSupportsCommonMark: gitlabClient.SupportsCommonMark(),
 		DisableApplyAll:          userConfig.DisableApplyAll,
 		DisableMarkdownFolding:   userConfig.DisableMarkdownFolding,
+		Redactor:                 redactor,
 	}
 
 	boltdb, err := db.New(userConfig.DataDir)
 	if err != nil {
 		return nil, err
 	}
+	webhooksManager, err := webhooks.NewMultiWebhookSender(webhooksConfig, webhooks.NewSlackClient(userConfig.SlackToken), boltdb, webhooks.NewSMTPClient(userConfig.SMTPHost, userConfig.SMTPUser, userConfig.SMTPPassword, userConfig.SMTPFrom, userConfig.SMTPRequireTLS))
+	if err != nil {
+		return nil, errors.Wrap(err, "initializing webhooks")
+	}
 	lockingClient := locking.NewClient(boltdb)
 	workingDirLocker := events.NewDefaultWorkingDirLocker()
 	workingDir := &events.FileWorkspace{
 		DataDir:       userConfig.DataDir,
 		CheckoutMerge: userConfig.CheckoutStrategy == "merge",
 	}
+	githubUser := userConfig.GithubUser
+	commentGithubUser := userConfig.GithubUser
+	if githubAppCredentials != nil {
+		// GitHub App installation tokens expire so rather than putting them in
+		// clone URLs, the working dir gets a current one before each clone.
+		workingDir.GithubCredentials = githubAppCredentials
+		githubUser = "x-access-token"
+		// Apps are mentioned by their slug. If --gh-app-slug isn't set, it's
+		// looked up from the app's bot user, ex. "atlantis[bot]".
+		appUser, err := githubAppCredentials.GetUser()
+		if err != nil {
+			return nil, errors.Wrap(err, "getting GitHub App's user")
+		}
+		commentGithubUser = strings.TrimSuffix(appUser, "[bot]")
+	}
 	projectLocker := &events.DefaultProjectLocker{
 		Locker:    lockingClient,
 		VCSClient: vcsClient,
+		Webhooks:  webhooksManager,
 	}
 	parsedURL, err := ParseAtlantisURL(userConfig.AtlantisURL)
 	if err != nil {
@@ -267,6 +392,10 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			return nil, errors.Wrapf(err, "parsing --%s", config.RepoConfigJSONFlag)
 		}
 	}
+	commandAuthorizer := &events.DefaultCommandAuthorizer{
+		GlobalCfg: globalCfg,
+		VCSClient: vcsClient,
+	}
 
 	underlyingRouter := mux.NewRouter()
 	router := &Router{
@@ -275,15 +404,18 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		LockViewRouteName:         LockViewRouteName,
 		Underlying:                underlyingRouter,
 	}
+	jobsManager := jobs.NewManager(redactor)
 	pullClosedExecutor := &events.PullClosedExecutor{
 		VCSClient:  vcsClient,
 		Locker:     lockingClient,
 		WorkingDir: workingDir,
 		Logger:     logger,
 		DB:         boltdb,
+		JobTracker: jobsManager,
+		Webhooks:   webhooksManager,
 	}
 	eventParser := &events.EventParser{
-		GithubUser:         userConfig.GithubUser,
+		GithubUser:         githubUser,
 		GithubToken:        userConfig.GithubToken,
 		GitlabUser:         userConfig.GitlabUser,
 		GitlabToken:        userConfig.GitlabToken,
@@ -292,12 +424,15 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		BitbucketServerURL: userConfig.BitbucketBaseURL,
 		AzureDevopsUser:    userConfig.AzureDevopsUser,
 		AzureDevopsToken:   userConfig.AzureDevopsToken,
+		GiteaUser:          userConfig.GiteaUser,
+		GiteaToken:         userConfig.GiteaToken,
 	}
 	commentParser := &events.CommentParser{
-		GithubUser:      userConfig.GithubUser,
+		GithubUser:      commentGithubUser,
 		GitlabUser:      userConfig.GitlabUser,
 		BitbucketUser:   userConfig.BitbucketUser,
 		AzureDevopsUser: userConfig.AzureDevopsUser,
+		GiteaUser:       userConfig.GiteaUser,
 	}
 	defaultTfVersion := terraformClient.DefaultVersion()
 	pendingPlanFinder := &events.DefaultPendingPlanFinder{}
@@ -306,22 +441,36 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		DefaultTFVersion:  defaultTfVersion,
 		TerraformBinDir:   terraformClient.TerraformBinDir(),
 	}
+	var stickyComments *events.StickyCommenter
+	if userConfig.StickyComments != "" {
+		stickyComments = &events.StickyCommenter{
+			VCSClient: vcsClient,
+			Mode:      userConfig.StickyComments,
+		}
+	}
 	commandRunner := &events.DefaultCommandRunner{
 		VCSClient:                vcsClient,
 		GithubPullGetter:         githubClient,
 		GitlabMergeRequestGetter: gitlabClient,
 		AzureDevopsPullGetter:    azuredevopsClient,
+		GiteaPullGetter:          giteaClient,
 		CommitStatusUpdater:      commitStatusUpdater,
 		EventParser:              eventParser,
 		MarkdownRenderer:         markdownRenderer,
 		Logger:                   logger,
+		Redactor:                 redactor,
 		AllowForkPRs:             userConfig.AllowForkPRs,
 		AllowForkPRsFlag:         config.AllowForkPRsFlag,
 		HidePrevPlanComments:     userConfig.HidePrevPlanComments,
+		StickyComments:           stickyComments,
 		SilenceForkPRErrors:      userConfig.SilenceForkPRErrors,
 		SilenceForkPRErrorsFlag:  config.SilenceForkPRErrorsFlag,
 		SilenceVCSStatusNoPlans:  userConfig.SilenceVCSStatusNoPlans,
 		DisableApplyAll:          userConfig.DisableApplyAll,
+		GlobalCfg:                globalCfg,
+		PullCleaner:              pullClosedExecutor,
+		Webhooks:                 webhooksManager,
+		JobURLGenerator:          router,
 		ProjectCommandBuilder: &events.DefaultProjectCommandBuilder{
 			ParserValidator:   validator,
 			ProjectFinder:     &events.DefaultProjectFinder{},
@@ -333,8 +482,9 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 			CommentBuilder:    commentParser,
 		},
 		ProjectCommandRunner: &events.DefaultProjectCommandRunner{
-			Locker:           projectLocker,
-			LockURLGenerator: router,
+			Locker:              projectLocker,
+			LockURLGenerator:    router,
+			OutputsURLGenerator: router,
 			InitStepRunner: &runtime.InitStepRunner{
 				TerraformExecutor: terraformClient,
 				DefaultTFVersion:  defaultTfVersion,
@@ -344,40 +494,67 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 				DefaultTFVersion:    defaultTfVersion,
 				CommitStatusUpdater: commitStatusUpdater,
 				AsyncTFExec:         terraformClient,
+				OutputWriter:        jobsManager,
 			},
 			ApplyStepRunner: &runtime.ApplyStepRunner{
 				TerraformExecutor:   terraformClient,
 				CommitStatusUpdater: commitStatusUpdater,
 				AsyncTFExec:         terraformClient,
+				OutputWriter:        jobsManager,
 			},
 			RunStepRunner: runStepRunner,
 			EnvStepRunner: &runtime.EnvStepRunner{
 				RunStepRunner: runStepRunner,
 			},
 			PullApprovedChecker: vcsClient,
+			CommitChecksGetter:  vcsClient,
+			StatusName:          userConfig.VCSStatusName,
 			WorkingDir:          workingDir,
-			Webhooks:            webhooksManager,
 			WorkingDirLocker:    workingDirLocker,
+			JobTracker:          jobsManager,
+			JobURLGenerator:     router,
+			CommitStatusUpdater: projectStatusUpdater,
+			Webhooks:            webhooksManager,
+			Redactor:            redactor,
 		},
 		WorkingDir:        workingDir,
+		WorkingDirLocker:  workingDirLocker,
 		PendingPlanFinder: pendingPlanFinder,
 		DB:                boltdb,
 		GlobalAutomerge:   userConfig.Automerge,
+		CommandAuthorizer: commandAuthorizer,
 	}
 	repoWhitelist, err := events.NewRepoWhitelistChecker(userConfig.RepoWhitelist)
 	if err != nil {
 		return nil, err
 	}
 	locksController := &LocksController{
-		AtlantisVersion:    config.AtlantisVersion,
-		AtlantisURL:        parsedURL,
-		Locker:             lockingClient,
-		Logger:             logger,
-		VCSClient:          vcsClient,
-		LockDetailTemplate: lockTemplate,
-		WorkingDir:         workingDir,
-		WorkingDirLocker:   workingDirLocker,
-		DB:                 boltdb,
+		AtlantisVersion:     config.AtlantisVersion,
+		AtlantisURL:         parsedURL,
+		Locker:              lockingClient,
+		Logger:              logger,
+		VCSClient:           vcsClient,
+		LockDetailTemplate:  lockTemplate,
+		WorkingDir:          workingDir,
+		WorkingDirLocker:    workingDirLocker,
+		DB:                  boltdb,
+		OutputsURLGenerator: router,
+		CommandAuthorizer:   commandAuthorizer,
+		Webhooks:            webhooksManager,
+	}
+	outputsController := &OutputsController{
+		AtlantisVersion: config.AtlantisVersion,
+		AtlantisURL:     parsedURL,
+		Logger:          logger,
+		OutputsTemplate: outputsTemplate,
+		DB:              boltdb,
+	}
+	jobsController := &JobsController{
+		AtlantisVersion: config.AtlantisVersion,
+		AtlantisURL:     parsedURL,
+		Logger:          logger,
+		JobsManager:     jobsManager,
+		JobTemplate:     jobTemplate,
 	}
 	eventsController := &EventsController{
 		CommandRunner:                   commandRunner,
@@ -397,6 +574,60 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		AzureDevopsWebhookBasicUser:     []byte(userConfig.AzureDevopsWebhookUser),
 		AzureDevopsWebhookBasicPassword: []byte(userConfig.AzureDevopsWebhookPassword),
 		AzureDevopsRequestValidator:     &DefaultAzureDevopsRequestValidator{},
+		GiteaWebhookSecret:              []byte(userConfig.GiteaWebhookSecret),
+		ReplanOnBaseBranchPush:          userConfig.CheckoutStrategy == "merge",
+		GlobalCfg:                       globalCfg,
+		OpenedPulls:                     NewOpenedPulls(),
+	}
+	var webAuthenticator WebAuthenticator
+	if userConfig.WebBasicAuth {
+		webAuthenticator = &BasicAuthenticator{
+			Username: userConfig.WebUsername,
+			Password: userConfig.WebPassword,
+		}
+	} else if userConfig.WebOIDCIssuerURL != "" {
+		webAuthenticator, err = NewOIDCAuthenticator(
+			http.DefaultClient,
+			userConfig.WebOIDCIssuerURL,
+			userConfig.WebOIDCClientID,
+			userConfig.WebOIDCClientSecret,
+			parsedURL)
+		if err != nil {
+			return nil, errors.Wrap(err, "initializing OIDC authentication")
+		}
+	}
+	healthChecks := []HealthCheck{
+		{Name: "boltdb", Check: boltdb.Ping},
+	}
+	readinessChecks := []HealthCheck{
+		{Name: "disk", Check: DiskSpaceCheck(userConfig.DataDir, MinFreeDiskBytes)},
+		{
+			Name: "terraform",
+			Check: func() error {
+				if terraformClient == nil {
+					return errors.New("terraform client not initialized")
+				}
+				return terraformClient.HasVersion(nil)
+			},
+		},
+	}
+	if githubClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "github", Check: githubClient.Ping})
+	}
+	if gitlabClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "gitlab", Check: gitlabClient.Ping})
+	}
+	if bitbucketCloudClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "bitbucket-cloud", Check: bitbucketCloudClient.Ping})
+	}
+	if bitbucketServerClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "bitbucket-server", Check: bitbucketServerClient.Ping})
+	}
+	if azuredevopsClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "azuredevops", Check: azuredevopsClient.Ping})
+	}
+	if giteaClient != nil {
+		readinessChecks = append(readinessChecks, HealthCheck{Name: "gitea", Check: giteaClient.Ping})
 	}
 	return &Server{
 		AtlantisVersion:    config.AtlantisVersion,
@@ -408,10 +639,18 @@ func NewServer(userConfig UserConfig, config Config) (*Server, error) {
 		Locker:             lockingClient,
 		EventsController:   eventsController,
 		LocksController:    locksController,
+		OutputsController:  outputsController,
+		JobsController:     jobsController,
 		IndexTemplate:      indexTemplate,
 		LockDetailTemplate: lockTemplate,
+		PullsTemplate:      pullsTemplate,
+		DB:                 boltdb,
 		SSLKeyFile:         userConfig.SSLKeyFile,
 		SSLCertFile:        userConfig.SSLCertFile,
+		HealthChecks:       healthChecks,
+		ReadinessChecks:    readinessChecks,
+		WebAuthenticator:   webAuthenticator,
+		Webhooks:           webhooksManager,
 	}, nil
 }
 
@@ -420,10 +659,15 @@ func (s *Server) Start() error {
 	s.Router.HandleFunc("/", s.Index).Methods("GET").MatcherFunc(func(r *http.Request, rm *mux.RouteMatch) bool {
 		return r.URL.Path == "/" || r.URL.Path == "/index.html"
 	})
+	s.Router.HandleFunc("/pulls", s.Pulls).Methods("GET")
 	s.Router.HandleFunc("/healthz", s.Healthz).Methods("GET")
+	s.Router.HandleFunc("/readyz", s.Readyz).Methods("GET")
 	s.Router.PathPrefix("/static/").Handler(http.FileServer(&assetfs.AssetFS{Asset: static.Asset, AssetDir: static.AssetDir, AssetInfo: static.AssetInfo}))
 	s.Router.HandleFunc("/events", s.EventsController.Post).Methods("POST")
 	s.Router.HandleFunc("/locks", s.LocksController.DeleteLock).Methods("DELETE").Queries("id", "{id:.*}")
+	s.Router.HandleFunc(ProjectOutputsPath, s.OutputsController.GetProjectOutputs).Methods("GET")
+	s.Router.HandleFunc(JobsPathPrefix+"{id}", s.JobsController.GetJob).Methods("GET")
+	s.Router.HandleFunc(JobsPathPrefix+"{id}/stream", s.JobsController.GetJobStream).Methods("GET")
 	s.Router.HandleFunc("/lock", s.LocksController.GetLock).Methods("GET").
 		Queries(LockViewRouteIDQueryParam, fmt.Sprintf("{%s}", LockViewRouteIDQueryParam)).Name(LockViewRouteName)
 	n := negroni.New(&negroni.Recovery{
@@ -432,6 +676,9 @@ func (s *Server) Start() error {
 		StackAll:   false,
 		StackSize:  1024 * 8,
 	}, NewRequestLogger(s.Logger))
+	if s.WebAuthenticator != nil {
+		n.Use(&AuthMiddleware{Authenticator: s.WebAuthenticator, Logger: s.Logger})
+	}
 	n.UseHandler(s.Router)
 
 	// Ensure server gracefully drains connections when stopped.
@@ -461,6 +708,12 @@ func (s *Server) Start() error {
 	if err := server.Shutdown(ctx); err != nil {
 		return cli.NewExitError(fmt.Sprintf("while shutting down: %s", err), 1)
 	}
+	// Webhooks are delivered in the background so wait for the queued ones.
+	webhooksCtx, cancel := context.WithTimeout(context.Background(), webhooksShutdownTimeout)
+	defer cancel()
+	if err := s.Webhooks.Close(webhooksCtx); err != nil {
+		s.Logger.Warn("not all webhooks were delivered: %s", err)
+	}
 	return nil
 }
 
@@ -502,20 +755,73 @@ func (s *Server) Index(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
-// Healthz returns the health check response. It always returns a 200 currently.
-func (s *Server) Healthz(w http.ResponseWriter, _ *http.Request) {
-	data, err := json.MarshalIndent(&struct {
-		Status string `json:"status"`
-	}{
-		Status: "ok",
-	}, "", "  ")
+// Pulls is the /pulls route. It lists the pull requests Atlantis has run
+// commands on along with the status of each project. The results can be
+// filtered with the repo and status query parameters.
+func (s *Server) Pulls(w http.ResponseWriter, r *http.Request) {
+	statuses, err := s.DB.ListPullStatuses()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		fmt.Fprintf(w, "Error creating status json response: %s", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "Could not retrieve pull requests: %s", err)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(data) // nolint: errcheck
+
+	repoFilter := r.URL.Query().Get(PullsRepoQueryParam)
+	statusFilter := r.URL.Query().Get(PullsStatusQueryParam)
+	repoSet := make(map[string]bool)
+	var pullResults []PullIndexData
+	for _, ps := range statuses {
+		repoSet[ps.Pull.BaseRepo.FullName] = true
+		if repoFilter != "" && ps.Pull.BaseRepo.FullName != repoFilter {
+			continue
+		}
+		var projects []ProjectIndexData
+		matchesStatus := statusFilter == ""
+		for _, p := range ps.Projects {
+			projects = append(projects, ProjectIndexData{
+				RepoRelDir:  p.RepoRelDir,
+				Workspace:   p.Workspace,
+				ProjectName: p.ProjectName,
+				Status:      p.Status.String(),
+			})
+			if p.Status.String() == statusFilter {
+				matchesStatus = true
+			}
+		}
+		if !matchesStatus {
+			continue
+		}
+		pullResults = append(pullResults, PullIndexData{
+			RepoFullName:       ps.Pull.BaseRepo.FullName,
+			PullNum:            ps.Pull.Num,
+			PullURL:            ps.Pull.URL,
+			Author:             ps.Pull.Author,
+			Projects:           projects,
+			UpdatedAt:          ps.UpdatedAt,
+			UpdatedAtFormatted: ps.UpdatedAt.Local().Format("02-01-2006 15:04:05"),
+		})
+	}
+
+	// Sort by date - most recently updated first.
+	sort.SliceStable(pullResults, func(i, j int) bool { return pullResults[i].UpdatedAt.After(pullResults[j].UpdatedAt) })
+	var repos []string
+	for repo := range repoSet {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	err = s.PullsTemplate.Execute(w, PullsData{
+		Pulls:           pullResults,
+		Repos:           repos,
+		Statuses:        pullStatusFilters,
+		RepoFilter:      repoFilter,
+		StatusFilter:    statusFilter,
+		AtlantisVersion: s.AtlantisVersion,
+		CleanedBasePath: s.AtlantisURL.Path,
+	})
+	if err != nil {
+		s.Logger.Err(err.Error())
+	}
 }
 
 // ParseAtlantisURL parses the user-passed atlantis URL to ensure it is valid
@@ -16,6 +16,7 @@ package server_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -26,6 +27,7 @@ import (
 	"time"
 
 	"github.com/runatlantis/atlantis/server/events"
+	"github.com/runatlantis/atlantis/server/events/db"
 	"github.com/runatlantis/atlantis/server/events/yaml/valid"
 
 	"github.com/gorilla/mux"
@@ -34,6 +36,7 @@ import (
 	"github.com/runatlantis/atlantis/server/events/locking/mocks"
 	"github.com/runatlantis/atlantis/server/events/models"
 	sMocks "github.com/runatlantis/atlantis/server/mocks"
+	"github.com/runatlantis/atlantis/server/mocks/matchers"
 	. "github.com/runatlantis/atlantis/testing"
 )
 
@@ -156,6 +159,121 @@ func TestIndex_Success(t *testing.T) {
 	responseContains(t, w, http.StatusOK, "")
 }
 
+func TestPulls_Filters(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+
+	addPull := func(repo string, num int, res models.ProjectResult) {
+		_, err := boltdb.UpdatePullWithResults(models.PullRequest{
+			Num:        num,
+			HeadCommit: "sha",
+			URL:        fmt.Sprintf("https://github.com/%s/pull/%d", repo, num),
+			Author:     "lkysow",
+			BaseRepo: models.Repo{
+				FullName: repo,
+				VCSHost:  models.VCSHost{Hostname: "github.com", Type: models.Github},
+			},
+		}, []models.ProjectResult{res})
+		Ok(t, err)
+	}
+	planned := models.ProjectResult{Command: models.PlanCommand, RepoRelDir: ".", Workspace: "default", PlanSuccess: &models.PlanSuccess{}}
+	errored := models.ProjectResult{Command: models.PlanCommand, RepoRelDir: "dir", Workspace: "staging", Failure: "failure"}
+	addPull("owner/repo1", 1, planned)
+	addPull("owner/repo1", 2, errored)
+	addPull("owner/repo2", 3, planned)
+
+	u, err := url.Parse("https://example.com/basepath")
+	Ok(t, err)
+	cases := []struct {
+		query    string
+		expPulls []int
+	}{
+		{"", []int{3, 2, 1}},
+		{"?repo=owner/repo1", []int{2, 1}},
+		{"?status=planned", []int{3, 1}},
+		{"?repo=owner/repo1&status=plan_errored", []int{2}},
+		{"?repo=owner/other", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			RegisterMockTestingT(t)
+			pt := sMocks.NewMockTemplateWriter()
+			s := server.Server{
+				DB:              boltdb,
+				PullsTemplate:   pt,
+				AtlantisVersion: "0.3.1",
+				AtlantisURL:     u,
+			}
+			req, _ := http.NewRequest("GET", "/pulls"+c.query, bytes.NewBuffer(nil))
+			w := httptest.NewRecorder()
+			s.Pulls(w, req)
+			_, data := pt.VerifyWasCalledOnce().Execute(matchers.AnyIoWriter(), AnyInterface()).GetCapturedArguments()
+			pullsData := data.(server.PullsData)
+
+			var pullNums []int
+			for _, p := range pullsData.Pulls {
+				pullNums = append(pullNums, p.PullNum)
+			}
+			Equals(t, c.expPulls, pullNums)
+			Equals(t, []string{"owner/repo1", "owner/repo2"}, pullsData.Repos)
+			Equals(t, "/basepath", pullsData.CleanedBasePath)
+		})
+	}
+}
+
+func TestPulls_ProjectData(t *testing.T) {
+	RegisterMockTestingT(t)
+	tmp, cleanup := TempDir(t)
+	defer cleanup()
+	boltdb, err := db.New(tmp)
+	Ok(t, err)
+	_, err = boltdb.UpdatePullWithResults(models.PullRequest{
+		Num:        9,
+		HeadCommit: "sha",
+		URL:        "https://github.com/lkysow/atlantis-example/pull/9",
+		Author:     "lkysow",
+		BaseRepo: models.Repo{
+			FullName: "lkysow/atlantis-example",
+			VCSHost:  models.VCSHost{Hostname: "github.com", Type: models.Github},
+		},
+	}, []models.ProjectResult{
+		{RepoRelDir: ".", Workspace: "default", ProjectName: "root", ApplySuccess: "success"},
+		{Command: models.PlanCommand, RepoRelDir: "dir", Workspace: "staging", Error: errors.New("err")},
+	})
+	Ok(t, err)
+
+	pt := sMocks.NewMockTemplateWriter()
+	u, err := url.Parse("https://example.com")
+	Ok(t, err)
+	s := server.Server{
+		DB:              boltdb,
+		PullsTemplate:   pt,
+		AtlantisVersion: "0.3.1",
+		AtlantisURL:     u,
+	}
+	req, _ := http.NewRequest("GET", "/pulls", bytes.NewBuffer(nil))
+	w := httptest.NewRecorder()
+	s.Pulls(w, req)
+	_, data := pt.VerifyWasCalledOnce().Execute(matchers.AnyIoWriter(), AnyInterface()).GetCapturedArguments()
+	pullsData := data.(server.PullsData)
+
+	Equals(t, 1, len(pullsData.Pulls))
+	pull := pullsData.Pulls[0]
+	Equals(t, "lkysow/atlantis-example", pull.RepoFullName)
+	Equals(t, 9, pull.PullNum)
+	Equals(t, "https://github.com/lkysow/atlantis-example/pull/9", pull.PullURL)
+	Equals(t, "lkysow", pull.Author)
+	Assert(t, !pull.UpdatedAt.IsZero(), "exp UpdatedAt to be set")
+	Equals(t, []server.ProjectIndexData{
+		{RepoRelDir: ".", Workspace: "default", ProjectName: "root", Status: "applied"},
+		{RepoRelDir: "dir", Workspace: "staging", Status: "plan_errored"},
+	}, pull.Projects)
+	Equals(t, []string{"plan_errored", "planned", "apply_errored", "applied"}, pullsData.Statuses)
+}
+
 func TestHealthz(t *testing.T) {
 	s := server.Server{}
 	req, _ := http.NewRequest("GET", "/healthz", bytes.NewBuffer(nil))
@@ -24,10 +24,17 @@ type UserConfig struct {
 	DataDir                    string `mapstructure:"data-dir"`
 	DisableApplyAll            bool   `mapstructure:"disable-apply-all"`
 	DisableMarkdownFolding     bool   `mapstructure:"disable-markdown-folding"`
+	GithubAppID                int    `mapstructure:"gh-app-id"`
+	GithubAppKeyFile           string `mapstructure:"gh-app-key-file"`
+	GithubAppSlug              string `mapstructure:"gh-app-slug"`
 	GithubHostname             string `mapstructure:"gh-hostname"`
 	GithubToken                string `mapstructure:"gh-token"`
 	GithubUser                 string `mapstructure:"gh-user"`
 	GithubWebhookSecret        string `mapstructure:"gh-webhook-secret"`
+	GiteaBaseURL               string `mapstructure:"gitea-base-url"`
+	GiteaToken                 string `mapstructure:"gitea-token"`
+	GiteaUser                  string `mapstructure:"gitea-user"`
+	GiteaWebhookSecret         string `mapstructure:"gitea-webhook-secret"`
 	GitlabHostname             string `mapstructure:"gitlab-hostname"`
 	GitlabToken                string `mapstructure:"gitlab-token"`
 	GitlabUser                 string `mapstructure:"gitlab-user"`
@@ -35,9 +42,19 @@ type UserConfig struct {
 	HidePrevPlanComments       bool   `mapstructure:"hide-prev-plan-comments"`
 	LogLevel                   string `mapstructure:"log-level"`
 	Port                       int    `mapstructure:"port"`
-	RepoConfig                 string `mapstructure:"repo-config"`
-	RepoConfigJSON             string `mapstructure:"repo-config-json"`
-	RepoWhitelist              string `mapstructure:"repo-whitelist"`
+	// ProjectStatuses is whether to set a commit status for each project
+	// that links to its output while commands run.
+	ProjectStatuses bool `mapstructure:"project-statuses"`
+	// RedactEnvVars is a comma separated list of environment variable names
+	// whose values should be redacted from output posted to pull requests.
+	RedactEnvVars string `mapstructure:"redact-env-vars"`
+	// RedactPatterns are regexes for secrets that should be redacted from
+	// output posted to pull requests. They can only be set in the config
+	// file since regexes commonly contain commas.
+	RedactPatterns []string `mapstructure:"redact-patterns"`
+	RepoConfig     string   `mapstructure:"repo-config"`
+	RepoConfigJSON string   `mapstructure:"repo-config-json"`
+	RepoWhitelist  string   `mapstructure:"repo-whitelist"`
 	// RequireApproval is whether to require pull request approval before
 	// allowing terraform apply's to be run.
 	RequireApproval bool `mapstructure:"require-approval"`
@@ -50,13 +67,25 @@ type UserConfig struct {
 	SilenceVCSStatusNoPlans bool            `mapstructure:"silence-vcs-status-no-plans"`
 	SilenceWhitelistErrors  bool            `mapstructure:"silence-whitelist-errors"`
 	SlackToken              string          `mapstructure:"slack-token"`
+	SMTPFrom                string          `mapstructure:"smtp-from"`
+	SMTPHost                string          `mapstructure:"smtp-host"`
+	SMTPPassword            string          `mapstructure:"smtp-password"`
+	SMTPRequireTLS          bool            `mapstructure:"smtp-require-tls"`
+	SMTPUser                string          `mapstructure:"smtp-user"`
 	SSLCertFile             string          `mapstructure:"ssl-cert-file"`
 	SSLKeyFile              string          `mapstructure:"ssl-key-file"`
+	StickyComments          string          `mapstructure:"sticky-comments"`
 	TFDownloadURL           string          `mapstructure:"tf-download-url"`
 	TFEHostname             string          `mapstructure:"tfe-hostname"`
 	TFEToken                string          `mapstructure:"tfe-token"`
 	VCSStatusName           string          `mapstructure:"vcs-status-name"`
 	DefaultTFVersion        string          `mapstructure:"default-tf-version"`
+	WebBasicAuth            bool            `mapstructure:"web-basic-auth"`
+	WebUsername             string          `mapstructure:"web-username"`
+	WebPassword             string          `mapstructure:"web-password"`
+	WebOIDCIssuerURL        string          `mapstructure:"web-oidc-issuer-url"`
+	WebOIDCClientID         string          `mapstructure:"web-oidc-client-id"`
+	WebOIDCClientSecret     string          `mapstructure:"web-oidc-client-secret"`
 	Webhooks                []WebhookConfig `mapstructure:"webhooks"`
 	WriteGitCreds           bool            `mapstructure:"write-git-creds"`
 }
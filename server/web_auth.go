@@ -0,0 +1,381 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/runatlantis/atlantis/server/logging"
+)
+
+const (
+	// OIDCCallbackPath is the path the identity provider redirects back to
+	// after the user has logged in. It must be registered as a redirect URI
+	// with the identity provider, ex. https://atlantis.example.com/auth/callback.
+	OIDCCallbackPath = "/auth/callback"
+
+	sessionCookieName = "atlantis_session"
+	stateCookieName   = "atlantis_oauth_state"
+	sessionDuration   = 12 * time.Hour
+	stateDuration     = 10 * time.Minute
+
+	// Signed values are prefixed with their purpose so a value signed for
+	// one cookie can't be used as another, ex. a state cookie, which is
+	// given to anyone, as a session cookie.
+	sessionPurpose = "session"
+	statePurpose   = "state"
+)
+
+// authExemptPaths are never authenticated. Webhooks are validated using their
+// own secrets and health checks must work for load balancers.
+var authExemptPaths = []string{"/events", "/healthz", "/readyz"}
+
+// authUserKey is the context key for the authenticated username.
+type authUserKey struct{}
+
+// WebAuthenticator authenticates requests to the web UI.
+type WebAuthenticator interface {
+	// Authenticate returns the username of the user making the request. If
+	// the request isn't authenticated, it writes a response (ex. a login
+	// challenge or redirect) and returns false.
+	Authenticate(w http.ResponseWriter, r *http.Request) (string, bool)
+}
+
+// AuthMiddleware is negroni middleware that requires all requests, except
+// those to authExemptPaths, to be authenticated.
+type AuthMiddleware struct {
+	Authenticator WebAuthenticator
+	Logger        *logging.SimpleLogger
+}
+
+// ServeHTTP implements the middleware function.
+func (a *AuthMiddleware) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	for _, p := range authExemptPaths {
+		if r.URL.Path == p {
+			next(rw, r)
+			return
+		}
+	}
+	username, ok := a.Authenticator.Authenticate(rw, r)
+	if !ok {
+		return
+	}
+	a.Logger.Debug("authenticated %s %s as %q", r.Method, r.URL.Path, username)
+	next(rw, r.WithContext(context.WithValue(r.Context(), authUserKey{}, username)))
+}
+
+// AuthenticatedUser returns the username set by AuthMiddleware or an empty
+// string if authentication isn't enabled.
+func AuthenticatedUser(r *http.Request) string {
+	username, _ := r.Context().Value(authUserKey{}).(string)
+	return username
+}
+
+// BasicAuthenticator authenticates using HTTP basic auth with a single
+// username and password.
+type BasicAuthenticator struct {
+	Username string
+	Password string
+}
+
+// Authenticate implements WebAuthenticator.
+func (b *BasicAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user, pass, ok := r.BasicAuth()
+	// Compare both even if the username doesn't match so we don't leak
+	// which one was wrong through timing.
+	userMatch := subtle.ConstantTimeCompare([]byte(user), []byte(b.Username)) == 1
+	passMatch := subtle.ConstantTimeCompare([]byte(pass), []byte(b.Password)) == 1
+	if ok && userMatch && passMatch {
+		return user, true
+	}
+	w.Header().Set("WWW-Authenticate", `Basic realm="Atlantis", charset="UTF-8"`)
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	return "", false
+}
+
+// OIDCAuthenticator authenticates users via the OpenID Connect (OAuth2)
+// authorization code flow. Logged in users are given a signed session cookie.
+type OIDCAuthenticator struct {
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the absolute URL of OIDCCallbackPath.
+	RedirectURL string
+	HTTPClient  *http.Client
+
+	authURL     string
+	tokenURL    string
+	userInfoURL string
+	// sessionKey signs session and state cookies. It's generated on
+	// startup so sessions don't survive restarts.
+	sessionKey []byte
+	// secureCookies is true if Atlantis is served over https.
+	secureCookies bool
+	// basePath is the path Atlantis is served under, ex. /basepath. It's
+	// prepended to the page we redirect back to after logging in.
+	basePath string
+}
+
+// oidcDiscovery is the subset of the discovery document we need.
+// See https://openid.net/specs/openid-connect-discovery-1_0.html.
+type oidcDiscovery struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// NewOIDCAuthenticator looks up the identity provider's endpoints using
+// OpenID Connect discovery. atlantisURL is used to construct the redirect URL.
+func NewOIDCAuthenticator(httpClient *http.Client, issuerURL string, clientID string, clientSecret string, atlantisURL *url.URL) (*OIDCAuthenticator, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	resp, err := httpClient.Get(discoveryURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "getting OIDC discovery document from %s", discoveryURL)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting OIDC discovery document from %s: unexpected status code %d", discoveryURL, resp.StatusCode)
+	}
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, errors.Wrapf(err, "parsing OIDC discovery document from %s", discoveryURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document from %s is missing the authorization, token or userinfo endpoint", discoveryURL)
+	}
+
+	sessionKey := make([]byte, 32)
+	if _, err := rand.Read(sessionKey); err != nil {
+		return nil, errors.Wrap(err, "generating session key")
+	}
+	return &OIDCAuthenticator{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   atlantisURL.String() + OIDCCallbackPath,
+		HTTPClient:    httpClient,
+		authURL:       discovery.AuthorizationEndpoint,
+		tokenURL:      discovery.TokenEndpoint,
+		userInfoURL:   discovery.UserInfoEndpoint,
+		sessionKey:    sessionKey,
+		secureCookies: atlantisURL.Scheme == "https",
+		basePath:      atlantisURL.Path,
+	}, nil
+}
+
+// Authenticate implements WebAuthenticator. Requests with a valid session
+// cookie are authenticated. Requests to OIDCCallbackPath complete the login.
+// All other requests are redirected to the identity provider.
+func (o *OIDCAuthenticator) Authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.URL.Path == OIDCCallbackPath {
+		o.handleCallback(w, r)
+		return "", false
+	}
+	if c, err := r.Cookie(sessionCookieName); err == nil {
+		if username, err := o.verify(sessionPurpose, c.Value); err == nil {
+			return username, true
+		}
+	}
+
+	// Only redirect browser navigations. API calls like DELETE /locks
+	// can't follow the login flow.
+	if r.Method != http.MethodGet {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	state, err := randomString()
+	if err != nil {
+		http.Error(w, "Failed to generate login state", http.StatusInternalServerError)
+		return "", false
+	}
+	// The state cookie holds the state and the page to return to after
+	// logging in.
+	o.setCookie(w, stateCookieName, o.sign(statePurpose, state+"|"+r.URL.RequestURI(), stateDuration), stateDuration)
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {o.ClientID},
+		"redirect_uri":  {o.RedirectURL},
+		"scope":         {"openid profile email"},
+		"state":         {state},
+	}
+	http.Redirect(w, r, o.authURL+"?"+params.Encode(), http.StatusFound)
+	return "", false
+}
+
+func (o *OIDCAuthenticator) handleCallback(w http.ResponseWriter, r *http.Request) {
+	c, err := r.Cookie(stateCookieName)
+	if err != nil {
+		http.Error(w, "Missing login state, try again", http.StatusBadRequest)
+		return
+	}
+	stateAndPath, err := o.verify(statePurpose, c.Value)
+	if err != nil {
+		http.Error(w, "Invalid login state, try again", http.StatusBadRequest)
+		return
+	}
+	split := strings.SplitN(stateAndPath, "|", 2)
+	if len(split) != 2 || subtle.ConstantTimeCompare([]byte(split[0]), []byte(r.URL.Query().Get("state"))) != 1 {
+		http.Error(w, "Login state does not match, try again", http.StatusBadRequest)
+		return
+	}
+	if errMsg := r.URL.Query().Get("error"); errMsg != "" {
+		http.Error(w, fmt.Sprintf("Login failed: %s", errMsg), http.StatusUnauthorized)
+		return
+	}
+
+	username, err := o.exchange(r.URL.Query().Get("code"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Login failed: %s", err), http.StatusUnauthorized)
+		return
+	}
+	o.setCookie(w, stateCookieName, "", -1)
+	o.setCookie(w, sessionCookieName, o.sign(sessionPurpose, username, sessionDuration), sessionDuration)
+
+	returnTo := split[1]
+	// Only redirect to local paths.
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") {
+		returnTo = "/"
+	}
+	http.Redirect(w, r, o.basePath+returnTo, http.StatusFound)
+}
+
+// exchange exchanges the authorization code for an access token and uses it
+// to look up the username.
+func (o *OIDCAuthenticator) exchange(code string) (string, error) {
+	if code == "" {
+		return "", errors.New("no authorization code")
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {o.RedirectURL},
+	}
+	req, err := http.NewRequest("POST", o.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := o.doJSON(req, &token); err != nil {
+		return "", errors.Wrap(err, "exchanging authorization code")
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("no access token in token response")
+	}
+
+	req, err = http.NewRequest("GET", o.userInfoURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	var userInfo struct {
+		Subject           string `json:"sub"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+	}
+	if err := o.doJSON(req, &userInfo); err != nil {
+		return "", errors.Wrap(err, "getting user info")
+	}
+	for _, name := range []string{userInfo.PreferredUsername, userInfo.Email, userInfo.Subject} {
+		if name != "" {
+			return name, nil
+		}
+	}
+	return "", errors.New("user info has no username")
+}
+
+func (o *OIDCAuthenticator) doJSON(req *http.Request, v interface{}) error {
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, v)
+}
+
+func (o *OIDCAuthenticator) setCookie(w http.ResponseWriter, name string, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   o.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// sign returns value with an expiry and signature appended. The signature
+// also covers purpose, which verify must be called with.
+func (o *OIDCAuthenticator) sign(purpose string, value string, validFor time.Duration) string {
+	payload := purpose + ":" + base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + strconv.FormatInt(time.Now().Add(validFor).Unix(), 10)
+	return payload + "." + o.mac(payload)
+}
+
+// verify returns the value signed by sign if the signature is valid, it was
+// signed for purpose and it hasn't expired.
+func (o *OIDCAuthenticator) verify(purpose string, signed string) (string, error) {
+	idx := strings.LastIndex(signed, ".")
+	if idx < 0 {
+		return "", errors.New("malformed")
+	}
+	payload, sig := signed[:idx], signed[idx+1:]
+	if !hmac.Equal([]byte(sig), []byte(o.mac(payload))) {
+		return "", errors.New("invalid signature")
+	}
+	if !strings.HasPrefix(payload, purpose+":") {
+		return "", errors.New("wrong purpose")
+	}
+	split := strings.SplitN(strings.TrimPrefix(payload, purpose+":"), ".", 2)
+	if len(split) != 2 {
+		return "", errors.New("malformed")
+	}
+	expiry, err := strconv.ParseInt(split[1], 10, 64)
+	if err != nil || time.Now().Unix() > expiry {
+		return "", errors.New("expired")
+	}
+	value, err := base64.RawURLEncoding.DecodeString(split[0])
+	if err != nil {
+		return "", errors.New("malformed")
+	}
+	return string(value), nil
+}
+
+func (o *OIDCAuthenticator) mac(payload string) string {
+	h := hmac.New(sha256.New, o.sessionKey)
+	h.Write([]byte(payload)) // nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,250 @@
+package server_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/runatlantis/atlantis/server"
+	"github.com/runatlantis/atlantis/server/logging"
+	. "github.com/runatlantis/atlantis/testing"
+)
+
+func TestAuthMiddleware_ExemptPaths(t *testing.T) {
+	m := server.AuthMiddleware{
+		Authenticator: &server.BasicAuthenticator{Username: "user", Password: "pass"},
+		Logger:        logging.NewNoopLogger(),
+	}
+	for _, path := range []string{"/events", "/healthz", "/readyz"} {
+		t.Run(path, func(t *testing.T) {
+			called := false
+			req, _ := http.NewRequest("GET", path, nil)
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, req, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				Equals(t, "", server.AuthenticatedUser(r))
+			})
+			Assert(t, called, "expected next to be called for %s", path)
+		})
+	}
+}
+
+func TestAuthMiddleware_BasicAuth(t *testing.T) {
+	m := server.AuthMiddleware{
+		Authenticator: &server.BasicAuthenticator{Username: "user", Password: "pass"},
+		Logger:        logging.NewNoopLogger(),
+	}
+	cases := []struct {
+		description string
+		username    string
+		password    string
+		setAuth     bool
+		expAuthed   bool
+	}{
+		{"no credentials", "", "", false, false},
+		{"wrong username", "other", "pass", true, false},
+		{"wrong password", "user", "wrong", true, false},
+		{"correct credentials", "user", "pass", true, true},
+	}
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			if c.setAuth {
+				req.SetBasicAuth(c.username, c.password)
+			}
+			w := httptest.NewRecorder()
+			var user string
+			called := false
+			m.ServeHTTP(w, req, func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				user = server.AuthenticatedUser(r)
+			})
+			Equals(t, c.expAuthed, called)
+			if c.expAuthed {
+				Equals(t, c.username, user)
+			} else {
+				Equals(t, http.StatusUnauthorized, w.Code)
+				Assert(t, strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic"), "expected basic auth challenge")
+			}
+		})
+	}
+}
+
+func TestNewOIDCAuthenticator_DiscoveryErr(t *testing.T) {
+	idp := httptest.NewServer(http.NotFoundHandler())
+	defer idp.Close()
+	atlantisURL, _ := url.Parse("https://atlantis.example.com")
+	_, err := server.NewOIDCAuthenticator(nil, idp.URL, "id", "secret", atlantisURL)
+	ErrContains(t, "unexpected status code 404", err)
+}
+
+func TestOIDCAuthenticator_LoginFlow(t *testing.T) {
+	idp := newTestIdP()
+	defer idp.Close()
+	atlantisURL, _ := url.Parse("https://atlantis.example.com/basepath")
+	auth, err := server.NewOIDCAuthenticator(nil, idp.URL, "client-id", "client-secret", atlantisURL)
+	Ok(t, err)
+	m := server.AuthMiddleware{
+		Authenticator: auth,
+		Logger:        logging.NewNoopLogger(),
+	}
+	next := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, server.AuthenticatedUser(r))
+	}
+
+	// Unauthenticated GET requests should be redirected to the IdP.
+	req, _ := http.NewRequest("GET", "/lock?id=abc", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusFound, w.Code)
+	redirect, err := url.Parse(w.Header().Get("Location"))
+	Ok(t, err)
+	Equals(t, idp.URL+"/authorize", fmt.Sprintf("%s://%s%s", redirect.Scheme, redirect.Host, redirect.Path))
+	Equals(t, "client-id", redirect.Query().Get("client_id"))
+	Equals(t, "https://atlantis.example.com/basepath/auth/callback", redirect.Query().Get("redirect_uri"))
+	state := redirect.Query().Get("state")
+	Assert(t, state != "", "expected state to be set")
+	stateCookies := w.Result().Cookies()
+
+	// Unauthenticated non-GET requests should be rejected.
+	req, _ = http.NewRequest("DELETE", "/locks?id=abc", nil)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusUnauthorized, w.Code)
+
+	// A callback with the wrong state should be rejected.
+	req, _ = http.NewRequest("GET", "/auth/callback?code=good-code&state=wrong", nil)
+	for _, c := range stateCookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusBadRequest, w.Code)
+
+	// The callback should set the session cookie and redirect back.
+	req, _ = http.NewRequest("GET", "/auth/callback?code=good-code&state="+url.QueryEscape(state), nil)
+	for _, c := range stateCookies {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusFound, w.Code)
+	Equals(t, "/basepath/lock?id=abc", w.Header().Get("Location"))
+	var session *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "atlantis_session" {
+			session = c
+		}
+	}
+	Assert(t, session != nil, "expected session cookie to be set")
+	Assert(t, session.Secure, "expected session cookie to be secure")
+
+	// Requests with the session cookie should be authenticated.
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.AddCookie(session)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusOK, w.Code)
+	Equals(t, "jdoe", w.Body.String())
+
+	// Tampered session cookies should not be.
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "atlantis_session", Value: strings.Replace(session.Value, "a", "b", 1) + "x"})
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, next)
+	Equals(t, http.StatusFound, w.Code)
+}
+
+// A state cookie is handed to anyone so it must not be accepted as a session
+// cookie.
+func TestOIDCAuthenticator_StateCookieAsSession(t *testing.T) {
+	idp := newTestIdP()
+	defer idp.Close()
+	atlantisURL, _ := url.Parse("http://atlantis.example.com")
+	auth, err := server.NewOIDCAuthenticator(nil, idp.URL, "client-id", "client-secret", atlantisURL)
+	Ok(t, err)
+	m := server.AuthMiddleware{
+		Authenticator: auth,
+		Logger:        logging.NewNoopLogger(),
+	}
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req, func(w http.ResponseWriter, r *http.Request) {})
+	var state *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "atlantis_oauth_state" {
+			state = c
+		}
+	}
+	Assert(t, state != nil, "expected state cookie to be set")
+
+	called := false
+	req, _ = http.NewRequest("DELETE", "/locks?id=abc", nil)
+	req.AddCookie(&http.Cookie{Name: "atlantis_session", Value: state.Value})
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, req, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	Assert(t, !called, "expected state cookie to be rejected as a session")
+	Equals(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestOIDCAuthenticator_BadCode(t *testing.T) {
+	idp := newTestIdP()
+	defer idp.Close()
+	atlantisURL, _ := url.Parse("http://atlantis.example.com")
+	auth, err := server.NewOIDCAuthenticator(nil, idp.URL, "client-id", "client-secret", atlantisURL)
+	Ok(t, err)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	auth.Authenticate(w, req)
+	redirect, err := url.Parse(w.Header().Get("Location"))
+	Ok(t, err)
+
+	req, _ = http.NewRequest("GET", "/auth/callback?code=bad-code&state="+url.QueryEscape(redirect.Query().Get("state")), nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	_, ok := auth.Authenticate(w, req)
+	Equals(t, false, ok)
+	Equals(t, http.StatusUnauthorized, w.Code)
+	Assert(t, strings.Contains(w.Body.String(), "exchanging authorization code"), "got body %q", w.Body.String())
+}
+
+// newTestIdP returns a server that acts as an OpenID Connect identity
+// provider. It accepts the authorization code "good-code".
+func newTestIdP() *httptest.Server {
+	var idp *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{ // nolint: errcheck
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"userinfo_endpoint":      idp.URL + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client-id" || secret != "client-secret" || r.PostFormValue("code") != "good-code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"token","token_type":"Bearer"}`)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"sub":"123","preferred_username":"jdoe","email":"jdoe@example.com"}`)
+	})
+	idp = httptest.NewServer(mux)
+	return idp
+}
@@ -17,6 +17,8 @@ import (
 	"html/template"
 	"io"
 	"time"
+
+	"github.com/runatlantis/atlantis/server/jobs"
 )
 
 //go:generate pegomock generate -m --use-experimental-model-gen --package mocks -o mocks/mock_template_writer.go TemplateWriter
@@ -82,6 +84,7 @@ var indexTemplate = template.Must(template.New("index.html.tmpl").Parse(`
   </section>
   <nav class="navbar">
     <div class="container">
+      <a href="{{ .CleanedBasePath }}/pulls">Pull Requests</a>
     </div>
   </nav>
   <div class="navbar-spacer"></div>
@@ -111,6 +114,124 @@ v{{ .AtlantisVersion }}
 </html>
 `))
 
+// PullIndexData holds the fields needed to display a pull request in the
+// pulls view.
+type PullIndexData struct {
+	RepoFullName       string
+	PullNum            int
+	PullURL            string
+	Author             string
+	Projects           []ProjectIndexData
+	UpdatedAt          time.Time
+	UpdatedAtFormatted string
+}
+
+// ProjectIndexData holds the fields needed to display a project's status in
+// the pulls view.
+type ProjectIndexData struct {
+	RepoRelDir  string
+	Workspace   string
+	ProjectName string
+	// Status is the string form of models.ProjectPlanStatus, ex. "planned".
+	Status string
+}
+
+// PullsData holds the data for rendering the pulls page.
+type PullsData struct {
+	Pulls []PullIndexData
+	// Repos are all the repos with pull requests, used for filtering.
+	Repos []string
+	// Statuses are all the project statuses, used for filtering.
+	Statuses []string
+	// RepoFilter and StatusFilter are the currently selected filters. They're
+	// empty if not filtering.
+	RepoFilter      string
+	StatusFilter    string
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var pullsTemplate = template.Must(template.New("pulls.html.tmpl").Parse(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>atlantis</title>
+  <meta name="description" content="">
+  <meta name="author" content="">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/normalize.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/skeleton.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/custom.css">
+  <link rel="icon" type="image/png" href="{{ .CleanedBasePath }}/static/images/atlantis-icon.png">
+  <style>
+    .pull-row { height: auto; text-align: left; padding: 10px 20px; line-height: 2.5rem; }
+    .pull-projects { margin: 5px 0 0 0; }
+    .status { padding: 0.2rem 0.5rem; border-radius: 4px; color: #fff; }
+    .status-planned { background-color: #1d73b3; }
+    .status-applied { background-color: #2e8540; }
+    .status-plan_errored, .status-apply_errored { background-color: #cd2026; }
+  </style>
+</head>
+<body>
+<div class="container">
+  <section class="header">
+    <a title="atlantis" href="{{ .CleanedBasePath }}/"><img class="hero" src="{{ .CleanedBasePath }}/static/images/atlantis-icon_512.png"/></a>
+    <p class="title-heading">atlantis</p>
+  </section>
+  <nav class="navbar">
+    <div class="container">
+      <a href="{{ .CleanedBasePath }}/">Locks</a>
+    </div>
+  </nav>
+  <div class="navbar-spacer"></div>
+  <br>
+  <section>
+    <p class="title-heading small"><strong>Pull Requests</strong></p>
+    <form method="GET" action="{{ .CleanedBasePath }}/pulls">
+      {{ $repoFilter := .RepoFilter }}
+      {{ $statusFilter := .StatusFilter }}
+      <select name="repo">
+        <option value="">All repos</option>
+        {{ range .Repos }}
+        <option value="{{ . }}"{{ if eq . $repoFilter }} selected{{ end }}>{{ . }}</option>
+        {{ end }}
+      </select>
+      <select name="status">
+        <option value="">All statuses</option>
+        {{ range .Statuses }}
+        <option value="{{ . }}"{{ if eq . $statusFilter }} selected{{ end }}>{{ . }}</option>
+        {{ end }}
+      </select>
+      <input class="button-primary" type="submit" value="Filter">
+    </form>
+    {{ if .Pulls }}
+    {{ range .Pulls }}
+      <div class="twelve columns content pull-row">
+        <div class="list-title"><a href="{{ .PullURL }}" target="_blank">{{ .RepoFullName }} <span class="heading-font-size">#{{ .PullNum }}</span></a>{{ if .Author }} by {{ .Author }}{{ end }}</div>
+        <div class="list-timestamp"><span class="heading-font-size">{{ .UpdatedAtFormatted }}</span></div>
+        <ul class="pull-projects">
+        {{ range .Projects }}
+          <li>{{ if .ProjectName }}<code>{{ .ProjectName }}</code> {{ end }}dir: <code>{{ .RepoRelDir }}</code> workspace: <code>{{ .Workspace }}</code> <span class="status status-{{ .Status }}">{{ .Status }}</span></li>
+        {{ end }}
+        </ul>
+      </div>
+    {{ end }}
+    {{ else }}
+    <p class="placeholder">No pull requests found.</p>
+    {{ end }}
+  </section>
+</div>
+<footer>
+v{{ .AtlantisVersion }}
+</footer>
+</body>
+</html>
+`))
+
 // LockDetailData holds the fields needed to display the lock detail view.
 type LockDetailData struct {
 	LockKeyEncoded  string
@@ -118,6 +239,9 @@ type LockDetailData struct {
 	RepoOwner       string
 	RepoName        string
 	PullRequestLink string
+	// OutputsURL is the URL to the plan and apply history of the locked
+	// project. It's empty if unknown.
+	OutputsURL      string
 	LockedBy        string
 	Workspace       string
 	Time            time.Time
@@ -159,6 +283,7 @@ var lockTemplate = template.Must(template.New("lock.html.tmpl").Parse(`
         <h6><code>Pull Request Link</code>: <a href="{{.PullRequestLink}}" target="_blank"><strong>{{.PullRequestLink}}</strong></a></h6>
         <h6><code>Locked By</code>: <strong>{{.LockedBy}}</strong></h6>
         <h6><code>Workspace</code>: <strong>{{.Workspace}}</strong></h6>
+        {{ if .OutputsURL }}<h6><code>Plan History</code>: <a href="{{.OutputsURL}}"><strong>View previous plans and applies</strong></a></h6>{{ end }}
         <br>
       </div>
       <div class="four columns">
@@ -229,3 +354,166 @@ v{{ .AtlantisVersion }}
 </body>
 </html>
 `))
+
+// ProjectOutputData holds the fields needed to display a single plan or apply
+// output in the project outputs view.
+type ProjectOutputData struct {
+	Command       string
+	Status        string
+	HeadCommit    string
+	Output        string
+	Time          time.Time
+	TimeFormatted string
+	// Diff is the diff from the previous plan to this plan. It's empty if
+	// this isn't a plan or there's no previous plan.
+	Diff []DiffLine
+	// DiffCommit is the commit of the previous plan that Diff is against.
+	DiffCommit string
+}
+
+// ProjectOutputsData holds the data for rendering the project outputs view.
+type ProjectOutputsData struct {
+	RepoFullName string
+	PullNum      int
+	RepoRelDir   string
+	Workspace    string
+	ProjectName  string
+	// Outputs are sorted newest first.
+	Outputs         []ProjectOutputData
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var outputsTemplate = template.Must(template.New("outputs.html.tmpl").Parse(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>atlantis</title>
+  <meta name="description" content="">
+  <meta name="author" content="">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/normalize.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/skeleton.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/custom.css">
+  <link rel="icon" type="image/png" href="{{ .CleanedBasePath }}/static/images/atlantis-icon.png">
+  <style>
+    pre.output { white-space: pre-wrap; word-wrap: break-word; text-align: left; }
+    .diff-add { color: #2e8540; }
+    .diff-remove { color: #cd2026; }
+    details { margin-bottom: 2rem; text-align: left; }
+  </style>
+</head>
+<body>
+<div class="container">
+  <section class="header">
+    <a title="atlantis" href="{{ .CleanedBasePath }}/"><img class="hero" src="{{ .CleanedBasePath }}/static/images/atlantis-icon_512.png"/></a>
+    <p class="title-heading">atlantis</p>
+    <p class="title-heading"><strong>{{ .RepoFullName }} #{{ .PullNum }}</strong></p>
+    <p>{{ if .ProjectName }}project: <code>{{ .ProjectName }}</code> {{ end }}dir: <code>{{ .RepoRelDir }}</code> workspace: <code>{{ .Workspace }}</code></p>
+  </section>
+  <div class="navbar-spacer"></div>
+  <br>
+  <section>
+    {{ if .Outputs }}
+    {{ range $i, $output := .Outputs }}
+    <details{{ if eq $i 0 }} open{{ end }}>
+      <summary><strong>{{ $output.Command }}</strong> <code>{{ $output.Status }}</code> at commit <code>{{ $output.HeadCommit }}</code> <span class="heading-font-size">{{ $output.TimeFormatted }}</span>{{ if eq $i 0 }} (latest){{ end }}</summary>
+      <pre class="output">{{ $output.Output }}</pre>
+      {{ if $output.Diff }}
+      <details>
+        <summary>Changes since the previous plan at commit <code>{{ $output.DiffCommit }}</code></summary>
+        <pre class="output">{{ range $output.Diff }}<span class="{{ if eq .Kind "+" }}diff-add{{ else if eq .Kind "-" }}diff-remove{{ end }}">{{ .Kind }} {{ .Text }}</span>
+{{ end }}</pre>
+      </details>
+      {{ end }}
+    </details>
+    {{ end }}
+    {{ else }}
+    <p class="placeholder">No outputs found. Outputs are deleted when the pull request is closed.</p>
+    {{ end }}
+  </section>
+</div>
+<footer>
+v{{ .AtlantisVersion }}
+</footer>
+</body>
+</html>
+`))
+
+// JobData holds the data for rendering the job view.
+type JobData struct {
+	Info jobs.Info
+	// StreamURL is the path of the job's output stream.
+	StreamURL       string
+	AtlantisVersion string
+	// CleanedBasePath is the path Atlantis is accessible at externally. If
+	// not using a path-based proxy, this will be an empty string. Never ends
+	// in a '/' (hence "cleaned").
+	CleanedBasePath string
+}
+
+var jobTemplate = template.Must(template.New("job.html.tmpl").Parse(`
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>atlantis</title>
+  <meta name="description" content="">
+  <meta name="author" content="">
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/normalize.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/skeleton.css">
+  <link rel="stylesheet" href="{{ .CleanedBasePath }}/static/css/custom.css">
+  <link rel="icon" type="image/png" href="{{ .CleanedBasePath }}/static/images/atlantis-icon.png">
+  <style>
+    pre.output { white-space: pre-wrap; word-wrap: break-word; text-align: left; min-height: 10rem; }
+  </style>
+</head>
+<body>
+<div class="container">
+  <section class="header">
+    <a title="atlantis" href="{{ .CleanedBasePath }}/"><img class="hero" src="{{ .CleanedBasePath }}/static/images/atlantis-icon_512.png"/></a>
+    <p class="title-heading">atlantis</p>
+    <p class="title-heading"><strong>{{ .Info.RepoFullName }} <a href="{{ .Info.PullURL }}">#{{ .Info.PullNum }}</a></strong></p>
+    <p>{{ if .Info.ProjectName }}project: <code>{{ .Info.ProjectName }}</code> {{ end }}dir: <code>{{ .Info.RepoRelDir }}</code> workspace: <code>{{ .Info.Workspace }}</code></p>
+  </section>
+  <div class="navbar-spacer"></div>
+  <br>
+  <section>
+    <p><strong>{{ .Info.Command }}</strong> <code id="job-status">{{ if .Info.Complete }}complete{{ else }}running{{ end }}</code></p>
+    <pre class="output" id="job-output"></pre>
+  </section>
+</div>
+<footer>
+v{{ .AtlantisVersion }}
+</footer>
+<script>
+  (function() {
+    var output = document.getElementById("job-output");
+    var status = document.getElementById("job-status");
+    var source = new EventSource("{{ .StreamURL }}");
+    source.onopen = function() {
+      // The full output is re-sent on each (re)connect.
+      output.textContent = "";
+      status.textContent = "running";
+    };
+    source.onmessage = function(e) {
+      var atBottom = (window.innerHeight + window.scrollY) >= document.body.offsetHeight - 10;
+      output.appendChild(document.createTextNode(e.data + "\n"));
+      if (atBottom) {
+        window.scrollTo(0, document.body.scrollHeight);
+      }
+    };
+    source.addEventListener("complete", function() {
+      status.textContent = "complete";
+      source.close();
+    });
+  })();
+</script>
+</body>
+</html>
+`))